@@ -38,6 +38,8 @@ type Config struct {
 
 	MarketPriceWorkerConfig MarketPriceWorkerConfig `yaml:"market_price_worker_config"`
 
+	FiatCurrencyWorkerConfig FiatCurrencyWorkerConfig `yaml:"fiat_currency_worker_config"`
+
 	RpcConfig RpcConfig `yaml:"rpc_config"`
 	Chains    []string  `yaml:"chains"`
 }
@@ -45,6 +47,30 @@ type Config struct {
 type MarketPriceWorkerConfig struct {
 	LoopInterval time.Duration `yaml:"loop_interval"`
 	UseRedis     bool          `yaml:"use_redis"`
+
+	Resolver MarketResolverConfig `yaml:"resolver"` // how quotes of several sources are merged into one price
+
+	PushInterval time.Duration `yaml:"push_interval"` // min interval between two websocket pushes of one channel (default 1s)
+}
+
+// MarketResolverConfig configures the market price resolver
+type MarketResolverConfig struct {
+	Type         string        `yaml:"type"`          // weighted_median (default) / info_level
+	MaxAge       time.Duration `yaml:"max_age"`       // quotes older than this are ignored (default 2m)
+	MaxDeviation float64       `yaml:"max_deviation"` // max relative distance from the median before a quote is an outlier (default 0.05)
+	MinSources   int           `yaml:"min_sources"`   // min number of agreeing sources to publish a price (default 1)
+	CEXWeight    float64       `yaml:"cex_weight"`    // weight of CEX and aggregator sources (default 1)
+	DEXWeight    float64       `yaml:"dex_weight"`    // weight of DEX sources (default 0.5)
+	DEXSources   []string      `yaml:"dex_sources"`   // provider names treated as DEX (built-in DEX providers when empty)
+}
+
+// FiatCurrencyWorkerConfig configures the fiat currency rate worker
+type FiatCurrencyWorkerConfig struct {
+	LoopInterval time.Duration `yaml:"loop_interval"` // interval between two rate updates (default 10m)
+	Base         string        `yaml:"base"`          // base currency of the rates (default USD)
+	Currencies   []string      `yaml:"currencies"`    // quoted currencies (default CNY, EUR, JPY, KRW, HKD, GBP, SGD)
+	StaticFile   string        `yaml:"static_file"`   // JSON rate file used when no live provider has a currency
+	StaleAfter   time.Duration `yaml:"stale_after"`   // alarm when the latest live rate time is older than this (default 72h)
 }
 
 type RpcConfig struct {
@@ -139,12 +165,76 @@ type AggregatorConfig struct {
 	ChainConsumerTokens        map[string]string `yaml:"chain_consumer_tokens"`         // optional per-chain tokens keyed by chain_id
 	ZeroXAPIURL                string            `yaml:"zerox_api_url"`                 // 0x Protocol API URL
 	ZeroXAPIKey                string            `yaml:"zerox_api_key"`                 // 0x Protocol API Key
+	ZeroXUsePermit2            bool              `yaml:"zerox_use_permit2"`             // 0x permit2 flow instead of allowance-holder (Settler must be whitelisted)
+	ZeroXFeeRecipient          string            `yaml:"zerox_fee_recipient"`           // 0x integrator fee recipient (swapFeeRecipient)
+	ZeroXFeeBps                int               `yaml:"zerox_fee_bps"`                 // 0x integrator fee in bps (swapFeeBps)
+	ZeroXFeeToken              string            `yaml:"zerox_fee_token"`               // 0x fee token, buy token when empty (swapFeeToken)
+	ZeroXSurplusRecipient      string            `yaml:"zerox_surplus_recipient"`       // 0x positive slippage recipient (tradeSurplusRecipient)
 	OneInchAPIURL              string            `yaml:"oneinch_api_url"`               // 1inch API URL
 	OneInchAPIKey              string            `yaml:"oneinch_api_key"`               // 1inch API Key
+	OneInchFusionAPIURL        string            `yaml:"oneinch_fusion_api_url"`        // 1inch Fusion API URL (gasless orders, optional)
+	OneInchUsePermit2          bool              `yaml:"oneinch_use_permit2"`           // 1inch permit2 flow for ERC20 sells instead of approving the router
+	OneInchSolanaAPIURL        string            `yaml:"oneinch_solana_api_url"`        // 1inch Solana API URL
 	JupiterAPIURL              string            `yaml:"jupiter_api_url"`               // Jupiter API URL
 	LiFiAPIURL                 string            `yaml:"lifi_api_url"`                  // LiFi API URL
 	LiFiAPIKey                 string            `yaml:"lifi_api_key"`                  // LiFi API Key
 	EnableProviders            map[string]bool   `yaml:"enable_providers"`              // Enable/disable specific providers
+	Validator                  ValidatorConfig   `yaml:"validator"`                     // router/spender whitelist and value limits
+	Ranking                    RankingConfig     `yaml:"ranking"`                       // quote ranking strategy
+	Breaker                    BreakerConfig     `yaml:"breaker"`                       // per-provider circuit breaker
+	Quote                      QuoteConfig       `yaml:"quote"`                         // quote lifetime and refresh
+}
+
+// QuoteConfig configures quote lifetime and refresh
+type QuoteConfig struct {
+	TTLSeconds           int `yaml:"ttl_seconds"`            // how long a quote can be prepared (default 60)
+	RefreshWindowSeconds int `yaml:"refresh_window_seconds"` // how long after expiry a quote can still be refreshed (default 600)
+	DriftToleranceBps    int `yaml:"drift_tolerance_bps"`    // max output drop a refresh accepts without confirmation (default 50)
+}
+
+// BreakerConfig configures the per-provider circuit breaker
+type BreakerConfig struct {
+	FailureThreshold   int            `yaml:"failure_threshold"`    // consecutive failures that open the circuit (default 5)
+	ErrorRateThreshold float64        `yaml:"error_rate_threshold"` // window error rate that opens the circuit (default 0.5)
+	MinRequests        int            `yaml:"min_requests"`         // min window size before the error rate is used (default 20)
+	WindowSize         int            `yaml:"window_size"`          // number of recent calls kept for stats (default 100)
+	OpenSeconds        int            `yaml:"open_seconds"`         // how long the circuit stays open before a probe (default 30)
+	QuoteTimeoutMs     int            `yaml:"quote_timeout_ms"`     // per-provider quote deadline (default 5000)
+	ProviderTimeoutsMs map[string]int `yaml:"provider_timeouts_ms"` // per-provider quote deadline overrides keyed by provider name
+}
+
+// RankingConfig configures how quotes are ranked
+type RankingConfig struct {
+	DefaultStrategy    string                        `yaml:"default_strategy"`    // BEST_NET_OUTPUT (default) / LOWEST_GAS / PREFERRED_PROVIDER / MOST_RELIABLE
+	PreferredProviders []string                      `yaml:"preferred_providers"` // providers favoured by PREFERRED_PROVIDER
+	PreferredBiasBps   int                           `yaml:"preferred_bias_bps"`  // score bonus of a preferred provider in bps (50 when empty)
+	Chains             map[string]RankingChainConfig `yaml:"chains"`              // per-chain overrides keyed by chain_id
+}
+
+// RankingChainConfig holds per-chain ranking overrides
+type RankingChainConfig struct {
+	Strategy           string   `yaml:"strategy"`            // default strategy on this chain
+	PreferredProviders []string `yaml:"preferred_providers"` // preferred providers on this chain
+	GasPriceGwei       float64  `yaml:"gas_price_gwei"`      // fallback gas price used to cost gas estimates
+}
+
+// ValidatorConfig drives the aggregator tx validator
+type ValidatorConfig struct {
+	Routers     []string                        `yaml:"routers"`       // router whitelist applied to every EVM chain (built-in defaults when empty)
+	Spenders    []string                        `yaml:"spenders"`      // spender whitelist applied to every EVM chain (built-in defaults when empty)
+	Programs    []string                        `yaml:"programs"`      // program whitelist applied to every Solana chain (built-in defaults when empty)
+	MaxValueWei string                          `yaml:"max_value_wei"` // default native value cap per tx in wei (100 ETH when empty)
+	Chains      map[string]ValidatorChainConfig `yaml:"chains"`        // per-chain rules keyed by chain_id; listed chains are supported
+}
+
+// ValidatorChainConfig holds per-chain validation rules
+type ValidatorChainConfig struct {
+	Disabled        bool              `yaml:"disabled"`          // reject this chain even if enabled in the chain table
+	Routers         []string          `yaml:"routers"`           // extra routers allowed on this chain
+	Spenders        []string          `yaml:"spenders"`          // extra spenders allowed on this chain
+	Programs        []string          `yaml:"programs"`          // extra programs allowed on this Solana chain
+	MaxValueWei     string            `yaml:"max_value_wei"`     // native value cap per tx in wei, overrides the default
+	TokenMaxAmounts map[string]string `yaml:"token_max_amounts"` // max sell amount (smallest unit) keyed by token address
 }
 
 func New(path string) (*Config, error) {
@@ -24,6 +24,9 @@ type Chain struct {
 	WalletNetwork   string    `gorm:"column:wallet_network;type:varchar(64);default:''" json:"wallet_network"`
 	WalletCoin      string    `gorm:"column:wallet_coin;type:varchar(32);default:''" json:"wallet_coin"`
 	RpcURL          string    `gorm:"column:rpc_url;type:varchar(255);default:''" json:"rpc_url"`
+	RequiredConfs   int       `gorm:"column:required_confirmations;type:integer;default:0" json:"required_confirmations"` // 0 使用 worker 默认值
+	IsL2            bool      `gorm:"column:is_l2;type:boolean;default:false" json:"is_l2"`
+	BlockTimeMs     int       `gorm:"column:block_time_ms;type:integer;default:0" json:"block_time_ms"` // 0 使用 worker 默认值
 	IsEnabled       bool      `gorm:"column:is_enabled;type:boolean;default:true" json:"is_enabled"`
 	CreateTime      time.Time `gorm:"column:created_at;autoCreateTime" json:"create_time"`
 	UpdateTime      time.Time `gorm:"column:updated_at;autoUpdateTime" json:"update_time"`
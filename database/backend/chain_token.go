@@ -25,6 +25,7 @@ type ChainTokenView interface {
 	GetByGuid(guid string) (*ChainToken, error)
 	GetByChainID(chainID string) ([]*ChainToken, error)
 	GetByTokenID(tokenID string) ([]*ChainToken, error)
+	GetAllChainTokens() ([]*ChainToken, error)
 }
 
 type ChainTokenDB interface {
@@ -86,6 +87,15 @@ func (db *chainTokenDB) GetByTokenID(tokenID string) ([]*ChainToken, error) {
 	return list, nil
 }
 
+func (db *chainTokenDB) GetAllChainTokens() ([]*ChainToken, error) {
+	var list []*ChainToken
+	if err := db.gorm.Find(&list).Error; err != nil {
+		log.Error("GetAllChainTokens error", "err", err)
+		return nil, err
+	}
+	return list, nil
+}
+
 func (db *chainTokenDB) UpdateChainToken(guid string, updates map[string]interface{}) error {
 	if guid == "" {
 		return fmt.Errorf("invalid guid")
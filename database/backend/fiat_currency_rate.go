@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type FiatCurrencyRate struct {
@@ -15,6 +16,9 @@ type FiatCurrencyRate struct {
 	ValueData  string    `gorm:"column:value_data;type:varchar(255);not null" json:"value_data"`
 	CreateTime time.Time `gorm:"column:created_at;autoCreateTime" json:"create_time"`
 	UpdateTime time.Time `gorm:"column:updated_at;autoUpdateTime" json:"update_time"`
+
+	Source   string    `gorm:"column:source;type:varchar(64)" json:"source"` // 汇率来源 provider
+	RateTime time.Time `gorm:"column:rate_time" json:"rate_time"`            // 来源给出的汇率时间
 }
 
 func (FiatCurrencyRate) TableName() string {
@@ -32,6 +36,7 @@ type FiatCurrencyRateDB interface {
 	StoreFiatCurrencyRate(r *FiatCurrencyRate) error
 	StoreFiatCurrencyRates(list []*FiatCurrencyRate) error
 	UpdateFiatCurrencyRate(guid string, updates map[string]interface{}) error
+	UpsertRate(r *FiatCurrencyRate) error
 }
 
 type fiatCurrencyRateDB struct {
@@ -92,3 +97,24 @@ func (db *fiatCurrencyRateDB) UpdateFiatCurrencyRate(guid string, updates map[st
 	}
 	return nil
 }
+
+// UpsertRate writes the rate of r.KeyName, replacing value, source and rate time of an existing row
+func (db *fiatCurrencyRateDB) UpsertRate(r *FiatCurrencyRate) error {
+	if r.KeyName == "" {
+		return fmt.Errorf("key_name required")
+	}
+	err := db.gorm.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key_name"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"value_data": gorm.Expr("EXCLUDED.value_data"),
+			"source":     gorm.Expr("EXCLUDED.source"),
+			"rate_time":  gorm.Expr("EXCLUDED.rate_time"),
+			"updated_at": gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	}).Create(r).Error
+	if err != nil {
+		log.Error("UpsertRate FiatCurrencyRate error", "err", err)
+		return err
+	}
+	return nil
+}
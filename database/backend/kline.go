@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Kline struct {
@@ -39,6 +40,7 @@ type KlineView interface {
 		end time.Time,
 		limit int,
 	) ([]*Kline, error)
+	GetLatestKline(tokenID, interval string, before time.Time) (*Kline, error)
 }
 
 type KlineDB interface {
@@ -47,6 +49,7 @@ type KlineDB interface {
 	StoreKline(k *Kline) error
 	StoreKlines(list []*Kline) error
 	UpdateKline(guid string, updates map[string]interface{}) error
+	UpsertKlines(list []*Kline) error
 }
 
 type klineDB struct {
@@ -81,17 +84,44 @@ func (db *klineDB) GetKlines(
 		query = query.Where("open_time <= ?", end)
 	}
 
+	// 未指定开始时间时返回最近的 limit 根，仍按时间升序
+	order := "open_time ASC"
+	if start.IsZero() {
+		order = "open_time DESC"
+	}
 	if err := query.
-		Order("open_time ASC").
+		Order(order).
 		Limit(limit).
 		Find(&list).Error; err != nil {
 		log.Error("GetKlines error", "err", err)
 		return nil, err
 	}
+	if start.IsZero() {
+		for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+			list[i], list[j] = list[j], list[i]
+		}
+	}
 
 	return list, nil
 }
 
+// GetLatestKline returns the last candle opened before the given time, nil when there is none
+func (db *klineDB) GetLatestKline(tokenID, interval string, before time.Time) (*Kline, error) {
+	var list []*Kline
+	if err := db.gorm.
+		Where("token_id = ? AND time_interval = ? AND open_time < ?", tokenID, interval, before).
+		Order("open_time DESC").
+		Limit(1).
+		Find(&list).Error; err != nil {
+		log.Error("GetLatestKline error", "err", err)
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0], nil
+}
+
 func (db *klineDB) StoreKline(k *Kline) error {
 	if err := db.gorm.Create(k).Error; err != nil {
 		log.Error("StoreKline error", "err", err)
@@ -108,6 +138,28 @@ func (db *klineDB) StoreKlines(list []*Kline) error {
 	return nil
 }
 
+// UpsertKlines merges ticks into candles: the open price of an existing candle is kept,
+// high / low widen and close takes the latest tick
+func (db *klineDB) UpsertKlines(list []*Kline) error {
+	if len(list) == 0 {
+		return nil
+	}
+	err := db.gorm.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "token_id"}, {Name: "time_interval"}, {Name: "open_time"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"high_price":  gorm.Expr("GREATEST(kline.high_price, EXCLUDED.high_price)"),
+			"low_price":   gorm.Expr("LEAST(kline.low_price, EXCLUDED.low_price)"),
+			"close_price": gorm.Expr("EXCLUDED.close_price"),
+			"updated_at":  gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	}).CreateInBatches(list, 200).Error
+	if err != nil {
+		log.Error("UpsertKlines error", "err", err)
+		return err
+	}
+	return nil
+}
+
 func (db *klineDB) GetByGuid(guid string) (*Kline, error) {
 	var k Kline
 	if err := db.gorm.Where("guid = ?", guid).First(&k).Error; err != nil {
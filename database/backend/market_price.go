@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MarketPrice struct {
@@ -17,11 +18,15 @@ type MarketPrice struct {
 	UsdPrice    string    `gorm:"column:usd_price;type:numeric(20,8);not null" json:"usd_price"`
 	MarketCap   string    `gorm:"column:market_cap;type:numeric(20,2)" json:"market_cap"` // 市值（美元）
 	Liquidity   string    `gorm:"column:liquidity;type:numeric(20,2)" json:"liquidity"`   // 流动性（美元）
-	Volume24h   string    `gorm:"column:24h_volume;type:numeric(20,2)" json:"24h_volume"` // 24小时成交量（美元）
+	Volume24h   string    `gorm:"column:volume_24h;type:numeric(20,2)" json:"24h_volume"` // 24小时成交量（美元）
 	PriceChange string    `gorm:"column:price_change;type:varchar(255);not null" json:"price_change"`
 	Ranking     string    `gorm:"column:ranking;type:varchar(255);not null" json:"ranking"`
 	CreateTime  time.Time `gorm:"column:created_at;autoCreateTime" json:"create_time"`
 	UpdateTime  time.Time `gorm:"column:updated_at;autoUpdateTime" json:"update_time"`
+
+	// 行情 worker 写入：多源聚合的置信度与参与的行情源
+	Confidence string `gorm:"column:confidence;type:numeric(5,4);default:0" json:"confidence"`
+	Source     string `gorm:"column:source;type:varchar(255);default:''" json:"source"`
 }
 
 func (MarketPrice) TableName() string {
@@ -39,6 +44,7 @@ type MarketPriceDB interface {
 	StoreMarketPrice(m *MarketPrice) error
 	StoreMarketPrices(list []*MarketPrice) error
 	UpdateMarketPrice(guid string, updates map[string]interface{}) error
+	UpsertByTokenID(m *MarketPrice) error
 }
 
 type marketPriceDB struct {
@@ -83,6 +89,33 @@ func (db *marketPriceDB) GetByTokenID(tokenID string) (*MarketPrice, error) {
 	return &m, nil
 }
 
+// UpsertByTokenID writes the price columns of the token's row in one statement, creating it when missing;
+// market cap, liquidity and ranking are maintained elsewhere and left untouched
+func (db *marketPriceDB) UpsertByTokenID(m *MarketPrice) error {
+	if m.TokenID == "" {
+		return fmt.Errorf("token_id required")
+	}
+	err := db.gorm.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "token_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Neq{Column: "token_id", Value: ""}}}, // 对应部分唯一索引 uniq_market_price_token_id
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"chain_id":     gorm.Expr("EXCLUDED.chain_id"),
+			"usdt_price":   gorm.Expr("EXCLUDED.usdt_price"),
+			"usd_price":    gorm.Expr("EXCLUDED.usd_price"),
+			"volume_24h":   gorm.Expr("EXCLUDED.volume_24h"),
+			"price_change": gorm.Expr("EXCLUDED.price_change"),
+			"confidence":   gorm.Expr("EXCLUDED.confidence"),
+			"source":       gorm.Expr("EXCLUDED.source"),
+			"updated_at":   gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	}).Create(m).Error
+	if err != nil {
+		log.Error("UpsertByTokenID MarketPrice error", "token_id", m.TokenID, "err", err)
+		return err
+	}
+	return nil
+}
+
 func (db *marketPriceDB) UpdateMarketPrice(guid string, updates map[string]interface{}) error {
 	if guid == "" {
 		return fmt.Errorf("invalid guid")
@@ -0,0 +1,299 @@
+// swap.go
+package backend
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Swap 聚合器 swap 主记录，步骤见 SwapStep
+type Swap struct {
+	SwapID         string    `gorm:"primaryKey;column:swap_id;type:varchar(255)" json:"swap_id"`
+	QuoteID        string    `gorm:"column:quote_id;type:varchar(255);default:'';index" json:"quote_id"`
+	UserAddress    string    `gorm:"column:user_address;type:varchar(255);default:'';index" json:"user_address"`
+	WalletUUID     string    `gorm:"column:wallet_uuid;type:varchar(255);default:'';index" json:"wallet_uuid"`
+	Status         int       `gorm:"column:status;type:integer;default:0" json:"status"`
+	FailReasonCode string    `gorm:"column:fail_reason_code;type:varchar(100);default:''" json:"fail_reason_code"`
+	FailMessage    string    `gorm:"column:fail_message;type:varchar(500);default:''" json:"fail_message"`
+	CreateTime     time.Time `gorm:"column:created_at;autoCreateTime" json:"create_time"`
+	UpdateTime     time.Time `gorm:"column:updated_at;autoUpdateTime" json:"update_time"`
+}
+
+func (Swap) TableName() string {
+	return "swap"
+}
+
+// SwapStep 单个步骤，Detail 为完整 step 的 JSON，其余列用于查询
+type SwapStep struct {
+	Guid           string    `gorm:"primaryKey;column:guid;type:text;default:replace(uuid_generate_v4()::text, '-', '')" json:"guid"`
+	SwapID         string    `gorm:"column:swap_id;type:varchar(255);not null;uniqueIndex:idx_swap_step_swap_step" json:"swap_id"`
+	StepIndex      int       `gorm:"column:step_index;type:integer;not null;uniqueIndex:idx_swap_step_swap_step" json:"step_index"`
+	ActionType     string    `gorm:"column:action_type;type:varchar(50);default:''" json:"action_type"`
+	TxHash         string    `gorm:"column:tx_hash;type:varchar(500);default:'';index" json:"tx_hash"`
+	Status         int       `gorm:"column:status;type:integer;default:0" json:"status"`
+	IdempotencyKey string    `gorm:"column:idempotency_key;type:varchar(255);default:''" json:"idempotency_key"`
+	Detail         string    `gorm:"column:detail;type:jsonb;not null" json:"detail"`
+	CreateTime     time.Time `gorm:"column:created_at;autoCreateTime" json:"create_time"`
+	UpdateTime     time.Time `gorm:"column:updated_at;autoUpdateTime" json:"update_time"`
+}
+
+func (SwapStep) TableName() string {
+	return "swap_step"
+}
+
+// SwapIdempotency 幂等键 -> 已提交的交易哈希（或订单哈希）
+type SwapIdempotency struct {
+	Guid           string     `gorm:"primaryKey;column:guid;type:text;default:replace(uuid_generate_v4()::text, '-', '')" json:"guid"`
+	SwapID         string     `gorm:"column:swap_id;type:varchar(255);not null;uniqueIndex:idx_swap_idempotency_key" json:"swap_id"`
+	StepIndex      int        `gorm:"column:step_index;type:integer;not null;uniqueIndex:idx_swap_idempotency_key" json:"step_index"`
+	IdempotencyKey string     `gorm:"column:idempotency_key;type:varchar(255);not null;uniqueIndex:idx_swap_idempotency_key" json:"idempotency_key"`
+	TxHash         string     `gorm:"column:tx_hash;type:varchar(500);default:''" json:"tx_hash"`
+	Completed      bool       `gorm:"column:completed;type:boolean;not null" json:"completed"` // false: 预占中，结果未提交
+	Owner          string     `gorm:"column:owner;type:varchar(255);default:''" json:"owner"`  // 预占者
+	LeaseUntil     *time.Time `gorm:"column:lease_until" json:"lease_until,omitempty"`         // 预占租约到期时间
+	CreateTime     time.Time  `gorm:"column:created_at;autoCreateTime" json:"create_time"`
+	UpdateTime     time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"update_time"`
+}
+
+func (SwapIdempotency) TableName() string {
+	return "swap_idempotency"
+}
+
+type SwapView interface {
+	GetBySwapID(swapID string) (*Swap, error)
+	GetSteps(swapID string) ([]*SwapStep, error)
+	GetStep(swapID string, stepIndex int) (*SwapStep, error)
+	CountSteps(swapID string) (int64, error)
+	GetIdempotency(swapID string, stepIndex int, idempotencyKey string) (*SwapIdempotency, error)
+}
+
+type SwapDB interface {
+	SwapView
+
+	StoreSwap(s *Swap, steps []*SwapStep) error
+	SaveSwap(s *Swap, steps []*SwapStep) error
+	TransitionSwapStatus(swapID string, fromStatus int, toStatus int, failReasonCode string, failMessage string) (bool, error)
+	UpsertStep(step *SwapStep) error
+	UpsertIdempotency(r *SwapIdempotency) error
+	ReserveIdempotency(r *SwapIdempotency) (*SwapIdempotency, bool, error)
+	ReleaseIdempotency(swapID string, stepIndex int, idempotencyKey string, owner string) error
+}
+
+type swapDB struct {
+	gorm *gorm.DB
+}
+
+func NewSwapDB(db *gorm.DB) SwapDB {
+	return &swapDB{gorm: db}
+}
+
+// StoreSwap inserts a swap and its steps in one transaction
+func (db *swapDB) StoreSwap(s *Swap, steps []*SwapStep) error {
+	err := db.gorm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(s).Error; err != nil {
+			return err
+		}
+		if len(steps) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(steps, len(steps)).Error
+	})
+	if err != nil {
+		log.Error("StoreSwap error", "swapID", s.SwapID, "err", err)
+		return err
+	}
+	return nil
+}
+
+// SaveSwap overwrites an existing swap row and upserts all of its steps
+func (db *swapDB) SaveSwap(s *Swap, steps []*SwapStep) error {
+	err := db.gorm.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&Swap{}).Where("swap_id = ?", s.SwapID).Updates(map[string]interface{}{
+			"quote_id":         s.QuoteID,
+			"user_address":     s.UserAddress,
+			"wallet_uuid":      s.WalletUUID,
+			"status":           s.Status,
+			"fail_reason_code": s.FailReasonCode,
+			"fail_message":     s.FailMessage,
+			"updated_at":       time.Now(),
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		for _, step := range steps {
+			if err := upsertStep(tx, step); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error("SaveSwap error", "swapID", s.SwapID, "err", err)
+		return err
+	}
+	return nil
+}
+
+// TransitionSwapStatus 仅当 swap 仍处于 fromStatus 时更新状态，不触碰步骤；返回是否更新成功
+func (db *swapDB) TransitionSwapStatus(swapID string, fromStatus int, toStatus int, failReasonCode string, failMessage string) (bool, error) {
+	res := db.gorm.Model(&Swap{}).Where("swap_id = ? AND status = ?", swapID, fromStatus).Updates(map[string]interface{}{
+		"status":           toStatus,
+		"fail_reason_code": failReasonCode,
+		"fail_message":     failMessage,
+		"updated_at":       time.Now(),
+	})
+	if res.Error != nil {
+		log.Error("TransitionSwapStatus error", "swapID", swapID, "err", res.Error)
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (db *swapDB) UpsertStep(step *SwapStep) error {
+	if err := upsertStep(db.gorm, step); err != nil {
+		log.Error("UpsertStep error", "swapID", step.SwapID, "stepIndex", step.StepIndex, "err", err)
+		return err
+	}
+	return nil
+}
+
+func upsertStep(tx *gorm.DB, step *SwapStep) error {
+	step.UpdateTime = time.Now()
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "swap_id"}, {Name: "step_index"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"action_type",
+			"tx_hash",
+			"status",
+			"idempotency_key",
+			"detail",
+			"updated_at",
+		}),
+	}).Create(step).Error
+}
+
+func (db *swapDB) GetBySwapID(swapID string) (*Swap, error) {
+	var s Swap
+	if err := db.gorm.Where("swap_id = ?", swapID).First(&s).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (db *swapDB) GetSteps(swapID string) ([]*SwapStep, error) {
+	var list []*SwapStep
+	if err := db.gorm.Where("swap_id = ?", swapID).Order("step_index ASC").Find(&list).Error; err != nil {
+		log.Error("GetSteps SwapStep error", "err", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+func (db *swapDB) GetStep(swapID string, stepIndex int) (*SwapStep, error) {
+	var step SwapStep
+	if err := db.gorm.Where("swap_id = ? AND step_index = ?", swapID, stepIndex).First(&step).Error; err != nil {
+		return nil, err
+	}
+	return &step, nil
+}
+
+func (db *swapDB) CountSteps(swapID string) (int64, error) {
+	var count int64
+	if err := db.gorm.Model(&SwapStep{}).Where("swap_id = ?", swapID).Count(&count).Error; err != nil {
+		log.Error("CountSteps SwapStep error", "err", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (db *swapDB) GetIdempotency(swapID string, stepIndex int, idempotencyKey string) (*SwapIdempotency, error) {
+	var r SwapIdempotency
+	err := db.gorm.Where("swap_id = ? AND step_index = ? AND idempotency_key = ?", swapID, stepIndex, idempotencyKey).
+		First(&r).Error
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// UpsertIdempotency records (or overwrites) the tx hash of an idempotency key, completing any reservation
+func (db *swapDB) UpsertIdempotency(r *SwapIdempotency) error {
+	if r.IdempotencyKey == "" {
+		return fmt.Errorf("invalid idempotency key")
+	}
+	r.Completed = true
+	r.Owner = ""
+	r.LeaseUntil = nil
+	r.UpdateTime = time.Now()
+	err := db.gorm.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "swap_id"}, {Name: "step_index"}, {Name: "idempotency_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"tx_hash", "completed", "owner", "lease_until", "updated_at"}),
+	}).Create(r).Error
+	if err != nil {
+		log.Error("UpsertIdempotency error", "swapID", r.SwapID, "stepIndex", r.StepIndex, "err", err)
+		return err
+	}
+	return nil
+}
+
+// ReserveIdempotency claims an idempotency key for r.Owner until r.LeaseUntil, relying on the unique index.
+// An expired reservation of another owner is taken over. When the key is not claimed, the current row is returned.
+func (db *swapDB) ReserveIdempotency(r *SwapIdempotency) (*SwapIdempotency, bool, error) {
+	if r.IdempotencyKey == "" || r.Owner == "" || r.LeaseUntil == nil {
+		return nil, false, fmt.Errorf("invalid idempotency reservation")
+	}
+	r.Completed = false
+	r.TxHash = ""
+
+	res := db.gorm.Clauses(clause.OnConflict{DoNothing: true}).Create(r)
+	if res.Error != nil {
+		log.Error("ReserveIdempotency insert error", "swapID", r.SwapID, "stepIndex", r.StepIndex, "err", res.Error)
+		return nil, false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return r, true, nil
+	}
+
+	now := time.Now()
+	res = db.gorm.Model(&SwapIdempotency{}).
+		Where("swap_id = ? AND step_index = ? AND idempotency_key = ?", r.SwapID, r.StepIndex, r.IdempotencyKey).
+		Where("completed = ? AND (owner = ? OR lease_until IS NULL OR lease_until < ?)", false, r.Owner, now).
+		Updates(map[string]interface{}{
+			"owner":       r.Owner,
+			"lease_until": r.LeaseUntil,
+			"updated_at":  now,
+		})
+	if res.Error != nil {
+		log.Error("ReserveIdempotency takeover error", "swapID", r.SwapID, "stepIndex", r.StepIndex, "err", res.Error)
+		return nil, false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return r, true, nil
+	}
+
+	current, err := db.GetIdempotency(r.SwapID, r.StepIndex, r.IdempotencyKey)
+	if err != nil {
+		return nil, false, err
+	}
+	return current, false, nil
+}
+
+// ReleaseIdempotency deletes owner's uncompleted reservation
+func (db *swapDB) ReleaseIdempotency(swapID string, stepIndex int, idempotencyKey string, owner string) error {
+	err := db.gorm.
+		Where("swap_id = ? AND step_index = ? AND idempotency_key = ?", swapID, stepIndex, idempotencyKey).
+		Where("completed = ? AND owner = ?", false, owner).
+		Delete(&SwapIdempotency{}).Error
+	if err != nil {
+		log.Error("ReleaseIdempotency error", "swapID", swapID, "stepIndex", stepIndex, "err", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// swap_quote.go
+package backend
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SwapQuote 聚合器报价，Detail 为完整报价 JSON
+type SwapQuote struct {
+	QuoteID     string     `gorm:"primaryKey;column:quote_id;type:varchar(255)" json:"quote_id"`
+	UserAddress string     `gorm:"column:user_address;type:varchar(255);default:''" json:"user_address"`
+	WalletUUID  string     `gorm:"column:wallet_uuid;type:varchar(255);default:''" json:"wallet_uuid"`
+	ExpiresAt   *time.Time `gorm:"column:expires_at" json:"expires_at"`
+	RetainUntil time.Time  `gorm:"column:retain_until;not null;index" json:"retain_until"` // 未被 swap 引用的报价在此之后不可读
+	Detail      string     `gorm:"column:detail;type:jsonb;not null" json:"detail"`
+	CreateTime  time.Time  `gorm:"column:created_at;autoCreateTime" json:"create_time"`
+	UpdateTime  time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"update_time"`
+}
+
+func (SwapQuote) TableName() string {
+	return "swap_quote"
+}
+
+type SwapQuoteView interface {
+	GetByQuoteID(quoteID string) (*SwapQuote, error)
+}
+
+type SwapQuoteDB interface {
+	SwapQuoteView
+
+	UpsertSwapQuote(q *SwapQuote) error
+	UpdateSwapQuote(q *SwapQuote) (bool, error)
+	DeleteSwapQuote(quoteID string) error
+	DeleteExpiredSwapQuotes(before time.Time) (int64, error)
+}
+
+type swapQuoteDB struct {
+	gorm *gorm.DB
+}
+
+func NewSwapQuoteDB(db *gorm.DB) SwapQuoteDB {
+	return &swapQuoteDB{gorm: db}
+}
+
+// GetByQuoteID returns a retained quote, or a quote of any age that a swap was prepared from
+func (db *swapQuoteDB) GetByQuoteID(quoteID string) (*SwapQuote, error) {
+	var q SwapQuote
+	err := db.gorm.
+		Where("quote_id = ?", quoteID).
+		Where("retain_until > ? OR EXISTS (SELECT 1 FROM swap WHERE swap.quote_id = swap_quote.quote_id)", time.Now()).
+		First(&q).Error
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (db *swapQuoteDB) UpsertSwapQuote(q *SwapQuote) error {
+	q.UpdateTime = time.Now()
+	err := db.gorm.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "quote_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"user_address",
+			"wallet_uuid",
+			"expires_at",
+			"retain_until",
+			"detail",
+			"updated_at",
+		}),
+	}).Create(q).Error
+	if err != nil {
+		log.Error("UpsertSwapQuote error", "quoteID", q.QuoteID, "err", err)
+		return err
+	}
+	return nil
+}
+
+// UpdateSwapQuote overwrites an existing quote, reporting false if it does not exist
+func (db *swapQuoteDB) UpdateSwapQuote(q *SwapQuote) (bool, error) {
+	res := db.gorm.Model(&SwapQuote{}).Where("quote_id = ?", q.QuoteID).Updates(map[string]interface{}{
+		"user_address": q.UserAddress,
+		"wallet_uuid":  q.WalletUUID,
+		"expires_at":   q.ExpiresAt,
+		"retain_until": q.RetainUntil,
+		"detail":       q.Detail,
+		"updated_at":   time.Now(),
+	})
+	if res.Error != nil {
+		log.Error("UpdateSwapQuote error", "quoteID", q.QuoteID, "err", res.Error)
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (db *swapQuoteDB) DeleteSwapQuote(quoteID string) error {
+	if err := db.gorm.Where("quote_id = ?", quoteID).Delete(&SwapQuote{}).Error; err != nil {
+		log.Error("DeleteSwapQuote error", "quoteID", quoteID, "err", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteExpiredSwapQuotes removes quotes past retention that no swap references
+func (db *swapQuoteDB) DeleteExpiredSwapQuotes(before time.Time) (int64, error) {
+	res := db.gorm.
+		Where("retain_until < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM swap WHERE swap.quote_id = swap_quote.quote_id)").
+		Delete(&SwapQuote{})
+	if res.Error != nil {
+		log.Error("DeleteExpiredSwapQuotes error", "err", res.Error)
+		return 0, res.Error
+	}
+	return res.RowsAffected, nil
+}
@@ -33,6 +33,7 @@ type TokenView interface {
 	GetByContractAddress(addr string) (*Token, error)
 	GetByContractAndChain(addr, chainID string) (*Token, error)
 	GetTokenList(page, pageSize int, filters map[string]interface{}) ([]*Token, int64, error)
+	GetAllTokens() ([]*Token, error)
 }
 
 type TokenDB interface {
@@ -143,6 +144,15 @@ func (db *tokenDB) GetTokenList(page, pageSize int, filters map[string]interface
 	return list, total, nil
 }
 
+func (db *tokenDB) GetAllTokens() ([]*Token, error) {
+	var list []*Token
+	if err := db.gorm.Find(&list).Error; err != nil {
+		log.Error("GetAllTokens error", "err", err)
+		return nil, err
+	}
+	return list, nil
+}
+
 func (db *tokenDB) UpdateToken(guid string, updates map[string]interface{}) error {
 	if guid == "" {
 		return fmt.Errorf("invalid guid")
@@ -0,0 +1,95 @@
+// tx_status_transition.go
+package backend
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+)
+
+// TxStatusTransition kind
+const (
+	TransitionKindTx   = "tx"   // wallet_tx_record 单笔交易
+	TransitionKindSwap = "swap" // swap 整体状态
+)
+
+// TxStatusTransition 状态流转审计，按 (created_at, id) 读取即为事件流
+type TxStatusTransition struct {
+	ID             int64     `gorm:"primaryKey;column:id;autoIncrement" json:"id"`
+	Kind           string    `gorm:"column:kind;type:varchar(20);not null" json:"kind"`
+	RecordGuid     string    `gorm:"column:record_guid;type:varchar(255);default:'';index" json:"record_guid"`
+	OperationID    string    `gorm:"column:operation_id;type:varchar(255);default:'';index" json:"operation_id"`
+	StepIndex      int       `gorm:"column:step_index;type:integer;default:0" json:"step_index"`
+	WalletUUID     string    `gorm:"column:wallet_uuid;type:varchar(255);default:'';index" json:"wallet_uuid"`
+	ChainID        string    `gorm:"column:chain_id;type:varchar(255);default:''" json:"chain_id"`
+	TxID           string    `gorm:"column:tx_id;type:varchar(500);default:''" json:"tx_id"`
+	FromStatus     int       `gorm:"column:from_status;type:integer;not null" json:"from_status"`
+	ToStatus       int       `gorm:"column:to_status;type:integer;not null" json:"to_status"`
+	FailReasonCode string    `gorm:"column:fail_reason_code;type:varchar(100);default:''" json:"fail_reason_code,omitempty"`
+	FailReasonMsg  string    `gorm:"column:fail_reason_msg;type:varchar(500);default:''" json:"fail_reason_msg,omitempty"`
+	Source         string    `gorm:"column:source;type:varchar(50);default:''" json:"source"`
+	CreateTime     time.Time `gorm:"column:created_at;autoCreateTime" json:"create_time"`
+}
+
+func (TxStatusTransition) TableName() string {
+	return "tx_status_transition"
+}
+
+type TxStatusTransitionView interface {
+	GetByRecordGuid(guid string) ([]*TxStatusTransition, error)
+	GetByOperationID(operationID string) ([]*TxStatusTransition, error)
+	GetTransitionsSince(since time.Time, afterID int64, limit int) ([]*TxStatusTransition, error)
+}
+
+type TxStatusTransitionDB interface {
+	TxStatusTransitionView
+
+	StoreTxStatusTransition(t *TxStatusTransition) error
+}
+
+type txStatusTransitionDB struct {
+	gorm *gorm.DB
+}
+
+func NewTxStatusTransitionDB(db *gorm.DB) TxStatusTransitionDB {
+	return &txStatusTransitionDB{gorm: db}
+}
+
+func (db *txStatusTransitionDB) StoreTxStatusTransition(t *TxStatusTransition) error {
+	if err := db.gorm.Create(t).Error; err != nil {
+		log.Error("StoreTxStatusTransition error", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (db *txStatusTransitionDB) GetByRecordGuid(guid string) ([]*TxStatusTransition, error) {
+	var list []*TxStatusTransition
+	if err := db.gorm.Where("record_guid = ?", guid).Order("id ASC").Find(&list).Error; err != nil {
+		log.Error("GetByRecordGuid TxStatusTransition error", "err", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+func (db *txStatusTransitionDB) GetByOperationID(operationID string) ([]*TxStatusTransition, error) {
+	var list []*TxStatusTransition
+	if err := db.gorm.Where("operation_id = ?", operationID).Order("id ASC").Find(&list).Error; err != nil {
+		log.Error("GetByOperationID TxStatusTransition error", "err", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetTransitionsSince 按 (created_at, id) 顺序返回 (since, afterID) 之后的流转记录
+func (db *txStatusTransitionDB) GetTransitionsSince(since time.Time, afterID int64, limit int) ([]*TxStatusTransition, error) {
+	var list []*TxStatusTransition
+	err := db.gorm.Where("created_at > ? OR (created_at = ? AND id > ?)", since, since, afterID).
+		Order("created_at ASC, id ASC").Limit(limit).Find(&list).Error
+	if err != nil {
+		log.Error("GetTransitionsSince TxStatusTransition error", "err", err)
+		return nil, err
+	}
+	return list, nil
+}
@@ -15,14 +15,22 @@ const (
 	TxStatusPending = 1 // PENDING: 广播成功并拿到 txHash
 	TxStatusFailed  = 2 // FAILED: 广播失败或链上执行失败或超时判失败
 	TxStatusSuccess = 3 // SUCCESS: 链上确认成功
+	// 跨链桥终态（源链成功后按目标链结果区分）
+	TxStatusPartial  = 4 // PARTIAL: 桥已完成，但目标链收到的不是目标代币
+	TxStatusRefunded = 5 // REFUNDED: 桥失败，资金已在源链退回
+	// 已上链但未达到链的确认数，期间被重组移除会退回 PENDING
+	TxStatusConfirming = 6 // CONFIRMING: 已打包，等待确认深度
 )
 
 // TxStatus 状态名称映射
 var TxStatusNames = map[int]string{
-	TxStatusCreated: "CREATED",
-	TxStatusPending: "PENDING",
-	TxStatusFailed:  "FAILED",
-	TxStatusSuccess: "SUCCESS",
+	TxStatusCreated:    "CREATED",
+	TxStatusPending:    "PENDING",
+	TxStatusFailed:     "FAILED",
+	TxStatusSuccess:    "SUCCESS",
+	TxStatusPartial:    "PARTIAL",
+	TxStatusRefunded:   "REFUNDED",
+	TxStatusConfirming: "CONFIRMING",
 }
 
 // 失败原因代码常量
@@ -31,6 +39,32 @@ const (
 	FailReasonChainFailed     = "CHAIN_FAILED"      // 链上执行失败
 	FailReasonNotFoundTimeout = "NOT_FOUND_TIMEOUT" // 查不到且超时
 	FailReasonUnknown         = "UNKNOWN"           // 未知错误
+	FailReasonBridgeFailed    = "BRIDGE_FAILED"     // 跨链桥失败（未退款）
+	FailReasonBridgeRefunded  = "BRIDGE_REFUNDED"   // 跨链桥失败，已在源链退款
+	FailReasonBridgeTimeout   = "BRIDGE_TIMEOUT"    // 源链成功但目标链超时未到账
+	FailReasonReplaced        = "REPLACED"          // 同 nonce 的其他交易已上链
+	FailReasonCancelled       = "CANCELLED"         // 用户发送的取消交易已上链
+
+	// 链下订单（1inch Fusion）过期或取消，未成交
+	FailReasonOrderFailed = "ORDER_FAILED"
+
+	// Solana: recent blockhash 已过期仍未上链，交易不会再被处理
+	FailReasonBlockhashExpired = "BLOCKHASH_EXPIRED"
+
+	// 链上执行失败的细分原因，由回执与 revert 数据解析得到，无法解析时为 CHAIN_FAILED
+	FailReasonSlippage              = "SLIPPAGE"               // 输出低于最小值
+	FailReasonExpired               = "EXPIRED"                // 报价 / 签名 / deadline 过期
+	FailReasonInsufficientAllowance = "INSUFFICIENT_ALLOWANCE" // 授权不足
+	FailReasonInsufficientBalance   = "INSUFFICIENT_BALANCE"   // 余额不足
+	FailReasonOutOfGas              = "OUT_OF_GAS"             // gas 耗尽
+	FailReasonPanic                 = "PANIC"                  // Solidity panic
+	FailReasonReverted              = "REVERTED"               // 其他 revert
+)
+
+// 替换交易类型
+const (
+	ReplaceKindSpeedUp = "speed_up" // 同 nonce、同调用，提高费用重发
+	ReplaceKindCancel  = "cancel"   // 同 nonce，0 值转给自己
 )
 
 // 完整动作链路过滤：OperationID、StepIndex、TxType
@@ -49,13 +83,24 @@ type WalletTxRecord struct {
 	Memo           string     `gorm:"column:memo;type:varchar(500);not null" json:"memo"`
 	TxID           string     `gorm:"column:tx_id;type:varchar(500);default:'';uniqueIndex" json:"tx_id"`
 	BlockHeight    string     `gorm:"column:block_height;type:varchar(500);default:''" json:"block_height"`
-	TxType         string     `gorm:"column:tx_type;type:varchar(50);default:'transfer';index" json:"tx_type"` // approve, swap, bridge, wrap, unwrap, transfer
+	BlockHash      string     `gorm:"column:block_hash;type:varchar(100);default:''" json:"block_hash,omitempty"`             // CONFIRMING 时所在区块，用于检测重组
+	TxType         string     `gorm:"column:tx_type;type:varchar(50);default:'transfer';index" json:"tx_type"`                // approve, swap, bridge, wrap, unwrap, transfer
+	DestChainID    string     `gorm:"column:dest_chain_id;type:varchar(255);default:''" json:"dest_chain_id,omitempty"`       // bridge: 目标链
+	DestTxID       string     `gorm:"column:dest_tx_id;type:varchar(500);default:''" json:"dest_tx_id,omitempty"`             // bridge: 目标链到账交易
+	ReplaceGroup   string     `gorm:"column:replace_group;type:varchar(255);default:'';index" json:"replace_group,omitempty"` // 被替换的原交易 guid，原交易自身同样记录
+	ReplaceKind    string     `gorm:"column:replace_kind;type:varchar(20);default:''" json:"replace_kind,omitempty"`          // speed_up / cancel，原交易为空
 	Status         int        `gorm:"column:status;type:integer;default:0;index:idx_status_last_checked" json:"status"`
 	FailReasonCode string     `gorm:"column:fail_reason_code;type:varchar(100);default:''" json:"fail_reason_code,omitempty"`
 	FailReasonMsg  string     `gorm:"column:fail_reason_msg;type:varchar(500);default:''" json:"fail_reason_msg,omitempty"`
 	LastCheckedAt  *time.Time `gorm:"column:last_checked_at;index:idx_status_last_checked" json:"last_checked_at,omitempty"`
+	NextCheckAt    *time.Time `gorm:"column:next_check_at" json:"next_check_at,omitempty"` // 下次检查时间，领取时用作租约
+	CheckCount     int        `gorm:"column:check_count;type:integer;default:0" json:"check_count"`
+	LastSeenAt     *time.Time `gorm:"column:last_seen_at" json:"last_seen_at,omitempty"` // 最后一次在链上查到交易的时间，PENDING 超时从此计算
 	CreateTime     time.Time  `gorm:"column:created_at;autoCreateTime" json:"create_time"`
 	UpdateTime     time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"update_time"`
+
+	// Solana: recent blockhash 的最后有效区块高度，超过后仍未上链即失败
+	LastValidBlockHeight int64 `gorm:"column:last_valid_block_height;type:bigint;default:0" json:"last_valid_block_height,omitempty"`
 }
 
 func (WalletTxRecord) TableName() string {
@@ -66,8 +111,9 @@ type WalletTxRecordView interface {
 	GetByGuid(guid string) (*WalletTxRecord, error)
 	GetByTxID(txID string) (*WalletTxRecord, error)
 	GetByOperationID(operationID string) ([]*WalletTxRecord, error)
+	GetByReplaceGroup(group string) ([]*WalletTxRecord, error)
 	GetTxList(page, pageSize int, filters map[string]interface{}) ([]*WalletTxRecord, int64, error)
-	GetPendingTxsForCheck(lastCheckedBefore time.Time, limit int) ([]*WalletTxRecord, error)
+	GetPendingChainIDs() ([]string, error)
 }
 
 type WalletTxRecordDB interface {
@@ -76,6 +122,8 @@ type WalletTxRecordDB interface {
 	StoreWalletTxRecord(r *WalletTxRecord) error
 	StoreWalletTxRecords(list []*WalletTxRecord) error
 	UpdateWalletTxRecord(guid string, updates map[string]interface{}) error
+	TransitionWalletTxRecord(guid string, fromStatus int, updates map[string]interface{}) (bool, error)
+	ClaimPendingTxsForCheck(chainID string, now time.Time, leaseUntil time.Time, limit int) ([]*WalletTxRecord, error)
 }
 
 type walletTxRecordDB struct {
@@ -129,6 +177,16 @@ func (db *walletTxRecordDB) GetByOperationID(operationID string) ([]*WalletTxRec
 	return list, nil
 }
 
+// GetByReplaceGroup 返回原交易及其所有替换交易，按创建时间排序
+func (db *walletTxRecordDB) GetByReplaceGroup(group string) ([]*WalletTxRecord, error) {
+	var list []*WalletTxRecord
+	if err := db.gorm.Where("replace_group = ?", group).Order("created_at ASC").Find(&list).Error; err != nil {
+		log.Error("GetByReplaceGroup WalletTxRecord error", "err", err)
+		return nil, err
+	}
+	return list, nil
+}
+
 func (db *walletTxRecordDB) GetTxList(page, pageSize int, filters map[string]interface{}) ([]*WalletTxRecord, int64, error) {
 	if page < 1 {
 		page = 1
@@ -191,22 +249,62 @@ func (db *walletTxRecordDB) UpdateWalletTxRecord(guid string, updates map[string
 	return nil
 }
 
-// GetPendingTxsForCheck 获取需要检查状态的 pending 交易
-// lastCheckedBefore: 上次检查时间早于此时间的记录
-// limit: 最多返回的记录数
-func (db *walletTxRecordDB) GetPendingTxsForCheck(lastCheckedBefore time.Time, limit int) ([]*WalletTxRecord, error) {
-	var list []*WalletTxRecord
-	query := db.gorm.Model(&WalletTxRecord{}).
-		Where("status = ?", TxStatusPending).
-		Where("tx_id != ?", ""). // 必须有 tx_id
-		Where("(last_checked_at IS NULL OR last_checked_at < ?)", lastCheckedBefore).
-		Order("last_checked_at ASC NULLS FIRST"). // 优先处理从未检查过的
-		Limit(limit)
-
-	if err := query.Find(&list).Error; err != nil {
-		log.Error("GetPendingTxsForCheck error", "err", err)
+// TransitionWalletTxRecord 仅当记录仍处于 fromStatus 时更新，返回是否更新成功；
+// 并发的两个判定方只有一个能完成同一次状态流转
+func (db *walletTxRecordDB) TransitionWalletTxRecord(guid string, fromStatus int, updates map[string]interface{}) (bool, error) {
+	if guid == "" {
+		return false, fmt.Errorf("invalid guid")
+	}
+	if len(updates) == 0 {
+		return false, fmt.Errorf("updates is empty")
+	}
+
+	updates["updated_at"] = time.Now()
+
+	res := db.gorm.Model(&WalletTxRecord{}).Where("guid = ? AND status = ?", guid, fromStatus).Updates(updates)
+	if res.Error != nil {
+		log.Error("TransitionWalletTxRecord error", "err", res.Error)
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// GetPendingChainIDs 返回有待检查交易的链
+func (db *walletTxRecordDB) GetPendingChainIDs() ([]string, error) {
+	var chainIDs []string
+	err := db.gorm.Model(&WalletTxRecord{}).
+		Where("status IN ?", []int{TxStatusPending, TxStatusConfirming}).
+		Where("tx_id != ?", "").
+		Distinct().
+		Pluck("chain_id", &chainIDs).Error
+	if err != nil {
+		log.Error("GetPendingChainIDs error", "err", err)
 		return nil, err
 	}
+	return chainIDs, nil
+}
 
+// ClaimPendingTxsForCheck 领取某条链上到期需要检查的交易。
+// 领取的记录 next_check_at 推后到 leaseUntil，其他实例在租约到期前不会再领取；
+// FOR UPDATE SKIP LOCKED 保证并发领取互不阻塞且不重复
+func (db *walletTxRecordDB) ClaimPendingTxsForCheck(chainID string, now time.Time, leaseUntil time.Time, limit int) ([]*WalletTxRecord, error) {
+	var list []*WalletTxRecord
+	err := db.gorm.Raw(`
+		UPDATE wallet_tx_record SET next_check_at = ?, last_checked_at = ?, check_count = check_count + 1
+		WHERE guid IN (
+			SELECT guid FROM wallet_tx_record
+			WHERE chain_id = ? AND status IN ? AND tx_id != ''
+			  AND (next_check_at IS NULL OR next_check_at <= ?)
+			ORDER BY next_check_at ASC NULLS FIRST
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		leaseUntil, now, chainID, []int{TxStatusPending, TxStatusConfirming}, now, limit,
+	).Scan(&list).Error
+	if err != nil {
+		log.Error("ClaimPendingTxsForCheck error", "chainID", chainID, "err", err)
+		return nil, err
+	}
 	return list, nil
 }
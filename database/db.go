@@ -38,6 +38,9 @@ type DB struct {
 	BackendWalletAddressNote backend.WalletAddressNoteDB
 	BackendWalletAsset       backend.WalletAssetDB
 	BackendWalletTxRecord    backend.WalletTxRecordDB
+	BackendSwap              backend.SwapDB
+	BackendSwapQuote         backend.SwapQuoteDB
+	BackendTxTransition      backend.TxStatusTransitionDB
 	QueneTxDB                backend.QueueTxDB
 }
 
@@ -92,6 +95,9 @@ func NewDB(ctx context.Context, dbConfig config.DBConfig) (*DB, error) {
 		BackendWalletAddressNote: backend.NewWalletAddressNoteDB(gorms),
 		BackendWalletAsset:       backend.NewWalletAssetDB(gorms),
 		BackendWalletTxRecord:    backend.NewWalletTxRecordDB(gorms),
+		BackendSwap:              backend.NewSwapDB(gorms),
+		BackendSwapQuote:         backend.NewSwapQuoteDB(gorms),
+		BackendTxTransition:      backend.NewTxStatusTransitionDB(gorms),
 		QueneTxDB:                backend.NewQueueTxDB(gorms),
 	}
 	return db, nil
@@ -121,6 +127,9 @@ func (db *DB) Transaction(fn func(db *DB) error) error {
 			BackendWalletAddressNote: backend.NewWalletAddressNoteDB(tx),
 			BackendWalletAsset:       backend.NewWalletAssetDB(tx),
 			BackendWalletTxRecord:    backend.NewWalletTxRecordDB(tx),
+			BackendSwap:              backend.NewSwapDB(tx),
+			BackendSwapQuote:         backend.NewSwapQuoteDB(tx),
+			BackendTxTransition:      backend.NewTxStatusTransitionDB(tx),
 			QueneTxDB:                backend.NewQueueTxDB(tx),
 		}
 		return fn(txDB)
@@ -3,6 +3,7 @@ module github.com/roothash-pay/wallet-services
 go 1.24.10
 
 require (
+	github.com/alicebob/miniredis/v2 v2.39.0
 	github.com/aws/aws-sdk-go-v2 v1.40.0
 	github.com/aws/aws-sdk-go-v2/config v1.18.45
 	github.com/aws/aws-sdk-go-v2/credentials v1.13.43
@@ -34,6 +35,7 @@ require (
 require (
 	github.com/swaggo/files v0.0.0-20220610200504-28940afbdbfe // indirect
 	github.com/swaggo/http-swagger v1.3.4 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
 )
 
 require (
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+type AggregatorMetricer interface {
+	RecordProviderRequest(provider, method string, success bool, latency time.Duration)
+	RecordProviderCircuitState(provider string, state backend.CircuitState)
+}
+
+type AggregatorMetrics struct {
+	providerRequests     *prometheus.CounterVec
+	providerLatency      *prometheus.HistogramVec
+	providerCircuitState *prometheus.GaugeVec
+}
+
+func NewAggregatorMetrics(registry *prometheus.Registry, subsystem string) *AggregatorMetrics {
+	providerRequests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "provider_requests_total",
+		Help:      "Aggregator provider calls by result",
+		Subsystem: subsystem,
+	}, []string{"provider", "method", "result"})
+
+	providerLatency := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:      "provider_latency_seconds",
+		Help:      "Aggregator provider call latency",
+		Subsystem: subsystem,
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 3, 5, 8, 12},
+	}, []string{"provider", "method"})
+
+	providerCircuitState := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "provider_circuit_state",
+		Help:      "Aggregator provider circuit state (0=closed, 1=half-open, 2=open)",
+		Subsystem: subsystem,
+	}, []string{"provider"})
+
+	registry.MustRegister(providerRequests)
+	registry.MustRegister(providerLatency)
+	registry.MustRegister(providerCircuitState)
+
+	return &AggregatorMetrics{
+		providerRequests:     providerRequests,
+		providerLatency:      providerLatency,
+		providerCircuitState: providerCircuitState,
+	}
+}
+
+func (am *AggregatorMetrics) RecordProviderRequest(provider, method string, success bool, latency time.Duration) {
+	result := "success"
+	if !success {
+		result = "failure"
+	}
+	am.providerRequests.WithLabelValues(provider, method, result).Inc()
+	if latency > 0 {
+		am.providerLatency.WithLabelValues(provider, method).Observe(latency.Seconds())
+	}
+}
+
+func (am *AggregatorMetrics) RecordProviderCircuitState(provider string, state backend.CircuitState) {
+	var value float64
+	switch state {
+	case backend.CircuitHalfOpen:
+		value = 1
+	case backend.CircuitOpen:
+		value = 2
+	}
+	am.providerCircuitState.WithLabelValues(provider).Set(value)
+}
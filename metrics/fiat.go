@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type FiatRateMetricer interface {
+	RecordFiatRateAge(base, currency string, age time.Duration)
+	RecordFiatRateStale(base, currency string, stale bool)
+}
+
+type FiatRateMetrics struct {
+	rateAge   *prometheus.GaugeVec
+	rateStale *prometheus.GaugeVec
+}
+
+func NewFiatRateMetrics(registry *prometheus.Registry, subsystem string) *FiatRateMetrics {
+	rateAge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "rate_age_seconds",
+		Help:      "Age of the latest live fiat currency rate, from the provider's rate time",
+		Subsystem: subsystem,
+	}, []string{"base", "currency"})
+
+	rateStale := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "rate_stale",
+		Help:      "Whether the live fiat currency rate is older than the stale threshold (1=stale)",
+		Subsystem: subsystem,
+	}, []string{"base", "currency"})
+
+	registry.MustRegister(rateAge)
+	registry.MustRegister(rateStale)
+
+	return &FiatRateMetrics{
+		rateAge:   rateAge,
+		rateStale: rateStale,
+	}
+}
+
+func (fm *FiatRateMetrics) RecordFiatRateAge(base, currency string, age time.Duration) {
+	fm.rateAge.WithLabelValues(base, currency).Set(age.Seconds())
+}
+
+func (fm *FiatRateMetrics) RecordFiatRateStale(base, currency string, stale bool) {
+	var value float64
+	if stale {
+		value = 1
+	}
+	fm.rateStale.WithLabelValues(base, currency).Set(value)
+}
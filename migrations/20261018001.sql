@@ -0,0 +1,5 @@
+-- 跨链桥状态跟踪：目标链与目标链到账交易
+-- status 新增 4=PARTIAL（桥完成但收到中间代币）、5=REFUNDED（桥失败已退款）
+ALTER TABLE wallet_tx_record ADD COLUMN IF NOT EXISTS dest_chain_id VARCHAR(255) DEFAULT '';
+ALTER TABLE wallet_tx_record ADD COLUMN IF NOT EXISTS dest_tx_id    VARCHAR(500) DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_wallet_tx_record_dest_tx_id ON wallet_tx_record (dest_tx_id);
@@ -0,0 +1,54 @@
+-- 聚合器 swap 持久化：Redis 只做缓存，swap 计划与状态以数据库为准
+CREATE TABLE IF NOT EXISTS swap (
+    swap_id          VARCHAR(255) PRIMARY KEY,
+    quote_id         VARCHAR(255) DEFAULT '' NOT NULL,
+    user_address     VARCHAR(255) DEFAULT '' NOT NULL,
+    wallet_uuid      VARCHAR(255) DEFAULT '',
+    status           INTEGER DEFAULT 0,                 -- 整体状态：0=CREATED, 1=PENDING, 2=FAILED, 3=SUCCESS, 4=PARTIAL, 5=REFUNDED
+    fail_reason_code VARCHAR(100) DEFAULT '',
+    fail_message     VARCHAR(500) DEFAULT '',
+    created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_swap_quote_id ON swap (quote_id);
+CREATE INDEX IF NOT EXISTS idx_swap_user_address ON swap (user_address);
+CREATE INDEX IF NOT EXISTS idx_swap_wallet_uuid ON swap (wallet_uuid);
+
+CREATE TABLE IF NOT EXISTS swap_step (
+    guid            TEXT PRIMARY KEY DEFAULT replace(uuid_generate_v4()::text, '-', ''),
+    swap_id         VARCHAR(255) NOT NULL,
+    step_index      INTEGER NOT NULL,
+    action_type     VARCHAR(50) DEFAULT '',
+    tx_hash         VARCHAR(500) DEFAULT '',
+    status          INTEGER DEFAULT 0,
+    idempotency_key VARCHAR(255) DEFAULT '',
+    detail          JSONB NOT NULL,                    -- 完整 step（期望交易快照、typed data、跨链状态等）
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_swap_step_swap_step ON swap_step (swap_id, step_index);
+CREATE INDEX IF NOT EXISTS idx_swap_step_tx_hash ON swap_step (tx_hash);
+
+CREATE TABLE IF NOT EXISTS swap_idempotency (
+    guid            TEXT PRIMARY KEY DEFAULT replace(uuid_generate_v4()::text, '-', ''),
+    swap_id         VARCHAR(255) NOT NULL,
+    step_index      INTEGER NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    tx_hash         VARCHAR(500) DEFAULT '',           -- 交易哈希或订单哈希
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_swap_idempotency_key ON swap_idempotency (swap_id, step_index, idempotency_key);
+
+-- 报价：retain_until 之后只有被 swap 引用的报价仍可读取（用于事后查询 swap 状态）
+CREATE TABLE IF NOT EXISTS swap_quote (
+    quote_id     VARCHAR(255) PRIMARY KEY,
+    user_address VARCHAR(255) DEFAULT '',
+    wallet_uuid  VARCHAR(255) DEFAULT '',
+    expires_at   TIMESTAMP,
+    retain_until TIMESTAMP NOT NULL,
+    detail       JSONB NOT NULL,                       -- 完整报价（含 provider 原始响应）
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_swap_quote_retain_until ON swap_quote (retain_until);
@@ -0,0 +1,5 @@
+-- 幂等键预占：广播前先占用 (swap_id, step_index, idempotency_key)，租约过期视为放弃
+-- completed=false 表示预占中，已有记录均为已完成
+ALTER TABLE swap_idempotency ADD COLUMN IF NOT EXISTS completed   BOOLEAN DEFAULT TRUE NOT NULL;
+ALTER TABLE swap_idempotency ADD COLUMN IF NOT EXISTS owner       VARCHAR(255) DEFAULT '';
+ALTER TABLE swap_idempotency ADD COLUMN IF NOT EXISTS lease_until TIMESTAMP;
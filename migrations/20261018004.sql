@@ -0,0 +1,21 @@
+-- 交易状态流转审计：wallet_tx_record 与 swap 的每次状态变化各记一行，同时作为 websocket 推送的事件源
+CREATE TABLE IF NOT EXISTS tx_status_transition (
+    id               BIGSERIAL PRIMARY KEY,
+    kind             VARCHAR(20) NOT NULL,              -- tx: wallet_tx_record 记录, swap: swap 整体状态
+    record_guid      VARCHAR(255) DEFAULT '',           -- wallet_tx_record.guid（kind=tx）
+    operation_id     VARCHAR(255) DEFAULT '',           -- swap_id
+    step_index       INTEGER DEFAULT 0,
+    wallet_uuid      VARCHAR(255) DEFAULT '',
+    chain_id         VARCHAR(255) DEFAULT '',
+    tx_id            VARCHAR(500) DEFAULT '',
+    from_status      INTEGER NOT NULL,
+    to_status        INTEGER NOT NULL,
+    fail_reason_code VARCHAR(100) DEFAULT '',
+    fail_reason_msg  VARCHAR(500) DEFAULT '',
+    source           VARCHAR(50) DEFAULT '',            -- 触发方：api / worker
+    created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_tx_status_transition_record_guid ON tx_status_transition (record_guid);
+CREATE INDEX IF NOT EXISTS idx_tx_status_transition_operation_id ON tx_status_transition (operation_id);
+CREATE INDEX IF NOT EXISTS idx_tx_status_transition_wallet_uuid ON tx_status_transition (wallet_uuid);
+CREATE INDEX IF NOT EXISTS idx_tx_status_transition_created_at ON tx_status_transition (created_at, id);
@@ -0,0 +1,5 @@
+-- 卡住交易的替换（speed_up）/ 取消（cancel）：替换交易新建一条记录，沿用 operation_id / step_index，
+-- 原交易与其所有替换交易的 replace_group 均为原交易 guid，任一笔上链即决定该步骤结果
+ALTER TABLE wallet_tx_record ADD COLUMN IF NOT EXISTS replace_group VARCHAR(255) DEFAULT '';
+ALTER TABLE wallet_tx_record ADD COLUMN IF NOT EXISTS replace_kind  VARCHAR(20) DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_wallet_tx_record_replace_group ON wallet_tx_record (replace_group);
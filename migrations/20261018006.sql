@@ -0,0 +1,9 @@
+-- 交易确认深度：required_confirmations 为 0 时使用 worker 默认值，L1 / L2 分别配置
+ALTER TABLE chain ADD COLUMN IF NOT EXISTS required_confirmations INTEGER DEFAULT 0;
+ALTER TABLE chain ADD COLUMN IF NOT EXISTS is_l2                  BOOLEAN DEFAULT FALSE;
+
+-- CONFIRMING 状态下记录交易所在区块哈希，用于检测重组
+ALTER TABLE wallet_tx_record ADD COLUMN IF NOT EXISTS block_hash VARCHAR(100) DEFAULT '';
+
+-- 最后一次在链上查到交易的时间，PENDING 超时从此计算（重组退回 PENDING 的交易不按创建时间超时）
+ALTER TABLE wallet_tx_record ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP;
@@ -0,0 +1,7 @@
+-- 按链调度 pending 交易扫描：出块时间决定轮询间隔与超时，0 使用 worker 默认值
+ALTER TABLE chain ADD COLUMN IF NOT EXISTS block_time_ms INTEGER DEFAULT 0;
+
+-- next_check_at 既是下次检查时间，也是多实例间的租约：领取时先推后到租约到期，检查完再写入真正的下次时间
+ALTER TABLE wallet_tx_record ADD COLUMN IF NOT EXISTS next_check_at TIMESTAMP;
+ALTER TABLE wallet_tx_record ADD COLUMN IF NOT EXISTS check_count   INTEGER DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_wallet_tx_record_chain_next_check ON wallet_tx_record (chain_id, status, next_check_at);
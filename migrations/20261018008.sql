@@ -0,0 +1,2 @@
+-- Solana: recent blockhash 的最后有效区块高度，超过后仍未上链的交易不会再被处理
+ALTER TABLE wallet_tx_record ADD COLUMN IF NOT EXISTS last_valid_block_height BIGINT DEFAULT 0;
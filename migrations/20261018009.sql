@@ -0,0 +1,12 @@
+-- 行情 worker 写入 market_price 时记录聚合置信度与行情源
+ALTER TABLE market_price ADD COLUMN IF NOT EXISTS confidence NUMERIC(5, 4) DEFAULT 0;
+ALTER TABLE market_price ADD COLUMN IF NOT EXISTS source VARCHAR(255) DEFAULT '';
+
+-- 行情按 token_id 单行 upsert：先清理重复行（保留最近更新的一行），再建唯一索引；空 token_id 的历史行不参与
+DELETE FROM market_price a
+    USING market_price b
+WHERE a.token_id = b.token_id
+  AND a.token_id <> ''
+  AND (COALESCE(a.updated_at, a.created_at, 'epoch') < COALESCE(b.updated_at, b.created_at, 'epoch')
+    OR (COALESCE(a.updated_at, a.created_at, 'epoch') = COALESCE(b.updated_at, b.created_at, 'epoch') AND a.guid < b.guid));
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_market_price_token_id ON market_price (token_id) WHERE token_id <> '';
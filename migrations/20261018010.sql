@@ -0,0 +1,3 @@
+-- 法币汇率 worker 记录汇率来源与来源给出的汇率时间
+ALTER TABLE fiat_currency_rate ADD COLUMN IF NOT EXISTS source VARCHAR(64) DEFAULT '';
+ALTER TABLE fiat_currency_rate ADD COLUMN IF NOT EXISTS rate_time TIMESTAMP;
@@ -0,0 +1,236 @@
+package fee
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+	"github.com/roothash-pay/wallet-services/services/common/chaininfo"
+	"github.com/roothash-pay/wallet-services/services/grpc_client/account"
+)
+
+// defaultTTL 费用建议的缓存时长，约一个以太坊区块
+const defaultTTL = 12 * time.Second
+
+// Client is the part of the account service the fee service needs
+type Client interface {
+	GetFee(ctx context.Context, consumerToken, chain, coin, network string) (*account.FeeInfo, error)
+}
+
+// PriceSource provides the USD price and decimals of a chain's native coin
+type PriceSource interface {
+	NativePrice(ctx context.Context, chainID string) (float64, int, error)
+}
+
+// Level is the fee of one speed. EIP-1559 chains set BaseFee, MaxFeePerGas and MaxPriorityFeePerGas;
+// legacy chains set GasPrice. On Solana GasPrice is the flat fee of a tx in lamports.
+type Level struct {
+	BaseFee              *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	GasPrice             *big.Int
+}
+
+// Effective returns the price per gas expected to be paid: base fee plus tip, or the legacy gas price
+func (l Level) Effective() *big.Int {
+	if l.GasPrice != nil {
+		return new(big.Int).Set(l.GasPrice)
+	}
+	return new(big.Int).Add(l.BaseFee, l.MaxPriorityFeePerGas)
+}
+
+// Suggestion holds the slow / normal / fast fee levels of a chain
+type Suggestion struct {
+	Solana bool // 按笔收费，与 gas 无关
+	Slow   Level
+	Normal Level
+	Fast   Level
+}
+
+type cachedSuggestion struct {
+	suggestion *Suggestion
+	expiresAt  time.Time
+}
+
+// Service suggests network fees from the account service's getFee, cached per chain
+type Service struct {
+	client    Client
+	chainInfo chaininfo.Provider
+	prices    PriceSource // 可选，nil 时不计算法币金额
+	ttl       time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedSuggestion
+}
+
+// NewService creates a fee service; ttl <= 0 uses the default cache lifetime
+func NewService(client Client, chainInfo chaininfo.Provider, prices PriceSource, ttl time.Duration) *Service {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	return &Service{
+		client:    client,
+		chainInfo: chainInfo,
+		prices:    prices,
+		ttl:       ttl,
+		cache:     make(map[string]cachedSuggestion),
+	}
+}
+
+// Suggest returns the fee levels of chainID
+func (s *Service) Suggest(ctx context.Context, chainID string) (*Suggestion, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[chainID]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.suggestion, nil
+	}
+
+	if s.chainInfo == nil {
+		return nil, fmt.Errorf("chain info provider not configured")
+	}
+	info, err := s.chainInfo.Get(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := s.client.GetFee(ctx, info.ConsumerToken, info.WalletChain, info.WalletCoin, info.WalletNetwork)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestion := &Suggestion{Solana: strings.EqualFold(info.ChainType, string(backend.ChainTypeSolana))}
+	for _, l := range []struct {
+		raw   string
+		level *Level
+	}{{raw.Slow, &suggestion.Slow}, {raw.Normal, &suggestion.Normal}, {raw.Fast, &suggestion.Fast}} {
+		if *l.level, err = ParseLevel(l.raw, suggestion.Solana); err != nil {
+			return nil, fmt.Errorf("chain %s: %w", chainID, err)
+		}
+	}
+
+	s.mu.Lock()
+	s.cache[chainID] = cachedSuggestion{suggestion: suggestion, expiresAt: time.Now().Add(s.ttl)}
+	s.mu.Unlock()
+	return suggestion, nil
+}
+
+// GasPrice returns the normal level's expected price per gas, so quote ranking costs gas at the
+// live network fee. Solana quotes estimate fees in lamports, so its gas price is 1.
+func (s *Service) GasPrice(ctx context.Context, chainID string) (*big.Int, error) {
+	suggestion, err := s.Suggest(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.Solana {
+		return big.NewInt(1), nil
+	}
+	return suggestion.Normal.Effective(), nil
+}
+
+// Estimate returns the fee tiers of a tx using gasLimit gas (ignored on Solana),
+// with the expected cost in the native coin's smallest unit and in USD when priced
+func (s *Service) Estimate(ctx context.Context, chainID string, gasLimit uint64) (*backend.FeeEstimate, error) {
+	suggestion, err := s.Suggest(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		nativePrice float64
+		decimals    int
+	)
+	if s.prices != nil {
+		if nativePrice, decimals, err = s.prices.NativePrice(ctx, chainID); err != nil {
+			nativePrice = 0
+		}
+	}
+
+	estimate := &backend.FeeEstimate{}
+	if !suggestion.Solana {
+		estimate.GasLimit = strconv.FormatUint(gasLimit, 10)
+	}
+	tier := func(l Level) *backend.FeeTier {
+		t := &backend.FeeTier{}
+		var fee *big.Int
+		switch {
+		case suggestion.Solana:
+			fee = new(big.Int).Set(l.GasPrice)
+		case l.MaxFeePerGas != nil:
+			t.MaxFeePerGas = l.MaxFeePerGas.String()
+			t.MaxPriorityFeePerGas = l.MaxPriorityFeePerGas.String()
+			fee = new(big.Int).Mul(l.Effective(), new(big.Int).SetUint64(gasLimit))
+		default:
+			t.GasPrice = l.GasPrice.String()
+			fee = new(big.Int).Mul(l.GasPrice, new(big.Int).SetUint64(gasLimit))
+		}
+		t.Fee = fee.String()
+		if nativePrice > 0 {
+			amount, _ := new(big.Float).Quo(new(big.Float).SetInt(fee), new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))).Float64()
+			t.FeeUSD = amount * nativePrice
+		}
+		return t
+	}
+	estimate.Slow = tier(suggestion.Slow)
+	estimate.Normal = tier(suggestion.Normal)
+	estimate.Fast = tier(suggestion.Fast)
+	return estimate, nil
+}
+
+// ParseLevel parses one fee level reported by the account service. EVM adapters report
+// "gasPrice|gasTipCap" optionally followed by a "|*N" multiplier for faster levels; a single
+// number is a legacy gas price (the flat fee in lamports on Solana).
+// For EIP-1559 the base fee is gasPrice - gasTipCap, and the max fee leaves room for the base fee
+// to double, the same rule wallets use.
+func ParseLevel(raw string, solana bool) (Level, error) {
+	parts := strings.Split(strings.TrimSpace(raw), "|")
+	multiplier := int64(1)
+	if last := parts[len(parts)-1]; len(parts) > 1 && strings.HasPrefix(last, "*") {
+		m, err := strconv.ParseInt(strings.TrimPrefix(last, "*"), 10, 64)
+		if err != nil || m <= 0 {
+			return Level{}, fmt.Errorf("invalid fee multiplier %q", last)
+		}
+		multiplier = m
+		parts = parts[:len(parts)-1]
+	}
+	mult := big.NewInt(multiplier)
+
+	price, err := parseAmount(parts[0])
+	if err != nil {
+		return Level{}, fmt.Errorf("invalid fee %q: %w", raw, err)
+	}
+	if len(parts) == 1 || solana {
+		return Level{GasPrice: price.Mul(price, mult)}, nil
+	}
+
+	tip, err := parseAmount(parts[1])
+	if err != nil {
+		return Level{}, fmt.Errorf("invalid fee %q: %w", raw, err)
+	}
+	baseFee := new(big.Int).Sub(price, tip)
+	if baseFee.Sign() < 0 {
+		baseFee.SetInt64(0)
+	}
+	priority := tip.Mul(tip, mult)
+	maxFee := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), priority)
+	return Level{BaseFee: baseFee, MaxFeePerGas: maxFee, MaxPriorityFeePerGas: priority}, nil
+}
+
+// parseAmount accepts decimal and 0x-prefixed hex integers
+func parseAmount(s string) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return hexutil.DecodeBig(s)
+	}
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok || v.Sign() < 0 {
+		return nil, fmt.Errorf("not an amount")
+	}
+	return v, nil
+}
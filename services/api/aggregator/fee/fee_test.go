@@ -0,0 +1,111 @@
+package fee
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roothash-pay/wallet-services/services/common/chaininfo"
+	"github.com/roothash-pay/wallet-services/services/grpc_client/account"
+)
+
+type fakeChains map[string]*chaininfo.Info
+
+func (f fakeChains) WarmUp(ctx context.Context) error { return nil }
+func (f fakeChains) Get(ctx context.Context, chainID string) (*chaininfo.Info, error) {
+	if info, ok := f[chainID]; ok {
+		return info, nil
+	}
+	return nil, fmt.Errorf("chain %s not found", chainID)
+}
+func (f fakeChains) Refresh(ctx context.Context, chainID string) (*chaininfo.Info, error) {
+	return f.Get(ctx, chainID)
+}
+
+type fakeClient struct {
+	fees  map[string]*account.FeeInfo
+	calls int
+}
+
+func (c *fakeClient) GetFee(ctx context.Context, consumerToken, chain, coin, network string) (*account.FeeInfo, error) {
+	c.calls++
+	return c.fees[chain], nil
+}
+
+type fakePrices struct{}
+
+func (fakePrices) NativePrice(ctx context.Context, chainID string) (float64, int, error) {
+	if chainID == "solana" {
+		return 100, 9, nil
+	}
+	return 2000, 18, nil
+}
+
+func TestParseLevel(t *testing.T) {
+	// 30 gwei gas price with a 2 gwei tip: base fee 28 gwei
+	l, err := ParseLevel("30000000000|2000000000", false)
+	require.NoError(t, err)
+	assert.Equal(t, "28000000000", l.BaseFee.String())
+	assert.Equal(t, "2000000000", l.MaxPriorityFeePerGas.String())
+	assert.Equal(t, "58000000000", l.MaxFeePerGas.String())
+	assert.Equal(t, "30000000000", l.Effective().String())
+
+	l, err = ParseLevel("30000000000|2000000000|*2", false)
+	require.NoError(t, err)
+	assert.Equal(t, "4000000000", l.MaxPriorityFeePerGas.String())
+	assert.Equal(t, "60000000000", l.MaxFeePerGas.String())
+
+	l, err = ParseLevel("0x3b9aca00|*3", false)
+	require.NoError(t, err)
+	assert.Nil(t, l.MaxFeePerGas)
+	assert.Equal(t, "3000000000", l.GasPrice.String())
+
+	_, err = ParseLevel("fast", false)
+	assert.Error(t, err)
+	_, err = ParseLevel("1|1|*0", false)
+	assert.Error(t, err)
+}
+
+func TestEstimate(t *testing.T) {
+	client := &fakeClient{fees: map[string]*account.FeeInfo{
+		"Ethereum": {Slow: "30000000000|1000000000", Normal: "30000000000|1000000000|*2", Fast: "30000000000|1000000000|*3"},
+		"Solana":   {Slow: "5000", Normal: "10000", Fast: "20000"},
+	}}
+	chains := fakeChains{
+		"1":      {ChainID: "1", ChainType: "EVM", WalletChain: "Ethereum"},
+		"solana": {ChainID: "solana", ChainType: "SOLANA", WalletChain: "Solana"},
+	}
+	s := NewService(client, chains, fakePrices{}, 0)
+	ctx := context.Background()
+
+	est, err := s.Estimate(ctx, "1", 100000)
+	require.NoError(t, err)
+	assert.Equal(t, "100000", est.GasLimit)
+	assert.Equal(t, "3000000000000000", est.Slow.Fee)
+	assert.Equal(t, "3100000000000000", est.Normal.Fee)
+	assert.Equal(t, "2000000000", est.Normal.MaxPriorityFeePerGas)
+	assert.Equal(t, "60000000000", est.Normal.MaxFeePerGas)
+	assert.InDelta(t, 6.2, est.Normal.FeeUSD, 1e-9)
+
+	gasPrice, err := s.GasPrice(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "31000000000", gasPrice.String())
+	assert.Equal(t, 1, client.calls, "suggestion is cached per chain")
+
+	est, err = s.Estimate(ctx, "solana", 0)
+	require.NoError(t, err)
+	assert.Empty(t, est.GasLimit)
+	assert.Equal(t, "10000", est.Normal.Fee)
+	assert.Empty(t, est.Normal.GasPrice)
+	assert.InDelta(t, 0.001, est.Normal.FeeUSD, 1e-12)
+
+	gasPrice, err = s.GasPrice(ctx, "solana")
+	require.NoError(t, err)
+	assert.Equal(t, "1", gasPrice.String())
+
+	_, err = s.Estimate(ctx, "56", 100000)
+	assert.Error(t, err)
+}
@@ -0,0 +1,311 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/config"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+const (
+	defaultFailureThreshold   = 5
+	defaultErrorRateThreshold = 0.5
+	defaultMinRequests        = 20
+	defaultWindowSize         = 100
+	defaultOpenDuration       = 30 * time.Second
+	defaultQuoteTimeout       = 5 * time.Second
+)
+
+// ErrCircuitOpen is returned when a provider's circuit is open and the call was not made
+var ErrCircuitOpen = errors.New("circuit open")
+
+// BreakerMetrics receives provider call results and circuit state changes
+type BreakerMetrics interface {
+	RecordProviderRequest(provider, method string, success bool, latency time.Duration)
+	RecordProviderCircuitState(provider string, state backend.CircuitState)
+}
+
+// BreakerSettings holds the resolved breaker settings of one provider
+type BreakerSettings struct {
+	FailureThreshold   int
+	ErrorRateThreshold float64
+	MinRequests        int
+	WindowSize         int
+	OpenDuration       time.Duration
+	QuoteTimeout       time.Duration
+}
+
+// NewBreakerSettings resolves the settings of the named provider from config, filling defaults
+func NewBreakerSettings(cfg config.BreakerConfig, name string) BreakerSettings {
+	s := BreakerSettings{
+		FailureThreshold:   cfg.FailureThreshold,
+		ErrorRateThreshold: cfg.ErrorRateThreshold,
+		MinRequests:        cfg.MinRequests,
+		WindowSize:         cfg.WindowSize,
+		OpenDuration:       time.Duration(cfg.OpenSeconds) * time.Second,
+		QuoteTimeout:       time.Duration(cfg.QuoteTimeoutMs) * time.Millisecond,
+	}
+	if ms, ok := cfg.ProviderTimeoutsMs[name]; ok && ms > 0 {
+		s.QuoteTimeout = time.Duration(ms) * time.Millisecond
+	}
+
+	if s.FailureThreshold <= 0 {
+		s.FailureThreshold = defaultFailureThreshold
+	}
+	if s.ErrorRateThreshold <= 0 {
+		s.ErrorRateThreshold = defaultErrorRateThreshold
+	}
+	if s.MinRequests <= 0 {
+		s.MinRequests = defaultMinRequests
+	}
+	if s.WindowSize <= 0 {
+		s.WindowSize = defaultWindowSize
+	}
+	if s.OpenDuration <= 0 {
+		s.OpenDuration = defaultOpenDuration
+	}
+	if s.QuoteTimeout <= 0 {
+		s.QuoteTimeout = defaultQuoteTimeout
+	}
+	return s
+}
+
+// callResult is one recorded provider call
+type callResult struct {
+	success bool
+	latency time.Duration
+}
+
+// BreakerProvider wraps a Provider with a circuit breaker, a per-call quote deadline and health stats.
+// After FailureThreshold consecutive failures (or a window error rate above ErrorRateThreshold)
+// the circuit opens; once OpenDuration has passed a single probe call is let through (HALF_OPEN)
+// and its outcome closes or re-opens the circuit.
+type BreakerProvider struct {
+	Provider
+
+	settings BreakerSettings
+	metrics  BreakerMetrics
+	now      func() time.Time
+
+	mu                  sync.Mutex
+	state               backend.CircuitState
+	consecutiveFailures int
+	openedAt            time.Time
+	probing             bool
+	window              []callResult
+	lastError           string
+}
+
+// NewBreakerProvider wraps p with a circuit breaker
+func NewBreakerProvider(p Provider, settings BreakerSettings, metrics BreakerMetrics) *BreakerProvider {
+	b := &BreakerProvider{
+		Provider: p,
+		settings: settings,
+		metrics:  metrics,
+		now:      time.Now,
+		state:    backend.CircuitClosed,
+	}
+	if metrics != nil {
+		metrics.RecordProviderCircuitState(p.Name(), backend.CircuitClosed)
+	}
+	return b
+}
+
+// GetQuote fetches a quote within the provider's quote deadline unless the circuit is open
+func (b *BreakerProvider) GetQuote(ctx context.Context, req *backend.QuoteRequest) (*backend.Quote, error) {
+	if !b.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, b.settings.QuoteTimeout)
+	defer cancel()
+
+	start := b.now()
+	quote, err := b.Provider.GetQuote(callCtx, req)
+	if err != nil && ctx.Err() != nil {
+		// 调用方取消（例如客户端断开）不计入 provider 健康度
+		b.release()
+		return nil, err
+	}
+	if err != nil && callCtx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("quote timeout after %s: %w", b.settings.QuoteTimeout, err)
+	}
+	b.record("quote", err, b.now().Sub(start))
+	return quote, err
+}
+
+// BuildSwap builds the swap through the wrapped provider. The user already picked this quote,
+// so the call is not blocked by an open circuit, but its outcome still counts towards health.
+func (b *BreakerProvider) BuildSwap(ctx context.Context, quote *backend.Quote, userAddress string) (*backend.BuildSwapResponse, error) {
+	start := b.now()
+	resp, err := b.Provider.BuildSwap(ctx, quote, userAddress)
+	b.record("build_swap", err, b.now().Sub(start))
+	return resp, err
+}
+
+// CheckRoute delegates to the wrapped provider's RouteChecker if it has one
+func (b *BreakerProvider) CheckRoute(fromChainID, toChainID string) error {
+	if checker, ok := b.Provider.(RouteChecker); ok {
+		return checker.CheckRoute(fromChainID, toChainID)
+	}
+	return nil
+}
+
+// Unwrap returns the wrapped provider
+func (b *BreakerProvider) Unwrap() Provider {
+	return b.Provider
+}
+
+// ReportFailure records a failure observed outside of the provider call itself,
+// e.g. a built swap that fails simulation
+func (b *BreakerProvider) ReportFailure(method string, err error) {
+	b.record(method, err, 0)
+}
+
+// allow reports whether a quote call may be made, moving OPEN to HALF_OPEN once the open period ended
+func (b *BreakerProvider) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case backend.CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.settings.OpenDuration {
+			return false
+		}
+		b.setState(backend.CircuitHalfOpen)
+		b.probing = true
+		return true
+	case backend.CircuitHalfOpen:
+		// 只放行一个探测请求
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// release gives back a HALF_OPEN probe slot without recording an outcome
+func (b *BreakerProvider) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *BreakerProvider) record(method string, err error, latency time.Duration) {
+	success := err == nil
+	if b.metrics != nil {
+		b.metrics.RecordProviderRequest(b.Name(), method, success, latency)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.window = append(b.window, callResult{success: success, latency: latency})
+	if len(b.window) > b.settings.WindowSize {
+		b.window = b.window[len(b.window)-b.settings.WindowSize:]
+	}
+
+	if success {
+		b.consecutiveFailures = 0
+		if b.state == backend.CircuitHalfOpen {
+			b.probing = false
+			b.window = nil // 恢复后重新统计
+			b.setState(backend.CircuitClosed)
+			log.Info("Provider circuit closed", "provider", b.Name())
+		}
+		return
+	}
+
+	b.consecutiveFailures++
+	b.lastError = err.Error()
+
+	switch b.state {
+	case backend.CircuitHalfOpen:
+		b.probing = false
+		b.open()
+	case backend.CircuitClosed:
+		if b.consecutiveFailures >= b.settings.FailureThreshold {
+			b.open()
+			return
+		}
+		if requests, failures := b.windowCounts(); requests >= b.settings.MinRequests &&
+			float64(failures)/float64(requests) >= b.settings.ErrorRateThreshold {
+			b.open()
+		}
+	}
+}
+
+func (b *BreakerProvider) open() {
+	b.openedAt = b.now()
+	b.setState(backend.CircuitOpen)
+	log.Warn("Provider circuit opened", "provider", b.Name(), "consecutiveFailures", b.consecutiveFailures, "lastError", b.lastError)
+}
+
+func (b *BreakerProvider) setState(state backend.CircuitState) {
+	b.state = state
+	if b.metrics != nil {
+		b.metrics.RecordProviderCircuitState(b.Name(), state)
+	}
+}
+
+func (b *BreakerProvider) windowCounts() (int, int) {
+	failures := 0
+	for _, r := range b.window {
+		if !r.success {
+			failures++
+		}
+	}
+	return len(b.window), failures
+}
+
+// Health returns a snapshot of the provider's circuit state and window stats
+func (b *BreakerProvider) Health() *backend.ProviderHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	requests, failures := b.windowCounts()
+	h := &backend.ProviderHealth{
+		Provider:            b.Name(),
+		State:               b.state,
+		ConsecutiveFailures: b.consecutiveFailures,
+		Requests:            requests,
+		Failures:            failures,
+		TimeoutMs:           b.settings.QuoteTimeout.Milliseconds(),
+		LastError:           b.lastError,
+	}
+	if requests > 0 {
+		h.ErrorRate = float64(failures) / float64(requests)
+	}
+
+	var latencies []time.Duration
+	var total time.Duration
+	for _, r := range b.window {
+		if r.latency > 0 {
+			latencies = append(latencies, r.latency)
+			total += r.latency
+		}
+	}
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		h.AvgLatencyMs = (total / time.Duration(len(latencies))).Milliseconds()
+		h.P95LatencyMs = latencies[(len(latencies)*95-1)/100].Milliseconds()
+	}
+
+	if b.state != backend.CircuitClosed {
+		openedAt := b.openedAt
+		nextProbeAt := openedAt.Add(b.settings.OpenDuration)
+		h.OpenedAt = &openedAt
+		h.NextProbeAt = &nextProbeAt
+	}
+	return h
+}
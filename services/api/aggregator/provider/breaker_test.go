@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roothash-pay/wallet-services/config"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+type flakyProvider struct {
+	fakeProvider
+	err   error
+	calls int
+	delay time.Duration
+}
+
+func (f *flakyProvider) GetQuote(ctx context.Context, req *backend.QuoteRequest) (*backend.Quote, error) {
+	f.calls++
+	if f.delay > 0 {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &backend.Quote{Provider: f.name}, nil
+}
+
+func TestBreakerProvider(t *testing.T) {
+	p := &flakyProvider{fakeProvider: fakeProvider{name: "flaky"}, err: errors.New("upstream 500")}
+	now := time.Now()
+	b := NewBreakerProvider(p, BreakerSettings{
+		FailureThreshold:   3,
+		ErrorRateThreshold: 0.5,
+		MinRequests:        100,
+		WindowSize:         100,
+		OpenDuration:       30 * time.Second,
+		QuoteTimeout:       time.Second,
+	}, nil)
+	b.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err := b.GetQuote(ctx, &backend.QuoteRequest{})
+		require.Error(t, err)
+	}
+	assert.Equal(t, backend.CircuitOpen, b.Health().State)
+
+	// open: calls are rejected without hitting the provider
+	_, err := b.GetQuote(ctx, &backend.QuoteRequest{})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 3, p.calls)
+
+	// after the open period a failing probe re-opens the circuit
+	now = now.Add(31 * time.Second)
+	_, err = b.GetQuote(ctx, &backend.QuoteRequest{})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, backend.CircuitOpen, b.Health().State)
+
+	// a successful probe closes it
+	now = now.Add(31 * time.Second)
+	p.err = nil
+	_, err = b.GetQuote(ctx, &backend.QuoteRequest{})
+	require.NoError(t, err)
+	health := b.Health()
+	assert.Equal(t, backend.CircuitClosed, health.State)
+	assert.Zero(t, health.ConsecutiveFailures)
+}
+
+func TestBreakerProviderQuoteTimeout(t *testing.T) {
+	p := &flakyProvider{fakeProvider: fakeProvider{name: "slow"}, delay: time.Second}
+	b := NewBreakerProvider(p, NewBreakerSettings(config.BreakerConfig{QuoteTimeoutMs: 20}, "slow"), nil)
+
+	start := time.Now()
+	_, err := b.GetQuote(context.Background(), &backend.QuoteRequest{})
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, 1, b.Health().Failures)
+}
@@ -74,8 +74,8 @@ type JupiterSwapRequest struct {
 
 // JupiterSwapResponse represents the response from Jupiter Swap API
 type JupiterSwapResponse struct {
-	SwapTransaction      string  `json:"swapTransaction"` // Base64 encoded transaction
-	LastValidBlockHeight float64 `json:"lastValidBlockHeight"`
+	SwapTransaction      string `json:"swapTransaction"` // Base64 encoded versioned transaction
+	LastValidBlockHeight uint64 `json:"lastValidBlockHeight"`
 }
 
 // GetQuote fetches a quote from Jupiter for Solana
@@ -192,8 +192,9 @@ func (p *Provider) BuildSwap(ctx context.Context, quote *backend.Quote, userAddr
 		ActionType: backend.ActionTypeSwap,
 		ChainID:    quote.ChainID,
 		SigningPayload: &backend.SigningPayload{
-			SerializedTx: jupSwapResp.SwapTransaction,
-			ChainID:      quote.ChainID,
+			SerializedTx:         jupSwapResp.SwapTransaction,
+			ChainID:              quote.ChainID,
+			LastValidBlockHeight: jupSwapResp.LastValidBlockHeight,
 		},
 		Description: fmt.Sprintf("Swap %s to %s via Jupiter",
 			getTokenSymbol(quote.FromToken),
@@ -210,9 +211,9 @@ func (p *Provider) Name() string {
 	return "Jupiter"
 }
 
-// SupportedChainType returns Solana
-func (p *Provider) SupportedChainType() backend.ChainType {
-	return backend.ChainTypeSolana
+// SupportedChainTypes returns Solana
+func (p *Provider) SupportedChainTypes() []backend.ChainType {
+	return []backend.ChainType{backend.ChainTypeSolana}
 }
 
 // getTokenSymbol extracts a simple token symbol from address (simulated)
@@ -222,3 +223,16 @@ func getTokenSymbol(tokenAddress string) string {
 	}
 	return tokenAddress
 }
+
+// CheckRoute Jupiter only serves same-chain swaps on Solana mainnet
+func (p *Provider) CheckRoute(fromChainID, toChainID string) error {
+	if toChainID != "" && toChainID != fromChainID {
+		return fmt.Errorf("cross-chain swap not supported")
+	}
+	switch fromChainID {
+	case "solana", "solana-mainnet":
+		return nil
+	default:
+		return fmt.Errorf("chain %s not supported", fromChainID)
+	}
+}
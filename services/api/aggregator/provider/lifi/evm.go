@@ -76,9 +76,9 @@ func (p *Provider) buildSwapEVM(ctx context.Context, quote *backend.Quote, userA
 		}
 	}
 
-	// Add SWAP action
+	// Add SWAP action（跨链为 BRIDGE，源链成功后还需跟踪目标链到账）
 	swapAction := &backend.Action{
-		ActionType: backend.ActionTypeSwap,
+		ActionType: swapActionType(quote),
 		ChainID:    quote.ChainID,
 		SigningPayload: &backend.SigningPayload{
 			To:      lifiResp.TransactionRequest.To,
@@ -122,6 +122,7 @@ func (p *Provider) convertToQuoteEVM(req *backend.QuoteRequest, lifiResp *LifiQu
 		Provider:    p.Name(),
 		ChainType:   backend.ChainTypeEVM,
 		ChainID:     req.FromChainID,
+		ToChainID:   crossChainID(req),
 		FromToken:   req.FromToken,
 		ToToken:     req.ToToken,
 		FromAmount:  req.Amount, // 用户手动输入的数量
@@ -95,9 +95,35 @@ func (p *Provider) Name() string {
 	return "lifi"
 }
 
-// SupportedChainType returns EVM (LiFi supports both EVM and Solana, but we start with EVM)
-func (p *Provider) SupportedChainType() backend.ChainType {
-	return backend.ChainTypeEVM
+// SupportedChainTypes returns EVM and Solana
+func (p *Provider) SupportedChainTypes() []backend.ChainType {
+	return []backend.ChainType{backend.ChainTypeEVM, backend.ChainTypeSolana}
+}
+
+// lifiSolanaChainID LiFi 对 Solana 主网使用的链 ID
+const lifiSolanaChainID = "1151111081099710"
+
+// isSolanaChainID 本服务的 Solana 链 ID
+func isSolanaChainID(chainID string) bool {
+	return chainID == "solana" || chainID == "solana-mainnet" || chainID == "solana-devnet"
+}
+
+// lifiChainID 转为 LiFi API 的链 ID
+func lifiChainID(chainID string) string {
+	if isSolanaChainID(chainID) {
+		return lifiSolanaChainID
+	}
+	return chainID
+}
+
+// CheckRoute LiFi 只支持 Solana 主网
+func (p *Provider) CheckRoute(fromChainID, toChainID string) error {
+	for _, id := range []string{fromChainID, toChainID} {
+		if id == "solana-devnet" {
+			return fmt.Errorf("chain %s not supported", id)
+		}
+	}
+	return nil
 }
 
 // 获取报价
@@ -124,7 +150,7 @@ func (p *Provider) GetQuote(ctx context.Context, req *backend.QuoteRequest) (*ba
 // 把报价转为标准形式
 func (p *Provider) convertToQuote(req *backend.QuoteRequest, resp *LifiQuoteResponse) *backend.Quote {
 	// check chain type
-	if req.FromChainID == "solana" || req.FromChainID == "solana-mainnet" || req.FromChainID == "solana-devnet" {
+	if isSolanaChainID(req.FromChainID) {
 		return p.convertToQuoteSolana(req, resp)
 	}
 
@@ -135,7 +161,7 @@ func (p *Provider) convertToQuote(req *backend.QuoteRequest, resp *LifiQuoteResp
 // 构建 swap actions
 func (p *Provider) BuildSwap(ctx context.Context, quote *backend.Quote, userAddress string) (*backend.BuildSwapResponse, error) {
 	// check chain type
-	if quote.ChainID == "solana" || quote.ChainID == "solana-mainnet" || quote.ChainID == "solana-devnet" {
+	if isSolanaChainID(quote.ChainID) {
 		return p.buildSwapSolana(ctx, quote, userAddress)
 	}
 
@@ -165,10 +191,10 @@ func (p *Provider) buildQuoteRequest(req *backend.QuoteRequest) (reqURL string,
 
 		// 构建跨链请求体
 		routesReq := backend.RoutesRequest{
-			FromChainId:      req.FromChainID,
+			FromChainId:      lifiChainID(req.FromChainID),
 			FromAmount:       req.Amount,
 			FromTokenAddress: req.FromToken,
-			ToChainId:        req.ToChainID,
+			ToChainId:        lifiChainID(req.ToChainID),
 			ToTokenAddress:   req.ToToken,
 			FromAddress:      req.UserAddress,
 			Slippage:         slippageDecimal,
@@ -200,8 +226,8 @@ func (p *Provider) buildQuoteRequest(req *backend.QuoteRequest) (reqURL string,
 
 	// Build query parameters
 	q := u.Query()
-	q.Set("fromChain", req.FromChainID)
-	q.Set("toChain", req.ToChainID)
+	q.Set("fromChain", lifiChainID(req.FromChainID))
+	q.Set("toChain", lifiChainID(req.ToChainID))
 	q.Set("fromToken", req.FromToken)
 	q.Set("toToken", req.ToToken)
 	q.Set("fromAmount", req.Amount)
@@ -80,6 +80,7 @@ func (p *Provider) convertToQuoteSolana(req *backend.QuoteRequest, lifiResp *Lif
 		Provider:    p.Name(),
 		ChainType:   backend.ChainTypeSolana,
 		ChainID:     req.FromChainID,
+		ToChainID:   crossChainID(req),
 		FromToken:   req.FromToken,
 		ToToken:     req.ToToken,
 		FromAmount:  req.Amount,
@@ -117,7 +118,7 @@ func (p *Provider) buildSwapSolana(ctx context.Context, quote *backend.Quote, us
 
 	// Create the swap action
 	swapAction := &backend.Action{
-		ActionType: backend.ActionTypeSwap,
+		ActionType: swapActionType(quote),
 		ChainID:    quote.ChainID,
 		SigningPayload: &backend.SigningPayload{
 			SerializedTx: serializedTx,
@@ -0,0 +1,46 @@
+package lifi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+func TestSolanaQuoteAndBuildSwap(t *testing.T) {
+	var fromChain, toChain string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromChain, toChain = r.URL.Query().Get("fromChain"), r.URL.Query().Get("toChain")
+		_, _ = w.Write([]byte(`{"estimate":{"toAmount":"990000"},"transactionRequest":{"data":"AQID"}}`))
+	}))
+	defer srv.Close()
+
+	p := NewProvider(srv.URL, "", nil)
+	assert.Contains(t, p.SupportedChainTypes(), backend.ChainTypeSolana)
+
+	quote, err := p.GetQuote(context.Background(), &backend.QuoteRequest{
+		FromChainID: "solana",
+		ToChainID:   "solana",
+		FromToken:   "So11111111111111111111111111111111111111112",
+		ToToken:     "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		Amount:      "1000000000",
+	})
+	require.NoError(t, err)
+	// 请求 LiFi 时使用 LiFi 的 Solana 链 ID
+	assert.Equal(t, lifiSolanaChainID, fromChain)
+	assert.Equal(t, lifiSolanaChainID, toChain)
+	assert.Equal(t, backend.ChainTypeSolana, quote.ChainType)
+	assert.Equal(t, "solana", quote.ChainID)
+	assert.Equal(t, "990000", quote.ToAmount)
+
+	resp, err := p.BuildSwap(context.Background(), quote, "9xQeWvG816bUx9EPjHmaT23yvVM2ZWbrrpZb9PusVFin")
+	require.NoError(t, err)
+	require.Len(t, resp.Actions, 1)
+	assert.Equal(t, backend.ActionTypeSwap, resp.Actions[0].ActionType)
+	assert.Equal(t, "AQID", resp.Actions[0].SigningPayload.SerializedTx)
+}
@@ -0,0 +1,136 @@
+package lifi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// LifiStatusResponse represents the response from LiFi status API
+type LifiStatusResponse struct {
+	Status           string `json:"status"`    // NOT_FOUND / INVALID / PENDING / DONE / FAILED
+	Substatus        string `json:"substatus"` // COMPLETED / PARTIAL / REFUNDED / WAIT_DESTINATION_TRANSACTION ...
+	SubstatusMessage string `json:"substatusMessage"`
+	Tool             string `json:"tool"`
+	Receiving        struct {
+		TxHash  string `json:"txHash"`
+		ChainID int64  `json:"chainId"`
+		Amount  string `json:"amount"`
+		Token   struct {
+			Address string `json:"address"`
+		} `json:"token"`
+	} `json:"receiving"`
+}
+
+// crossChainID returns the destination chain of a cross-chain request, empty for same-chain
+func crossChainID(req *backend.QuoteRequest) string {
+	if req.ToChainID == "" || req.ToChainID == req.FromChainID {
+		return ""
+	}
+	return req.ToChainID
+}
+
+// swapActionType 跨链 quote 的主交易为 BRIDGE
+func swapActionType(quote *backend.Quote) backend.ActionType {
+	if quote.ToChainID != "" && quote.ToChainID != quote.ChainID {
+		return backend.ActionTypeBridge
+	}
+	return backend.ActionTypeSwap
+}
+
+// GetBridgeStatus queries LiFi for the destination-chain result of a bridge tx
+// API: GET /status?txHash=&fromChain=&toChain=
+func (p *Provider) GetBridgeStatus(ctx context.Context, fromChainID, toChainID, txHash string) (*backend.BridgeStatus, error) {
+	baseURL := p.apiURL
+	if baseURL == "" {
+		baseURL = "https://li.quest/v1"
+	}
+
+	u, err := url.Parse(baseURL + "/status")
+	if err != nil {
+		return nil, fmt.Errorf("invalid status URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("txHash", txHash)
+	if fromChainID != "" {
+		q.Set("fromChain", lifiChainID(fromChainID))
+	}
+	if toChainID != "" {
+		q.Set("toChain", lifiChainID(toChainID))
+	}
+	u.RawQuery = q.Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if p.apiKey != "" {
+		httpReq.Header.Set("x-lifi-api-key", p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// 源链交易刚广播时 LiFi 可能还未索引到，返回 404
+	if resp.StatusCode == http.StatusNotFound {
+		return &backend.BridgeStatus{Status: backend.TxStatusPending, Substatus: "NOT_FOUND", DestChainID: toChainID}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("LiFi API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var statusResp LifiStatusResponse
+	if err := json.Unmarshal(body, &statusResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return convertBridgeStatus(&statusResp, toChainID), nil
+}
+
+// convertBridgeStatus maps LiFi status/substatus onto TxStatus
+func convertBridgeStatus(resp *LifiStatusResponse, toChainID string) *backend.BridgeStatus {
+	status := &backend.BridgeStatus{
+		Status:         backend.TxStatusPending,
+		Substatus:      resp.Substatus,
+		Message:        resp.SubstatusMessage,
+		DestChainID:    toChainID,
+		DestTxHash:     resp.Receiving.TxHash,
+		ReceivedToken:  resp.Receiving.Token.Address,
+		ReceivedAmount: resp.Receiving.Amount,
+	}
+	if resp.Receiving.ChainID != 0 && !isSolanaChainID(toChainID) {
+		status.DestChainID = strconv.FormatInt(resp.Receiving.ChainID, 10)
+	}
+	if status.Substatus == "" {
+		status.Substatus = resp.Status
+	}
+
+	switch resp.Status {
+	case "DONE":
+		switch resp.Substatus {
+		case "PARTIAL":
+			status.Status = backend.TxStatusPartial
+		case "REFUNDED":
+			status.Status = backend.TxStatusRefunded
+		default:
+			status.Status = backend.TxStatusSuccess
+		}
+	case "FAILED", "INVALID":
+		status.Status = backend.TxStatusFailed
+	}
+	return status
+}
@@ -45,7 +45,8 @@ func (p *Provider) convertToQuoteEVM(req *backend.QuoteRequest, quoteResp *OneIn
 }
 
 // buildSwapEVM builds the swap transaction for EVM chains
-func (p *Provider) buildSwapEVM(ctx context.Context, quote *backend.Quote, userAddress string) (*backend.BuildSwapResponse, error) {
+// permit (optional) is the ABI-encoded Permit2 permit that replaces the approve
+func (p *Provider) buildSwapEVM(ctx context.Context, quote *backend.Quote, userAddress string, permit string) (*backend.BuildSwapResponse, error) {
 	log.Info("1inch BuildSwap called", "chainID", quote.ChainID, "userAddress", userAddress)
 
 	// Call 1inch Swap API
@@ -62,6 +63,10 @@ func (p *Provider) buildSwapEVM(ctx context.Context, quote *backend.Quote, userA
 	q.Set("from", userAddress)
 	q.Set("slippage", "1")           // Default 1% slippage for now, or parse from QuoteRequest ctx if passed
 	q.Set("disableEstimate", "true") // Disable estimate to avoid failure if approval not yet set
+	if permit != "" {
+		q.Set("usePermit2", "true")
+		q.Set("permit", permit)
+	}
 
 	u.RawQuery = q.Encode()
 
@@ -0,0 +1,331 @@
+package oneinch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/utils"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// limitOrderProtocol 1inch Limit Order Protocol v4（与 Aggregation Router v6 同地址），Fusion 订单的授权对象
+const limitOrderProtocol = aggregationRouter
+
+// FusionQuoteResponse represents the response from the Fusion quoter API
+type FusionQuoteResponse struct {
+	QuoteID           interface{}             `json:"quoteId"`
+	FromTokenAmount   string                  `json:"fromTokenAmount"`
+	ToTokenAmount     string                  `json:"toTokenAmount"`
+	FeeToken          string                  `json:"feeToken"`
+	Presets           map[string]FusionPreset `json:"presets"`
+	RecommendedPreset string                  `json:"recommended_preset"`
+	SettlementAddress string                  `json:"settlementAddress"`
+}
+
+// FusionPreset is one auction preset (fast/medium/slow) of a Fusion quote
+type FusionPreset struct {
+	AuctionDuration    int64  `json:"auctionDuration"`
+	StartAuctionIn     int64  `json:"startAuctionIn"`
+	InitialRateBump    int64  `json:"initialRateBump"`
+	AuctionStartAmount string `json:"auctionStartAmount"`
+	AuctionEndAmount   string `json:"auctionEndAmount"`
+	CostInDstToken     string `json:"costInDstToken"`
+}
+
+// FusionBuildResponse represents the response from the Fusion quote/build API
+type FusionBuildResponse struct {
+	TypedData apitypes.TypedData `json:"typedData"`
+	OrderHash string             `json:"orderHash"`
+	Extension string             `json:"extension"`
+}
+
+// fusionOrderData is kept on the SIGN_ORDER step and sent back on submission
+type fusionOrderData struct {
+	QuoteID   interface{} `json:"quoteId"`
+	Extension string      `json:"extension"`
+	OrderHash string      `json:"orderHash"`
+}
+
+// FusionOrderStatusResponse represents the response from the Fusion order status API
+type FusionOrderStatusResponse struct {
+	OrderHash string `json:"orderHash"`
+	Status    string `json:"status"`
+	Fills     []struct {
+		TxHash string `json:"txHash"`
+	} `json:"fills"`
+}
+
+// fusionEnabled reports whether a gasless Fusion quote should be used for req
+func (p *Provider) fusionEnabled(req *backend.QuoteRequest) bool {
+	return req.Gasless && p.fusionAPIURL != ""
+}
+
+// getFusionQuote fetches a gasless quote from the Fusion quoter
+// API: /quoter/v2.0/{chainID}/quote/receive
+func (p *Provider) getFusionQuote(ctx context.Context, req *backend.QuoteRequest) (*backend.Quote, error) {
+	if req.UserAddress == "" {
+		return nil, fmt.Errorf("user address required for fusion quote")
+	}
+	if isNativeToken(req.FromToken) {
+		return nil, fmt.Errorf("fusion does not support selling native token")
+	}
+
+	u, err := url.Parse(fmt.Sprintf("%s/quoter/v2.0/%s/quote/receive", p.fusionAPIURL, req.FromChainID))
+	if err != nil {
+		return nil, fmt.Errorf("invalid fusion API URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("fromTokenAddress", req.FromToken)
+	q.Set("toTokenAddress", req.ToToken)
+	q.Set("amount", req.Amount)
+	q.Set("walletAddress", req.UserAddress)
+	q.Set("enableEstimate", "true")
+	u.RawQuery = q.Encode()
+
+	body, err := p.doRequest(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var quoteResp FusionQuoteResponse
+	if err := json.Unmarshal(body, &quoteResp); err != nil {
+		return nil, fmt.Errorf("failed to parse fusion quote: %w", err)
+	}
+	if quoteResp.ToTokenAmount == "" {
+		return nil, fmt.Errorf("fusion quote missing toTokenAmount")
+	}
+
+	return &backend.Quote{
+		Provider:    p.Name(),
+		ChainType:   backend.ChainTypeEVM,
+		ChainID:     req.FromChainID,
+		FromToken:   req.FromToken,
+		ToToken:     req.ToToken,
+		FromAmount:  req.Amount,
+		ToAmount:    quoteResp.ToTokenAmount,
+		GasEstimate: "0", // resolver 支付 gas
+		Fees:        "0",
+		Spender:     limitOrderProtocol,
+		Router:      limitOrderProtocol,
+		Raw:         string(body),
+		Gasless:     true,
+	}, nil
+}
+
+// buildFusionOrder builds the EIP-712 order for a Fusion quote: an approve to the
+// Limit Order Protocol (dropped by the service if allowance suffices) and a SIGN_ORDER step
+// API: /quoter/v2.0/{chainID}/quote/build
+func (p *Provider) buildFusionOrder(ctx context.Context, quote *backend.Quote, userAddress string) (*backend.BuildSwapResponse, error) {
+	log.Info("1inch Fusion BuildSwap called", "chainID", quote.ChainID, "userAddress", userAddress)
+
+	var quoteResp FusionQuoteResponse
+	if err := json.Unmarshal([]byte(quote.Raw), &quoteResp); err != nil {
+		return nil, fmt.Errorf("invalid fusion quote: %w", err)
+	}
+
+	u, err := url.Parse(fmt.Sprintf("%s/quoter/v2.0/%s/quote/build", p.fusionAPIURL, quote.ChainID))
+	if err != nil {
+		return nil, fmt.Errorf("invalid fusion API URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("fromTokenAddress", quote.FromToken)
+	q.Set("toTokenAddress", quote.ToToken)
+	q.Set("amount", quote.FromAmount)
+	q.Set("walletAddress", userAddress)
+	if quoteResp.RecommendedPreset != "" {
+		q.Set("preset", quoteResp.RecommendedPreset)
+	}
+	u.RawQuery = q.Encode()
+
+	body, err := p.doRequest(ctx, http.MethodPost, u.String(), []byte(quote.Raw))
+	if err != nil {
+		return nil, err
+	}
+
+	var buildResp FusionBuildResponse
+	if err := json.Unmarshal(body, &buildResp); err != nil {
+		return nil, fmt.Errorf("failed to parse fusion order: %w", err)
+	}
+	if buildResp.OrderHash == "" || buildResp.TypedData.PrimaryType == "" {
+		return nil, fmt.Errorf("fusion order missing typed data")
+	}
+	if buildResp.TypedData.Domain.ChainId == nil {
+		chainID, ok := new(big.Int).SetString(quote.ChainID, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid chain id: %s", quote.ChainID)
+		}
+		buildResp.TypedData.Domain.ChainId = (*math.HexOrDecimal256)(chainID)
+	}
+
+	orderData, _ := json.Marshal(&fusionOrderData{
+		QuoteID:   quoteResp.QuoteID,
+		Extension: buildResp.Extension,
+		OrderHash: buildResp.OrderHash,
+	})
+
+	// 只授权本订单的卖出数量
+	amount, ok := new(big.Int).SetString(quote.FromAmount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid fromAmount: %s", quote.FromAmount)
+	}
+	actions := []*backend.Action{
+		{
+			ActionType: backend.ActionTypeApprove,
+			ChainID:    quote.ChainID,
+			SigningPayload: &backend.SigningPayload{
+				To:      quote.FromToken,
+				Data:    utils.EncodeApproveData(limitOrderProtocol, amount),
+				Value:   "0",
+				Gas:     "60000",
+				ChainID: quote.ChainID,
+			},
+			Description: fmt.Sprintf("Approve 1inch Limit Order Protocol to spend %s", getTokenSymbol(quote.FromToken)),
+		},
+		{
+			ActionType: backend.ActionTypeSignOrder,
+			ChainID:    quote.ChainID,
+			SigningPayload: &backend.SigningPayload{
+				ChainID:   quote.ChainID,
+				TypedData: &buildResp.TypedData,
+				OrderData: string(orderData),
+			},
+			Description: fmt.Sprintf("Sign gasless order to swap %s to %s via 1inch Fusion",
+				getTokenSymbol(quote.FromToken),
+				getTokenSymbol(quote.ToToken)),
+		},
+	}
+
+	return &backend.BuildSwapResponse{
+		Actions: actions,
+	}, nil
+}
+
+// SubmitOrder submits the signed Fusion order to the relayer
+// API: /relayer/v2.0/{chainID}/order/submit
+func (p *Provider) SubmitOrder(ctx context.Context, quote *backend.Quote, step *backend.Step, signature string) (string, error) {
+	if p.fusionAPIURL == "" {
+		return "", fmt.Errorf("fusion not enabled")
+	}
+	if step.TypedData == nil {
+		return "", fmt.Errorf("step has no typed data")
+	}
+
+	var orderData fusionOrderData
+	if err := json.Unmarshal([]byte(step.OrderData), &orderData); err != nil {
+		return "", fmt.Errorf("invalid order data: %w", err)
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"order":     step.TypedData.Message,
+		"signature": signature,
+		"extension": orderData.Extension,
+		"quoteId":   orderData.QuoteID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode order: %w", err)
+	}
+
+	u := fmt.Sprintf("%s/relayer/v2.0/%s/order/submit", p.fusionAPIURL, quote.ChainID)
+	if _, err := p.doRequest(ctx, http.MethodPost, u, payload); err != nil {
+		return "", err
+	}
+
+	log.Info("1inch Fusion order submitted", "chainID", quote.ChainID, "orderHash", orderData.OrderHash)
+	return orderData.OrderHash, nil
+}
+
+// GetOrderStatus queries the Fusion order status
+// API: /orders/v2.0/{chainID}/order/status/{orderHash}
+func (p *Provider) GetOrderStatus(ctx context.Context, chainID, orderHash string) (*backend.OrderStatus, error) {
+	if p.fusionAPIURL == "" {
+		return nil, fmt.Errorf("fusion not enabled")
+	}
+
+	u := fmt.Sprintf("%s/orders/v2.0/%s/order/status/%s", p.fusionAPIURL, chainID, orderHash)
+	body, err := p.doRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var statusResp FusionOrderStatusResponse
+	if err := json.Unmarshal(body, &statusResp); err != nil {
+		return nil, fmt.Errorf("failed to parse order status: %w", err)
+	}
+
+	status := &backend.OrderStatus{
+		Status: fusionStatus(statusResp.Status),
+		Reason: statusResp.Status,
+	}
+	for _, fill := range statusResp.Fills {
+		if fill.TxHash != "" {
+			status.FillTxHashes = append(status.FillTxHashes, fill.TxHash)
+		}
+	}
+	return status, nil
+}
+
+// fusionStatus maps Fusion order statuses onto TxStatus
+func fusionStatus(status string) int {
+	switch status {
+	case "filled":
+		return backend.TxStatusSuccess
+	case "pending", "partially-filled":
+		return backend.TxStatusPending
+	case "expired", "cancelled", "false-predicate", "not-enough-balance-or-allowance", "wrong-permit", "invalid-signature":
+		return backend.TxStatusFailed
+	default:
+		return backend.TxStatusPending
+	}
+}
+
+// doRequest executes a request against the 1inch API and returns the body of a 2xx response
+func (p *Provider) doRequest(ctx context.Context, method, reqURL string, payload []byte) ([]byte, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, reqURL, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("1inch API error (status %d): %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// isNativeToken checks if the token is the native coin placeholder address
+func isNativeToken(token string) bool {
+	return token == "0x0000000000000000000000000000000000000000" ||
+		token == "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE" ||
+		token == "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"
+}
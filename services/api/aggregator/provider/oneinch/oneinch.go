@@ -9,13 +9,19 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/utils"
 	"github.com/roothash-pay/wallet-services/services/api/models/backend"
 )
 
 type Provider struct {
-	apiURL     string
-	apiKey     string
-	httpClient *http.Client
+	apiURL       string
+	apiKey       string
+	fusionAPIURL string // 为空时不启用 Fusion（gasless）模式
+	evmCaller    *utils.EVMCaller
+	usePermit2   bool // ERC20 卖出用 Permit2 签名代替对 router 的 approve
+	httpClient   *http.Client
 }
 
 // OneInchQuoteResponse represents the response from 1inch quote API
@@ -24,14 +30,18 @@ type OneInchQuoteResponse struct {
 	Gas       int64  `json:"gas"`
 }
 
-// NewProvider creates a new 1inch provider
-func NewProvider(apiURL, apiKey string) *Provider {
+// NewProvider creates a new 1inch provider; fusionAPIURL enables gasless Fusion orders and
+// usePermit2 (needs evmCaller for the Permit2 nonce lookup) enables permit signatures instead of approve txs
+func NewProvider(apiURL, apiKey, fusionAPIURL string, evmCaller *utils.EVMCaller, usePermit2 bool) *Provider {
 	if apiURL == "" {
 		apiURL = "https://api.1inch.dev/swap/v6.0"
 	}
 	return &Provider{
-		apiURL: apiURL,
-		apiKey: apiKey,
+		apiURL:       apiURL,
+		apiKey:       apiKey,
+		fusionAPIURL: fusionAPIURL,
+		evmCaller:    evmCaller,
+		usePermit2:   usePermit2,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -42,11 +52,40 @@ func (p *Provider) Name() string {
 	return "1inch"
 }
 
-func (p *Provider) SupportedChainType() backend.ChainType {
-	return backend.ChainTypeEVM
+func (p *Provider) SupportedChainTypes() []backend.ChainType {
+	return []backend.ChainType{backend.ChainTypeEVM}
+}
+
+// supportedChainIDs 1inch Swap API 支持的 EVM 链
+var supportedChainIDs = map[string]bool{
+	"1":     true, // Ethereum
+	"10":    true, // Optimism
+	"56":    true, // BSC
+	"100":   true, // Gnosis
+	"137":   true, // Polygon
+	"324":   true, // zkSync Era
+	"8453":  true, // Base
+	"42161": true, // Arbitrum
+	"43114": true, // Avalanche
+	"59144": true, // Linea
+}
+
+// CheckRoute 1inch 只支持同链 swap
+func (p *Provider) CheckRoute(fromChainID, toChainID string) error {
+	if toChainID != "" && toChainID != fromChainID {
+		return fmt.Errorf("cross-chain swap not supported")
+	}
+	if !supportedChainIDs[fromChainID] {
+		return fmt.Errorf("chain %s not supported", fromChainID)
+	}
+	return nil
 }
 
 func (p *Provider) GetQuote(ctx context.Context, req *backend.QuoteRequest) (*backend.Quote, error) {
+	if p.fusionEnabled(req) {
+		return p.getFusionQuote(ctx, req)
+	}
+
 	resp, err := p.fetchQuote(ctx, req)
 	if err != nil {
 		return nil, err
@@ -58,7 +97,17 @@ func (p *Provider) GetQuote(ctx context.Context, req *backend.QuoteRequest) (*ba
 }
 
 func (p *Provider) BuildSwap(ctx context.Context, quote *backend.Quote, userAddress string) (*backend.BuildSwapResponse, error) {
-	return p.buildSwapEVM(ctx, quote, userAddress)
+	if quote.Gasless {
+		return p.buildFusionOrder(ctx, quote, userAddress)
+	}
+	if p.usePermit2 && p.evmCaller != nil && !isNativeToken(quote.FromToken) {
+		resp, err := p.buildPermitPlan(ctx, quote, userAddress)
+		if err == nil {
+			return resp, nil
+		}
+		log.Warn("1inch permit plan unavailable, fall back to swap tx", "chainID", quote.ChainID, "err", err)
+	}
+	return p.buildSwapEVM(ctx, quote, userAddress, "")
 }
 
 // buildQuoteRequest builds the quote request URL
@@ -0,0 +1,95 @@
+package oneinch
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/utils"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// aggregationRouter 1inch Aggregation Router v6，Permit2 授权的 spender
+const aggregationRouter = "0x111111125421cA6dc452d289314280a0f8842A65"
+
+const (
+	permitExpiration  = 30 * 24 * time.Hour // Permit2 授权有效期
+	permitSigDeadline = 30 * time.Minute    // 签名有效期
+)
+
+// buildPermitPlan returns an approve of the sell amount to Permit2 (dropped by the service if allowance suffices),
+// a Permit2 PermitSingle for the router to sign, and the swap action to be built after signing
+func (p *Provider) buildPermitPlan(ctx context.Context, quote *backend.Quote, userAddress string) (*backend.BuildSwapResponse, error) {
+	amount, ok := new(big.Int).SetString(quote.FromAmount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %s", quote.FromAmount)
+	}
+
+	nonce, err := p.evmCaller.GetPermit2Nonce(ctx, quote.ChainID, userAddress, quote.FromToken, aggregationRouter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permit2 nonce: %w", err)
+	}
+
+	now := time.Now()
+	typedData, err := utils.NewPermit2SingleTypedData(
+		quote.ChainID,
+		quote.FromToken,
+		amount,
+		uint64(now.Add(permitExpiration).Unix()),
+		nonce,
+		aggregationRouter,
+		uint64(now.Add(permitSigDeadline).Unix()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []*backend.Action{
+		{
+			ActionType: backend.ActionTypeApprove,
+			ChainID:    quote.ChainID,
+			SigningPayload: &backend.SigningPayload{
+				To:      quote.FromToken,
+				Data:    utils.EncodeApproveData(utils.Permit2Address, amount),
+				Value:   "0",
+				Gas:     "60000",
+				ChainID: quote.ChainID,
+			},
+			Description: fmt.Sprintf("Approve Permit2 to spend %s", getTokenSymbol(quote.FromToken)),
+		},
+		{
+			ActionType: backend.ActionTypeSignTypedData,
+			ChainID:    quote.ChainID,
+			SigningPayload: &backend.SigningPayload{
+				ChainID:   quote.ChainID,
+				TypedData: typedData,
+			},
+			Description: fmt.Sprintf("Sign permit for 1inch to spend %s", getTokenSymbol(quote.FromToken)),
+		},
+		{
+			ActionType: backend.ActionTypeSwap,
+			ChainID:    quote.ChainID,
+			Description: fmt.Sprintf("Swap %s to %s via 1inch",
+				getTokenSymbol(quote.FromToken),
+				getTokenSymbol(quote.ToToken)),
+		},
+	}
+
+	return &backend.BuildSwapResponse{
+		Actions: actions,
+	}, nil
+}
+
+// BuildSwapWithPermit builds the swap tx that consumes the signed Permit2 permit
+func (p *Provider) BuildSwapWithPermit(ctx context.Context, quote *backend.Quote, userAddress string, permit *backend.PermitSignature) (*backend.BuildSwapResponse, error) {
+	log.Info("1inch BuildSwapWithPermit called", "chainID", quote.ChainID, "userAddress", userAddress)
+
+	encoded, err := utils.EncodePermit2Single(permit.TypedData, permit.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode permit: %w", err)
+	}
+	return p.buildSwapEVM(ctx, quote, userAddress, encoded)
+}
@@ -1,3 +1,164 @@
 package oneinch
 
-// TODO: Implement Solana support for 1inch (if/when available)
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// Solana implements the Provider interface for 1inch swaps on Solana
+// 复用 EVM Provider 的 HTTP 配置，但不内嵌，避免继承 Fusion 的 OrderProvider 方法
+type Solana struct {
+	api Provider
+}
+
+// SolanaQuoteResponse represents the response from 1inch Solana quote API
+type SolanaQuoteResponse struct {
+	InputMint      string `json:"inputMint"`
+	OutputMint     string `json:"outputMint"`
+	InAmount       string `json:"inAmount"`
+	OutAmount      string `json:"outAmount"`
+	SlippageBps    int    `json:"slippageBps"`
+	PriceImpactPct string `json:"priceImpactPct"`
+}
+
+// SolanaSwapResponse represents the response from 1inch Solana swap API
+type SolanaSwapResponse struct {
+	SwapTransaction      string `json:"swapTransaction"` // Base64 encoded versioned transaction
+	LastValidBlockHeight int64  `json:"lastValidBlockHeight"`
+}
+
+// NewSolanaProvider creates a new 1inch Solana provider
+func NewSolanaProvider(apiURL, apiKey string) *Solana {
+	if apiURL == "" {
+		apiURL = "https://api.1inch.dev/solana/v1.0"
+	}
+	return &Solana{
+		api: Provider{
+			apiURL: apiURL,
+			apiKey: apiKey,
+			httpClient: &http.Client{
+				Timeout: 30 * time.Second,
+			},
+		},
+	}
+}
+
+func (p *Solana) Name() string {
+	return "1inch-solana"
+}
+
+func (p *Solana) SupportedChainTypes() []backend.ChainType {
+	return []backend.ChainType{backend.ChainTypeSolana}
+}
+
+// CheckRoute 1inch Solana 只支持 Solana 主网同链 swap
+func (p *Solana) CheckRoute(fromChainID, toChainID string) error {
+	if toChainID != "" && toChainID != fromChainID {
+		return fmt.Errorf("cross-chain swap not supported")
+	}
+	switch fromChainID {
+	case "solana", "solana-mainnet":
+		return nil
+	default:
+		return fmt.Errorf("chain %s not supported", fromChainID)
+	}
+}
+
+// GetQuote fetches a quote from 1inch for Solana
+// API: /solana/v1.0/quote
+func (p *Solana) GetQuote(ctx context.Context, req *backend.QuoteRequest) (*backend.Quote, error) {
+	u, err := url.Parse(p.api.apiURL + "/quote")
+	if err != nil {
+		return nil, fmt.Errorf("invalid API URL: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("inputMint", req.FromToken)
+	q.Set("outputMint", req.ToToken)
+	q.Set("amount", req.Amount)
+	q.Set("slippageBps", fmt.Sprintf("%d", int(req.SlippageBps)))
+	u.RawQuery = q.Encode()
+
+	body, err := p.api.doRequest(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var quoteResp SolanaQuoteResponse
+	if err := json.Unmarshal(body, &quoteResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if quoteResp.OutAmount == "" {
+		return nil, fmt.Errorf("1inch solana quote missing outAmount")
+	}
+
+	return &backend.Quote{
+		Provider:    p.Name(),
+		ChainType:   backend.ChainTypeSolana,
+		ChainID:     req.FromChainID,
+		FromToken:   req.FromToken,
+		ToToken:     req.ToToken,
+		FromAmount:  req.Amount,
+		ToAmount:    quoteResp.OutAmount,
+		GasEstimate: "5000",
+		Fees:        "0",
+		Raw:         string(body), // 原样回传给 swap 接口
+	}, nil
+}
+
+// BuildSwap builds the serialized Solana transaction for the quote
+// API: /solana/v1.0/swap
+func (p *Solana) BuildSwap(ctx context.Context, quote *backend.Quote, userAddress string) (*backend.BuildSwapResponse, error) {
+	log.Info("1inch Solana BuildSwap called", "chainID", quote.ChainID, "userAddress", userAddress)
+
+	if quote.Raw == "" {
+		return nil, fmt.Errorf("quote raw data is empty, cannot build swap")
+	}
+
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"quoteResponse":    json.RawMessage(quote.Raw),
+		"userPublicKey":    userAddress,
+		"wrapAndUnwrapSol": true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal swap request: %w", err)
+	}
+
+	body, err := p.api.doRequest(ctx, http.MethodPost, p.api.apiURL+"/swap", reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var swapResp SolanaSwapResponse
+	if err := json.Unmarshal(body, &swapResp); err != nil {
+		return nil, fmt.Errorf("failed to parse swap response: %w", err)
+	}
+	if swapResp.SwapTransaction == "" {
+		return nil, fmt.Errorf("1inch solana swap missing transaction")
+	}
+
+	action := &backend.Action{
+		ActionType: backend.ActionTypeSwap,
+		ChainID:    quote.ChainID,
+		SigningPayload: &backend.SigningPayload{
+			SerializedTx:         swapResp.SwapTransaction,
+			ChainID:              quote.ChainID,
+			LastValidBlockHeight: uint64(swapResp.LastValidBlockHeight),
+		},
+		Description: fmt.Sprintf("Swap %s to %s via 1inch",
+			getTokenSymbol(quote.FromToken),
+			getTokenSymbol(quote.ToToken)),
+	}
+
+	return &backend.BuildSwapResponse{
+		Actions: []*backend.Action{action},
+	}, nil
+}
@@ -17,6 +17,42 @@ type Provider interface {
 	// Name returns the provider name
 	Name() string
 
-	// SupportedChainType returns the chain type this provider supports
-	SupportedChainType() backend.ChainType
+	// SupportedChainTypes returns the origin chain types this provider supports
+	SupportedChainTypes() []backend.ChainType
+}
+
+// OrderProvider is implemented by providers that settle swaps through signed off-chain orders
+// (SIGN_ORDER steps) instead of user-broadcast transactions
+type OrderProvider interface {
+	// SubmitOrder submits the user-signed order of step and returns the order hash
+	SubmitOrder(ctx context.Context, quote *backend.Quote, step *backend.Step, signature string) (string, error)
+
+	// GetOrderStatus returns the current status of a submitted order
+	GetOrderStatus(ctx context.Context, chainID, orderHash string) (*backend.OrderStatus, error)
+}
+
+// PermitProvider is implemented by providers whose swap plans use a permit signature
+// (SIGN_TYPED_DATA step) instead of an on-chain approve. BuildSwap then returns the swap
+// action without a signing payload; it is built here once the user signed the permit.
+type PermitProvider interface {
+	// BuildSwapWithPermit builds the swap tx(s) that consume the signed permit
+	BuildSwapWithPermit(ctx context.Context, quote *backend.Quote, userAddress string, permit *backend.PermitSignature) (*backend.BuildSwapResponse, error)
+}
+
+// BridgeStatusProvider is implemented by cross-chain providers that can report whether
+// a bridge tx has completed on the destination chain
+type BridgeStatusProvider interface {
+	// GetBridgeStatus returns the cross-chain status of the source-chain tx txHash
+	GetBridgeStatus(ctx context.Context, fromChainID, toChainID, txHash string) (*backend.BridgeStatus, error)
+}
+
+// Unwrap returns the innermost provider behind wrappers such as BreakerProvider
+func Unwrap(p Provider) Provider {
+	for {
+		w, ok := p.(interface{ Unwrap() Provider })
+		if !ok {
+			return p
+		}
+		p = w.Unwrap()
+	}
 }
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+	"github.com/roothash-pay/wallet-services/services/common/chaininfo"
+)
+
+// RouteChecker is optionally implemented by providers that only serve a subset
+// of the chains of their chain type (specific chain IDs, same-chain only, ...).
+// A non-nil error explains why the route is not served.
+type RouteChecker interface {
+	CheckRoute(fromChainID, toChainID string) error
+}
+
+// Router selects the providers able to serve a quote request
+type Router struct {
+	providers []Provider
+	chainInfo chaininfo.Provider
+}
+
+// NewRouter creates a new provider router
+func NewRouter(providers []Provider, chainInfo chaininfo.Provider) *Router {
+	return &Router{
+		providers: providers,
+		chainInfo: chainInfo,
+	}
+}
+
+// Route returns the providers that support the request's chain type and chain IDs,
+// together with an UNSUPPORTED result for every provider that was filtered out
+func (r *Router) Route(ctx context.Context, req *backend.QuoteRequest) ([]Provider, []*backend.ProviderResult) {
+	chainType := r.ResolveChainType(ctx, req.FromChainID)
+
+	var selected []Provider
+	var skipped []*backend.ProviderResult
+	for _, p := range r.providers {
+		if !supportsChainType(p, chainType) {
+			skipped = append(skipped, &backend.ProviderResult{
+				Provider: p.Name(),
+				Status:   backend.ProviderResultUnsupported,
+				Reason:   fmt.Sprintf("chain type %s not supported", chainType),
+			})
+			continue
+		}
+
+		if checker, ok := p.(RouteChecker); ok {
+			if err := checker.CheckRoute(req.FromChainID, req.ToChainID); err != nil {
+				skipped = append(skipped, &backend.ProviderResult{
+					Provider: p.Name(),
+					Status:   backend.ProviderResultUnsupported,
+					Reason:   err.Error(),
+				})
+				continue
+			}
+		}
+
+		selected = append(selected, p)
+	}
+
+	return selected, skipped
+}
+
+func supportsChainType(p Provider, chainType backend.ChainType) bool {
+	for _, t := range p.SupportedChainTypes() {
+		if t == chainType {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveChainType resolves the chain type of chainID through the chain info cache,
+// falling back to inferring it from the chain ID when the chain is not configured
+func (r *Router) ResolveChainType(ctx context.Context, chainID string) backend.ChainType {
+	if r.chainInfo != nil {
+		info, err := r.chainInfo.Get(ctx, chainID)
+		if err == nil && info != nil && info.ChainType != "" {
+			return backend.ChainType(strings.ToUpper(info.ChainType))
+		}
+		log.Warn("Chain info not available, infer chain type from chain ID", "chainID", chainID, "err", err)
+	}
+	return InferChainType(chainID)
+}
+
+// InferChainType guesses the chain type from the chain ID format:
+// Solana clusters use "solana*" IDs, everything else is treated as EVM
+func InferChainType(chainID string) backend.ChainType {
+	if strings.HasPrefix(strings.ToLower(chainID), "solana") {
+		return backend.ChainTypeSolana
+	}
+	return backend.ChainTypeEVM
+}
@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/provider/lifi"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+	"github.com/roothash-pay/wallet-services/services/common/chaininfo"
+)
+
+type fakeProvider struct {
+	name      string
+	chainType backend.ChainType
+}
+
+func (f *fakeProvider) GetQuote(ctx context.Context, req *backend.QuoteRequest) (*backend.Quote, error) {
+	return nil, nil
+}
+
+func (f *fakeProvider) BuildSwap(ctx context.Context, quote *backend.Quote, userAddress string) (*backend.BuildSwapResponse, error) {
+	return nil, nil
+}
+
+func (f *fakeProvider) Name() string { return f.name }
+
+func (f *fakeProvider) SupportedChainTypes() []backend.ChainType {
+	return []backend.ChainType{f.chainType}
+}
+
+type sameChainProvider struct {
+	fakeProvider
+}
+
+func (f *sameChainProvider) CheckRoute(fromChainID, toChainID string) error {
+	if fromChainID != toChainID {
+		return fmt.Errorf("cross-chain swap not supported")
+	}
+	return nil
+}
+
+type fakeChainInfo map[string]*chaininfo.Info
+
+func (f fakeChainInfo) WarmUp(ctx context.Context) error { return nil }
+
+func (f fakeChainInfo) Get(ctx context.Context, chainID string) (*chaininfo.Info, error) {
+	info, ok := f[chainID]
+	if !ok {
+		return nil, fmt.Errorf("chain %s not found", chainID)
+	}
+	return info, nil
+}
+
+func (f fakeChainInfo) Refresh(ctx context.Context, chainID string) (*chaininfo.Info, error) {
+	return f.Get(ctx, chainID)
+}
+
+func TestRouterRoute(t *testing.T) {
+	providers := []Provider{
+		&fakeProvider{name: "evm", chainType: backend.ChainTypeEVM},
+		&sameChainProvider{fakeProvider{name: "evm-same-chain", chainType: backend.ChainTypeEVM}},
+		&fakeProvider{name: "solana", chainType: backend.ChainTypeSolana},
+	}
+	chains := fakeChainInfo{
+		"1":   {ChainID: "1", ChainType: "evm"},
+		"501": {ChainID: "501", ChainType: "SOLANA"},
+	}
+	router := NewRouter(providers, chains)
+
+	tests := []struct {
+		name     string
+		req      *backend.QuoteRequest
+		selected []string
+		skipped  []string
+	}{
+		{
+			name:     "EVM same chain",
+			req:      &backend.QuoteRequest{FromChainID: "1", ToChainID: "1"},
+			selected: []string{"evm", "evm-same-chain"},
+			skipped:  []string{"solana"},
+		},
+		{
+			name:     "EVM cross chain",
+			req:      &backend.QuoteRequest{FromChainID: "1", ToChainID: "56"},
+			selected: []string{"evm"},
+			skipped:  []string{"evm-same-chain", "solana"},
+		},
+		{
+			name:     "Solana from chain table",
+			req:      &backend.QuoteRequest{FromChainID: "501", ToChainID: "501"},
+			selected: []string{"solana"},
+			skipped:  []string{"evm", "evm-same-chain"},
+		},
+		{
+			name:     "Unknown chain inferred from ID",
+			req:      &backend.QuoteRequest{FromChainID: "solana-mainnet", ToChainID: "solana-mainnet"},
+			selected: []string{"solana"},
+			skipped:  []string{"evm", "evm-same-chain"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, skipped := router.Route(context.Background(), tt.req)
+
+			var selectedNames []string
+			for _, p := range selected {
+				selectedNames = append(selectedNames, p.Name())
+			}
+			assert.Equal(t, tt.selected, selectedNames)
+
+			var skippedNames []string
+			for _, r := range skipped {
+				require.Equal(t, backend.ProviderResultUnsupported, r.Status)
+				require.NotEmpty(t, r.Reason)
+				skippedNames = append(skippedNames, r.Provider)
+			}
+			assert.Equal(t, tt.skipped, skippedNames)
+		})
+	}
+}
+
+func TestRouterRouteMultiChainType(t *testing.T) {
+	providers := []Provider{
+		&fakeProvider{name: "evm", chainType: backend.ChainTypeEVM},
+		&fakeProvider{name: "solana", chainType: backend.ChainTypeSolana},
+		lifi.NewProvider("", "", nil),
+	}
+	router := NewRouter(providers, nil)
+
+	names := func(list []Provider) []string {
+		var out []string
+		for _, p := range list {
+			out = append(out, p.Name())
+		}
+		return out
+	}
+
+	// LiFi 同时支持 EVM 与 Solana 起始链
+	selected, _ := router.Route(context.Background(), &backend.QuoteRequest{FromChainID: "solana", ToChainID: "1"})
+	assert.Equal(t, []string{"solana", "lifi"}, names(selected))
+
+	selected, _ = router.Route(context.Background(), &backend.QuoteRequest{FromChainID: "1", ToChainID: "solana"})
+	assert.Equal(t, []string{"evm", "lifi"}, names(selected))
+
+	// LiFi 不支持 Solana devnet
+	selected, skipped := router.Route(context.Background(), &backend.QuoteRequest{FromChainID: "solana-devnet", ToChainID: "solana-devnet"})
+	assert.Equal(t, []string{"solana"}, names(selected))
+	require.Len(t, skipped, 2)
+	assert.Equal(t, "lifi", skipped[1].Provider)
+}
@@ -0,0 +1,222 @@
+package zerox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+const (
+	defaultAPIURL = "https://api.0x.org"
+	apiVersion    = "v2"
+
+	// nativeToken 0x 用该地址表示原生币
+	nativeToken = "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE"
+)
+
+// Swap API v2 的两种授权模式
+const (
+	flowAllowanceHolder = "allowance-holder" // approve 给 AllowanceHolder，交易发往 AllowanceHolder
+	flowPermit2         = "permit2"          // approve 给 Permit2 + EIP-712 签名，交易发往 Settler
+)
+
+// ZeroXFee is a fee entry of a 0x v2 response
+type ZeroXFee struct {
+	Amount string `json:"amount"`
+	Token  string `json:"token"`
+	Type   string `json:"type"`
+}
+
+// ZeroXQuoteResponse is the response of 0x Swap API v2 /swap/{allowance-holder|permit2}/{price|quote}.
+// price responses carry gas/gasPrice, firm quotes carry transaction (and permit2 for the permit2 flow).
+type ZeroXQuoteResponse struct {
+	AllowanceTarget    string `json:"allowanceTarget"`
+	BlockNumber        string `json:"blockNumber"`
+	BuyAmount          string `json:"buyAmount"`
+	BuyToken           string `json:"buyToken"`
+	SellAmount         string `json:"sellAmount"`
+	SellToken          string `json:"sellToken"`
+	MinBuyAmount       string `json:"minBuyAmount"`
+	LiquidityAvailable bool   `json:"liquidityAvailable"`
+	Gas                string `json:"gas,omitempty"`
+	GasPrice           string `json:"gasPrice,omitempty"`
+	TotalNetworkFee    string `json:"totalNetworkFee"`
+	Fees               struct {
+		IntegratorFee *ZeroXFee `json:"integratorFee"`
+		ZeroExFee     *ZeroXFee `json:"zeroExFee"`
+		GasFee        *ZeroXFee `json:"gasFee"`
+	} `json:"fees"`
+	Issues struct {
+		Allowance *struct {
+			Actual  string `json:"actual"`
+			Spender string `json:"spender"`
+		} `json:"allowance"`
+		Balance *struct {
+			Token    string `json:"token"`
+			Actual   string `json:"actual"`
+			Expected string `json:"expected"`
+		} `json:"balance"`
+		SimulationIncomplete bool     `json:"simulationIncomplete"`
+		InvalidSourcesPassed []string `json:"invalidSourcesPassed"`
+	} `json:"issues"`
+	Permit2 *struct {
+		Type   string              `json:"type"`
+		Hash   string              `json:"hash"`
+		EIP712 *apitypes.TypedData `json:"eip712"`
+	} `json:"permit2,omitempty"`
+	Transaction *struct {
+		To       string `json:"to"`
+		Data     string `json:"data"`
+		Gas      string `json:"gas"`
+		GasPrice string `json:"gasPrice"`
+		Value    string `json:"value"`
+	} `json:"transaction,omitempty"`
+	Zid string `json:"zid"`
+}
+
+// zeroXError is the error body of the 0x API
+type zeroXError struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// swapRequest is one call to the Swap API
+type swapRequest struct {
+	flow      string // flowAllowanceHolder / flowPermit2
+	firm      bool   // quote（可执行交易）还是 price（指示性报价）
+	chainID   string
+	sellToken string
+	buyToken  string
+	amount    string
+	taker     string
+	slippage  float64 // bps
+}
+
+// fetch calls GET /swap/{flow}/{price|quote} and returns the decoded response and its raw body
+func (p *Provider) fetch(ctx context.Context, sr *swapRequest) (*ZeroXQuoteResponse, string, error) {
+	endpoint := "price"
+	if sr.firm {
+		endpoint = "quote"
+	}
+	u, err := url.Parse(fmt.Sprintf("%s/swap/%s/%s", p.baseURL(), sr.flow, endpoint))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid API URL: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("chainId", sr.chainID)
+	q.Set("sellToken", toZeroXToken(sr.sellToken))
+	q.Set("buyToken", toZeroXToken(sr.buyToken))
+	q.Set("sellAmount", sr.amount)
+	if sr.taker != "" {
+		q.Set("taker", sr.taker)
+	}
+	if sr.slippage > 0 {
+		q.Set("slippageBps", strconv.Itoa(int(sr.slippage)))
+	}
+
+	// 集成方手续费与正滑点收益
+	if p.fees.Recipient != "" && p.fees.Bps > 0 {
+		q.Set("swapFeeRecipient", p.fees.Recipient)
+		q.Set("swapFeeBps", strconv.Itoa(p.fees.Bps))
+		feeToken := p.fees.Token
+		if feeToken == "" {
+			feeToken = sr.buyToken
+		}
+		q.Set("swapFeeToken", toZeroXToken(feeToken))
+	}
+	if p.fees.SurplusRecipient != "" {
+		q.Set("tradeSurplusRecipient", p.fees.SurplusRecipient)
+	}
+	u.RawQuery = q.Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("0x-version", apiVersion)
+	if p.apiKey != "" {
+		httpReq.Header.Set("0x-api-key", p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to call 0x API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr zeroXError
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Name != "" {
+			return nil, "", fmt.Errorf("0x API error (status %d): %s: %s", resp.StatusCode, apiErr.Name, apiErr.Message)
+		}
+		return nil, "", fmt.Errorf("0x API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var quoteResp ZeroXQuoteResponse
+	if err := json.Unmarshal(body, &quoteResp); err != nil {
+		return nil, "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if !quoteResp.LiquidityAvailable {
+		return nil, "", fmt.Errorf("0x: no liquidity available")
+	}
+
+	return &quoteResp, string(body), nil
+}
+
+func (p *Provider) baseURL() string {
+	if p.apiURL == "" {
+		return defaultAPIURL
+	}
+	return strings.TrimSuffix(p.apiURL, "/")
+}
+
+// toZeroXToken converts our native token representation to the 0x one
+func toZeroXToken(token string) string {
+	if isNativeToken(token) {
+		return nativeToken
+	}
+	return token
+}
+
+// isNativeToken checks if the token is the chain's native token
+func isNativeToken(token string) bool {
+	return token == "" ||
+		token == "0x0000000000000000000000000000000000000000" ||
+		strings.EqualFold(token, nativeToken)
+}
+
+// convertIssues maps the 0x issues block
+func convertIssues(resp *ZeroXQuoteResponse) *backend.QuoteIssues {
+	issues := &backend.QuoteIssues{
+		SimulationIncomplete: resp.Issues.SimulationIncomplete,
+		InvalidSources:       resp.Issues.InvalidSourcesPassed,
+	}
+	if a := resp.Issues.Allowance; a != nil {
+		issues.AllowanceActual = a.Actual
+		issues.AllowanceSpender = a.Spender
+	}
+	if b := resp.Issues.Balance; b != nil {
+		issues.BalanceToken = b.Token
+		issues.BalanceActual = b.Actual
+		issues.BalanceExpected = b.Expected
+	}
+	if resp.Issues.Allowance == nil && resp.Issues.Balance == nil && !issues.SimulationIncomplete && len(issues.InvalidSources) == 0 {
+		return nil
+	}
+	return issues
+}
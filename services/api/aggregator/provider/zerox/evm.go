@@ -2,36 +2,103 @@ package zerox
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"net/http"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/utils"
 	"github.com/roothash-pay/wallet-services/services/api/models/backend"
 )
 
-// Provider implements the Provider interface for 0x Protocol
+// FeeConfig is the integrator fee / trade surplus setting sent with every 0x request
+type FeeConfig struct {
+	Recipient        string // swapFeeRecipient
+	Bps              int    // swapFeeBps
+	Token            string // swapFeeToken，为空时收买入代币
+	SurplusRecipient string // tradeSurplusRecipient，正滑点收益接收地址
+}
+
+// Provider implements the Provider interface for 0x Protocol (Swap API v2)
 type Provider struct {
-	apiURL string
-	apiKey string
+	apiURL     string
+	apiKey     string
+	fees       FeeConfig
+	usePermit2 bool // 有 taker 时用 permit2 流程（签名代替对 router 的 approve）
+	httpClient *http.Client
 }
 
 // NewProvider creates a new 0x provider
-func NewProvider(apiURL, apiKey string) *Provider {
+func NewProvider(apiURL, apiKey string, fees FeeConfig, usePermit2 bool) *Provider {
 	return &Provider{
-		apiURL: apiURL,
-		apiKey: apiKey,
+		apiURL:     apiURL,
+		apiKey:     apiKey,
+		fees:       fees,
+		usePermit2: usePermit2,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
 	}
 }
 
-// GetQuote fetches a quote from 0x Protocol for EVM chains
+// GetQuote fetches a quote from 0x Protocol for EVM chains.
+// With a user address it requests a firm quote (executable tx, and the permit to sign
+// in the permit2 flow); without one only an indicative price.
 func (p *Provider) GetQuote(ctx context.Context, req *backend.QuoteRequest) (*backend.Quote, error) {
-	// TODO: Implement actual HTTP call to 0x API
-	// Example endpoint: https://api.0x.org/swap/v1/quote
-	// Request params: sellToken, buyToken, sellAmount, slippagePercentage
+	log.Info("ZeroX GetQuote called", "chainID", req.FromChainID, "fromToken", req.FromToken, "toToken", req.ToToken)
+
+	sr := &swapRequest{
+		flow:      p.flowFor(req.FromToken, req.UserAddress),
+		firm:      req.UserAddress != "",
+		chainID:   req.FromChainID,
+		sellToken: req.FromToken,
+		buyToken:  req.ToToken,
+		amount:    req.Amount,
+		taker:     req.UserAddress,
+		slippage:  req.SlippageBps,
+	}
+	resp, raw, err := p.fetch(ctx, sr)
+	if err != nil {
+		return nil, err
+	}
 
-	log.Info("ZeroX GetQuote called", "fromToken", req.FromToken, "toToken", req.ToToken)
+	quote := p.convertToQuote(req, resp, raw)
+
+	log.Info("ZeroX GetQuote success",
+		"flow", sr.flow,
+		"firm", sr.firm,
+		"fromAmount", req.Amount,
+		"toAmount", quote.ToAmount,
+		"hasIssues", quote.Issues != nil,
+	)
+	return quote, nil
+}
+
+// flowFor permit2 needs a firm quote for the permit to sign and does not apply to native sells
+func (p *Provider) flowFor(sellToken, taker string) string {
+	if p.usePermit2 && taker != "" && !isNativeToken(sellToken) {
+		return flowPermit2
+	}
+	return flowAllowanceHolder
+}
+
+// convertToQuote converts a 0x response to internal Quote format
+func (p *Provider) convertToQuote(req *backend.QuoteRequest, resp *ZeroXQuoteResponse, raw string) *backend.Quote {
+	gas := resp.Gas
+	router := resp.AllowanceTarget // allowance-holder 流程交易发往 AllowanceHolder
+	if resp.Transaction != nil {
+		gas = resp.Transaction.Gas
+		router = resp.Transaction.To
+	}
+
+	spender := resp.AllowanceTarget
+	if isNativeToken(req.FromToken) {
+		spender = ""
+	}
 
-	// Placeholder response
 	return &backend.Quote{
 		Provider:    p.Name(),
 		ChainType:   backend.ChainTypeEVM,
@@ -39,57 +106,143 @@ func (p *Provider) GetQuote(ctx context.Context, req *backend.QuoteRequest) (*ba
 		FromToken:   req.FromToken,
 		ToToken:     req.ToToken,
 		FromAmount:  req.Amount,
-		ToAmount:    "0", // TODO: Parse from API response
-		GasEstimate: "21000",
-		Spender:     "0x0000000000000000000000000000000000000000", // TODO: Get from API
-		Router:      "0x0000000000000000000000000000000000000000", // TODO: Get from API
-	}, fmt.Errorf("TODO: implement 0x API integration")
+		ToAmount:    resp.BuyAmount,
+		MinToAmount: resp.MinBuyAmount,
+		GasEstimate: gas,
+		Fees:        resp.TotalNetworkFee,
+		Spender:     spender,
+		Router:      router,
+		Issues:      convertIssues(resp),
+		Raw:         raw,
+	}
 }
 
 // BuildSwap builds the swap transaction based on the quote for EVM chains
 func (p *Provider) BuildSwap(ctx context.Context, quote *backend.Quote, userAddress string) (*backend.BuildSwapResponse, error) {
-	// TODO: Implement actual HTTP call to 0x API to build transaction
-	// Example endpoint: https://api.0x.org/swap/v1/quote (with takerAddress)
-
 	log.Info("ZeroX BuildSwap called", "chainID", quote.ChainID, "userAddress", userAddress)
 
-	// Placeholder response
-	return &backend.BuildSwapResponse{
-		Actions: []*backend.Action{
-			{
-				ActionType: backend.ActionTypeApprove,
-				ChainID:    quote.ChainID,
-				SigningPayload: &backend.SigningPayload{
-					To:      quote.FromToken,
-					Data:    "0x", // TODO: Encode approve
-					Value:   "0",
-					Gas:     "50000",
-					ChainID: quote.ChainID,
-				},
-				Description: fmt.Sprintf("Approve %s to spend %s", quote.Spender, quote.FromToken),
-			},
-			{
-				ActionType: backend.ActionTypeSwap,
-				ChainID:    quote.ChainID,
-				SigningPayload: &backend.SigningPayload{
-					To:      quote.Router,
-					Data:    "0x", // TODO: Encode swap calldata from provider
-					Value:   quote.FromAmount,
-					Gas:     quote.GasEstimate,
-					ChainID: quote.ChainID,
-				},
-				Description: fmt.Sprintf("Swap %s %s for %s %s", quote.FromAmount, quote.FromToken, quote.ToAmount, quote.ToToken),
+	// permit2 报价：用签名代替对 router 的 approve
+	if pq := parsePermit2Quote(quote.Raw); pq != nil {
+		return p.buildPermitPlan(quote, pq)
+	}
+
+	if quote.Raw == "" {
+		return nil, fmt.Errorf("quote.Raw is empty, cannot build swap")
+	}
+	var resp ZeroXQuoteResponse
+	if err := json.Unmarshal([]byte(quote.Raw), &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse quote.Raw: %w", err)
+	}
+
+	// 指示性报价没有交易，按用户地址取 allowance-holder 确定报价
+	if resp.Transaction == nil {
+		firm, _, err := p.fetch(ctx, &swapRequest{
+			flow:      flowAllowanceHolder,
+			firm:      true,
+			chainID:   quote.ChainID,
+			sellToken: quote.FromToken,
+			buyToken:  quote.ToToken,
+			amount:    quote.FromAmount,
+			taker:     userAddress,
+			slippage:  slippageBps(resp.BuyAmount, resp.MinBuyAmount),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get firm quote: %w", err)
+		}
+		resp = *firm
+	}
+	if resp.Transaction == nil {
+		return nil, fmt.Errorf("0x quote has no transaction")
+	}
+	if b := resp.Issues.Balance; b != nil {
+		log.Warn("0x reports insufficient balance", "token", b.Token, "actual", b.Actual, "expected", b.Expected, "userAddress", userAddress)
+	}
+
+	var actions []*backend.Action
+
+	// ERC20 卖出需授权 AllowanceHolder（授权已足够时由 service 去掉）
+	if !isNativeToken(quote.FromToken) && resp.AllowanceTarget != "" {
+		amount, ok := new(big.Int).SetString(quote.FromAmount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid fromAmount: %s", quote.FromAmount)
+		}
+		actions = append(actions, &backend.Action{
+			ActionType: backend.ActionTypeApprove,
+			ChainID:    quote.ChainID,
+			SigningPayload: &backend.SigningPayload{
+				To:      quote.FromToken,
+				Data:    utils.EncodeApproveData(resp.AllowanceTarget, amount),
+				Value:   "0",
+				Gas:     "60000",
+				ChainID: quote.ChainID,
 			},
+			Description: fmt.Sprintf("Approve %s to spend %s", resp.AllowanceTarget, quote.FromToken),
+		})
+	}
+
+	actions = append(actions, &backend.Action{
+		ActionType: backend.ActionTypeSwap,
+		ChainID:    quote.ChainID,
+		SigningPayload: &backend.SigningPayload{
+			To:      resp.Transaction.To,
+			Data:    resp.Transaction.Data,
+			Value:   resp.Transaction.Value,
+			Gas:     resp.Transaction.Gas,
+			ChainID: quote.ChainID,
 		},
+		Description: fmt.Sprintf("Swap %s %s for %s %s", quote.FromAmount, quote.FromToken, resp.BuyAmount, quote.ToToken),
+	})
+
+	return &backend.BuildSwapResponse{
+		Actions: actions,
 	}, nil
 }
 
+// slippageBps recovers the slippage of a price response from buyAmount and minBuyAmount, 0 if unknown
+func slippageBps(buyAmount, minBuyAmount string) float64 {
+	buy, ok1 := new(big.Int).SetString(buyAmount, 10)
+	min, ok2 := new(big.Int).SetString(minBuyAmount, 10)
+	if !ok1 || !ok2 || buy.Sign() <= 0 || min.Cmp(buy) > 0 {
+		return 0
+	}
+	diff := new(big.Int).Sub(buy, min)
+	diff.Mul(diff, big.NewInt(10000))
+	return float64(diff.Quo(diff, buy).Int64())
+}
+
 // Name returns the provider name
 func (p *Provider) Name() string {
 	return "0x"
 }
 
-// SupportedChainType returns EVM
-func (p *Provider) SupportedChainType() backend.ChainType {
-	return backend.ChainTypeEVM
+// SupportedChainTypes returns EVM
+func (p *Provider) SupportedChainTypes() []backend.ChainType {
+	return []backend.ChainType{backend.ChainTypeEVM}
+}
+
+// supportedChainIDs chains served by the 0x Swap API
+var supportedChainIDs = map[string]bool{
+	"1":        true, // Ethereum
+	"10":       true, // Optimism
+	"56":       true, // BSC
+	"137":      true, // Polygon
+	"8453":     true, // Base
+	"42161":    true, // Arbitrum
+	"43114":    true, // Avalanche
+	"59144":    true, // Linea
+	"534352":   true, // Scroll
+	"81457":    true, // Blast
+	"5000":     true, // Mantle
+	"11155111": true, // Sepolia
+}
+
+// CheckRoute 0x only supports same-chain swaps on the chains above
+func (p *Provider) CheckRoute(fromChainID, toChainID string) error {
+	if toChainID != "" && toChainID != fromChainID {
+		return fmt.Errorf("cross-chain swap not supported")
+	}
+	if !supportedChainIDs[fromChainID] {
+		return fmt.Errorf("chain %s not supported", fromChainID)
+	}
+	return nil
 }
@@ -0,0 +1,243 @@
+package zerox
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/utils"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+const (
+	testWETH  = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+	testUSDC  = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	testTaker = "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"
+)
+
+// replayServer serves recorded 0x responses keyed by request path and records the requests it got
+type replayServer struct {
+	t        *testing.T
+	fixtures map[string]string // path -> testdata file
+	status   int
+	requests []*http.Request
+}
+
+func (s *replayServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests = append(s.requests, r)
+	file, ok := s.fixtures[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := os.ReadFile(filepath.Join("testdata", file))
+	require.NoError(s.t, err)
+	w.Header().Set("Content-Type", "application/json")
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+	}
+	_, _ = w.Write(body)
+}
+
+func newReplay(t *testing.T, fixtures map[string]string) (*replayServer, string) {
+	rs := &replayServer{t: t, fixtures: fixtures}
+	srv := httptest.NewServer(rs)
+	t.Cleanup(srv.Close)
+	return rs, srv.URL
+}
+
+func quoteRequest(userAddress string) *backend.QuoteRequest {
+	return &backend.QuoteRequest{
+		FromChainID: "1",
+		ToChainID:   "1",
+		FromToken:   testWETH,
+		ToToken:     testUSDC,
+		Amount:      "1000000000000000000",
+		UserAddress: userAddress,
+		SlippageBps: 50,
+	}
+}
+
+func TestGetQuoteIndicativePrice(t *testing.T) {
+	rs, apiURL := newReplay(t, map[string]string{
+		"/swap/allowance-holder/price": "allowance_holder_price.json",
+	})
+	p := NewProvider(apiURL, "test-key", FeeConfig{
+		Recipient:        "0x1111111111111111111111111111111111111111",
+		Bps:              10,
+		SurplusRecipient: "0x2222222222222222222222222222222222222222",
+	}, true)
+
+	quote, err := p.GetQuote(context.Background(), quoteRequest(""))
+	require.NoError(t, err)
+
+	assert.Equal(t, "2999100000", quote.ToAmount)
+	assert.Equal(t, "2984104500", quote.MinToAmount)
+	assert.Equal(t, "188000", quote.GasEstimate)
+	assert.Equal(t, "2256000000000000", quote.Fees)
+	assert.Equal(t, "0x0000000000001ff3684f28c67538d4d072c22734", quote.Spender)
+	assert.Equal(t, "0x0000000000001ff3684f28c67538d4d072c22734", quote.Router)
+	assert.Nil(t, quote.Issues)
+
+	// permit2 needs a taker, so a price request falls back to allowance-holder
+	require.Len(t, rs.requests, 1)
+	r := rs.requests[0]
+	assert.Equal(t, "v2", r.Header.Get("0x-version"))
+	assert.Equal(t, "test-key", r.Header.Get("0x-api-key"))
+	q := r.URL.Query()
+	assert.Equal(t, "1", q.Get("chainId"))
+	assert.Equal(t, testWETH, q.Get("sellToken"))
+	assert.Equal(t, testUSDC, q.Get("buyToken"))
+	assert.Equal(t, "1000000000000000000", q.Get("sellAmount"))
+	assert.Equal(t, "50", q.Get("slippageBps"))
+	assert.Empty(t, q.Get("taker"))
+	assert.Equal(t, "0x1111111111111111111111111111111111111111", q.Get("swapFeeRecipient"))
+	assert.Equal(t, "10", q.Get("swapFeeBps"))
+	assert.Equal(t, testUSDC, q.Get("swapFeeToken"))
+	assert.Equal(t, "0x2222222222222222222222222222222222222222", q.Get("tradeSurplusRecipient"))
+}
+
+func TestGetQuoteMapsIssues(t *testing.T) {
+	rs, apiURL := newReplay(t, map[string]string{
+		"/swap/allowance-holder/quote": "allowance_holder_quote.json",
+	})
+	p := NewProvider(apiURL, "", FeeConfig{}, false)
+
+	quote, err := p.GetQuote(context.Background(), quoteRequest(testTaker))
+	require.NoError(t, err)
+
+	require.NotNil(t, quote.Issues)
+	assert.Equal(t, "0", quote.Issues.AllowanceActual)
+	assert.Equal(t, "0x0000000000001ff3684f28c67538d4d072c22734", quote.Issues.AllowanceSpender)
+	assert.Equal(t, "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", quote.Issues.BalanceToken)
+	assert.Equal(t, "500000000000000000", quote.Issues.BalanceActual)
+	assert.Equal(t, "1000000000000000000", quote.Issues.BalanceExpected)
+
+	require.Len(t, rs.requests, 1)
+	q := rs.requests[0].URL.Query()
+	assert.Equal(t, testTaker, q.Get("taker"))
+	assert.Empty(t, q.Get("swapFeeRecipient"))
+	assert.Empty(t, rs.requests[0].Header.Get("0x-api-key"))
+}
+
+func TestBuildSwapAllowanceHolder(t *testing.T) {
+	rs, apiURL := newReplay(t, map[string]string{
+		"/swap/allowance-holder/price": "allowance_holder_price.json",
+		"/swap/allowance-holder/quote": "allowance_holder_quote.json",
+	})
+	p := NewProvider(apiURL, "", FeeConfig{}, false)
+
+	quote, err := p.GetQuote(context.Background(), quoteRequest(""))
+	require.NoError(t, err)
+
+	// the price response has no transaction, so BuildSwap fetches a firm quote for the user
+	resp, err := p.BuildSwap(context.Background(), quote, testTaker)
+	require.NoError(t, err)
+	require.Len(t, rs.requests, 2)
+	firm := rs.requests[1]
+	assert.Equal(t, "/swap/allowance-holder/quote", firm.URL.Path)
+	assert.Equal(t, testTaker, firm.URL.Query().Get("taker"))
+	assert.Equal(t, "50", firm.URL.Query().Get("slippageBps"))
+
+	require.Len(t, resp.Actions, 2)
+	approve := resp.Actions[0]
+	assert.Equal(t, backend.ActionTypeApprove, approve.ActionType)
+	assert.Equal(t, testWETH, approve.SigningPayload.To)
+	spender, amount, err := utils.DecodeApproveData(approve.SigningPayload.Data)
+	require.NoError(t, err)
+	assert.True(t, strings.EqualFold("0x0000000000001ff3684f28c67538d4d072c22734", spender))
+	assert.Equal(t, "1000000000000000000", amount.String())
+
+	swap := resp.Actions[1]
+	assert.Equal(t, backend.ActionTypeSwap, swap.ActionType)
+	assert.Equal(t, "0x0000000000001ff3684f28c67538d4d072c22734", swap.SigningPayload.To)
+	assert.Equal(t, "0x2213bc0b000000000000000000000000", swap.SigningPayload.Data)
+	assert.Equal(t, "188000", swap.SigningPayload.Gas)
+}
+
+func TestBuildSwapNativeSellSkipsApprove(t *testing.T) {
+	_, apiURL := newReplay(t, map[string]string{
+		"/swap/allowance-holder/quote": "allowance_holder_quote.json",
+	})
+	p := NewProvider(apiURL, "", FeeConfig{}, true)
+
+	req := quoteRequest(testTaker)
+	req.FromToken = "0x0000000000000000000000000000000000000000"
+	quote, err := p.GetQuote(context.Background(), req)
+	require.NoError(t, err)
+	assert.Empty(t, quote.Spender)
+
+	resp, err := p.BuildSwap(context.Background(), quote, testTaker)
+	require.NoError(t, err)
+	require.Len(t, resp.Actions, 1)
+	assert.Equal(t, backend.ActionTypeSwap, resp.Actions[0].ActionType)
+}
+
+func TestPermit2Flow(t *testing.T) {
+	rs, apiURL := newReplay(t, map[string]string{
+		"/swap/permit2/quote": "permit2_quote.json",
+	})
+	p := NewProvider(apiURL, "", FeeConfig{}, true)
+
+	quote, err := p.GetQuote(context.Background(), quoteRequest(testTaker))
+	require.NoError(t, err)
+	require.Len(t, rs.requests, 1)
+	assert.Equal(t, "/swap/permit2/quote", rs.requests[0].URL.Path)
+	assert.Equal(t, "0x7f6cee965959295cc64d0e6c00d99d6532d8e86b", quote.Router)
+	assert.Equal(t, "0x000000000022d473030f116ddee9f6b43ac78ba3", quote.Spender)
+
+	plan, err := p.BuildSwap(context.Background(), quote, testTaker)
+	require.NoError(t, err)
+	require.Len(t, plan.Actions, 3)
+	assert.Equal(t, backend.ActionTypeApprove, plan.Actions[0].ActionType)
+	spender, amount, err := utils.DecodeApproveData(plan.Actions[0].SigningPayload.Data)
+	require.NoError(t, err)
+	assert.True(t, strings.EqualFold(utils.Permit2Address, spender))
+	assert.Equal(t, quote.FromAmount, amount.String())
+	assert.Equal(t, backend.ActionTypeSignTypedData, plan.Actions[1].ActionType)
+	require.NotNil(t, plan.Actions[1].SigningPayload.TypedData)
+	assert.Equal(t, "PermitTransferFrom", plan.Actions[1].SigningPayload.TypedData.PrimaryType)
+	assert.Equal(t, backend.ActionTypeSwap, plan.Actions[2].ActionType)
+	assert.Nil(t, plan.Actions[2].SigningPayload)
+
+	sig := "0x" + strings.Repeat("ab", 65)
+	resp, err := p.BuildSwapWithPermit(context.Background(), quote, testTaker, &backend.PermitSignature{Signature: sig})
+	require.NoError(t, err)
+	require.Len(t, resp.Actions, 1)
+	payload := resp.Actions[0].SigningPayload
+	assert.Equal(t, "0x7f6cee965959295cc64d0e6c00d99d6532d8e86b", payload.To)
+	// data ‖ uint256(65) ‖ signature
+	assert.Equal(t, "0x1fff991f000000000000000000000000"+
+		"0000000000000000000000000000000000000000000000000000000000000041"+
+		strings.Repeat("ab", 65), payload.Data)
+}
+
+func TestGetQuoteErrors(t *testing.T) {
+	_, apiURL := newReplay(t, map[string]string{
+		"/swap/allowance-holder/price": "no_liquidity.json",
+	})
+	p := NewProvider(apiURL, "", FeeConfig{}, false)
+	_, err := p.GetQuote(context.Background(), quoteRequest(""))
+	assert.ErrorContains(t, err, "no liquidity")
+
+	rs, apiURL := newReplay(t, map[string]string{
+		"/swap/allowance-holder/price": "error_input_invalid.json",
+	})
+	rs.status = http.StatusBadRequest
+	p = NewProvider(apiURL, "", FeeConfig{}, false)
+	_, err = p.GetQuote(context.Background(), quoteRequest(""))
+	assert.ErrorContains(t, err, "INPUT_INVALID")
+}
+
+func TestSlippageBps(t *testing.T) {
+	assert.Equal(t, float64(50), slippageBps("2999100000", "2984104500"))
+	assert.Equal(t, float64(0), slippageBps("", "1"))
+	assert.Equal(t, float64(0), slippageBps("100", "200"))
+}
@@ -0,0 +1,104 @@
+package zerox
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/utils"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// parsePermit2Quote returns the permit2 firm quote stored in quote.Raw, or nil if the quote has no permit
+func parsePermit2Quote(raw string) *ZeroXQuoteResponse {
+	if raw == "" {
+		return nil
+	}
+	var q ZeroXQuoteResponse
+	if err := json.Unmarshal([]byte(raw), &q); err != nil || q.Permit2 == nil || q.Permit2.EIP712 == nil || q.Transaction == nil {
+		return nil
+	}
+	return &q
+}
+
+// buildPermitPlan returns an approve of the sell amount to Permit2 (dropped by the service if allowance suffices),
+// the quote's Permit2 typed data to sign, and the swap action to be built after signing
+func (p *Provider) buildPermitPlan(quote *backend.Quote, pq *ZeroXQuoteResponse) (*backend.BuildSwapResponse, error) {
+	amount, ok := new(big.Int).SetString(quote.FromAmount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid fromAmount: %s", quote.FromAmount)
+	}
+	return &backend.BuildSwapResponse{
+		Actions: []*backend.Action{
+			{
+				ActionType: backend.ActionTypeApprove,
+				ChainID:    quote.ChainID,
+				SigningPayload: &backend.SigningPayload{
+					To:      quote.FromToken,
+					Data:    utils.EncodeApproveData(utils.Permit2Address, amount),
+					Value:   "0",
+					Gas:     "60000",
+					ChainID: quote.ChainID,
+				},
+				Description: fmt.Sprintf("Approve Permit2 to spend %s", quote.FromToken),
+			},
+			{
+				ActionType: backend.ActionTypeSignTypedData,
+				ChainID:    quote.ChainID,
+				SigningPayload: &backend.SigningPayload{
+					ChainID:   quote.ChainID,
+					TypedData: pq.Permit2.EIP712,
+				},
+				Description: fmt.Sprintf("Sign permit for 0x to spend %s", quote.FromToken),
+			},
+			{
+				ActionType:  backend.ActionTypeSwap,
+				ChainID:     quote.ChainID,
+				Description: fmt.Sprintf("Swap %s %s for %s %s", quote.FromAmount, quote.FromToken, quote.ToAmount, quote.ToToken),
+			},
+		},
+	}, nil
+}
+
+// BuildSwapWithPermit appends the permit signature to the quoted swap calldata:
+// data ‖ uint256(len(signature)) ‖ signature, as required by the 0x Settler
+func (p *Provider) BuildSwapWithPermit(ctx context.Context, quote *backend.Quote, userAddress string, permit *backend.PermitSignature) (*backend.BuildSwapResponse, error) {
+	log.Info("ZeroX BuildSwapWithPermit called", "chainID", quote.ChainID, "userAddress", userAddress)
+
+	pq := parsePermit2Quote(quote.Raw)
+	if pq == nil {
+		return nil, fmt.Errorf("quote has no permit2 data")
+	}
+	sig, err := hexutil.Decode(permit.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature hex: %w", err)
+	}
+
+	data := pq.Transaction.Data +
+		hex.EncodeToString(common.LeftPadBytes(big.NewInt(int64(len(sig))).Bytes(), 32)) +
+		strings.TrimPrefix(hexutil.Encode(sig), "0x")
+
+	return &backend.BuildSwapResponse{
+		Actions: []*backend.Action{
+			{
+				ActionType: backend.ActionTypeSwap,
+				ChainID:    quote.ChainID,
+				SigningPayload: &backend.SigningPayload{
+					To:      pq.Transaction.To,
+					Data:    data,
+					Value:   pq.Transaction.Value,
+					Gas:     pq.Transaction.Gas,
+					ChainID: quote.ChainID,
+				},
+				Description: fmt.Sprintf("Swap %s %s for %s %s", quote.FromAmount, quote.FromToken, quote.ToAmount, quote.ToToken),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,290 @@
+package ranking
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/config"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+const defaultPreferredBiasBps = 50
+
+// PriceSource provides USD prices of tokens for cost conversion
+type PriceSource interface {
+	// TokenPrice returns the USD price of one whole token and its decimals
+	TokenPrice(ctx context.Context, chainID, token string) (float64, int, error)
+	// NativePrice returns the USD price of the chain's native coin and its decimals
+	NativePrice(ctx context.Context, chainID string) (float64, int, error)
+}
+
+// GasPriceSource provides the gas price of a chain in the native coin's smallest unit per gas unit
+type GasPriceSource interface {
+	GasPrice(ctx context.Context, chainID string) (*big.Int, error)
+}
+
+// ReliabilitySource provides the historical success rate of providers
+type ReliabilitySource interface {
+	SuccessRate(provider string) float64
+}
+
+// Engine ranks quotes with a pluggable strategy
+type Engine struct {
+	prices      PriceSource
+	gasPrices   GasPriceSource
+	reliability ReliabilitySource
+
+	defaultStrategy    backend.RankingStrategy
+	preferredProviders []string
+	preferredBias      float64
+	chains             map[string]config.RankingChainConfig
+}
+
+// NewEngine creates a ranking engine from the ranking config
+func NewEngine(cfg config.RankingConfig, prices PriceSource, gasPrices GasPriceSource, reliability ReliabilitySource) (*Engine, error) {
+	defaultStrategy := backend.RankingBestNetOutput
+	if cfg.DefaultStrategy != "" {
+		defaultStrategy = backend.RankingStrategy(strings.ToUpper(cfg.DefaultStrategy))
+		if !IsValidStrategy(defaultStrategy) {
+			return nil, fmt.Errorf("unsupported ranking strategy: %s", cfg.DefaultStrategy)
+		}
+	}
+	for chainID, c := range cfg.Chains {
+		if c.Strategy != "" && !IsValidStrategy(backend.RankingStrategy(strings.ToUpper(c.Strategy))) {
+			return nil, fmt.Errorf("unsupported ranking strategy for chain %s: %s", chainID, c.Strategy)
+		}
+	}
+
+	biasBps := cfg.PreferredBiasBps
+	if biasBps == 0 {
+		biasBps = defaultPreferredBiasBps
+	}
+
+	return &Engine{
+		prices:             prices,
+		gasPrices:          gasPrices,
+		reliability:        reliability,
+		defaultStrategy:    defaultStrategy,
+		preferredProviders: cfg.PreferredProviders,
+		preferredBias:      float64(biasBps) / 10000,
+		chains:             cfg.Chains,
+	}, nil
+}
+
+// IsValidStrategy reports whether strategy is a known ranking strategy
+func IsValidStrategy(strategy backend.RankingStrategy) bool {
+	switch strategy {
+	case backend.RankingBestNetOutput, backend.RankingLowestGas, backend.RankingPreferredProvider, backend.RankingMostReliable:
+		return true
+	}
+	return false
+}
+
+// Strategy returns the strategy used for req: the request's own, else the chain default, else the global default
+func (e *Engine) Strategy(req *backend.QuoteRequest) (backend.RankingStrategy, error) {
+	if req.RankingStrategy != "" {
+		strategy := backend.RankingStrategy(strings.ToUpper(string(req.RankingStrategy)))
+		if !IsValidStrategy(strategy) {
+			return "", fmt.Errorf("unsupported ranking strategy: %s", req.RankingStrategy)
+		}
+		return strategy, nil
+	}
+	if c, ok := e.chains[req.FromChainID]; ok && c.Strategy != "" {
+		return backend.RankingStrategy(strings.ToUpper(c.Strategy)), nil
+	}
+	return e.defaultStrategy, nil
+}
+
+// Rank scores the quotes with the request's strategy and sorts them best first
+func (e *Engine) Rank(ctx context.Context, req *backend.QuoteRequest, quotes []*backend.Quote) (backend.RankingStrategy, error) {
+	strategy, err := e.Strategy(req)
+	if err != nil {
+		return "", err
+	}
+
+	costs := e.quoteCosts(ctx, req, quotes)
+
+	switch strategy {
+	case backend.RankingBestNetOutput:
+		for i, q := range quotes {
+			c := costs[i]
+			q.Score = c.score(strategy)
+			q.Score.Score = c.netOutput()
+		}
+	case backend.RankingLowestGas:
+		for i, q := range quotes {
+			c := costs[i]
+			q.Score = c.score(strategy)
+			q.Score.Score = -c.gasCost()
+		}
+	case backend.RankingPreferredProvider:
+		preferred := e.preferredFor(req)
+		for i, q := range quotes {
+			c := costs[i]
+			q.Score = c.score(strategy)
+			if preferred[strings.ToLower(q.Provider)] {
+				q.Score.ProviderBias = e.preferredBias
+			}
+			q.Score.Score = c.netOutput() * (1 + q.Score.ProviderBias)
+		}
+	case backend.RankingMostReliable:
+		for i, q := range quotes {
+			c := costs[i]
+			q.Score = c.score(strategy)
+			q.Score.Reliability = 1
+			if e.reliability != nil {
+				q.Score.Reliability = e.reliability.SuccessRate(q.Provider)
+			}
+			q.Score.Score = q.Score.Reliability
+		}
+	}
+
+	// 分数相同按输出数量排序
+	sort.SliceStable(quotes, func(i, j int) bool {
+		if quotes[i].Score.Score != quotes[j].Score.Score {
+			return quotes[i].Score.Score > quotes[j].Score.Score
+		}
+		return compareAmount(quotes[i].ToAmount, quotes[j].ToAmount) > 0
+	})
+
+	return strategy, nil
+}
+
+func (e *Engine) preferredFor(req *backend.QuoteRequest) map[string]bool {
+	providers := req.PreferredProviders
+	if len(providers) == 0 {
+		if c, ok := e.chains[req.FromChainID]; ok && len(c.PreferredProviders) > 0 {
+			providers = c.PreferredProviders
+		} else {
+			providers = e.preferredProviders
+		}
+	}
+
+	set := make(map[string]bool, len(providers))
+	for _, p := range providers {
+		set[strings.ToLower(p)] = true
+	}
+	return set
+}
+
+// quoteCost holds the converted values of one quote
+type quoteCost struct {
+	outputAmount float64 // 输出数量（最小单位）
+	gasUnits     float64
+	outputUSD    float64
+	gasCostUSD   float64
+	priced       bool // 所有报价的输出和 gas 均已换算为 USD
+	note         string
+}
+
+func (c *quoteCost) score(strategy backend.RankingStrategy) *backend.QuoteScore {
+	s := &backend.QuoteScore{Strategy: strategy, Note: c.note}
+	if c.priced {
+		s.OutputUSD = c.outputUSD
+		s.GasCostUSD = c.gasCostUSD
+		s.NetOutputUSD = c.outputUSD - c.gasCostUSD
+	}
+	return s
+}
+
+// netOutput is the net output in USD when priced, otherwise the raw output amount
+func (c *quoteCost) netOutput() float64 {
+	if c.priced {
+		return c.outputUSD - c.gasCostUSD
+	}
+	return c.outputAmount
+}
+
+// gasCost is the gas cost in USD when priced, otherwise the raw gas units
+func (c *quoteCost) gasCost() float64 {
+	if c.priced {
+		return c.gasCostUSD
+	}
+	return c.gasUnits
+}
+
+// quoteCosts converts output and gas of every quote to USD through the market price cache.
+// If any quote can't be priced, all quotes fall back to raw amounts so scores stay comparable.
+func (e *Engine) quoteCosts(ctx context.Context, req *backend.QuoteRequest, quotes []*backend.Quote) []*quoteCost {
+	costs := make([]*quoteCost, len(quotes))
+	for i, q := range quotes {
+		costs[i] = &quoteCost{
+			outputAmount: parseFloat(q.ToAmount),
+			gasUnits:     parseFloat(q.GasEstimate),
+		}
+	}
+
+	note, ok := e.priceQuotes(ctx, req, quotes, costs)
+	for _, c := range costs {
+		c.priced = ok
+		c.note = note
+	}
+	return costs
+}
+
+func (e *Engine) priceQuotes(ctx context.Context, req *backend.QuoteRequest, quotes []*backend.Quote, costs []*quoteCost) (string, bool) {
+	if e.prices == nil || e.gasPrices == nil || len(quotes) == 0 {
+		return "price source not configured, ranked by raw amounts", false
+	}
+
+	toChainID := req.ToChainID
+	if toChainID == "" {
+		toChainID = req.FromChainID
+	}
+	tokenPrice, tokenDecimals, err := e.prices.TokenPrice(ctx, toChainID, req.ToToken)
+	if err != nil {
+		log.Debug("Token price unavailable for ranking", "chainID", toChainID, "token", req.ToToken, "err", err)
+		return "output token price unavailable, ranked by raw amounts", false
+	}
+	nativePrice, nativeDecimals, err := e.prices.NativePrice(ctx, req.FromChainID)
+	if err != nil {
+		log.Debug("Native price unavailable for ranking", "chainID", req.FromChainID, "err", err)
+		return "native price unavailable, ranked by raw amounts", false
+	}
+	gasPrice, err := e.gasPrices.GasPrice(ctx, req.FromChainID)
+	if err != nil || gasPrice == nil {
+		log.Debug("Gas price unavailable for ranking", "chainID", req.FromChainID, "err", err)
+		return "gas price unavailable, ranked by raw amounts", false
+	}
+	gasPriceF, _ := new(big.Float).SetInt(gasPrice).Float64()
+
+	for _, c := range costs {
+		c.outputUSD = c.outputAmount / pow10(tokenDecimals) * tokenPrice
+		c.gasCostUSD = c.gasUnits * gasPriceF / pow10(nativeDecimals) * nativePrice
+	}
+	return "", true
+}
+
+func parseFloat(s string) float64 {
+	f, ok := new(big.Float).SetString(s)
+	if !ok {
+		return 0
+	}
+	v, _ := f.Float64()
+	return v
+}
+
+func pow10(n int) float64 {
+	v := 1.0
+	for i := 0; i < n; i++ {
+		v *= 10
+	}
+	return v
+}
+
+func compareAmount(a, b string) int {
+	x, ok := new(big.Int).SetString(a, 10)
+	if !ok {
+		x = new(big.Int)
+	}
+	y, ok := new(big.Int).SetString(b, 10)
+	if !ok {
+		y = new(big.Int)
+	}
+	return x.Cmp(y)
+}
@@ -0,0 +1,144 @@
+package ranking
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roothash-pay/wallet-services/config"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+type fakePrices struct{}
+
+func (fakePrices) TokenPrice(ctx context.Context, chainID, token string) (float64, int, error) {
+	return 1, 6, nil // USDC
+}
+
+func (fakePrices) NativePrice(ctx context.Context, chainID string) (float64, int, error) {
+	return 2000, 18, nil // ETH
+}
+
+type fakeGasPrices struct{}
+
+func (fakeGasPrices) GasPrice(ctx context.Context, chainID string) (*big.Int, error) {
+	return big.NewInt(10_000_000_000), nil // 10 gwei
+}
+
+func newQuotes() []*backend.Quote {
+	return []*backend.Quote{
+		// 100 USDC out, 500k gas = 10 USD
+		{Provider: "lifi", ToAmount: "100000000", GasEstimate: "500000"},
+		// 99 USDC out, 100k gas = 2 USD
+		{Provider: "1inch", ToAmount: "99000000", GasEstimate: "100000"},
+		// 98 USDC out, 50k gas = 1 USD
+		{Provider: "0x", ToAmount: "98000000", GasEstimate: "50000"},
+	}
+}
+
+func providersOf(quotes []*backend.Quote) []string {
+	names := make([]string, len(quotes))
+	for i, q := range quotes {
+		names[i] = q.Provider
+	}
+	return names
+}
+
+func TestEngineRank(t *testing.T) {
+	stats := NewProviderStats()
+	for i := 0; i < 10; i++ {
+		stats.Record("lifi", i%2 == 0)
+		stats.Record("1inch", false)
+		stats.Record("0x", true)
+	}
+
+	engine, err := NewEngine(config.RankingConfig{
+		PreferredProviders: []string{"lifi"},
+		PreferredBiasBps:   1000,
+		Chains: map[string]config.RankingChainConfig{
+			"56": {Strategy: "lowest_gas"},
+		},
+	}, fakePrices{}, fakeGasPrices{}, stats)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		req      *backend.QuoteRequest
+		strategy backend.RankingStrategy
+		order    []string
+	}{
+		{
+			name:     "default best net output",
+			req:      &backend.QuoteRequest{FromChainID: "1"},
+			strategy: backend.RankingBestNetOutput,
+			order:    []string{"1inch", "0x", "lifi"},
+		},
+		{
+			name:     "chain default lowest gas",
+			req:      &backend.QuoteRequest{FromChainID: "56"},
+			strategy: backend.RankingLowestGas,
+			order:    []string{"0x", "1inch", "lifi"},
+		},
+		{
+			name:     "preferred provider",
+			req:      &backend.QuoteRequest{FromChainID: "1", RankingStrategy: backend.RankingPreferredProvider},
+			strategy: backend.RankingPreferredProvider,
+			order:    []string{"lifi", "1inch", "0x"},
+		},
+		{
+			name:     "most reliable",
+			req:      &backend.QuoteRequest{FromChainID: "56", RankingStrategy: backend.RankingMostReliable},
+			strategy: backend.RankingMostReliable,
+			order:    []string{"0x", "lifi", "1inch"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quotes := newQuotes()
+			strategy, err := engine.Rank(context.Background(), tt.req, quotes)
+			require.NoError(t, err)
+			assert.Equal(t, tt.strategy, strategy)
+			assert.Equal(t, tt.order, providersOf(quotes))
+			for _, q := range quotes {
+				require.NotNil(t, q.Score)
+				assert.Equal(t, tt.strategy, q.Score.Strategy)
+			}
+		})
+	}
+
+	_, err = engine.Rank(context.Background(), &backend.QuoteRequest{RankingStrategy: "CHEAPEST"}, newQuotes())
+	assert.Error(t, err)
+}
+
+func TestEngineRankWithoutPrices(t *testing.T) {
+	engine, err := NewEngine(config.RankingConfig{}, nil, nil, nil)
+	require.NoError(t, err)
+
+	quotes := newQuotes()
+	_, err = engine.Rank(context.Background(), &backend.QuoteRequest{FromChainID: "1"}, quotes)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lifi", "1inch", "0x"}, providersOf(quotes))
+	assert.NotEmpty(t, quotes[0].Score.Note)
+	assert.Zero(t, quotes[0].Score.NetOutputUSD)
+}
+
+func TestFallbackGasPriceSource(t *testing.T) {
+	live := NewConfigGasPriceSource(map[string]config.RankingChainConfig{"1": {GasPriceGwei: 20}}, nil)
+	source := NewFallbackGasPriceSource(live, fakeGasPrices{})
+
+	price, err := source.GasPrice(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Equal(t, "20000000000", price.String())
+
+	// chain 56 is missing from the first source
+	price, err = source.GasPrice(context.Background(), "56")
+	require.NoError(t, err)
+	assert.Equal(t, "10000000000", price.String())
+
+	_, err = NewFallbackGasPriceSource(live).GasPrice(context.Background(), "56")
+	assert.Error(t, err)
+}
@@ -0,0 +1,207 @@
+package ranking
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/roothash-pay/wallet-services/config"
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+	"github.com/roothash-pay/wallet-services/services/common/chaininfo"
+	marketService "github.com/roothash-pay/wallet-services/services/market/service"
+)
+
+// MarketPriceSource resolves token prices from the market price cache, by contract first and then by symbol
+type MarketPriceSource struct {
+	market    marketService.MarketService
+	tokens    dbBackend.TokenView
+	chainInfo chaininfo.Provider
+}
+
+// NewMarketPriceSource creates a price source backed by the market cache and the token table
+func NewMarketPriceSource(market marketService.MarketService, tokens dbBackend.TokenView, chainInfo chaininfo.Provider) *MarketPriceSource {
+	return &MarketPriceSource{
+		market:    market,
+		tokens:    tokens,
+		chainInfo: chainInfo,
+	}
+}
+
+// TokenPrice returns the USD price and decimals of token on chainID
+func (s *MarketPriceSource) TokenPrice(ctx context.Context, chainID, token string) (float64, int, error) {
+	if isNativeToken(token) {
+		return s.NativePrice(ctx, chainID)
+	}
+	if s.tokens == nil {
+		return 0, 0, fmt.Errorf("token table not configured")
+	}
+
+	t, err := s.tokens.GetByContractAndChain(token, chainID)
+	if err != nil || t == nil {
+		return 0, 0, fmt.Errorf("token not found: %s", token)
+	}
+	decimals, err := strconv.Atoi(t.TokenDecimal)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid token decimal %q: %w", t.TokenDecimal, err)
+	}
+
+	// 优先按合约地址取价，避免同名代币串价；未收录时退回符号价格
+	if s.market != nil {
+		if quote, err := s.market.GetPriceByContract(ctx, chainID, token); err == nil && quote != nil && quote.Price > 0 {
+			return quote.Price, decimals, nil
+		}
+	}
+	price, err := s.symbolPrice(ctx, t.TokenSymbol)
+	if err != nil {
+		return 0, 0, err
+	}
+	return price, decimals, nil
+}
+
+// NativePrice returns the USD price and decimals of the chain's native coin
+func (s *MarketPriceSource) NativePrice(ctx context.Context, chainID string) (float64, int, error) {
+	if s.chainInfo == nil {
+		return 0, 0, fmt.Errorf("chain info provider not configured")
+	}
+	info, err := s.chainInfo.Get(ctx, chainID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if info.NativeSymbol == "" {
+		return 0, 0, fmt.Errorf("native symbol not configured for chain %s", chainID)
+	}
+
+	decimals := 18
+	if strings.EqualFold(info.ChainType, string(backend.ChainTypeSolana)) {
+		decimals = 9
+	}
+
+	price, err := s.symbolPrice(ctx, info.NativeSymbol)
+	if err != nil {
+		return 0, 0, err
+	}
+	return price, decimals, nil
+}
+
+func (s *MarketPriceSource) symbolPrice(ctx context.Context, symbol string) (float64, error) {
+	if s.market == nil {
+		return 0, fmt.Errorf("market service not configured")
+	}
+	quote, err := s.market.GetPrice(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	if quote == nil || quote.Price <= 0 {
+		return 0, fmt.Errorf("price not found: %s", symbol)
+	}
+	return quote.Price, nil
+}
+
+// ConfigGasPriceSource serves the gas prices configured per chain (ranking.chains.<id>.gas_price_gwei).
+// Solana fees are already expressed in lamports, so its gas price is 1.
+type ConfigGasPriceSource struct {
+	chains    map[string]config.RankingChainConfig
+	chainInfo chaininfo.Provider
+}
+
+// NewConfigGasPriceSource creates a gas price source from the ranking config
+func NewConfigGasPriceSource(chains map[string]config.RankingChainConfig, chainInfo chaininfo.Provider) *ConfigGasPriceSource {
+	return &ConfigGasPriceSource{
+		chains:    chains,
+		chainInfo: chainInfo,
+	}
+}
+
+// GasPrice returns the gas price of chainID in wei (lamports on Solana)
+func (s *ConfigGasPriceSource) GasPrice(ctx context.Context, chainID string) (*big.Int, error) {
+	if c, ok := s.chains[chainID]; ok && c.GasPriceGwei > 0 {
+		wei, _ := new(big.Float).Mul(big.NewFloat(c.GasPriceGwei), big.NewFloat(1e9)).Int(nil)
+		return wei, nil
+	}
+	if s.chainInfo != nil {
+		if info, err := s.chainInfo.Get(ctx, chainID); err == nil && strings.EqualFold(info.ChainType, string(backend.ChainTypeSolana)) {
+			return big.NewInt(1), nil
+		}
+	}
+	return nil, fmt.Errorf("gas price not configured for chain %s", chainID)
+}
+
+// FallbackGasPriceSource tries each source in order and returns the first gas price found,
+// e.g. the live network fee first and the configured gas price when the chain adapter is down
+type FallbackGasPriceSource struct {
+	sources []GasPriceSource
+}
+
+// NewFallbackGasPriceSource creates a gas price source that falls back through sources
+func NewFallbackGasPriceSource(sources ...GasPriceSource) *FallbackGasPriceSource {
+	return &FallbackGasPriceSource{sources: sources}
+}
+
+// GasPrice returns the gas price of the first source that has one
+func (s *FallbackGasPriceSource) GasPrice(ctx context.Context, chainID string) (*big.Int, error) {
+	err := fmt.Errorf("no gas price source for chain %s", chainID)
+	for _, source := range s.sources {
+		price, sourceErr := source.GasPrice(ctx, chainID)
+		if sourceErr == nil && price != nil {
+			return price, nil
+		}
+		if sourceErr != nil {
+			err = sourceErr
+		}
+	}
+	return nil, err
+}
+
+const reliabilityWindow = 100
+
+// ProviderStats tracks the recent quote outcomes of each provider
+type ProviderStats struct {
+	mu       sync.RWMutex
+	outcomes map[string][]bool
+}
+
+// NewProviderStats creates an empty provider stats tracker
+func NewProviderStats() *ProviderStats {
+	return &ProviderStats{
+		outcomes: make(map[string][]bool),
+	}
+}
+
+// Record records the outcome of one provider call, keeping the last reliabilityWindow outcomes
+func (s *ProviderStats) Record(provider string, success bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := append(s.outcomes[provider], success)
+	if len(list) > reliabilityWindow {
+		list = list[len(list)-reliabilityWindow:]
+	}
+	s.outcomes[provider] = list
+}
+
+// SuccessRate returns the smoothed success rate of provider; providers without history score 0.5
+func (s *ProviderStats) SuccessRate(provider string) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := s.outcomes[provider]
+	success := 0
+	for _, ok := range list {
+		if ok {
+			success++
+		}
+	}
+	// Laplace 平滑，避免少量样本时分数极端
+	return float64(success+1) / float64(len(list)+2)
+}
+
+// isNativeToken checks if the token is the native coin placeholder address
+func isNativeToken(token string) bool {
+	return token == "" ||
+		strings.EqualFold(token, "0x0000000000000000000000000000000000000000") ||
+		strings.EqualFold(token, "0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+}
@@ -0,0 +1,273 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/store"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// ErrInvalidTransition is returned for a status change the state machine does not allow
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// Transition describes a status change of a wallet_tx_record
+type Transition struct {
+	To             int
+	Source         string
+	FailReasonCode string
+	FailReasonMsg  string
+	// Updates are other columns written together with the status (tx_id, block_height, memo...)
+	Updates map[string]interface{}
+	// Bridge carries the destination-chain result onto the swap step
+	Bridge *backend.BridgeStatus
+	// SkipStep leaves the swap step alone, for records that do not decide the step outcome
+	// (a replaced tx, or the cancel tx itself)
+	SkipStep bool
+}
+
+// Engine is the single place where tx and swap statuses change.
+// Every transition is checked against the state machine, applied with a compare-and-set
+// so concurrent deciders cannot both move the same tx, and recorded in tx_status_transition,
+// which also feeds the websocket push.
+type Engine struct {
+	records dbBackend.WalletTxRecordDB
+	audit   dbBackend.TxStatusTransitionDB
+	swaps   store.SwapStore // 可选，记录属于 swap 步骤时同步 step 与 swap 状态
+}
+
+// NewEngine creates a status engine
+func NewEngine(records dbBackend.WalletTxRecordDB, audit dbBackend.TxStatusTransitionDB, swaps store.SwapStore) *Engine {
+	return &Engine{
+		records: records,
+		audit:   audit,
+		swaps:   swaps,
+	}
+}
+
+// ApplyTx moves a wallet_tx_record to t.To. It returns false without error when the record is
+// already in that status or another caller changed it first; record is updated in place on success.
+func (e *Engine) ApplyTx(ctx context.Context, record *dbBackend.WalletTxRecord, t Transition) (bool, error) {
+	from := record.Status
+	if from == t.To {
+		return false, nil
+	}
+	if !CanTransition(from, t.To) {
+		return false, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, backend.TxStatusNames[from], backend.TxStatusNames[t.To])
+	}
+	if e.records == nil {
+		return false, fmt.Errorf("wallet tx record storage not configured")
+	}
+
+	updates := make(map[string]interface{}, len(t.Updates)+3)
+	for k, v := range t.Updates {
+		updates[k] = v
+	}
+	updates["status"] = t.To
+	if t.FailReasonCode != "" {
+		updates["fail_reason_code"] = t.FailReasonCode
+		updates["fail_reason_msg"] = t.FailReasonMsg
+	}
+
+	ok, err := e.records.TransitionWalletTxRecord(record.Guid, from, updates)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		log.Info("Tx status already changed, skip transition", "guid", record.Guid, "from", backend.TxStatusNames[from], "to", backend.TxStatusNames[t.To])
+		return false, nil
+	}
+
+	record.Status = t.To
+	if txID, ok := t.Updates["tx_id"].(string); ok {
+		record.TxID = txID
+	}
+	if t.FailReasonCode != "" {
+		record.FailReasonCode = t.FailReasonCode
+		record.FailReasonMsg = t.FailReasonMsg
+	}
+	log.Info("Tx status changed", "guid", record.Guid, "hash", record.TxID, "from", backend.TxStatusNames[from], "to", backend.TxStatusNames[t.To], "source", t.Source)
+
+	e.recordTransition(&dbBackend.TxStatusTransition{
+		Kind:           dbBackend.TransitionKindTx,
+		RecordGuid:     record.Guid,
+		OperationID:    record.OperationID,
+		StepIndex:      record.StepIndex,
+		WalletUUID:     record.WalletUUID,
+		ChainID:        record.ChainID,
+		TxID:           record.TxID,
+		FromStatus:     from,
+		ToStatus:       t.To,
+		FailReasonCode: t.FailReasonCode,
+		FailReasonMsg:  t.FailReasonMsg,
+		Source:         t.Source,
+	})
+
+	if record.OperationID != "" && e.swaps != nil && !t.SkipStep {
+		e.syncStep(ctx, record, t)
+	}
+	return true, nil
+}
+
+// ApplySwap persists swap with its status moved to `to`, recording the transition if the status changed
+func (e *Engine) ApplySwap(ctx context.Context, swap *backend.Swap, to int, source string) error {
+	if e.swaps == nil {
+		return fmt.Errorf("swap storage not configured")
+	}
+
+	from := swap.Status
+	if from != to && !CanTransition(from, to) {
+		return fmt.Errorf("%w: swap %s %s -> %s", ErrInvalidTransition, swap.SwapID, backend.TxStatusNames[from], backend.TxStatusNames[to])
+	}
+
+	swap.Status = to
+	swap.FailReasonCode, swap.FailMessage = swapFailReason(swap, to)
+	if err := e.swaps.UpdateSwap(ctx, swap); err != nil {
+		swap.Status = from
+		return err
+	}
+	if from == to {
+		return nil
+	}
+
+	e.recordSwapTransition(swap, from, source)
+	return nil
+}
+
+// SyncSwap persists swap with the status derived from its steps
+func (e *Engine) SyncSwap(ctx context.Context, swap *backend.Swap, source string) error {
+	return e.ApplySwap(ctx, swap, SwapStatus(swap.Status, swap.Steps), source)
+}
+
+// syncStep mirrors a swap step's record transition onto the swap store.
+// Only the record's own step is written, so concurrent records of the same swap do not overwrite
+// each other's steps with a stale read; the swap status is then derived from the stored steps.
+func (e *Engine) syncStep(ctx context.Context, record *dbBackend.WalletTxRecord, t Transition) {
+	stored, err := e.swaps.GetStep(ctx, record.OperationID, record.StepIndex)
+	if err != nil {
+		log.Warn("Step not found for tx transition", "swapID", record.OperationID, "stepIndex", record.StepIndex, "guid", record.Guid, "err", err)
+		return
+	}
+
+	step := *stored
+	if record.TxID != "" {
+		// 步骤被替换交易推进时以该交易为准
+		step.TxHash = record.TxID
+	}
+	if step.Status != t.To {
+		now := time.Now()
+		step.Status = t.To
+		switch t.To {
+		case backend.TxStatusSuccess, backend.TxStatusPartial:
+			step.ConfirmedAt = &now
+		case backend.TxStatusFailed, backend.TxStatusRefunded:
+			step.FailReasonCode = t.FailReasonCode
+			step.FailMessage = t.FailReasonMsg
+		}
+	}
+	if t.Bridge != nil {
+		step.BridgeStatus = t.Bridge.Substatus
+		if t.Bridge.DestChainID != "" {
+			step.DestChainID = t.Bridge.DestChainID
+		}
+		if t.Bridge.DestTxHash != "" {
+			step.DestTxHash = t.Bridge.DestTxHash
+		}
+		if t.Bridge.ReceivedToken != "" {
+			step.ReceivedToken = t.Bridge.ReceivedToken
+		}
+		if t.Bridge.ReceivedAmount != "" {
+			step.ReceivedAmount = t.Bridge.ReceivedAmount
+		}
+	}
+
+	if err := e.swaps.UpdateStep(ctx, record.OperationID, record.StepIndex, &step); err != nil {
+		log.Warn("Failed to update swap step", "swapID", record.OperationID, "stepIndex", record.StepIndex, "err", err)
+		return
+	}
+	e.syncSwapStatus(ctx, record.OperationID, t.Source)
+}
+
+// syncSwapStatusAttempts bounds the re-reads when other writers keep moving the swap status
+const syncSwapStatusAttempts = 3
+
+// syncSwapStatus re-reads the swap, derives its status from the stored steps and writes only the
+// status with a compare-and-set; a swap changed in between is read again
+func (e *Engine) syncSwapStatus(ctx context.Context, swapID string, source string) {
+	for attempt := 0; attempt < syncSwapStatusAttempts; attempt++ {
+		swap, err := e.swaps.GetSwap(ctx, swapID)
+		if err != nil {
+			log.Warn("Swap not found for status sync", "swapID", swapID, "err", err)
+			return
+		}
+
+		from := swap.Status
+		to := SwapStatus(from, swap.Steps)
+		if from == to {
+			return
+		}
+		if !CanTransition(from, to) {
+			log.Warn("Invalid swap status transition, skip sync", "swapID", swapID, "from", backend.TxStatusNames[from], "to", backend.TxStatusNames[to])
+			return
+		}
+
+		failReasonCode, failMessage := swapFailReason(swap, to)
+		ok, err := e.swaps.TransitionSwapStatus(ctx, swapID, from, to, failReasonCode, failMessage)
+		if err != nil {
+			log.Warn("Failed to sync swap status", "swapID", swapID, "err", err)
+			return
+		}
+		if !ok {
+			continue
+		}
+
+		swap.Status = to
+		swap.FailReasonCode = failReasonCode
+		swap.FailMessage = failMessage
+		e.recordSwapTransition(swap, from, source)
+		return
+	}
+	log.Warn("Swap status kept changing, skip sync", "swapID", swapID)
+}
+
+// swapFailReason keeps the swap's fail reason, or takes it from the step that failed or refunded the swap
+func swapFailReason(swap *backend.Swap, to int) (string, string) {
+	if (to == backend.TxStatusFailed || to == backend.TxStatusRefunded) && swap.FailReasonCode == "" {
+		for _, step := range swap.Steps {
+			if step.Status == to {
+				return step.FailReasonCode, step.FailMessage
+			}
+		}
+	}
+	return swap.FailReasonCode, swap.FailMessage
+}
+
+// recordSwapTransition logs a swap status change and writes its audit row
+func (e *Engine) recordSwapTransition(swap *backend.Swap, from int, source string) {
+	log.Info("Swap status changed", "swapID", swap.SwapID, "from", backend.TxStatusNames[from], "to", backend.TxStatusNames[swap.Status], "source", source)
+	e.recordTransition(&dbBackend.TxStatusTransition{
+		Kind:           dbBackend.TransitionKindSwap,
+		OperationID:    swap.SwapID,
+		WalletUUID:     swap.WalletUUID,
+		FromStatus:     from,
+		ToStatus:       swap.Status,
+		FailReasonCode: swap.FailReasonCode,
+		FailReasonMsg:  swap.FailMessage,
+		Source:         source,
+	})
+}
+
+// recordTransition writes the audit row; a failed write is logged and does not undo the transition
+func (e *Engine) recordTransition(t *dbBackend.TxStatusTransition) {
+	if e.audit == nil {
+		return
+	}
+	if err := e.audit.StoreTxStatusTransition(t); err != nil {
+		log.Error("Failed to record status transition", "kind", t.Kind, "guid", t.RecordGuid, "operationID", t.OperationID, "err", err)
+	}
+}
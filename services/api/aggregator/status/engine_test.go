@@ -0,0 +1,309 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/store"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// memRecords keeps wallet_tx_record statuses in memory
+type memRecords struct {
+	dbBackend.WalletTxRecordDB
+	mu       sync.Mutex
+	statuses map[string]int
+}
+
+func (m *memRecords) TransitionWalletTxRecord(guid string, fromStatus int, updates map[string]interface{}) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.statuses[guid] != fromStatus {
+		return false, nil
+	}
+	m.statuses[guid] = updates["status"].(int)
+	return true, nil
+}
+
+type memAudit struct {
+	dbBackend.TxStatusTransitionDB
+	mu   sync.Mutex
+	rows []*dbBackend.TxStatusTransition
+}
+
+func (m *memAudit) StoreTxStatusTransition(t *dbBackend.TxStatusTransition) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rows = append(m.rows, t)
+	return nil
+}
+
+func newTestEngine() (*Engine, *memRecords, *memAudit, store.SwapStore) {
+	records := &memRecords{statuses: map[string]int{}}
+	audit := &memAudit{}
+	swaps := store.NewInMemorySwapStore()
+	return NewEngine(records, audit, swaps), records, audit, swaps
+}
+
+func TestApplyTxSyncsSwap(t *testing.T) {
+	ctx := context.Background()
+	engine, records, audit, swaps := newTestEngine()
+
+	require.NoError(t, swaps.CreateSwap(ctx, &backend.Swap{
+		SwapID:     "swap-1",
+		WalletUUID: "wallet-1",
+		Status:     backend.TxStatusPending,
+		Steps: []*backend.Step{
+			{StepIndex: 0, ActionType: backend.ActionTypeApprove, Status: backend.TxStatusSuccess},
+			{StepIndex: 1, ActionType: backend.ActionTypeSwap, Status: backend.TxStatusPending, TxHash: "0xswap"},
+		},
+	}))
+	records.statuses["guid-1"] = backend.TxStatusPending
+	record := &dbBackend.WalletTxRecord{
+		Guid:        "guid-1",
+		OperationID: "swap-1",
+		StepIndex:   1,
+		WalletUUID:  "wallet-1",
+		TxID:        "0xswap",
+		Status:      backend.TxStatusPending,
+	}
+
+	changed, err := engine.ApplyTx(ctx, record, Transition{To: backend.TxStatusSuccess, Source: SourceWorker})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, backend.TxStatusSuccess, record.Status)
+
+	swap, err := swaps.GetSwap(ctx, "swap-1")
+	require.NoError(t, err)
+	assert.Equal(t, backend.TxStatusSuccess, swap.Steps[1].Status)
+	assert.NotNil(t, swap.Steps[1].ConfirmedAt)
+	assert.Equal(t, backend.TxStatusSuccess, swap.Status)
+
+	require.Len(t, audit.rows, 2)
+	assert.Equal(t, dbBackend.TransitionKindTx, audit.rows[0].Kind)
+	assert.Equal(t, "guid-1", audit.rows[0].RecordGuid)
+	assert.Equal(t, backend.TxStatusPending, audit.rows[0].FromStatus)
+	assert.Equal(t, backend.TxStatusSuccess, audit.rows[0].ToStatus)
+	assert.Equal(t, dbBackend.TransitionKindSwap, audit.rows[1].Kind)
+	assert.Equal(t, "swap-1", audit.rows[1].OperationID)
+	assert.Equal(t, "wallet-1", audit.rows[1].WalletUUID)
+
+	// the same outcome reported again is a no-op
+	changed, err = engine.ApplyTx(ctx, record, Transition{To: backend.TxStatusSuccess, Source: SourceAPI})
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Len(t, audit.rows, 2)
+}
+
+func TestApplyTxFailureFailsSwap(t *testing.T) {
+	ctx := context.Background()
+	engine, records, _, swaps := newTestEngine()
+
+	require.NoError(t, swaps.CreateSwap(ctx, &backend.Swap{
+		SwapID: "swap-2",
+		Status: backend.TxStatusPending,
+		Steps: []*backend.Step{
+			{StepIndex: 0, ActionType: backend.ActionTypeSwap, Status: backend.TxStatusPending},
+		},
+	}))
+	records.statuses["guid-2"] = backend.TxStatusPending
+	record := &dbBackend.WalletTxRecord{Guid: "guid-2", OperationID: "swap-2", Status: backend.TxStatusPending}
+
+	_, err := engine.ApplyTx(ctx, record, Transition{
+		To:             backend.TxStatusFailed,
+		Source:         SourceWorker,
+		FailReasonCode: dbBackend.FailReasonChainFailed,
+		FailReasonMsg:  "Transaction failed on chain",
+	})
+	require.NoError(t, err)
+
+	swap, err := swaps.GetSwap(ctx, "swap-2")
+	require.NoError(t, err)
+	assert.Equal(t, backend.TxStatusFailed, swap.Status)
+	assert.Equal(t, dbBackend.FailReasonChainFailed, swap.Steps[0].FailReasonCode)
+	assert.Equal(t, dbBackend.FailReasonChainFailed, swap.FailReasonCode)
+}
+
+func TestApplyTxRejectsInvalidTransition(t *testing.T) {
+	engine, records, audit, _ := newTestEngine()
+	records.statuses["guid-3"] = backend.TxStatusSuccess
+	record := &dbBackend.WalletTxRecord{Guid: "guid-3", Status: backend.TxStatusSuccess}
+
+	changed, err := engine.ApplyTx(context.Background(), record, Transition{To: backend.TxStatusFailed})
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+	assert.False(t, changed)
+	assert.Empty(t, audit.rows)
+}
+
+func TestApplyTxOnlyOneDeciderWins(t *testing.T) {
+	engine, records, audit, _ := newTestEngine()
+	records.statuses["guid-4"] = backend.TxStatusPending
+
+	outcomes := []int{backend.TxStatusSuccess, backend.TxStatusFailed}
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		wins int
+	)
+	for _, to := range outcomes {
+		wg.Add(1)
+		go func(to int) {
+			defer wg.Done()
+			// each decider works on its own stale copy of the record
+			record := &dbBackend.WalletTxRecord{Guid: "guid-4", Status: backend.TxStatusPending}
+			changed, err := engine.ApplyTx(context.Background(), record, Transition{To: to})
+			if !assert.NoError(t, err) {
+				return
+			}
+			if changed {
+				mu.Lock()
+				wins++
+				mu.Unlock()
+			}
+		}(to)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, wins)
+	assert.Len(t, audit.rows, 1)
+}
+
+func TestApplySwapRejectsLeavingFinalStatus(t *testing.T) {
+	ctx := context.Background()
+	engine, _, audit, swaps := newTestEngine()
+
+	swap := &backend.Swap{SwapID: "swap-5", Status: backend.TxStatusSuccess}
+	require.NoError(t, swaps.CreateSwap(ctx, swap))
+
+	err := engine.ApplySwap(ctx, swap, backend.TxStatusPending, SourceAPI)
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+	assert.Equal(t, backend.TxStatusSuccess, swap.Status)
+	assert.Empty(t, audit.rows)
+}
+
+func TestApplyTxReplacement(t *testing.T) {
+	ctx := context.Background()
+	engine, records, _, swaps := newTestEngine()
+
+	require.NoError(t, swaps.CreateSwap(ctx, &backend.Swap{
+		SwapID: "swap-6",
+		Status: backend.TxStatusPending,
+		Steps: []*backend.Step{
+			{StepIndex: 0, ActionType: backend.ActionTypeSwap, Status: backend.TxStatusPending, TxHash: "0xoriginal"},
+		},
+	}))
+	records.statuses["guid-original"] = backend.TxStatusPending
+	records.statuses["guid-speedup"] = backend.TxStatusPending
+	original := &dbBackend.WalletTxRecord{Guid: "guid-original", OperationID: "swap-6", TxID: "0xoriginal", Status: backend.TxStatusPending}
+	speedUp := &dbBackend.WalletTxRecord{Guid: "guid-speedup", OperationID: "swap-6", TxID: "0xspeedup", Status: backend.TxStatusPending}
+
+	// the superseded tx fails without touching the step
+	_, err := engine.ApplyTx(ctx, original, Transition{
+		To:             backend.TxStatusFailed,
+		FailReasonCode: dbBackend.FailReasonReplaced,
+		SkipStep:       true,
+	})
+	require.NoError(t, err)
+	swap, err := swaps.GetSwap(ctx, "swap-6")
+	require.NoError(t, err)
+	assert.Equal(t, backend.TxStatusPending, swap.Steps[0].Status)
+	assert.Equal(t, "0xoriginal", swap.Steps[0].TxHash)
+
+	// the mined replacement decides the step
+	_, err = engine.ApplyTx(ctx, speedUp, Transition{To: backend.TxStatusSuccess})
+	require.NoError(t, err)
+	swap, err = swaps.GetSwap(ctx, "swap-6")
+	require.NoError(t, err)
+	assert.Equal(t, backend.TxStatusSuccess, swap.Steps[0].Status)
+	assert.Equal(t, "0xspeedup", swap.Steps[0].TxHash)
+	assert.Equal(t, backend.TxStatusSuccess, swap.Status)
+}
+
+// snapshotSwapStore hands out copies as the DB and Redis stores do, and runs afterRead once after the first read
+type snapshotSwapStore struct {
+	store.SwapStore
+	afterRead func()
+}
+
+func (s *snapshotSwapStore) GetSwap(ctx context.Context, swapID string) (*backend.Swap, error) {
+	swap, err := s.SwapStore.GetSwap(ctx, swapID)
+	if err != nil {
+		return nil, err
+	}
+	cp := *swap
+	cp.Steps = make([]*backend.Step, len(swap.Steps))
+	for i, step := range swap.Steps {
+		stepCopy := *step
+		cp.Steps[i] = &stepCopy
+	}
+	s.fire()
+	return &cp, nil
+}
+
+func (s *snapshotSwapStore) GetStep(ctx context.Context, swapID string, stepIndex int) (*backend.Step, error) {
+	step, err := s.SwapStore.GetStep(ctx, swapID, stepIndex)
+	if err != nil {
+		return nil, err
+	}
+	cp := *step
+	s.fire()
+	return &cp, nil
+}
+
+func (s *snapshotSwapStore) fire() {
+	if f := s.afterRead; f != nil {
+		s.afterRead = nil
+		f()
+	}
+}
+
+func TestApplyTxKeepsConcurrentStepUpdates(t *testing.T) {
+	ctx := context.Background()
+	records := &memRecords{statuses: map[string]int{}}
+	audit := &memAudit{}
+	swaps := &snapshotSwapStore{SwapStore: store.NewInMemorySwapStore()}
+	engine := NewEngine(records, audit, swaps)
+
+	require.NoError(t, swaps.CreateSwap(ctx, &backend.Swap{
+		SwapID: "swap-7",
+		Status: backend.TxStatusPending,
+		Steps: []*backend.Step{
+			{StepIndex: 0, ActionType: backend.ActionTypeApprove, Status: backend.TxStatusPending},
+			{StepIndex: 1, ActionType: backend.ActionTypeSwap, Status: backend.TxStatusPending},
+		},
+	}))
+	records.statuses["guid-7a"] = backend.TxStatusPending
+	records.statuses["guid-7b"] = backend.TxStatusPending
+
+	// step 1 is confirmed by another worker between step 0's read and write
+	swaps.afterRead = func() {
+		other := &dbBackend.WalletTxRecord{Guid: "guid-7b", OperationID: "swap-7", StepIndex: 1, Status: backend.TxStatusPending}
+		_, err := engine.ApplyTx(ctx, other, Transition{To: backend.TxStatusSuccess})
+		require.NoError(t, err)
+	}
+	record := &dbBackend.WalletTxRecord{Guid: "guid-7a", OperationID: "swap-7", StepIndex: 0, Status: backend.TxStatusPending}
+	_, err := engine.ApplyTx(ctx, record, Transition{To: backend.TxStatusSuccess})
+	require.NoError(t, err)
+
+	swap, err := swaps.GetSwap(ctx, "swap-7")
+	require.NoError(t, err)
+	assert.Equal(t, backend.TxStatusSuccess, swap.Steps[0].Status)
+	assert.Equal(t, backend.TxStatusSuccess, swap.Steps[1].Status)
+	assert.Equal(t, backend.TxStatusSuccess, swap.Status)
+
+	var swapRows int
+	for _, row := range audit.rows {
+		if row.Kind == dbBackend.TransitionKindSwap {
+			swapRows++
+		}
+	}
+	assert.Equal(t, 1, swapRows)
+}
@@ -0,0 +1,72 @@
+// Package status owns the tx status state machine shared by swaps and plain transfers:
+// CREATED -> PENDING -> CONFIRMING -> SUCCESS / FAILED (PARTIAL / REFUNDED for bridges),
+// with CONFIRMING -> PENDING when a reorg drops the tx from its block.
+package status
+
+import (
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// Source records who drove a transition
+const (
+	SourceAPI    = "api"    // 用户请求（广播、提交订单、查询状态）
+	SourceWorker = "worker" // 后台扫描链上 / 跨链结果
+)
+
+// transitions lists the allowed next statuses; terminal statuses have none
+var transitions = map[int][]int{
+	backend.TxStatusCreated:    {backend.TxStatusPending, backend.TxStatusFailed},
+	backend.TxStatusPending:    {backend.TxStatusConfirming, backend.TxStatusSuccess, backend.TxStatusFailed, backend.TxStatusPartial, backend.TxStatusRefunded},
+	backend.TxStatusConfirming: {backend.TxStatusPending, backend.TxStatusSuccess, backend.TxStatusFailed},
+}
+
+// CanTransition reports whether a tx or swap may move from one status to another
+func CanTransition(from, to int) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal reports whether a status is terminal
+func IsFinal(status int) bool {
+	switch status {
+	case backend.TxStatusSuccess, backend.TxStatusFailed, backend.TxStatusPartial, backend.TxStatusRefunded:
+		return true
+	}
+	return false
+}
+
+// SwapStatus derives the swap status from its steps:
+// any FAILED -> FAILED, any REFUNDED -> REFUNDED, all done with a PARTIAL bridge -> PARTIAL,
+// all SUCCESS -> SUCCESS, otherwise the current status is kept
+func SwapStatus(current int, steps []*backend.Step) int {
+	allDone := true
+	anyPartial := false
+	anyRefunded := false
+	for _, step := range steps {
+		switch step.Status {
+		case backend.TxStatusFailed:
+			return backend.TxStatusFailed
+		case backend.TxStatusRefunded:
+			anyRefunded = true
+		case backend.TxStatusPartial:
+			anyPartial = true
+		case backend.TxStatusSuccess:
+		default:
+			allDone = false
+		}
+	}
+
+	switch {
+	case anyRefunded:
+		return backend.TxStatusRefunded
+	case allDone && anyPartial:
+		return backend.TxStatusPartial
+	case allDone:
+		return backend.TxStatusSuccess
+	}
+	return current
+}
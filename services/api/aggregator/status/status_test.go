@@ -0,0 +1,88 @@
+package status
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// TestCanTransition tests the tx status state machine
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to int
+		allowed  bool
+	}{
+		{backend.TxStatusCreated, backend.TxStatusPending, true},
+		{backend.TxStatusCreated, backend.TxStatusFailed, true},
+		{backend.TxStatusCreated, backend.TxStatusSuccess, false},
+		{backend.TxStatusPending, backend.TxStatusSuccess, true},
+		{backend.TxStatusPending, backend.TxStatusRefunded, true},
+		{backend.TxStatusPending, backend.TxStatusCreated, false},
+		{backend.TxStatusSuccess, backend.TxStatusFailed, false},
+		{backend.TxStatusFailed, backend.TxStatusPending, false},
+		{backend.TxStatusPending, backend.TxStatusConfirming, true},
+		{backend.TxStatusConfirming, backend.TxStatusSuccess, true},
+		{backend.TxStatusConfirming, backend.TxStatusPending, true},
+		{backend.TxStatusConfirming, backend.TxStatusRefunded, false},
+		{backend.TxStatusSuccess, backend.TxStatusConfirming, false},
+	}
+
+	for _, tt := range tests {
+		name := backend.TxStatusNames[tt.from] + "->" + backend.TxStatusNames[tt.to]
+		assert.Equal(t, tt.allowed, CanTransition(tt.from, tt.to), name)
+	}
+}
+
+// TestSwapStatus tests deriving the swap status from its steps
+func TestSwapStatus(t *testing.T) {
+	steps := func(statuses ...int) []*backend.Step {
+		var out []*backend.Step
+		for i, st := range statuses {
+			out = append(out, &backend.Step{StepIndex: i, Status: st})
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		steps    []*backend.Step
+		expected int
+	}{
+		{
+			name:     "Bridge still in flight",
+			steps:    steps(backend.TxStatusSuccess, backend.TxStatusPending),
+			expected: backend.TxStatusPending,
+		},
+		{
+			name:     "All success",
+			steps:    steps(backend.TxStatusSuccess, backend.TxStatusSuccess),
+			expected: backend.TxStatusSuccess,
+		},
+		{
+			name:     "Partial bridge",
+			steps:    steps(backend.TxStatusSuccess, backend.TxStatusPartial),
+			expected: backend.TxStatusPartial,
+		},
+		{
+			name:     "Refunded bridge",
+			steps:    steps(backend.TxStatusSuccess, backend.TxStatusRefunded),
+			expected: backend.TxStatusRefunded,
+		},
+		{
+			name:     "Failed step wins",
+			steps:    steps(backend.TxStatusFailed, backend.TxStatusRefunded),
+			expected: backend.TxStatusFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := SwapStatus(backend.TxStatusPending, tt.steps)
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", backend.TxStatusNames[tt.expected], backend.TxStatusNames[result])
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// CachedSwapStore puts a Redis write-through cache in front of a durable SwapStore.
+// Writes go to the primary store first; the cache is only an optimisation and a
+// cache miss (expired key, restart, Redis down) falls back to the primary store.
+type CachedSwapStore struct {
+	primary SwapStore
+	cache   *RedisSwapStore
+}
+
+// NewCachedSwapStore creates a swap store backed by primary and cached in Redis
+func NewCachedSwapStore(primary SwapStore, cache *RedisSwapStore) *CachedSwapStore {
+	return &CachedSwapStore{
+		primary: primary,
+		cache:   cache,
+	}
+}
+
+// CreateSwap creates a new swap
+func (s *CachedSwapStore) CreateSwap(ctx context.Context, swap *backend.Swap) error {
+	if err := s.primary.CreateSwap(ctx, swap); err != nil {
+		return err
+	}
+	s.cacheSwap(ctx, swap)
+	return nil
+}
+
+// GetSwap retrieves a swap by ID, from the cache when possible
+func (s *CachedSwapStore) GetSwap(ctx context.Context, swapID string) (*backend.Swap, error) {
+	if swap, err := s.cache.GetSwap(ctx, swapID); err == nil {
+		return swap, nil
+	}
+
+	swap, err := s.primary.GetSwap(ctx, swapID)
+	if err != nil {
+		return nil, err
+	}
+	s.cacheSwap(ctx, swap)
+	return swap, nil
+}
+
+// UpdateSwap updates an existing swap
+func (s *CachedSwapStore) UpdateSwap(ctx context.Context, swap *backend.Swap) error {
+	if err := s.primary.UpdateSwap(ctx, swap); err != nil {
+		return err
+	}
+	s.cacheSwap(ctx, swap)
+	return nil
+}
+
+// TransitionSwapStatus updates the swap status in the primary store and refreshes the cache
+func (s *CachedSwapStore) TransitionSwapStatus(ctx context.Context, swapID string, from int, to int, failReasonCode string, failMessage string) (bool, error) {
+	ok, err := s.primary.TransitionSwapStatus(ctx, swapID, from, to, failReasonCode, failMessage)
+	if err != nil {
+		return false, err
+	}
+	s.reload(ctx, swapID)
+	return ok, nil
+}
+
+// AddStep adds a new step to a swap
+func (s *CachedSwapStore) AddStep(ctx context.Context, swapID string, step *backend.Step) error {
+	if err := s.primary.AddStep(ctx, swapID, step); err != nil {
+		return err
+	}
+	s.reload(ctx, swapID)
+	return nil
+}
+
+// UpdateStep updates an existing step
+func (s *CachedSwapStore) UpdateStep(ctx context.Context, swapID string, stepIndex int, step *backend.Step) error {
+	if err := s.primary.UpdateStep(ctx, swapID, stepIndex, step); err != nil {
+		return err
+	}
+	s.reload(ctx, swapID)
+	return nil
+}
+
+// GetStep retrieves a specific step
+func (s *CachedSwapStore) GetStep(ctx context.Context, swapID string, stepIndex int) (*backend.Step, error) {
+	swap, err := s.GetSwap(ctx, swapID)
+	if err != nil {
+		return nil, err
+	}
+
+	if stepIndex < 0 || stepIndex >= len(swap.Steps) {
+		return nil, fmt.Errorf("invalid step index: %d", stepIndex)
+	}
+
+	return swap.Steps[stepIndex], nil
+}
+
+// CheckIdempotency checks if a request is duplicate and returns existing txHash if found
+func (s *CachedSwapStore) CheckIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string) (string, bool) {
+	if txHash, exists := s.cache.CheckIdempotency(ctx, swapID, stepIndex, idempotencyKey); exists {
+		return txHash, true
+	}
+
+	txHash, exists := s.primary.CheckIdempotency(ctx, swapID, stepIndex, idempotencyKey)
+	if exists {
+		_ = s.cache.RecordIdempotency(ctx, swapID, stepIndex, idempotencyKey, txHash)
+	}
+	return txHash, exists
+}
+
+// RecordIdempotency records an idempotency key with its txHash
+func (s *CachedSwapStore) RecordIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, txHash string) error {
+	if err := s.primary.RecordIdempotency(ctx, swapID, stepIndex, idempotencyKey, txHash); err != nil {
+		return err
+	}
+	if err := s.cache.RecordIdempotency(ctx, swapID, stepIndex, idempotencyKey, txHash); err != nil {
+		log.Warn("Failed to cache idempotency key", "swapID", swapID, "stepIndex", stepIndex, "err", err)
+	}
+	return nil
+}
+
+// ReserveIdempotency takes the Redis lease first so concurrent replicas are turned away cheaply,
+// then reserves in the primary store, which stays authoritative when the cache is unavailable
+func (s *CachedSwapStore) ReserveIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, owner string, lease time.Duration) (*IdempotencyReservation, error) {
+	cached, err := s.cache.ReserveIdempotency(ctx, swapID, stepIndex, idempotencyKey, owner, lease)
+	if err != nil {
+		log.Warn("Failed to reserve idempotency key in cache", "swapID", swapID, "stepIndex", stepIndex, "err", err)
+	} else if !cached.Reserved {
+		return cached, nil
+	}
+
+	reservation, err := s.primary.ReserveIdempotency(ctx, swapID, stepIndex, idempotencyKey, owner, lease)
+	if err != nil || !reservation.Reserved {
+		_ = s.cache.ReleaseIdempotency(ctx, swapID, stepIndex, idempotencyKey, owner)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !reservation.Reserved && !reservation.InFlight {
+		_ = s.cache.RecordIdempotency(ctx, swapID, stepIndex, idempotencyKey, reservation.TxHash)
+	}
+
+	return reservation, nil
+}
+
+// ReleaseIdempotency drops owner's reservation in both stores
+func (s *CachedSwapStore) ReleaseIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, owner string) error {
+	if err := s.primary.ReleaseIdempotency(ctx, swapID, stepIndex, idempotencyKey, owner); err != nil {
+		return err
+	}
+	if err := s.cache.ReleaseIdempotency(ctx, swapID, stepIndex, idempotencyKey, owner); err != nil {
+		log.Warn("Failed to release idempotency key in cache", "swapID", swapID, "stepIndex", stepIndex, "err", err)
+	}
+	return nil
+}
+
+// cacheSwap writes the swap to Redis; on failure the stale entry is dropped so reads fall back to the primary store
+func (s *CachedSwapStore) cacheSwap(ctx context.Context, swap *backend.Swap) {
+	if err := s.cache.putSwap(ctx, swap); err != nil {
+		log.Warn("Failed to cache swap", "swapID", swap.SwapID, "err", err)
+		_ = s.cache.evictSwap(ctx, swap.SwapID)
+	}
+}
+
+// reload refreshes the cached swap from the primary store after a step write
+func (s *CachedSwapStore) reload(ctx context.Context, swapID string) {
+	swap, err := s.primary.GetSwap(ctx, swapID)
+	if err != nil {
+		log.Warn("Failed to reload swap for cache", "swapID", swapID, "err", err)
+		_ = s.cache.evictSwap(ctx, swapID)
+		return
+	}
+	s.cacheSwap(ctx, swap)
+}
+
+// CachedQuoteStore puts a Redis write-through cache in front of a durable QuoteStore
+type CachedQuoteStore struct {
+	primary QuoteStore
+	cache   *RedisQuoteStore
+}
+
+// NewCachedQuoteStore creates a quote store backed by primary and cached in Redis
+func NewCachedQuoteStore(primary QuoteStore, cache *RedisQuoteStore) *CachedQuoteStore {
+	return &CachedQuoteStore{
+		primary: primary,
+		cache:   cache,
+	}
+}
+
+// Save stores a quote with TTL
+func (s *CachedQuoteStore) Save(ctx context.Context, quoteID string, quote *backend.QuoteStore, ttl time.Duration) error {
+	if err := s.primary.Save(ctx, quoteID, quote, ttl); err != nil {
+		return err
+	}
+	s.cacheQuote(ctx, quoteID, quote, ttl)
+	return nil
+}
+
+// Update overwrites an existing quote and refreshes its TTL
+func (s *CachedQuoteStore) Update(ctx context.Context, quoteID string, quote *backend.QuoteStore, ttl time.Duration) error {
+	if err := s.primary.Update(ctx, quoteID, quote, ttl); err != nil {
+		return err
+	}
+	s.cacheQuote(ctx, quoteID, quote, ttl)
+	return nil
+}
+
+// Get retrieves a quote by ID, from the cache when possible.
+// Quotes loaded from the primary store are not re-cached, their remaining TTL is unknown.
+func (s *CachedQuoteStore) Get(ctx context.Context, quoteID string) (*backend.QuoteStore, error) {
+	if quote, err := s.cache.Get(ctx, quoteID); err == nil {
+		return quote, nil
+	}
+	return s.primary.Get(ctx, quoteID)
+}
+
+// Delete removes a quote
+func (s *CachedQuoteStore) Delete(ctx context.Context, quoteID string) error {
+	if err := s.primary.Delete(ctx, quoteID); err != nil {
+		return err
+	}
+	return s.cache.Delete(ctx, quoteID)
+}
+
+func (s *CachedQuoteStore) cacheQuote(ctx context.Context, quoteID string, quote *backend.QuoteStore, ttl time.Duration) {
+	if err := s.cache.Save(ctx, quoteID, quote, ttl); err != nil {
+		log.Warn("Failed to cache quote", "quoteID", quoteID, "err", err)
+		_ = s.cache.Delete(ctx, quoteID)
+	}
+}
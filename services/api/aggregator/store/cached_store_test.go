@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// countingSwapStore counts reads that reach the primary store and can fail them
+type countingSwapStore struct {
+	SwapStore
+	gets    int
+	failGet bool
+}
+
+func (s *countingSwapStore) GetSwap(ctx context.Context, swapID string) (*backend.Swap, error) {
+	s.gets++
+	if s.failGet {
+		return nil, errors.New("primary unavailable")
+	}
+	return s.SwapStore.GetSwap(ctx, swapID)
+}
+
+func newCachedSwapStore(t *testing.T) (*CachedSwapStore, *countingSwapStore, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	primary := &countingSwapStore{SwapStore: NewInMemorySwapStore()}
+	return NewCachedSwapStore(primary, NewRedisSwapStore(client)), primary, mr
+}
+
+func testSwap(swapID string) *backend.Swap {
+	return &backend.Swap{
+		SwapID: swapID,
+		Status: backend.TxStatusPending,
+		Steps: []*backend.Step{
+			{StepIndex: 0, ActionType: backend.ActionTypeSwap, Status: backend.TxStatusPending, TxHash: "0xswap"},
+		},
+	}
+}
+
+func TestCachedSwapStoreHit(t *testing.T) {
+	ctx := context.Background()
+	s, primary, mr := newCachedSwapStore(t)
+
+	require.NoError(t, s.CreateSwap(ctx, testSwap("swap-1")))
+	assert.True(t, mr.Exists("aggregator:swap:swap-1"))
+
+	swap, err := s.GetSwap(ctx, "swap-1")
+	require.NoError(t, err)
+	assert.Equal(t, "0xswap", swap.Steps[0].TxHash)
+	step, err := s.GetStep(ctx, "swap-1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "0xswap", step.TxHash)
+	assert.Zero(t, primary.gets, "served from cache")
+}
+
+func TestCachedSwapStoreMissFallsBackToPrimary(t *testing.T) {
+	ctx := context.Background()
+	s, primary, mr := newCachedSwapStore(t)
+
+	// 只在主存储中存在，例如 Redis 过期或重启后
+	require.NoError(t, primary.CreateSwap(ctx, testSwap("swap-2")))
+	assert.False(t, mr.Exists("aggregator:swap:swap-2"))
+
+	swap, err := s.GetSwap(ctx, "swap-2")
+	require.NoError(t, err)
+	assert.Equal(t, "swap-2", swap.SwapID)
+	assert.Equal(t, 1, primary.gets)
+	assert.True(t, mr.Exists("aggregator:swap:swap-2"), "primary result is cached")
+
+	_, err = s.GetSwap(ctx, "swap-2")
+	require.NoError(t, err)
+	assert.Equal(t, 1, primary.gets)
+
+	// 缓存过期后再次回源
+	mr.FastForward(25 * time.Hour)
+	_, err = s.GetSwap(ctx, "swap-2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, primary.gets)
+
+	_, err = s.GetSwap(ctx, "missing")
+	assert.EqualError(t, err, "swap not found: missing")
+}
+
+func TestCachedSwapStoreInvalidation(t *testing.T) {
+	ctx := context.Background()
+	s, primary, mr := newCachedSwapStore(t)
+	require.NoError(t, s.CreateSwap(ctx, testSwap("swap-3")))
+
+	// 步骤写入后缓存从主存储刷新
+	require.NoError(t, s.UpdateStep(ctx, "swap-3", 0, &backend.Step{ActionType: backend.ActionTypeSwap, Status: backend.TxStatusSuccess, TxHash: "0xspeedup"}))
+	ok, err := s.TransitionSwapStatus(ctx, "swap-3", backend.TxStatusPending, backend.TxStatusSuccess, "", "")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	gets := primary.gets
+	swap, err := s.GetSwap(ctx, "swap-3")
+	require.NoError(t, err)
+	assert.Equal(t, gets, primary.gets, "served from the refreshed cache")
+	assert.Equal(t, "0xspeedup", swap.Steps[0].TxHash)
+	assert.Equal(t, backend.TxStatusSuccess, swap.Status)
+
+	// 刷新失败时丢弃缓存，之后的读取回源而不是读到旧数据
+	primary.failGet = true
+	require.NoError(t, s.UpdateStep(ctx, "swap-3", 0, &backend.Step{ActionType: backend.ActionTypeSwap, Status: backend.TxStatusSuccess, TxHash: "0xfinal"}))
+	assert.False(t, mr.Exists("aggregator:swap:swap-3"))
+
+	primary.failGet = false
+	swap, err = s.GetSwap(ctx, "swap-3")
+	require.NoError(t, err)
+	assert.Equal(t, "0xfinal", swap.Steps[0].TxHash)
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// DBQuoteStore implements QuoteStore on PostgreSQL.
+// The TTL bounds how long a quote can be read, except for quotes a swap was prepared from,
+// which stay readable for the swap's status queries.
+type DBQuoteStore struct {
+	db dbBackend.SwapQuoteDB
+}
+
+// NewDBQuoteStore creates a new database-backed quote store
+func NewDBQuoteStore(db dbBackend.SwapQuoteDB) *DBQuoteStore {
+	return &DBQuoteStore{
+		db: db,
+	}
+}
+
+// Save stores a quote with TTL
+func (s *DBQuoteStore) Save(ctx context.Context, quoteID string, quote *backend.QuoteStore, ttl time.Duration) error {
+	row, err := toQuoteRow(quoteID, quote, ttl)
+	if err != nil {
+		return err
+	}
+	if err := s.db.UpsertSwapQuote(row); err != nil {
+		return fmt.Errorf("failed to save quote to database: %w", err)
+	}
+
+	return nil
+}
+
+// Update overwrites an existing quote and refreshes its TTL
+func (s *DBQuoteStore) Update(ctx context.Context, quoteID string, quote *backend.QuoteStore, ttl time.Duration) error {
+	row, err := toQuoteRow(quoteID, quote, ttl)
+	if err != nil {
+		return err
+	}
+	found, err := s.db.UpdateSwapQuote(row)
+	if err != nil {
+		return fmt.Errorf("failed to update quote in database: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("quote not found: %s", quoteID)
+	}
+
+	return nil
+}
+
+// Get retrieves a quote by ID
+func (s *DBQuoteStore) Get(ctx context.Context, quoteID string) (*backend.QuoteStore, error) {
+	row, err := s.db.GetByQuoteID(quoteID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("quote not found: %s", quoteID)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get quote from database: %w", err)
+	}
+
+	var quote backend.QuoteStore
+	if err := json.Unmarshal([]byte(row.Detail), &quote); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal quote: %w", err)
+	}
+
+	return &quote, nil
+}
+
+// Delete removes a quote
+func (s *DBQuoteStore) Delete(ctx context.Context, quoteID string) error {
+	if err := s.db.DeleteSwapQuote(quoteID); err != nil {
+		return fmt.Errorf("failed to delete quote from database: %w", err)
+	}
+
+	return nil
+}
+
+func toQuoteRow(quoteID string, quote *backend.QuoteStore, ttl time.Duration) (*dbBackend.SwapQuote, error) {
+	detail, err := json.Marshal(quote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal quote: %w", err)
+	}
+	row := &dbBackend.SwapQuote{
+		QuoteID:     quoteID,
+		UserAddress: quote.UserAddress,
+		WalletUUID:  quote.WalletUUID,
+		RetainUntil: time.Now().Add(ttl),
+		Detail:      string(detail),
+	}
+	if !quote.ExpiresAt.IsZero() {
+		expiresAt := quote.ExpiresAt
+		row.ExpiresAt = &expiresAt
+	}
+	return row, nil
+}
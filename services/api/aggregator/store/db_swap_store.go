@@ -0,0 +1,280 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// DBSwapStore implements SwapStore on PostgreSQL, so swaps outlive Redis TTLs and restarts
+type DBSwapStore struct {
+	db dbBackend.SwapDB
+}
+
+// NewDBSwapStore creates a new database-backed swap store
+func NewDBSwapStore(db dbBackend.SwapDB) *DBSwapStore {
+	return &DBSwapStore{
+		db: db,
+	}
+}
+
+// CreateSwap creates a new swap with its steps
+func (s *DBSwapStore) CreateSwap(ctx context.Context, swap *backend.Swap) error {
+	if _, err := s.db.GetBySwapID(swap.SwapID); err == nil {
+		return fmt.Errorf("swap already exists: %s", swap.SwapID)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to check swap existence: %w", err)
+	}
+
+	swap.CreatedAt = time.Now()
+	swap.UpdatedAt = time.Now()
+
+	steps, err := toStepRows(swap.SwapID, swap.Steps)
+	if err != nil {
+		return err
+	}
+	if err := s.db.StoreSwap(toSwapRow(swap), steps); err != nil {
+		return fmt.Errorf("failed to save swap to database: %w", err)
+	}
+
+	return nil
+}
+
+// GetSwap retrieves a swap and its steps
+func (s *DBSwapStore) GetSwap(ctx context.Context, swapID string) (*backend.Swap, error) {
+	row, err := s.db.GetBySwapID(swapID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("swap not found: %s", swapID)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get swap from database: %w", err)
+	}
+
+	stepRows, err := s.db.GetSteps(swapID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get swap steps from database: %w", err)
+	}
+
+	swap := &backend.Swap{
+		SwapID:         row.SwapID,
+		QuoteID:        row.QuoteID,
+		UserAddress:    row.UserAddress,
+		WalletUUID:     row.WalletUUID,
+		Status:         row.Status,
+		Steps:          make([]*backend.Step, 0, len(stepRows)),
+		CreatedAt:      row.CreateTime,
+		UpdatedAt:      row.UpdateTime,
+		FailReasonCode: row.FailReasonCode,
+		FailMessage:    row.FailMessage,
+	}
+	for _, stepRow := range stepRows {
+		step, err := fromStepRow(stepRow)
+		if err != nil {
+			return nil, err
+		}
+		swap.Steps = append(swap.Steps, step)
+	}
+
+	return swap, nil
+}
+
+// UpdateSwap overwrites an existing swap and all of its steps
+func (s *DBSwapStore) UpdateSwap(ctx context.Context, swap *backend.Swap) error {
+	swap.UpdatedAt = time.Now()
+
+	steps, err := toStepRows(swap.SwapID, swap.Steps)
+	if err != nil {
+		return err
+	}
+	err = s.db.SaveSwap(toSwapRow(swap), steps)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("swap not found: %s", swap.SwapID)
+	} else if err != nil {
+		return fmt.Errorf("failed to update swap in database: %w", err)
+	}
+
+	return nil
+}
+
+// TransitionSwapStatus updates only the swap row, with a compare-and-set on its status
+func (s *DBSwapStore) TransitionSwapStatus(ctx context.Context, swapID string, from int, to int, failReasonCode string, failMessage string) (bool, error) {
+	ok, err := s.db.TransitionSwapStatus(swapID, from, to, failReasonCode, failMessage)
+	if err != nil {
+		return false, fmt.Errorf("failed to update swap status in database: %w", err)
+	}
+	if !ok {
+		if _, err := s.db.GetBySwapID(swapID); errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, fmt.Errorf("swap not found: %s", swapID)
+		}
+	}
+
+	return ok, nil
+}
+
+// AddStep appends a new step to a swap
+func (s *DBSwapStore) AddStep(ctx context.Context, swapID string, step *backend.Step) error {
+	if _, err := s.GetSwap(ctx, swapID); err != nil {
+		return err
+	}
+
+	count, err := s.db.CountSteps(swapID)
+	if err != nil {
+		return fmt.Errorf("failed to count swap steps: %w", err)
+	}
+
+	row, err := toStepRow(swapID, int(count), step)
+	if err != nil {
+		return err
+	}
+	if err := s.db.UpsertStep(row); err != nil {
+		return fmt.Errorf("failed to add swap step: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateStep updates an existing step
+func (s *DBSwapStore) UpdateStep(ctx context.Context, swapID string, stepIndex int, step *backend.Step) error {
+	if _, err := s.GetStep(ctx, swapID, stepIndex); err != nil {
+		return err
+	}
+
+	row, err := toStepRow(swapID, stepIndex, step)
+	if err != nil {
+		return err
+	}
+	if err := s.db.UpsertStep(row); err != nil {
+		return fmt.Errorf("failed to update swap step: %w", err)
+	}
+
+	return nil
+}
+
+// GetStep retrieves a specific step
+func (s *DBSwapStore) GetStep(ctx context.Context, swapID string, stepIndex int) (*backend.Step, error) {
+	row, err := s.db.GetStep(swapID, stepIndex)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if _, swapErr := s.db.GetBySwapID(swapID); errors.Is(swapErr, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("swap not found: %s", swapID)
+		}
+		return nil, fmt.Errorf("invalid step index: %d", stepIndex)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get swap step from database: %w", err)
+	}
+
+	return fromStepRow(row)
+}
+
+// CheckIdempotency checks if a request is duplicate and returns existing txHash if found
+func (s *DBSwapStore) CheckIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string) (string, bool) {
+	r, err := s.db.GetIdempotency(swapID, stepIndex, idempotencyKey)
+	if err != nil || !r.Completed {
+		return "", false
+	}
+
+	return r.TxHash, true
+}
+
+// RecordIdempotency records an idempotency key with its txHash
+func (s *DBSwapStore) RecordIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, txHash string) error {
+	err := s.db.UpsertIdempotency(&dbBackend.SwapIdempotency{
+		SwapID:         swapID,
+		StepIndex:      stepIndex,
+		IdempotencyKey: idempotencyKey,
+		TxHash:         txHash,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record idempotency: %w", err)
+	}
+
+	return nil
+}
+
+// ReserveIdempotency claims an idempotency key through the table's unique index
+func (s *DBSwapStore) ReserveIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, owner string, lease time.Duration) (*IdempotencyReservation, error) {
+	leaseUntil := time.Now().Add(lease)
+	current, reserved, err := s.db.ReserveIdempotency(&dbBackend.SwapIdempotency{
+		SwapID:         swapID,
+		StepIndex:      stepIndex,
+		IdempotencyKey: idempotencyKey,
+		Owner:          owner,
+		LeaseUntil:     &leaseUntil,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	if reserved {
+		return &IdempotencyReservation{Reserved: true}, nil
+	}
+	if current.Completed {
+		return &IdempotencyReservation{TxHash: current.TxHash}, nil
+	}
+	return &IdempotencyReservation{InFlight: true}, nil
+}
+
+// ReleaseIdempotency drops owner's reservation so the request can be retried
+func (s *DBSwapStore) ReleaseIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, owner string) error {
+	if err := s.db.ReleaseIdempotency(swapID, stepIndex, idempotencyKey, owner); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func toSwapRow(swap *backend.Swap) *dbBackend.Swap {
+	return &dbBackend.Swap{
+		SwapID:         swap.SwapID,
+		QuoteID:        swap.QuoteID,
+		UserAddress:    swap.UserAddress,
+		WalletUUID:     swap.WalletUUID,
+		Status:         swap.Status,
+		FailReasonCode: swap.FailReasonCode,
+		FailMessage:    swap.FailMessage,
+		CreateTime:     swap.CreatedAt,
+		UpdateTime:     swap.UpdatedAt,
+	}
+}
+
+// toStepRows keys steps by their position in the swap, as the in-memory store does
+func toStepRows(swapID string, steps []*backend.Step) ([]*dbBackend.SwapStep, error) {
+	rows := make([]*dbBackend.SwapStep, 0, len(steps))
+	for i, step := range steps {
+		row, err := toStepRow(swapID, i, step)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func toStepRow(swapID string, stepIndex int, step *backend.Step) (*dbBackend.SwapStep, error) {
+	detail, err := json.Marshal(step)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal step %d: %w", stepIndex, err)
+	}
+	return &dbBackend.SwapStep{
+		SwapID:         swapID,
+		StepIndex:      stepIndex,
+		ActionType:     string(step.ActionType),
+		TxHash:         step.TxHash,
+		Status:         step.Status,
+		IdempotencyKey: step.IdempotencyKey,
+		Detail:         string(detail),
+	}, nil
+}
+
+func fromStepRow(row *dbBackend.SwapStep) (*backend.Step, error) {
+	var step backend.Step
+	if err := json.Unmarshal([]byte(row.Detail), &step); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal step %d: %w", row.StepIndex, err)
+	}
+	return &step, nil
+}
@@ -0,0 +1,196 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// memSwapDB keeps swap and swap_step rows in memory, keyed as the tables are
+type memSwapDB struct {
+	dbBackend.SwapDB
+	swaps map[string]*dbBackend.Swap
+	steps map[string]*dbBackend.SwapStep
+}
+
+func newMemSwapDB() *memSwapDB {
+	return &memSwapDB{
+		swaps: map[string]*dbBackend.Swap{},
+		steps: map[string]*dbBackend.SwapStep{},
+	}
+}
+
+func stepKey(swapID string, stepIndex int) string {
+	return fmt.Sprintf("%s:%d", swapID, stepIndex)
+}
+
+func (m *memSwapDB) StoreSwap(s *dbBackend.Swap, steps []*dbBackend.SwapStep) error {
+	row := *s
+	m.swaps[s.SwapID] = &row
+	for _, step := range steps {
+		if err := m.UpsertStep(step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memSwapDB) SaveSwap(s *dbBackend.Swap, steps []*dbBackend.SwapStep) error {
+	if _, ok := m.swaps[s.SwapID]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	return m.StoreSwap(s, steps)
+}
+
+func (m *memSwapDB) TransitionSwapStatus(swapID string, fromStatus int, toStatus int, failReasonCode string, failMessage string) (bool, error) {
+	row, ok := m.swaps[swapID]
+	if !ok || row.Status != fromStatus {
+		return false, nil
+	}
+	row.Status = toStatus
+	row.FailReasonCode = failReasonCode
+	row.FailMessage = failMessage
+	return true, nil
+}
+
+func (m *memSwapDB) UpsertStep(step *dbBackend.SwapStep) error {
+	row := *step
+	m.steps[stepKey(step.SwapID, step.StepIndex)] = &row
+	return nil
+}
+
+func (m *memSwapDB) GetBySwapID(swapID string) (*dbBackend.Swap, error) {
+	row, ok := m.swaps[swapID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *row
+	return &cp, nil
+}
+
+func (m *memSwapDB) GetSteps(swapID string) ([]*dbBackend.SwapStep, error) {
+	var list []*dbBackend.SwapStep
+	for _, row := range m.steps {
+		if row.SwapID == swapID {
+			cp := *row
+			list = append(list, &cp)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].StepIndex < list[j].StepIndex })
+	return list, nil
+}
+
+func (m *memSwapDB) GetStep(swapID string, stepIndex int) (*dbBackend.SwapStep, error) {
+	row, ok := m.steps[stepKey(swapID, stepIndex)]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *row
+	return &cp, nil
+}
+
+func (m *memSwapDB) CountSteps(swapID string) (int64, error) {
+	list, _ := m.GetSteps(swapID)
+	return int64(len(list)), nil
+}
+
+func TestDBSwapStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	db := newMemSwapDB()
+	s := NewDBSwapStore(db)
+
+	confirmedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	swap := &backend.Swap{
+		SwapID:      "swap-1",
+		QuoteID:     "quote-1",
+		UserAddress: "0xuser",
+		WalletUUID:  "wallet-1",
+		Status:      backend.TxStatusPending,
+		Steps: []*backend.Step{
+			{StepIndex: 0, ActionType: backend.ActionTypeApprove, TxHash: "0xapprove", Status: backend.TxStatusSuccess, ConfirmedAt: &confirmedAt},
+			{
+				StepIndex:        1,
+				ActionType:       backend.ActionTypeBridge,
+				TxHash:           "0xbridge",
+				Status:           backend.TxStatusPending,
+				IdempotencyKey:   "idem-1",
+				ExpectedChainID:  "1",
+				ExpectedTo:       "0xrouter",
+				ExpectedDataHash: "0xhash",
+				DestChainID:      "42161",
+				BridgeStatus:     "WAIT_DESTINATION_TRANSACTION",
+			},
+		},
+	}
+	require.NoError(t, s.CreateSwap(ctx, swap))
+	assert.Error(t, s.CreateSwap(ctx, &backend.Swap{SwapID: "swap-1"}), "duplicate swap")
+
+	// 查询列与 detail 一起写入
+	row := db.steps[stepKey("swap-1", 1)]
+	require.NotNil(t, row)
+	assert.Equal(t, string(backend.ActionTypeBridge), row.ActionType)
+	assert.Equal(t, "0xbridge", row.TxHash)
+	assert.Equal(t, "idem-1", row.IdempotencyKey)
+
+	loaded, err := s.GetSwap(ctx, "swap-1")
+	require.NoError(t, err)
+	assert.Equal(t, swap.QuoteID, loaded.QuoteID)
+	assert.Equal(t, swap.UserAddress, loaded.UserAddress)
+	assert.Equal(t, swap.WalletUUID, loaded.WalletUUID)
+	assert.Equal(t, swap.Status, loaded.Status)
+	require.Len(t, loaded.Steps, 2)
+	assert.True(t, confirmedAt.Equal(*loaded.Steps[0].ConfirmedAt))
+	loaded.Steps[0].ConfirmedAt = swap.Steps[0].ConfirmedAt
+	assert.Equal(t, swap.Steps, loaded.Steps)
+
+	// 单步更新只写该步骤
+	updated := *loaded.Steps[1]
+	updated.Status = backend.TxStatusSuccess
+	updated.DestTxHash = "0xdest"
+	require.NoError(t, s.UpdateStep(ctx, "swap-1", 1, &updated))
+	step, err := s.GetStep(ctx, "swap-1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, backend.TxStatusSuccess, step.Status)
+	assert.Equal(t, "0xdest", step.DestTxHash)
+	assert.Equal(t, backend.TxStatusSuccess, db.steps[stepKey("swap-1", 1)].Status)
+
+	ok, err := s.TransitionSwapStatus(ctx, "swap-1", backend.TxStatusPending, backend.TxStatusSuccess, "", "")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.TransitionSwapStatus(ctx, "swap-1", backend.TxStatusPending, backend.TxStatusFailed, "CHAIN_FAILED", "")
+	require.NoError(t, err)
+	assert.False(t, ok, "status already moved")
+
+	require.NoError(t, s.AddStep(ctx, "swap-1", &backend.Step{ActionType: backend.ActionTypeSwap, Status: backend.TxStatusCreated}))
+	loaded, err = s.GetSwap(ctx, "swap-1")
+	require.NoError(t, err)
+	assert.Equal(t, backend.TxStatusSuccess, loaded.Status)
+	require.Len(t, loaded.Steps, 3)
+	assert.Equal(t, backend.ActionTypeSwap, loaded.Steps[2].ActionType)
+}
+
+func TestDBSwapStoreNotFound(t *testing.T) {
+	ctx := context.Background()
+	s := NewDBSwapStore(newMemSwapDB())
+
+	_, err := s.GetSwap(ctx, "missing")
+	assert.EqualError(t, err, "swap not found: missing")
+	_, err = s.GetStep(ctx, "missing", 0)
+	assert.EqualError(t, err, "swap not found: missing")
+	assert.EqualError(t, s.UpdateSwap(ctx, &backend.Swap{SwapID: "missing"}), "swap not found: missing")
+	_, err = s.TransitionSwapStatus(ctx, "missing", backend.TxStatusPending, backend.TxStatusSuccess, "", "")
+	assert.EqualError(t, err, "swap not found: missing")
+
+	require.NoError(t, s.CreateSwap(ctx, &backend.Swap{SwapID: "swap-2"}))
+	_, err = s.GetStep(ctx, "swap-2", 3)
+	assert.EqualError(t, err, "invalid step index: 3")
+}
@@ -0,0 +1,158 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type idempotencyCase struct {
+	name  string
+	store SwapStore
+	// expire lets leases of the given duration run out
+	expire func(lease time.Duration)
+}
+
+func idempotencyCases(t *testing.T) []idempotencyCase {
+	sleep := func(lease time.Duration) { time.Sleep(lease + 20*time.Millisecond) }
+
+	newRedis := func() (*miniredis.Miniredis, *RedisSwapStore) {
+		mr := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+		return mr, NewRedisSwapStore(client)
+	}
+
+	redisMR, redisStore := newRedis()
+	cachedMR, cache := newRedis()
+
+	return []idempotencyCase{
+		{name: "memory", store: NewInMemorySwapStore(), expire: sleep},
+		{name: "redis", store: redisStore, expire: redisMR.FastForward},
+		{
+			name:  "cached",
+			store: NewCachedSwapStore(NewInMemorySwapStore(), cache),
+			expire: func(lease time.Duration) {
+				cachedMR.FastForward(lease)
+				sleep(lease)
+			},
+		},
+	}
+}
+
+func TestReserveIdempotencyConcurrent(t *testing.T) {
+	for _, tc := range idempotencyCases(t) {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			const callers = 20
+
+			var (
+				wg       sync.WaitGroup
+				mu       sync.Mutex
+				winners  []string
+				inFlight int
+			)
+			for i := 0; i < callers; i++ {
+				wg.Add(1)
+				go func(owner string) {
+					defer wg.Done()
+					res, err := tc.store.ReserveIdempotency(ctx, "swap-1", 0, "key-1", owner, time.Minute)
+					if !assert.NoError(t, err) {
+						return
+					}
+
+					mu.Lock()
+					defer mu.Unlock()
+					if res.Reserved {
+						winners = append(winners, owner)
+					} else if res.InFlight {
+						inFlight++
+					}
+				}(fmt.Sprintf("owner-%d", i))
+			}
+			wg.Wait()
+
+			require.Len(t, winners, 1, "exactly one caller may broadcast")
+			assert.Equal(t, callers-1, inFlight)
+
+			_, exists := tc.store.CheckIdempotency(ctx, "swap-1", 0, "key-1")
+			assert.False(t, exists, "a reservation is not a result")
+
+			require.NoError(t, tc.store.RecordIdempotency(ctx, "swap-1", 0, "key-1", "0xabc"))
+
+			res, err := tc.store.ReserveIdempotency(ctx, "swap-1", 0, "key-1", "late", time.Minute)
+			require.NoError(t, err)
+			assert.False(t, res.Reserved)
+			assert.False(t, res.InFlight)
+			assert.Equal(t, "0xabc", res.TxHash)
+
+			txHash, exists := tc.store.CheckIdempotency(ctx, "swap-1", 0, "key-1")
+			assert.True(t, exists)
+			assert.Equal(t, "0xabc", txHash)
+		})
+	}
+}
+
+func TestReleaseIdempotency(t *testing.T) {
+	for _, tc := range idempotencyCases(t) {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			res, err := tc.store.ReserveIdempotency(ctx, "swap-2", 1, "key-2", "a", time.Minute)
+			require.NoError(t, err)
+			require.True(t, res.Reserved)
+
+			// only the owner can release
+			require.NoError(t, tc.store.ReleaseIdempotency(ctx, "swap-2", 1, "key-2", "b"))
+			res, err = tc.store.ReserveIdempotency(ctx, "swap-2", 1, "key-2", "b", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, res.InFlight)
+
+			// the owner may re-enter its own reservation
+			res, err = tc.store.ReserveIdempotency(ctx, "swap-2", 1, "key-2", "a", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, res.Reserved)
+
+			require.NoError(t, tc.store.ReleaseIdempotency(ctx, "swap-2", 1, "key-2", "a"))
+			res, err = tc.store.ReserveIdempotency(ctx, "swap-2", 1, "key-2", "b", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, res.Reserved)
+		})
+	}
+}
+
+func TestReserveIdempotencyRecoversAbandonedLease(t *testing.T) {
+	for _, tc := range idempotencyCases(t) {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			lease := 50 * time.Millisecond
+
+			res, err := tc.store.ReserveIdempotency(ctx, "swap-3", 0, "key-3", "crashed", lease)
+			require.NoError(t, err)
+			require.True(t, res.Reserved)
+
+			res, err = tc.store.ReserveIdempotency(ctx, "swap-3", 0, "key-3", "retry", lease)
+			require.NoError(t, err)
+			assert.True(t, res.InFlight)
+
+			tc.expire(lease)
+
+			res, err = tc.store.ReserveIdempotency(ctx, "swap-3", 0, "key-3", "retry", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, res.Reserved)
+
+			// the crashed owner coming back must not release the new reservation
+			require.NoError(t, tc.store.ReleaseIdempotency(ctx, "swap-3", 0, "key-3", "crashed"))
+			res, err = tc.store.ReserveIdempotency(ctx, "swap-3", 0, "key-3", "third", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, res.InFlight)
+		})
+	}
+}
@@ -103,6 +103,26 @@ func (s *RedisSwapStore) UpdateSwap(ctx context.Context, swap *backend.Swap) err
 	return nil
 }
 
+// TransitionSwapStatus updates the swap status if it is still `from`
+func (s *RedisSwapStore) TransitionSwapStatus(ctx context.Context, swapID string, from int, to int, failReasonCode string, failMessage string) (bool, error) {
+	swap, err := s.GetSwap(ctx, swapID)
+	if err != nil {
+		return false, err
+	}
+	if swap.Status != from {
+		return false, nil
+	}
+
+	swap.Status = to
+	swap.FailReasonCode = failReasonCode
+	swap.FailMessage = failMessage
+	if err := s.UpdateSwap(ctx, swap); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 // AddStep adds a new step to a swap
 func (s *RedisSwapStore) AddStep(ctx context.Context, swapID string, step *backend.Step) error {
 	swap, err := s.GetSwap(ctx, swapID)
@@ -157,7 +177,7 @@ func (s *RedisSwapStore) CheckIdempotency(ctx context.Context, swapID string, st
 	return txHash, true
 }
 
-// RecordIdempotency records an idempotency key with its txHash
+// RecordIdempotency records an idempotency key with its txHash and ends any reservation
 func (s *RedisSwapStore) RecordIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, txHash string) error {
 	key := s.idempotencyKey(swapID, stepIndex, idempotencyKey)
 
@@ -166,9 +186,85 @@ func (s *RedisSwapStore) RecordIdempotency(ctx context.Context, swapID string, s
 		return fmt.Errorf("failed to record idempotency: %w", err)
 	}
 
+	// 结果写入后再释放租约，抢到租约的请求会重新读到结果
+	if err := s.client.Del(ctx, s.idempotencyLockKey(swapID, stepIndex, idempotencyKey)).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency lease: %w", err)
+	}
+
+	return nil
+}
+
+// ReserveIdempotency claims an idempotency key with SETNX; the lease expires on its own if the owner dies
+func (s *RedisSwapStore) ReserveIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, owner string, lease time.Duration) (*IdempotencyReservation, error) {
+	if txHash, exists := s.CheckIdempotency(ctx, swapID, stepIndex, idempotencyKey); exists {
+		return &IdempotencyReservation{TxHash: txHash}, nil
+	}
+
+	lockKey := s.idempotencyLockKey(swapID, stepIndex, idempotencyKey)
+	ok, err := s.client.SetNX(ctx, lockKey, owner, lease).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	if !ok {
+		holder, err := s.client.Get(ctx, lockKey).Result()
+		if err == nil && holder == owner {
+			if err := s.client.PExpire(ctx, lockKey, lease).Err(); err != nil {
+				return nil, fmt.Errorf("failed to extend idempotency lease: %w", err)
+			}
+			return &IdempotencyReservation{Reserved: true}, nil
+		}
+		// 持有者可能刚好提交了结果
+		if txHash, exists := s.CheckIdempotency(ctx, swapID, stepIndex, idempotencyKey); exists {
+			return &IdempotencyReservation{TxHash: txHash}, nil
+		}
+		return &IdempotencyReservation{InFlight: true}, nil
+	}
+
+	// 首次检查与 SETNX 之间可能有请求完成并释放了租约，避免重复广播
+	if txHash, exists := s.CheckIdempotency(ctx, swapID, stepIndex, idempotencyKey); exists {
+		_ = s.ReleaseIdempotency(ctx, swapID, stepIndex, idempotencyKey, owner)
+		return &IdempotencyReservation{TxHash: txHash}, nil
+	}
+
+	return &IdempotencyReservation{Reserved: true}, nil
+}
+
+// releaseLeaseScript deletes the lease only if it is still held by the caller
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// ReleaseIdempotency drops owner's reservation so the request can be retried
+func (s *RedisSwapStore) ReleaseIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, owner string) error {
+	lockKey := s.idempotencyLockKey(swapID, stepIndex, idempotencyKey)
+	if err := releaseLeaseScript.Run(ctx, s.client, []string{lockKey}, owner).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency lease: %w", err)
+	}
+
+	return nil
+}
+
+// putSwap writes a swap regardless of whether it is cached, used when Redis fronts another store
+func (s *RedisSwapStore) putSwap(ctx context.Context, swap *backend.Swap) error {
+	data, err := json.Marshal(swap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal swap: %w", err)
+	}
+	if err := s.client.Set(ctx, s.swapKey(swap.SwapID), data, 24*time.Hour).Err(); err != nil {
+		return fmt.Errorf("failed to save swap to Redis: %w", err)
+	}
 	return nil
 }
 
+// evictSwap removes a cached swap
+func (s *RedisSwapStore) evictSwap(ctx context.Context, swapID string) error {
+	return s.client.Del(ctx, s.swapKey(swapID)).Err()
+}
+
 // swapKey generates the Redis key for a swap
 func (s *RedisSwapStore) swapKey(swapID string) string {
 	return fmt.Sprintf("aggregator:swap:%s", swapID)
@@ -178,3 +274,8 @@ func (s *RedisSwapStore) swapKey(swapID string) string {
 func (s *RedisSwapStore) idempotencyKey(swapID string, stepIndex int, idempotencyKey string) string {
 	return fmt.Sprintf("aggregator:idempotency:%s:%d:%s", swapID, stepIndex, idempotencyKey)
 }
+
+// idempotencyLockKey generates the Redis key for the reservation lease of an idempotency key
+func (s *RedisSwapStore) idempotencyLockKey(swapID string, stepIndex int, idempotencyKey string) string {
+	return fmt.Sprintf("aggregator:idempotency-lease:%s:%d:%s", swapID, stepIndex, idempotencyKey)
+}
@@ -14,26 +14,50 @@ type SwapStore interface {
 	CreateSwap(ctx context.Context, swap *backend.Swap) error
 	GetSwap(ctx context.Context, swapID string) (*backend.Swap, error)
 	UpdateSwap(ctx context.Context, swap *backend.Swap) error
+	// TransitionSwapStatus moves only the swap status (and fail reason) from `from` to `to`, leaving the steps alone.
+	// It returns false without error when the swap is no longer in `from`.
+	TransitionSwapStatus(ctx context.Context, swapID string, from int, to int, failReasonCode string, failMessage string) (bool, error)
 	AddStep(ctx context.Context, swapID string, step *backend.Step) error
 	UpdateStep(ctx context.Context, swapID string, stepIndex int, step *backend.Step) error
 	GetStep(ctx context.Context, swapID string, stepIndex int) (*backend.Step, error)
 	CheckIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string) (string, bool)
 	RecordIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, txHash string) error
+
+	// ReserveIdempotency atomically claims an idempotency key for owner for the lease duration.
+	// The winner broadcasts, then commits the result with RecordIdempotency or gives the key
+	// back with ReleaseIdempotency; a lease that runs out is treated as abandoned and can be claimed again.
+	ReserveIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, owner string, lease time.Duration) (*IdempotencyReservation, error)
+	ReleaseIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, owner string) error
+}
+
+// IdempotencyReservation is the outcome of ReserveIdempotency
+type IdempotencyReservation struct {
+	Reserved bool   // caller holds the lease
+	TxHash   string // result of a completed request with the same key
+	InFlight bool   // another caller holds an unexpired lease
+}
+
+// idempotencyEntry is a reserved or completed idempotency key
+type idempotencyEntry struct {
+	txHash     string
+	owner      string
+	leaseUntil time.Time
+	done       bool
 }
 
 // InMemorySwapStore implements SwapStore using in-memory storage
 type InMemorySwapStore struct {
 	mu    sync.RWMutex
 	swaps map[string]*backend.Swap
-	// idempotencyMap: swapID+stepIndex+idempotencyKey -> txHash
-	idempotencyMap map[string]string
+	// idempotencyMap: swapID+stepIndex+idempotencyKey -> reservation or txHash
+	idempotencyMap map[string]*idempotencyEntry
 }
 
 // NewInMemorySwapStore creates a new in-memory swap store
 func NewInMemorySwapStore() *InMemorySwapStore {
 	return &InMemorySwapStore{
 		swaps:          make(map[string]*backend.Swap),
-		idempotencyMap: make(map[string]string),
+		idempotencyMap: make(map[string]*idempotencyEntry),
 	}
 }
 
@@ -81,6 +105,27 @@ func (s *InMemorySwapStore) UpdateSwap(ctx context.Context, swap *backend.Swap)
 	return nil
 }
 
+// TransitionSwapStatus updates the swap status if it is still `from`
+func (s *InMemorySwapStore) TransitionSwapStatus(ctx context.Context, swapID string, from int, to int, failReasonCode string, failMessage string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	swap, exists := s.swaps[swapID]
+	if !exists {
+		return false, fmt.Errorf("swap not found: %s", swapID)
+	}
+	if swap.Status != from {
+		return false, nil
+	}
+
+	swap.Status = to
+	swap.FailReasonCode = failReasonCode
+	swap.FailMessage = failMessage
+	swap.UpdatedAt = time.Now()
+
+	return true, nil
+}
+
 // AddStep adds a new step to a swap
 func (s *InMemorySwapStore) AddStep(ctx context.Context, swapID string, step *backend.Step) error {
 	s.mu.Lock()
@@ -139,19 +184,64 @@ func (s *InMemorySwapStore) CheckIdempotency(ctx context.Context, swapID string,
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	key := fmt.Sprintf("%s:%d:%s", swapID, stepIndex, idempotencyKey)
-	txHash, exists := s.idempotencyMap[key]
+	entry, exists := s.idempotencyMap[idempotencyMapKey(swapID, stepIndex, idempotencyKey)]
+	if !exists || !entry.done {
+		return "", false
+	}
 
-	return txHash, exists
+	return entry.txHash, true
 }
 
-// RecordIdempotency records an idempotency key with its txHash
+// RecordIdempotency records an idempotency key with its txHash, completing any reservation
 func (s *InMemorySwapStore) RecordIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, txHash string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	key := fmt.Sprintf("%s:%d:%s", swapID, stepIndex, idempotencyKey)
-	s.idempotencyMap[key] = txHash
+	s.idempotencyMap[idempotencyMapKey(swapID, stepIndex, idempotencyKey)] = &idempotencyEntry{
+		txHash: txHash,
+		done:   true,
+	}
+
+	return nil
+}
+
+// ReserveIdempotency claims an idempotency key unless it is completed or leased to another owner
+func (s *InMemorySwapStore) ReserveIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, owner string, lease time.Duration) (*IdempotencyReservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := idempotencyMapKey(swapID, stepIndex, idempotencyKey)
+	now := time.Now()
+	if entry, exists := s.idempotencyMap[key]; exists {
+		if entry.done {
+			return &IdempotencyReservation{TxHash: entry.txHash}, nil
+		}
+		if entry.owner != owner && now.Before(entry.leaseUntil) {
+			return &IdempotencyReservation{InFlight: true}, nil
+		}
+	}
+
+	s.idempotencyMap[key] = &idempotencyEntry{
+		owner:      owner,
+		leaseUntil: now.Add(lease),
+	}
+
+	return &IdempotencyReservation{Reserved: true}, nil
+}
+
+// ReleaseIdempotency drops owner's reservation so the request can be retried
+func (s *InMemorySwapStore) ReleaseIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := idempotencyMapKey(swapID, stepIndex, idempotencyKey)
+	if entry, exists := s.idempotencyMap[key]; exists && !entry.done && entry.owner == owner {
+		delete(s.idempotencyMap, key)
+	}
 
 	return nil
 }
+
+func idempotencyMapKey(swapID string, stepIndex int, idempotencyKey string) string {
+	return fmt.Sprintf("%s:%d:%s", swapID, stepIndex, idempotencyKey)
+}
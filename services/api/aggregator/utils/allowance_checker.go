@@ -2,44 +2,75 @@ package utils
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"math/big"
+	"strings"
 
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/common"
 )
 
-// AllowanceChecker checks ERC20 token allowances
+// ERC20 approve(address spender, uint256 amount) selector
+const approveSelector = "095ea7b3"
+
+// AllowanceChecker checks ERC20 token allowances via the wallet account service
 type AllowanceChecker struct {
-	client *ethclient.Client
+	evmCaller *EVMCaller
 }
 
 // NewAllowanceChecker creates a new allowance checker
-func NewAllowanceChecker(rpcURL string) (*AllowanceChecker, error) {
-	client, err := ethclient.Dial(rpcURL)
+func NewAllowanceChecker(evmCaller *EVMCaller) *AllowanceChecker {
+	return &AllowanceChecker{
+		evmCaller: evmCaller,
+	}
+}
+
+// CheckAllowance returns the current allowance of spender over owner's tokens
+// and whether it already covers requiredAmount
+func (c *AllowanceChecker) CheckAllowance(
+	ctx context.Context,
+	chainID string,
+	tokenAddress string,
+	ownerAddress string,
+	spenderAddress string,
+	requiredAmount *big.Int,
+) (*big.Int, bool, error) {
+	if c.evmCaller == nil {
+		return nil, false, fmt.Errorf("evm caller not configured")
+	}
+	if requiredAmount == nil || requiredAmount.Sign() < 0 {
+		return nil, false, fmt.Errorf("invalid required amount")
+	}
+
+	allowance, err := c.evmCaller.GetERC20Allowance(ctx, chainID, tokenAddress, ownerAddress, spenderAddress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Ethereum node: %w", err)
+		return nil, false, fmt.Errorf("failed to query allowance: %w", err)
 	}
 
-	return &AllowanceChecker{
-		client: client,
-	}, nil
+	return allowance, allowance.Cmp(requiredAmount) >= 0, nil
 }
 
-// CheckAllowance checks if the user has sufficient allowance for the spender
-func (c *AllowanceChecker) CheckAllowance(ctx context.Context, tokenAddress, ownerAddress, spenderAddress string, requiredAmount *big.Int) (*big.Int, error) {
-	// TODO: Implement actual ERC20 allowance check
-	// This would involve:
-	// 1. Creating an ERC20 contract instance
-	// 2. Calling the allowance(owner, spender) function
-	// 3. Comparing with requiredAmount
+// DecodeApproveData decodes ERC20 approve calldata into spender and amount
+func DecodeApproveData(data string) (string, *big.Int, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(data), "0x"))
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid approve data: %w", err)
+	}
+	if len(raw) != 4+32+32 {
+		return "", nil, fmt.Errorf("invalid approve data length: %d", len(raw))
+	}
+	if hex.EncodeToString(raw[:4]) != approveSelector {
+		return "", nil, fmt.Errorf("not an approve call: 0x%x", raw[:4])
+	}
 
-	// Placeholder: return zero allowance
-	return big.NewInt(0), nil
+	spender := common.BytesToAddress(raw[4:36]).Hex()
+	amount := new(big.Int).SetBytes(raw[36:68])
+	return spender, amount, nil
 }
 
-// Close closes the Ethereum client connection
-func (c *AllowanceChecker) Close() {
-	if c.client != nil {
-		c.client.Close()
-	}
+// EncodeApproveData encodes ERC20 approve(spender, amount) calldata
+func EncodeApproveData(spender string, amount *big.Int) string {
+	return "0x" + approveSelector +
+		hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(spender).Bytes(), 32)) +
+		hex.EncodeToString(common.LeftPadBytes(amount.Bytes(), 32))
 }
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeApproveData(t *testing.T) {
+	data := "0x095ea7b3" +
+		"0000000000000000000000001231deb6f5749ef6ce6943a275a1d3e7486f4eae" +
+		"00000000000000000000000000000000000000000000000000000000000f4240"
+
+	spender, amount, err := DecodeApproveData(data)
+	require.NoError(t, err)
+	assert.Equal(t, "0x1231DEB6f5749EF6cE6943a275A1D3E7486F4EaE", spender)
+	assert.Equal(t, big.NewInt(1000000), amount)
+
+	_, _, err = DecodeApproveData("0x")
+	assert.Error(t, err)
+
+	// transfer(address,uint256) is not an approve call
+	_, _, err = DecodeApproveData("0xa9059cbb" + data[10:])
+	assert.Error(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
@@ -18,6 +19,7 @@ import (
 type EVMCaller struct {
 	accountClient *account.WalletAccountClient
 	chainInfo     chaininfo.Provider
+	ethClients    sync.Map // rpc_url -> *ethclient.Client，用于 eth_call 模拟
 }
 
 // NewEVMCaller creates a new EVM caller
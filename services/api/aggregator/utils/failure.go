@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ExplainFailedTx finds why a mined tx failed: out of gas when it used up its gas limit,
+// otherwise the revert reason from replaying it with eth_call. It returns nil if the tx succeeded.
+func (c *EVMCaller) ExplainFailedTx(ctx context.Context, chainID, txHash string) (*RevertReason, error) {
+	client, err := c.ethClient(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := common.HexToHash(txHash)
+	receipt, err := client.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("get receipt: %w", err)
+	}
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return nil, nil
+	}
+
+	tx, _, err := client.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("get tx: %w", err)
+	}
+	if tx.Gas() > 0 && receipt.GasUsed >= tx.Gas() {
+		return &RevertReason{Kind: RevertKindEmpty, Message: "out of gas", Code: RevertOutOfGas}, nil
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("recover sender: %w", err)
+	}
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+
+	// 以所在区块开始时的状态（父区块）重放；同区块内排在前面的交易造成的状态变化无法复现，
+	// 重放成功时只能给出通用原因
+	parent := new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+	_, err = client.CallContract(ctx, msg, parent)
+	if err == nil {
+		return &RevertReason{Kind: RevertKindEmpty, Message: "execution reverted", Code: RevertUnknown}, nil
+	}
+	if reason := revertFromError(err); reason != nil {
+		return reason, nil
+	}
+	return nil, fmt.Errorf("replay failed tx: %w", err)
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainFailedTx(t *testing.T) {
+	key, err := crypto.ToECDSA(common.FromHex("0x4c0883a69102937d6231471b5dbb6204fe512961708279f3e3f4f0a4e5a7d4c1"))
+	require.NoError(t, err)
+	router := common.HexToAddress("0x1111111254eeb25477b68fb85ed929f73a960582")
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(10),
+		Gas:       200000,
+		To:        &router,
+		Data:      []byte{0x12, 0x34},
+	})
+	require.NoError(t, err)
+
+	uintType, _ := abi.NewType("uint256", "", nil)
+	revertData := encodeRevert(t, "ReturnAmountIsNotEnough(uint256,uint256)", abi.Arguments{{Type: uintType}, {Type: uintType}}, big.NewInt(1), big.NewInt(2))
+
+	gasUsed := uint64(50000)
+	var callBlock string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "eth_getTransactionReceipt":
+			receipt := &types.Receipt{
+				Type:        types.DynamicFeeTxType,
+				Status:      types.ReceiptStatusFailed,
+				GasUsed:     gasUsed,
+				Logs:        []*types.Log{},
+				TxHash:      tx.Hash(),
+				BlockNumber: big.NewInt(100),
+			}
+			resp["result"] = receipt
+		case "eth_getTransactionByHash":
+			raw, _ := tx.MarshalJSON()
+			var fields map[string]interface{}
+			require.NoError(t, json.Unmarshal(raw, &fields))
+			fields["blockNumber"] = "0x64"
+			fields["blockHash"] = common.Hash{1}.Hex()
+			resp["result"] = fields
+		case "eth_call":
+			require.NoError(t, json.Unmarshal(req.Params[1], &callBlock))
+			resp["error"] = map[string]interface{}{
+				"code":    3,
+				"message": "execution reverted",
+				"data":    "0x" + hex.EncodeToString(revertData),
+			}
+		default:
+			t.Fatalf("unexpected method %s", req.Method)
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	caller := NewEVMCaller(nil, staticChainInfo{"1": {ChainID: "1", RPCURL: srv.URL}})
+	ctx := context.Background()
+
+	reason, err := caller.ExplainFailedTx(ctx, "1", tx.Hash().Hex())
+	require.NoError(t, err)
+	require.NotNil(t, reason)
+	assert.Equal(t, RevertSlippage, reason.Code)
+	assert.Equal(t, "ReturnAmountIsNotEnough(uint256,uint256)", reason.Message)
+	// replayed on the state the block started from
+	assert.Equal(t, "0x63", callBlock)
+
+	gasUsed = 200000
+	reason, err = caller.ExplainFailedTx(ctx, "1", tx.Hash().Hex())
+	require.NoError(t, err)
+	require.NotNil(t, reason)
+	assert.Equal(t, RevertOutOfGas, reason.Code)
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Permit2Address Uniswap Permit2 合约，各 EVM 链地址相同
+const Permit2Address = "0x000000000022D473030F116dDEE9F6B43aC78BA3"
+
+// Permit2 allowance(address owner, address token, address spender) selector
+var permit2AllowanceSelector = crypto.Keccak256([]byte("allowance(address,address,address)"))[:4]
+
+// GetPermit2Nonce returns the current Permit2 AllowanceTransfer nonce of owner for (token, spender)
+func (c *EVMCaller) GetPermit2Nonce(ctx context.Context, chainID, owner, token, spender string) (uint64, error) {
+	callData := "0x" + hex.EncodeToString(permit2AllowanceSelector) +
+		hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(owner).Bytes(), 32)) +
+		hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(token).Bytes(), 32)) +
+		hex.EncodeToString(common.LeftPadBytes(common.HexToAddress(spender).Bytes(), 32))
+
+	resultHex, err := c.CallContract(ctx, chainID, Permit2Address, callData)
+	if err != nil {
+		return 0, err
+	}
+
+	// 返回 (uint160 amount, uint48 expiration, uint48 nonce)
+	raw, err := hexutil.Decode(resultHex)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode permit2 allowance: %w", err)
+	}
+	if len(raw) < 96 {
+		return 0, fmt.Errorf("invalid permit2 allowance length: %d", len(raw))
+	}
+	return new(big.Int).SetBytes(raw[64:96]).Uint64(), nil
+}
+
+// NewPermit2SingleTypedData builds the EIP-712 PermitSingle (AllowanceTransfer) that lets spender
+// pull amount of token through Permit2 until expiration
+func NewPermit2SingleTypedData(chainID, token string, amount *big.Int, expiration, nonce uint64, spender string, sigDeadline uint64) (*apitypes.TypedData, error) {
+	chain, ok := new(big.Int).SetString(chainID, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid chain id: %s", chainID)
+	}
+
+	return &apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"PermitSingle": {
+				{Name: "details", Type: "PermitDetails"},
+				{Name: "spender", Type: "address"},
+				{Name: "sigDeadline", Type: "uint256"},
+			},
+			"PermitDetails": {
+				{Name: "token", Type: "address"},
+				{Name: "amount", Type: "uint160"},
+				{Name: "expiration", Type: "uint48"},
+				{Name: "nonce", Type: "uint48"},
+			},
+		},
+		PrimaryType: "PermitSingle",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "Permit2",
+			ChainId:           (*math.HexOrDecimal256)(chain),
+			VerifyingContract: Permit2Address,
+		},
+		Message: apitypes.TypedDataMessage{
+			"details": map[string]interface{}{
+				"token":      common.HexToAddress(token).Hex(),
+				"amount":     amount.String(),
+				"expiration": fmt.Sprintf("%d", expiration),
+				"nonce":      fmt.Sprintf("%d", nonce),
+			},
+			"spender":     common.HexToAddress(spender).Hex(),
+			"sigDeadline": fmt.Sprintf("%d", sigDeadline),
+		},
+	}, nil
+}
+
+// EncodePermit2Single ABI-encodes (PermitSingle, bytes signature), the permit argument
+// accepted by routers that call Permit2.permit(owner, permitSingle, signature)
+func EncodePermit2Single(typedData *apitypes.TypedData, signature string) (string, error) {
+	if typedData == nil || typedData.PrimaryType != "PermitSingle" {
+		return "", fmt.Errorf("not a Permit2 PermitSingle")
+	}
+	details, ok := typedData.Message["details"].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("permit details missing")
+	}
+
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature hex: %w", err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return "", fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+
+	var words [][]byte
+	for _, v := range []interface{}{
+		details["token"], details["amount"], details["expiration"], details["nonce"],
+		typedData.Message["spender"], typedData.Message["sigDeadline"],
+	} {
+		word, err := permitWord(v)
+		if err != nil {
+			return "", err
+		}
+		words = append(words, word)
+	}
+
+	// 动态 bytes：offset（7 个静态字之后）、长度、右侧补零的签名
+	words = append(words,
+		common.LeftPadBytes(big.NewInt(7*32).Bytes(), 32),
+		common.LeftPadBytes(big.NewInt(int64(len(sig))).Bytes(), 32),
+		common.RightPadBytes(sig, 96),
+	)
+
+	var sb strings.Builder
+	sb.WriteString("0x")
+	for _, w := range words {
+		sb.WriteString(hex.EncodeToString(w))
+	}
+	return sb.String(), nil
+}
+
+// permitWord encodes an address or decimal/hex integer message value as a 32-byte word
+func permitWord(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected permit value: %v", v)
+	}
+	if common.IsHexAddress(s) {
+		return common.LeftPadBytes(common.HexToAddress(s).Bytes(), 32), nil
+	}
+	n, ok := math.ParseBig256(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid permit value: %s", s)
+	}
+	return common.LeftPadBytes(n.Bytes(), 32), nil
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermit2Single(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+
+	td, err := NewPermit2SingleTypedData("1",
+		"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+		big.NewInt(1000000),
+		1700000000,
+		3,
+		"0x111111125421cA6dc452d289314280a0f8842A65",
+		1690000000,
+	)
+	require.NoError(t, err)
+
+	digest, err := TypedDataDigest(td)
+	require.NoError(t, err)
+	sig, err := crypto.Sign(digest.Bytes(), key)
+	require.NoError(t, err)
+	sig[crypto.RecoveryIDOffset] += 27
+	require.NoError(t, VerifyTypedDataSignature(td, hexutil.Encode(sig), owner.Hex()))
+
+	encoded, err := EncodePermit2Single(td, hexutil.Encode(sig))
+	require.NoError(t, err)
+	raw, err := hexutil.Decode(encoded)
+	require.NoError(t, err)
+	require.Len(t, raw, 11*32)
+
+	assert.Equal(t, "a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", hexutil.Encode(raw[12:32])[2:])
+	assert.Equal(t, int64(1000000), new(big.Int).SetBytes(raw[32:64]).Int64())
+	assert.Equal(t, int64(3), new(big.Int).SetBytes(raw[96:128]).Int64())
+	assert.True(t, strings.EqualFold("0x111111125421cA6dc452d289314280a0f8842A65", hexutil.Encode(raw[140:160])))
+	assert.Equal(t, int64(7*32), new(big.Int).SetBytes(raw[192:224]).Int64())
+	assert.Equal(t, int64(65), new(big.Int).SetBytes(raw[224:256]).Int64())
+	assert.Equal(t, sig, raw[256:321])
+
+	_, err = EncodePermit2Single(testOrder(), hexutil.Encode(sig))
+	assert.Error(t, err)
+}
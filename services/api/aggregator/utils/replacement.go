@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// minReplacementBumpBps 节点接受同 nonce 替换交易的最小加价（geth txpool 默认 10%）
+	minReplacementBumpBps = 1000
+	// replacementBumpBps 生成替换交易时的加价，比最小值多留余量
+	replacementBumpBps = 1250
+)
+
+// ErrTxNotFound is returned when the node does not know the tx
+var ErrTxNotFound = errors.New("tx not found")
+
+// GetTransaction returns a tx from the chain's rpc_url and whether it is still in the mempool
+func (c *EVMCaller) GetTransaction(ctx context.Context, chainID, txHash string) (*types.Transaction, bool, error) {
+	client, err := c.ethClient(ctx, chainID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	tx, pending, err := client.TransactionByHash(ctx, common.HexToHash(txHash))
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, false, fmt.Errorf("%w: %s", ErrTxNotFound, txHash)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return tx, pending, nil
+}
+
+// SuggestFees returns the node's suggested priority fee and the latest block's base fee
+// (zero on chains without EIP-1559)
+func (c *EVMCaller) SuggestFees(ctx context.Context, chainID string) (tip *big.Int, baseFee *big.Int, err error) {
+	client, err := c.ethClient(ctx, chainID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tip, err = client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("suggest gas tip cap: %w", err)
+	}
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get latest header: %w", err)
+	}
+	baseFee = new(big.Int)
+	if header.BaseFee != nil {
+		baseFee.Set(header.BaseFee)
+	}
+	return tip, baseFee, nil
+}
+
+// ReplacementFees returns the EIP-1559 fees for a tx replacing original: both fees bumped
+// above the txpool minimum, and raised to the current market when that is higher.
+// A legacy original's gas price counts as both its tip and its fee cap.
+func ReplacementFees(original *types.Transaction, suggestedTip, baseFee *big.Int) (tip *big.Int, feeCap *big.Int) {
+	tip = bumpBps(original.GasTipCap(), replacementBumpBps)
+	if suggestedTip != nil && suggestedTip.Cmp(tip) > 0 {
+		tip = new(big.Int).Set(suggestedTip)
+	}
+
+	feeCap = bumpBps(original.GasFeeCap(), replacementBumpBps)
+	if baseFee != nil {
+		// 与钱包一致：2 倍 base fee 可扛住连续几个满块
+		market := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
+		if market.Cmp(feeCap) > 0 {
+			feeCap = market
+		}
+	}
+	if feeCap.Cmp(tip) < 0 {
+		feeCap = new(big.Int).Set(tip)
+	}
+	return tip, feeCap
+}
+
+// ValidateReplacement checks that replacement can replace original from sender:
+// same chain and nonce, fees bumped at least the txpool minimum, and for a speed-up the same call,
+// for a cancel a zero-value self-transfer without calldata.
+func ValidateReplacement(original, replacement *types.Transaction, sender common.Address, cancel bool) error {
+	if replacement.ChainId() == nil || original.ChainId() == nil || replacement.ChainId().Cmp(original.ChainId()) != 0 {
+		return fmt.Errorf("chainId mismatch: got %v want %v", replacement.ChainId(), original.ChainId())
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(replacement.ChainId()), replacement)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	if from != sender {
+		return fmt.Errorf("sender mismatch: got %s want %s", from.Hex(), sender.Hex())
+	}
+	if replacement.Nonce() != original.Nonce() {
+		return fmt.Errorf("nonce mismatch: got %d want %d", replacement.Nonce(), original.Nonce())
+	}
+
+	minTip := bumpBps(original.GasTipCap(), minReplacementBumpBps)
+	if replacement.GasTipCap().Cmp(minTip) < 0 {
+		return fmt.Errorf("priority fee too low: got %s want at least %s", replacement.GasTipCap(), minTip)
+	}
+	minFeeCap := bumpBps(original.GasFeeCap(), minReplacementBumpBps)
+	if replacement.GasFeeCap().Cmp(minFeeCap) < 0 {
+		return fmt.Errorf("max fee too low: got %s want at least %s", replacement.GasFeeCap(), minFeeCap)
+	}
+
+	if replacement.To() == nil {
+		return fmt.Errorf("contract creation not allowed")
+	}
+	if cancel {
+		if *replacement.To() != sender {
+			return fmt.Errorf("cancel must be sent to the sender, got %s", replacement.To().Hex())
+		}
+		if replacement.Value().Sign() != 0 || len(replacement.Data()) != 0 {
+			return fmt.Errorf("cancel must be a zero-value transfer without data")
+		}
+		return nil
+	}
+
+	if original.To() == nil || !strings.EqualFold(replacement.To().Hex(), original.To().Hex()) {
+		return fmt.Errorf("to mismatch: got %s want %v", replacement.To().Hex(), original.To())
+	}
+	if replacement.Value().Cmp(original.Value()) != 0 {
+		return fmt.Errorf("value mismatch: got %s want %s", replacement.Value(), original.Value())
+	}
+	if string(replacement.Data()) != string(original.Data()) {
+		return fmt.Errorf("data mismatch")
+	}
+	return nil
+}
+
+// bumpBps returns v increased by bps basis points, rounded up
+func bumpBps(v *big.Int, bps int64) *big.Int {
+	out := new(big.Int).Mul(v, big.NewInt(10000+bps))
+	out.Add(out, big.NewInt(9999))
+	return out.Div(out, big.NewInt(10000))
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signDynamicFeeTx(t *testing.T, key []byte, tx *types.DynamicFeeTx) *types.Transaction {
+	privKey, err := crypto.ToECDSA(key)
+	require.NoError(t, err)
+	signed, err := types.SignNewTx(privKey, types.LatestSignerForChainID(tx.ChainID), tx)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestReplacementFees(t *testing.T) {
+	original := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		GasTipCap: big.NewInt(1_000_000_000),
+		GasFeeCap: big.NewInt(30_000_000_000),
+	})
+
+	// quiet market: the bump decides
+	tip, feeCap := ReplacementFees(original, big.NewInt(100_000_000), big.NewInt(5_000_000_000))
+	assert.Equal(t, "1125000000", tip.String())
+	assert.Equal(t, "33750000000", feeCap.String())
+
+	// busy market: the current fees decide
+	tip, feeCap = ReplacementFees(original, big.NewInt(3_000_000_000), big.NewInt(20_000_000_000))
+	assert.Equal(t, "3000000000", tip.String())
+	assert.Equal(t, "43000000000", feeCap.String())
+
+	// legacy gas price counts as both
+	legacy := types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(10_000_000_000)})
+	tip, feeCap = ReplacementFees(legacy, nil, big.NewInt(0))
+	assert.Equal(t, "11250000000", tip.String())
+	assert.Equal(t, "11250000000", feeCap.String())
+}
+
+func TestValidateReplacement(t *testing.T) {
+	key := common.FromHex("0x4c0883a69102937d6231471b5dbb6204fe512961708279f3e3f4f0a4e5a7d4c1")
+	privKey, err := crypto.ToECDSA(key)
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(privKey.PublicKey)
+	router := common.HexToAddress("0x1111111254eeb25477b68fb85ed929f73a960582")
+
+	base := types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     7,
+		GasTipCap: big.NewInt(1_000_000_000),
+		GasFeeCap: big.NewInt(30_000_000_000),
+		Gas:       200000,
+		To:        &router,
+		Value:     big.NewInt(5),
+		Data:      []byte{0x12, 0x34},
+	}
+	original := signDynamicFeeTx(t, key, &base)
+
+	bumped := base
+	bumped.GasTipCap = big.NewInt(1_100_000_000)
+	bumped.GasFeeCap = big.NewInt(33_000_000_000)
+	assert.NoError(t, ValidateReplacement(original, signDynamicFeeTx(t, key, &bumped), sender, false))
+
+	low := bumped
+	low.GasTipCap = big.NewInt(1_050_000_000)
+	assert.ErrorContains(t, ValidateReplacement(original, signDynamicFeeTx(t, key, &low), sender, false), "priority fee too low")
+
+	otherNonce := bumped
+	otherNonce.Nonce = 8
+	assert.ErrorContains(t, ValidateReplacement(original, signDynamicFeeTx(t, key, &otherNonce), sender, false), "nonce mismatch")
+
+	otherData := bumped
+	otherData.Data = []byte{0x56}
+	assert.ErrorContains(t, ValidateReplacement(original, signDynamicFeeTx(t, key, &otherData), sender, false), "data mismatch")
+
+	cancel := bumped
+	cancel.To = &sender
+	cancel.Value = big.NewInt(0)
+	cancel.Data = nil
+	cancel.Gas = 21000
+	assert.NoError(t, ValidateReplacement(original, signDynamicFeeTx(t, key, &cancel), sender, true))
+	// a cancel is not a valid speed-up and vice versa
+	assert.Error(t, ValidateReplacement(original, signDynamicFeeTx(t, key, &cancel), sender, false))
+	assert.Error(t, ValidateReplacement(original, signDynamicFeeTx(t, key, &bumped), sender, true))
+
+	assert.ErrorContains(t, ValidateReplacement(original, signDynamicFeeTx(t, key, &bumped), router, false), "sender mismatch")
+}
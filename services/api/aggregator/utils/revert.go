@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Revert reason codes shown to users
+const (
+	RevertInsufficientBalance   = "INSUFFICIENT_BALANCE"   // 余额不足（代币或原生币）
+	RevertInsufficientAllowance = "INSUFFICIENT_ALLOWANCE" // 授权不足
+	RevertExpired               = "EXPIRED"                // 报价/签名/订单过期
+	RevertSlippage              = "SLIPPAGE"               // 输出低于最小值
+	RevertPanic                 = "PANIC"                  // Solidity panic
+	RevertOutOfGas              = "OUT_OF_GAS"             // gas 耗尽（仅链上交易）
+	RevertUnknown               = "REVERTED"               // 其他 revert
+)
+
+// RevertKind is the ABI shape of revert data
+type RevertKind string
+
+const (
+	RevertKindError  RevertKind = "Error"  // Error(string)
+	RevertKindPanic  RevertKind = "Panic"  // Panic(uint256)
+	RevertKindCustom RevertKind = "Custom" // 自定义 error
+	RevertKindEmpty  RevertKind = "Empty"  // 无 revert 数据
+)
+
+// RevertReason is decoded revert data
+type RevertReason struct {
+	Kind     RevertKind
+	Selector string   // 4 字节 selector，0x 前缀
+	Message  string   // Error 的字符串、Panic 的说明或自定义 error 签名
+	Code     string   // Revert* 原因码
+	Panic    *big.Int // Panic 码
+}
+
+var (
+	errorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+)
+
+// panicMessages Solidity panic 码说明
+var panicMessages = map[uint64]string{
+	0x01: "assertion failed",
+	0x11: "arithmetic overflow or underflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array",
+	0x31: "pop on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to zero-initialized function",
+}
+
+// knownErrors 常见路由/代币合约的自定义 error，按 selector 匹配
+var knownErrors = map[string]struct {
+	signature string
+	code      string
+}{}
+
+func init() {
+	for signature, code := range map[string]string{
+		// 1inch Aggregation Router v6
+		"ReturnAmountIsNotEnough(uint256,uint256)": RevertSlippage,
+		"OrderExpired()":       RevertExpired,
+		"SwapWithZeroAmount()": RevertUnknown,
+		// 0x Settler
+		"TooMuchSlippage(address,uint256,uint256)": RevertSlippage,
+		// Permit2
+		"SignatureExpired(uint256)":      RevertExpired,
+		"AllowanceExpired(uint256)":      RevertInsufficientAllowance,
+		"InsufficientAllowance(uint256)": RevertInsufficientAllowance,
+		"InvalidNonce()":                 RevertExpired,
+		// LiFi Diamond
+		"CumulativeSlippageTooHigh(uint256,uint256)": RevertSlippage,
+		"InsufficientBalance(uint256,uint256)":       RevertInsufficientBalance,
+		// OpenZeppelin ERC20 v5
+		"ERC20InsufficientBalance(address,uint256,uint256)":   RevertInsufficientBalance,
+		"ERC20InsufficientAllowance(address,uint256,uint256)": RevertInsufficientAllowance,
+	} {
+		selector := "0x" + hex.EncodeToString(crypto.Keccak256([]byte(signature))[:4])
+		knownErrors[selector] = struct {
+			signature string
+			code      string
+		}{signature, code}
+	}
+}
+
+// DecodeRevertData decodes the return data of a reverted call
+func DecodeRevertData(data []byte) *RevertReason {
+	if len(data) < 4 {
+		return &RevertReason{Kind: RevertKindEmpty, Message: "execution reverted", Code: RevertUnknown}
+	}
+
+	selector := "0x" + hex.EncodeToString(data[:4])
+	switch {
+	case bytes.Equal(data[:4], errorSelector):
+		msg, err := abi.UnpackRevert(data)
+		if err != nil {
+			return &RevertReason{Kind: RevertKindError, Selector: selector, Message: "malformed Error(string)", Code: RevertUnknown}
+		}
+		return &RevertReason{Kind: RevertKindError, Selector: selector, Message: msg, Code: ClassifyRevertMessage(msg)}
+
+	case bytes.Equal(data[:4], panicSelector):
+		if len(data) < 36 {
+			return &RevertReason{Kind: RevertKindPanic, Selector: selector, Message: "malformed Panic(uint256)", Code: RevertPanic}
+		}
+		code := new(big.Int).SetBytes(data[4:36])
+		msg, ok := panicMessages[code.Uint64()]
+		if !ok || !code.IsUint64() {
+			msg = "unknown panic"
+		}
+		return &RevertReason{Kind: RevertKindPanic, Selector: selector, Message: fmt.Sprintf("panic 0x%x: %s", code, msg), Code: RevertPanic, Panic: code}
+	}
+
+	if known, ok := knownErrors[selector]; ok {
+		return &RevertReason{Kind: RevertKindCustom, Selector: selector, Message: known.signature, Code: known.code}
+	}
+	return &RevertReason{Kind: RevertKindCustom, Selector: selector, Message: "custom error " + selector, Code: RevertUnknown}
+}
+
+// DecodeRevertHex decodes hex revert data as returned in JSON-RPC error data
+func DecodeRevertHex(dataHex string) *RevertReason {
+	data, err := hex.DecodeString(strings.TrimPrefix(dataHex, "0x"))
+	if err != nil {
+		return &RevertReason{Kind: RevertKindEmpty, Message: "execution reverted", Code: RevertUnknown}
+	}
+	return DecodeRevertData(data)
+}
+
+// ClassifyRevertMessage maps a revert string or node error to a Revert* code by keyword
+func ClassifyRevertMessage(msg string) string {
+	m := strings.ToLower(msg)
+	switch {
+	case strings.Contains(m, "insufficient funds"),
+		strings.Contains(m, "exceeds balance"),
+		strings.Contains(m, "insufficient balance"):
+		return RevertInsufficientBalance
+	case strings.Contains(m, "allowance"):
+		return RevertInsufficientAllowance
+	case strings.Contains(m, "expired"),
+		strings.Contains(m, "deadline"):
+		return RevertExpired
+	case strings.Contains(m, "slippage"),
+		strings.Contains(m, "too little received"),
+		strings.Contains(m, "insufficient output"),
+		strings.Contains(m, "return amount"),
+		strings.Contains(m, "min return"):
+		return RevertSlippage
+	}
+	return RevertUnknown
+}
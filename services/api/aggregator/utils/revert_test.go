@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roothash-pay/wallet-services/services/common/chaininfo"
+)
+
+func encodeRevert(t *testing.T, signature string, args abi.Arguments, values ...interface{}) []byte {
+	packed, err := args.Pack(values...)
+	require.NoError(t, err)
+	return append(crypto.Keccak256([]byte(signature))[:4], packed...)
+}
+
+func TestDecodeRevertData(t *testing.T) {
+	stringType, _ := abi.NewType("string", "", nil)
+	uintType, _ := abi.NewType("uint256", "", nil)
+
+	reason := DecodeRevertData(encodeRevert(t, "Error(string)", abi.Arguments{{Type: stringType}}, "ERC20: transfer amount exceeds balance"))
+	assert.Equal(t, RevertKindError, reason.Kind)
+	assert.Equal(t, "ERC20: transfer amount exceeds balance", reason.Message)
+	assert.Equal(t, RevertInsufficientBalance, reason.Code)
+
+	reason = DecodeRevertData(encodeRevert(t, "Panic(uint256)", abi.Arguments{{Type: uintType}}, big.NewInt(0x11)))
+	assert.Equal(t, RevertKindPanic, reason.Kind)
+	assert.Equal(t, RevertPanic, reason.Code)
+	assert.Equal(t, "panic 0x11: arithmetic overflow or underflow", reason.Message)
+
+	reason = DecodeRevertData(encodeRevert(t, "ReturnAmountIsNotEnough(uint256,uint256)",
+		abi.Arguments{{Type: uintType}, {Type: uintType}}, big.NewInt(1), big.NewInt(2)))
+	assert.Equal(t, RevertKindCustom, reason.Kind)
+	assert.Equal(t, RevertSlippage, reason.Code)
+	assert.Equal(t, "ReturnAmountIsNotEnough(uint256,uint256)", reason.Message)
+
+	reason = DecodeRevertHex("0xdeadbeef")
+	assert.Equal(t, RevertUnknown, reason.Code)
+	assert.Equal(t, "custom error 0xdeadbeef", reason.Message)
+
+	assert.Equal(t, RevertKindEmpty, DecodeRevertData(nil).Kind)
+	assert.Equal(t, RevertExpired, ClassifyRevertMessage("UniswapV2Router: EXPIRED"))
+	assert.Equal(t, RevertSlippage, ClassifyRevertMessage("Too little received"))
+}
+
+type staticChainInfo map[string]*chaininfo.Info
+
+func (s staticChainInfo) WarmUp(context.Context) error { return nil }
+
+func (s staticChainInfo) Get(_ context.Context, chainID string) (*chaininfo.Info, error) {
+	return s[chainID], nil
+}
+
+func (s staticChainInfo) Refresh(ctx context.Context, chainID string) (*chaininfo.Info, error) {
+	return s.Get(ctx, chainID)
+}
+
+func TestSimulateCall(t *testing.T) {
+	stringType, _ := abi.NewType("string", "", nil)
+	revertData := encodeRevert(t, "Error(string)", abi.Arguments{{Type: stringType}}, "Transaction too old: deadline expired")
+
+	var revert bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "eth_call", req.Method)
+
+		var call map[string]string
+		require.NoError(t, json.Unmarshal(req.Params[0], &call))
+		assert.Equal(t, "0x00000000000000000000000000000000000000a1", call["from"])
+		assert.Equal(t, "0x64", call["value"])
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if revert {
+			resp["error"] = map[string]interface{}{
+				"code":    3,
+				"message": "execution reverted: Transaction too old: deadline expired",
+				"data":    "0x" + hex.EncodeToString(revertData),
+			}
+		} else {
+			resp["result"] = "0x"
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	caller := NewEVMCaller(nil, staticChainInfo{"1": {ChainID: "1", RPCURL: srv.URL}, "56": {ChainID: "56"}})
+	ctx := context.Background()
+	from := "0x00000000000000000000000000000000000000A1"
+	to := "0x00000000000000000000000000000000000000B2"
+
+	reason, err := caller.SimulateCall(ctx, "1", from, to, "0x12345678", big.NewInt(100))
+	require.NoError(t, err)
+	assert.Nil(t, reason)
+
+	revert = true
+	reason, err = caller.SimulateCall(ctx, "1", from, to, "0x12345678", big.NewInt(100))
+	require.NoError(t, err)
+	require.NotNil(t, reason)
+	assert.Equal(t, RevertExpired, reason.Code)
+	assert.Equal(t, "Transaction too old: deadline expired", reason.Message)
+
+	_, err = caller.SimulateCall(ctx, "56", from, to, "0x12345678", big.NewInt(0))
+	assert.ErrorIs(t, err, ErrSimulationUnavailable)
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrSimulationUnavailable is returned when the chain has no RPC endpoint to run eth_call from the user
+var ErrSimulationUnavailable = errors.New("simulation unavailable")
+
+// SimulateCall dry-runs a tx with eth_call from the sender, with its calldata and value.
+// wallet-chain-account CallContract cannot set from/value, so the chain's rpc_url is used.
+// It returns (nil, nil) on success, the decoded revert reason if the call would revert,
+// or an error if the simulation itself could not run.
+func (c *EVMCaller) SimulateCall(ctx context.Context, chainID, from, to, data string, value *big.Int) (*RevertReason, error) {
+	client, err := c.ethClient(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	callData, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calldata: %w", err)
+	}
+	toAddr := common.HexToAddress(to)
+	msg := ethereum.CallMsg{
+		From:  common.HexToAddress(from),
+		To:    &toAddr,
+		Data:  callData,
+		Value: value,
+	}
+
+	_, err = client.CallContract(ctx, msg, nil)
+	if err == nil {
+		return nil, nil
+	}
+	if reason := revertFromError(err); reason != nil {
+		return reason, nil
+	}
+	return nil, fmt.Errorf("eth_call failed: %w", err)
+}
+
+// revertFromError extracts the revert reason from an eth_call error, nil if the error is not a revert
+func revertFromError(err error) *RevertReason {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if dataHex, ok := dataErr.ErrorData().(string); ok && dataHex != "" {
+			return DecodeRevertHex(dataHex)
+		}
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "execution reverted"):
+		return &RevertReason{Kind: RevertKindEmpty, Message: msg, Code: ClassifyRevertMessage(msg)}
+	case strings.Contains(msg, "insufficient funds"):
+		// 原生币不足以支付 value（节点在执行前拒绝）
+		return &RevertReason{Kind: RevertKindEmpty, Message: msg, Code: RevertInsufficientBalance}
+	}
+	return nil
+}
+
+// ethClient returns a cached JSON-RPC client for the chain's rpc_url
+func (c *EVMCaller) ethClient(ctx context.Context, chainID string) (*ethclient.Client, error) {
+	info, err := c.getChainInfo(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if info.RPCURL == "" {
+		return nil, fmt.Errorf("%w: chain %s has no rpc_url", ErrSimulationUnavailable, chainID)
+	}
+
+	if cached, ok := c.ethClients.Load(info.RPCURL); ok {
+		return cached.(*ethclient.Client), nil
+	}
+	client, err := ethclient.DialContext(ctx, info.RPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial chain %s rpc: %w", chainID, err)
+	}
+	actual, loaded := c.ethClients.LoadOrStore(info.RPCURL, client)
+	if loaded {
+		client.Close()
+	}
+	return actual.(*ethclient.Client), nil
+}
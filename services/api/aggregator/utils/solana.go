@@ -0,0 +1,290 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	solanaSignatureLen = 64
+	solanaPubkeyLen    = 32
+	// solanaVersionPrefix 版本化交易的 message 首字节最高位为 1，低 7 位为版本号
+	solanaVersionPrefix = 0x80
+)
+
+// SolanaLegacyVersion is the Version of a legacy (unversioned) Solana tx
+const SolanaLegacyVersion = -1
+
+var errSolanaTxTruncated = errors.New("solana tx truncated")
+
+// SolanaTx is the part of a serialized Solana transaction the aggregator checks:
+// who pays the fee, which programs are invoked and which blockhash bounds its lifetime
+type SolanaTx struct {
+	Signatures      [][]byte
+	Version         int // SolanaLegacyVersion 或 0
+	NumSigners      int // header.numRequiredSignatures
+	AccountKeys     []string
+	RecentBlockhash string
+	ProgramIDs      []string // 按指令顺序去重
+	Message         []byte   // 签名覆盖的 message 原文
+}
+
+// FeePayer returns the fee payer, the first static account key
+func (tx *SolanaTx) FeePayer() string {
+	if len(tx.AccountKeys) == 0 {
+		return ""
+	}
+	return tx.AccountKeys[0]
+}
+
+// Signature returns the fee payer signature in base58, which is also the tx id
+func (tx *SolanaTx) Signature() string {
+	if len(tx.Signatures) == 0 {
+		return ""
+	}
+	return EncodeBase58(tx.Signatures[0])
+}
+
+// VerifySignatures checks that every required signer signed the message
+func (tx *SolanaTx) VerifySignatures() error {
+	if len(tx.Signatures) != tx.NumSigners {
+		return fmt.Errorf("signature count mismatch: got %d want %d", len(tx.Signatures), tx.NumSigners)
+	}
+	for i, sig := range tx.Signatures {
+		pubkey, err := DecodeBase58(tx.AccountKeys[i])
+		if err != nil {
+			return err
+		}
+		if !ed25519.Verify(pubkey, tx.Message, sig) {
+			return fmt.Errorf("invalid signature of %s", tx.AccountKeys[i])
+		}
+	}
+	return nil
+}
+
+// DecodeSolanaTx decodes a serialized Solana tx. Providers and wallets use base64;
+// base58 is accepted as well.
+func DecodeSolanaTx(encoded string) (*SolanaTx, error) {
+	encoded = strings.TrimSpace(encoded)
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err == nil {
+		tx, parseErr := ParseSolanaTx(raw)
+		if parseErr == nil {
+			return tx, nil
+		}
+		err = parseErr
+	}
+	// base58 字符集与 base64 有重叠，base64 解析失败再按 base58 尝试
+	if raw, b58Err := DecodeBase58(encoded); b58Err == nil {
+		if tx, parseErr := ParseSolanaTx(raw); parseErr == nil {
+			return tx, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid solana tx: %w", err)
+}
+
+// ParseSolanaTx parses the wire format of a legacy or v0 Solana transaction
+func ParseSolanaTx(raw []byte) (*SolanaTx, error) {
+	r := &solanaReader{buf: raw}
+
+	sigCount, err := r.shortVec()
+	if err != nil {
+		return nil, err
+	}
+	tx := &SolanaTx{Version: SolanaLegacyVersion}
+	for i := 0; i < sigCount; i++ {
+		sig, err := r.bytes(solanaSignatureLen)
+		if err != nil {
+			return nil, err
+		}
+		tx.Signatures = append(tx.Signatures, sig)
+	}
+
+	tx.Message = raw[r.pos:]
+	prefix, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	if prefix&solanaVersionPrefix != 0 {
+		tx.Version = int(prefix &^ solanaVersionPrefix)
+		if tx.Version != 0 {
+			return nil, fmt.Errorf("unsupported solana tx version: %d", tx.Version)
+		}
+		if prefix, err = r.byte(); err != nil {
+			return nil, err
+		}
+	}
+	// header: numRequiredSignatures, numReadonlySigned, numReadonlyUnsigned
+	tx.NumSigners = int(prefix)
+	if _, err = r.bytes(2); err != nil {
+		return nil, err
+	}
+
+	keyCount, err := r.shortVec()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < keyCount; i++ {
+		key, err := r.bytes(solanaPubkeyLen)
+		if err != nil {
+			return nil, err
+		}
+		tx.AccountKeys = append(tx.AccountKeys, EncodeBase58(key))
+	}
+	if keyCount == 0 || tx.NumSigners == 0 || tx.NumSigners > keyCount {
+		return nil, fmt.Errorf("invalid solana tx header: %d signers, %d accounts", tx.NumSigners, keyCount)
+	}
+
+	blockhash, err := r.bytes(solanaPubkeyLen)
+	if err != nil {
+		return nil, err
+	}
+	tx.RecentBlockhash = EncodeBase58(blockhash)
+
+	ixCount, err := r.shortVec()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < ixCount; i++ {
+		programIndex, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		// program id 只能是静态账户，不能来自 address lookup table
+		if int(programIndex) >= keyCount {
+			return nil, fmt.Errorf("instruction %d program index %d out of range", i, programIndex)
+		}
+		if program := tx.AccountKeys[programIndex]; !seen[program] {
+			seen[program] = true
+			tx.ProgramIDs = append(tx.ProgramIDs, program)
+		}
+		if err = r.skipVec(); err != nil { // account indexes
+			return nil, err
+		}
+		if err = r.skipVec(); err != nil { // data
+			return nil, err
+		}
+	}
+
+	if tx.Version == 0 {
+		lookupCount, err := r.shortVec()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < lookupCount; i++ {
+			if _, err = r.bytes(solanaPubkeyLen); err != nil {
+				return nil, err
+			}
+			if err = r.skipVec(); err != nil { // writable indexes
+				return nil, err
+			}
+			if err = r.skipVec(); err != nil { // readonly indexes
+				return nil, err
+			}
+		}
+	}
+	if r.pos != len(raw) {
+		return nil, fmt.Errorf("solana tx has %d trailing bytes", len(raw)-r.pos)
+	}
+	return tx, nil
+}
+
+// solanaReader reads the compact wire format
+type solanaReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *solanaReader) byte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, errSolanaTxTruncated
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *solanaReader) bytes(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.buf) {
+		return nil, errSolanaTxTruncated
+	}
+	b := r.buf[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// shortVec reads a compact-u16 length
+func (r *solanaReader) shortVec() (int, error) {
+	n := 0
+	for i := 0; i < 3; i++ {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		n |= int(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid compact-u16 length")
+}
+
+func (r *solanaReader) skipVec() error {
+	n, err := r.shortVec()
+	if err != nil {
+		return err
+	}
+	_, err = r.bytes(n)
+	return err
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var bigRadix58 = big.NewInt(58)
+
+// EncodeBase58 encodes bytes with the bitcoin alphabet used by Solana keys and signatures
+func EncodeBase58(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+	mod := new(big.Int)
+	out := make([]byte, 0, len(b)*138/100+1)
+	for x.Sign() > 0 {
+		x.DivMod(x, bigRadix58, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// DecodeBase58 decodes a base58 string
+func DecodeBase58(s string) ([]byte, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty base58 string")
+	}
+	x := new(big.Int)
+	for _, c := range s {
+		idx := strings.IndexRune(base58Alphabet, c)
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", c)
+		}
+		x.Mul(x, bigRadix58)
+		x.Add(x, big.NewInt(int64(idx)))
+	}
+	leading := 0
+	for leading < len(s) && s[leading] == base58Alphabet[0] {
+		leading++
+	}
+	return append(make([]byte, leading), x.Bytes()...), nil
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/roothash-pay/wallet-services/services/common/chaininfo"
+)
+
+// Solana commitment levels
+const (
+	CommitmentProcessed = "processed"
+	CommitmentConfirmed = "confirmed" // 超级多数投票确认，极少回滚
+	CommitmentFinalized = "finalized" // 之后又有 31 个以上 slot 确认，不可回滚
+)
+
+const (
+	// SolanaFinalizedDepth finalized 相对 confirmed 需要的 slot 深度
+	SolanaFinalizedDepth = 32
+	// SolanaBlockhashValidity recent blockhash 在此数量的区块内有效（MAX_PROCESSING_AGE）
+	SolanaBlockhashValidity = 150
+)
+
+// ErrSolanaRPCUnavailable is returned when the chain has no rpc_url to query
+var ErrSolanaRPCUnavailable = errors.New("solana rpc unavailable")
+
+// SolanaCaller queries a Solana chain's rpc_url for what the account service does not expose
+type SolanaCaller struct {
+	chainInfo chaininfo.Provider
+	clients   sync.Map // rpc_url -> *rpc.Client
+}
+
+// NewSolanaCaller creates a new Solana caller
+func NewSolanaCaller(chainInfo chaininfo.Provider) *SolanaCaller {
+	return &SolanaCaller{chainInfo: chainInfo}
+}
+
+// BlockHeight returns the current block height at the commitment level. Blockhash expiry
+// (lastValidBlockHeight) is measured in block height, not in slots.
+func (c *SolanaCaller) BlockHeight(ctx context.Context, chainID, commitment string) (uint64, error) {
+	client, err := c.client(ctx, chainID)
+	if err != nil {
+		return 0, err
+	}
+	var height uint64
+	if err = client.CallContext(ctx, &height, "getBlockHeight", map[string]string{"commitment": commitment}); err != nil {
+		return 0, fmt.Errorf("getBlockHeight: %w", err)
+	}
+	return height, nil
+}
+
+// client returns a cached JSON-RPC client for the chain's rpc_url
+func (c *SolanaCaller) client(ctx context.Context, chainID string) (*rpc.Client, error) {
+	if c.chainInfo == nil {
+		return nil, fmt.Errorf("chain info provider not configured")
+	}
+	info, err := c.chainInfo.Get(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if info.RPCURL == "" {
+		return nil, fmt.Errorf("%w: chain %s has no rpc_url", ErrSolanaRPCUnavailable, chainID)
+	}
+
+	if cached, ok := c.clients.Load(info.RPCURL); ok {
+		return cached.(*rpc.Client), nil
+	}
+	client, err := rpc.DialContext(ctx, info.RPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial chain %s rpc: %w", chainID, err)
+	}
+	actual, loaded := c.clients.LoadOrStore(info.RPCURL, client)
+	if loaded {
+		client.Close()
+	}
+	return actual.(*rpc.Client), nil
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jupiterProgram = "JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4"
+
+// buildSolanaTx serializes a signed tx with one compute budget and one swap instruction
+func buildSolanaTx(t *testing.T, key ed25519.PrivateKey, blockhash string, versioned bool) string {
+	payer := key.Public().(ed25519.PublicKey)
+	keys := [][]byte{payer}
+	for _, k := range []string{jupiterProgram, "ComputeBudget111111111111111111111111111111"} {
+		b, err := DecodeBase58(k)
+		require.NoError(t, err)
+		keys = append(keys, b)
+	}
+	hash, err := DecodeBase58(blockhash)
+	require.NoError(t, err)
+
+	var msg bytes.Buffer
+	if versioned {
+		msg.WriteByte(0x80)
+	}
+	msg.Write([]byte{1, 0, 2})
+	msg.WriteByte(byte(len(keys)))
+	for _, k := range keys {
+		msg.Write(k)
+	}
+	msg.Write(hash)
+	msg.WriteByte(2)
+	msg.Write([]byte{2, 0, 5, 2, 0x40, 0x0d, 0x03, 0x00}) // compute budget: no accounts, 5 bytes data
+	msg.Write([]byte{1, 1, 0, 3, 0xe5, 0x17, 0xcb})       // swap: payer account, 3 bytes data
+	if versioned {
+		msg.WriteByte(1)
+		msg.Write(make([]byte, 32)) // lookup table
+		msg.Write([]byte{1, 4, 1, 7})
+	}
+
+	var tx bytes.Buffer
+	tx.WriteByte(1)
+	tx.Write(ed25519.Sign(key, msg.Bytes()))
+	tx.Write(msg.Bytes())
+	return base64.StdEncoding.EncodeToString(tx.Bytes())
+}
+
+func TestBase58(t *testing.T) {
+	assert.Equal(t, "StV1DL6CwTryKyV", EncodeBase58([]byte("hello world")))
+	assert.Equal(t, "11111111111111111111111111111111", EncodeBase58(make([]byte, 32)))
+
+	b, err := DecodeBase58("11111111111111111111111111111111")
+	require.NoError(t, err)
+	assert.Equal(t, make([]byte, 32), b)
+
+	_, err = DecodeBase58("0OIl")
+	assert.Error(t, err)
+}
+
+func TestDecodeSolanaTx(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, 32))
+	payer := EncodeBase58(key.Public().(ed25519.PublicKey))
+	blockhash := EncodeBase58(bytes.Repeat([]byte{9}, 32))
+
+	for _, versioned := range []bool{false, true} {
+		encoded := buildSolanaTx(t, key, blockhash, versioned)
+		tx, err := DecodeSolanaTx(encoded)
+		require.NoError(t, err)
+
+		if versioned {
+			assert.Equal(t, 0, tx.Version)
+		} else {
+			assert.Equal(t, SolanaLegacyVersion, tx.Version)
+		}
+		assert.Equal(t, payer, tx.FeePayer())
+		assert.Equal(t, blockhash, tx.RecentBlockhash)
+		assert.Equal(t, []string{"ComputeBudget111111111111111111111111111111", jupiterProgram}, tx.ProgramIDs)
+		assert.NoError(t, tx.VerifySignatures())
+		assert.Equal(t, EncodeBase58(tx.Signatures[0]), tx.Signature())
+
+		// base58 encoding of the same tx
+		raw, _ := base64.StdEncoding.DecodeString(encoded)
+		tx58, err := DecodeSolanaTx(EncodeBase58(raw))
+		require.NoError(t, err)
+		assert.Equal(t, tx.Signature(), tx58.Signature())
+
+		// tampered message no longer matches the fee payer signature
+		raw[len(raw)-1] ^= 0xff
+		tampered, err := ParseSolanaTx(raw)
+		if err == nil {
+			assert.Error(t, tampered.VerifySignatures())
+		}
+	}
+
+	_, err := DecodeSolanaTx("AQID")
+	assert.Error(t, err)
+}
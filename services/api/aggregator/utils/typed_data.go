@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// TypedDataDigest returns the EIP-712 digest (keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message)))
+func TypedDataDigest(typedData *apitypes.TypedData) (common.Hash, error) {
+	if typedData == nil {
+		return common.Hash{}, fmt.Errorf("typed data is nil")
+	}
+	digest, _, err := apitypes.TypedDataAndHash(*typedData)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+	return common.BytesToHash(digest), nil
+}
+
+// RecoverTypedDataSigner recovers the address that produced signature over typedData
+func RecoverTypedDataSigner(typedData *apitypes.TypedData, signature string) (common.Address, error) {
+	digest, err := TypedDataDigest(typedData)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid signature hex: %w", err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+
+	// 钱包返回的 v 为 27/28，crypto 需要 0/1
+	sig = append([]byte{}, sig...)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pub, err := crypto.SigToPub(digest.Bytes(), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover signer: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// VerifyTypedDataSignature checks that signature over typedData was produced by expectedSigner
+func VerifyTypedDataSignature(typedData *apitypes.TypedData, signature, expectedSigner string) error {
+	signer, err := RecoverTypedDataSigner(typedData, signature)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(signer.Hex(), expectedSigner) {
+		return fmt.Errorf("signer mismatch: got %s want %s", signer.Hex(), expectedSigner)
+	}
+	return nil
+}
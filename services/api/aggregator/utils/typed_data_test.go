@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOrder() *apitypes.TypedData {
+	return &apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Order": {
+				{Name: "maker", Type: "address"},
+				{Name: "makingAmount", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Order",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "1inch Aggregation Router",
+			Version:           "6",
+			ChainId:           (*math.HexOrDecimal256)(big.NewInt(1)),
+			VerifyingContract: "0x111111125421cA6dc452d289314280a0f8842A65",
+		},
+		Message: apitypes.TypedDataMessage{
+			"maker":        "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+			"makingAmount": "1000000",
+		},
+	}
+}
+
+func TestVerifyTypedDataSignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	td := testOrder()
+	digest, err := TypedDataDigest(td)
+	require.NoError(t, err)
+
+	sig, err := crypto.Sign(digest.Bytes(), key)
+	require.NoError(t, err)
+	sig[crypto.RecoveryIDOffset] += 27 // 钱包格式
+
+	require.NoError(t, VerifyTypedDataSignature(td, hexutil.Encode(sig), signer.Hex()))
+
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	assert.Error(t, VerifyTypedDataSignature(td, hexutil.Encode(sig), crypto.PubkeyToAddress(other.PublicKey).Hex()))
+
+	// 修改订单后签名不再匹配
+	td.Message["makingAmount"] = "2000000"
+	assert.Error(t, VerifyTypedDataSignature(td, hexutil.Encode(sig), signer.Hex()))
+
+	assert.Error(t, VerifyTypedDataSignature(testOrder(), "0x1234", signer.Hex()))
+}
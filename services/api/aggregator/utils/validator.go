@@ -1,103 +1,244 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/config"
+	"github.com/roothash-pay/wallet-services/services/common/chaininfo"
 )
 
+// defaultRouters 官方 router 合约，配置未指定全局白名单时使用
+var defaultRouters = []string{
+	"0x1231DEB6f5749EF6cE6943a275A1D3E7486F4EaE", // LiFi Diamond
+	"0x111111125421cA6dc452d289314280a0f8842A65", // 1inch Aggregation Router v6
+	"0x0000000000001fF3684f28c67538d4D072C22734", // 0x AllowanceHolder
+	"0xDef1C0ded9bec7F1a1670819833240f027b25EfF", // 0x Exchange Proxy
+}
+
+// defaultSpenders 官方 spender 合约，配置未指定全局白名单时使用
+var defaultSpenders = []string{
+	"0x1231DEB6f5749EF6cE6943a275A1D3E7486F4EaE", // LiFi Diamond
+	"0x111111125421cA6dc452d289314280a0f8842A65", // 1inch Aggregation Router v6
+	"0x0000000000001fF3684f28c67538d4D072C22734", // 0x AllowanceHolder
+	"0x000000000022D473030F116dDEE9F6B43aC78BA3", // Uniswap Permit2
+}
+
+// defaultPrograms Solana 交易顶层指令可调用的 program，配置未指定全局白名单时使用：
+// 系统 / token program 与 Jupiter、LiFi 在 Solana 上使用的 swap 与跨链 program
+var defaultPrograms = []string{
+	"11111111111111111111111111111111",             // System Program
+	"ComputeBudget111111111111111111111111111111",  // Compute Budget
+	"TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA",  // SPL Token
+	"TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb",  // SPL Token-2022
+	"ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL", // Associated Token Account
+	"MemoSq4gqABAXKb96qnH8TysNcWxMyWCqXgDLGmfcHr",  // Memo v2
+	"JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4",  // Jupiter v6（Jupiter 与 LiFi 同链 swap）
+	"src5qyZHqTqecJV4aY6Cb6zDZLMDzrDKKezs22MPHr4",  // deBridge DLN source（LiFi 跨链）
+	"BLZRi6frs4X4DNLw56V4EXai1b6QVESN1BhHBTYM9VcY", // Mayan Swift（LiFi 跨链）
+	"BrdgN2RPzEMWF96ZbnnJaUtQDQx7VRXYaHHbYCBvceWB", // Allbridge Core（LiFi 跨链）
+	"CCTPiPYPc6AsJuwueEnWgSgucamXDZwBd53dQ11YiKX3", // Circle CCTP Token Messenger Minter（LiFi 跨链）
+	"CCTPmbSD7gX1bxKPAmg77w8oFzNFpaQiQUWD43TKaecd", // Circle CCTP Message Transmitter（LiFi 跨链）
+}
+
+// defaultMaxValueWei 100 ETH
+var defaultMaxValueWei = new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+
+// chainRule holds the resolved validation rules of one chain
+type chainRule struct {
+	disabled        bool
+	routers         map[string]bool
+	spenders        map[string]bool
+	programs        map[string]bool
+	maxValueWei     *big.Int
+	tokenMaxAmounts map[string]*big.Int
+}
+
 // Validator provides validation utilities for DEX operations
 type Validator struct {
 	whitelistedRouters  map[string]bool
 	whitelistedSpenders map[string]bool
+	whitelistedPrograms map[string]bool
 	maxValueWei         *big.Int
+	chains              map[string]*chainRule
+	chainInfo           chaininfo.Provider
 }
 
-// NewValidator creates a new validator
-func NewValidator() *Validator {
-	// TODO: Load from configuration
-	return &Validator{
-		whitelistedRouters: map[string]bool{
-			"0x0000000000000000000000000000000000000000": true, // Placeholder
-		},
-		whitelistedSpenders: map[string]bool{
-			"0x0000000000000000000000000000000000000000": true, // Placeholder
-		},
-		maxValueWei: new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18)), // 100 ETH
+// NewValidator creates a validator from the aggregator config.
+// Chains listed in the config are supported; other chains fall back to the chain table (is_enabled).
+func NewValidator(cfg config.ValidatorConfig, chainInfo chaininfo.Provider) (*Validator, error) {
+	routers := cfg.Routers
+	if len(routers) == 0 {
+		routers = defaultRouters
+	}
+	spenders := cfg.Spenders
+	if len(spenders) == 0 {
+		spenders = defaultSpenders
+	}
+	programs := cfg.Programs
+	if len(programs) == 0 {
+		programs = defaultPrograms
 	}
+
+	maxValueWei := defaultMaxValueWei
+	if cfg.MaxValueWei != "" {
+		v, ok := new(big.Int).SetString(cfg.MaxValueWei, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid max_value_wei: %s", cfg.MaxValueWei)
+		}
+		maxValueWei = v
+	}
+
+	v := &Validator{
+		whitelistedRouters:  toAddressSet(routers),
+		whitelistedSpenders: toAddressSet(spenders),
+		whitelistedPrograms: toProgramSet(programs),
+		maxValueWei:         maxValueWei,
+		chains:              make(map[string]*chainRule, len(cfg.Chains)),
+		chainInfo:           chainInfo,
+	}
+
+	for chainID, c := range cfg.Chains {
+		rule := &chainRule{
+			disabled:        c.Disabled,
+			routers:         toAddressSet(c.Routers),
+			spenders:        toAddressSet(c.Spenders),
+			programs:        toProgramSet(c.Programs),
+			tokenMaxAmounts: make(map[string]*big.Int, len(c.TokenMaxAmounts)),
+		}
+		if c.MaxValueWei != "" {
+			max, ok := new(big.Int).SetString(c.MaxValueWei, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid max_value_wei for chain %s: %s", chainID, c.MaxValueWei)
+			}
+			rule.maxValueWei = max
+		}
+		for token, amount := range c.TokenMaxAmounts {
+			max, ok := new(big.Int).SetString(amount, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid token max amount for chain %s token %s: %s", chainID, token, amount)
+			}
+			rule.tokenMaxAmounts[strings.ToLower(token)] = max
+		}
+		v.chains[chainID] = rule
+	}
+
+	return v, nil
 }
 
 // ValidateChainID validates that the chain ID is supported
-func (v *Validator) ValidateChainID(chainID string) error {
-	// TODO: Implement chain ID validation
-	supportedChains := map[string]bool{
-		// Ethereum & L2s
-		"1":        true, // Ethereum Mainnet
-		"5":        true, // Goerli
-		"10":       true, // Optimism
-		"420":      true, // Optimism Goerli
-		"42161":    true, // Arbitrum One
-		"42170":    true, // Arbitrum Nova
-		"421613":   true, // Arbitrum Goerli
-		"11155111": true, // Sepolia
-		// BNB Chain
-		"56": true, // BNB Chain
-		"97": true, // BNB Testnet
-		// Polygon
-		"137":   true, // Polygon
-		"80001": true, // Polygon Mumbai
-		// Avalanche
-		"43114": true, // Avalanche C-Chain
-		"43113": true, // Avalanche Fuji
-		// Fantom
-		"250":  true, // Fantom
-		"4002": true, // Fantom Testnet
-		// zkSync
-		"324": true, // zkSync Era
-		"280": true, // zkSync Testnet
-		// Base
-		"8453":  true, // Base
-		"84531": true, // Base Goerli
-		// Linea
-		"59144": true, // Linea Mainnet
-		"59140": true, // Linea Sepolia
-		// Scroll
-		"534352": true, // Scroll
-		"534351": true, // Scroll Sepolia
-		// Misc
-		"solana-mainnet": true,
-		"solana-devnet":  true,
-	}
-
-	if !supportedChains[chainID] {
-		return fmt.Errorf("unsupported chain ID: %s", chainID)
+func (v *Validator) ValidateChainID(ctx context.Context, chainID string) error {
+	if rule, ok := v.chains[chainID]; ok {
+		if rule.disabled {
+			return fmt.Errorf("unsupported chain ID: %s", chainID)
+		}
+		return nil
 	}
 
+	if v.chainInfo == nil {
+		return fmt.Errorf("unsupported chain ID: %s", chainID)
+	}
+	info, err := v.chainInfo.Get(ctx, chainID)
+	if err != nil {
+		log.Warn("Chain info lookup failed", "chainID", chainID, "err", err)
+		return fmt.Errorf("unsupported chain ID: %s", chainID)
+	}
+	if !info.IsEnabled {
+		return fmt.Errorf("unsupported chain ID: %s", chainID)
+	}
 	return nil
 }
 
-// ValidateRouter validates that the router address is whitelisted
-func (v *Validator) ValidateRouter(router string) error {
+// ValidateRouter validates that the router address is whitelisted on the chain
+func (v *Validator) ValidateRouter(chainID, router string) error {
 	router = strings.ToLower(router)
-	if !v.whitelistedRouters[router] {
-		return fmt.Errorf("router not whitelisted: %s", router)
+	if v.whitelistedRouters[router] {
+		return nil
 	}
-	return nil
+	if rule, ok := v.chains[chainID]; ok && rule.routers[router] {
+		return nil
+	}
+	return fmt.Errorf("router not whitelisted on chain %s: %s", chainID, router)
 }
 
-// ValidateSpender validates that the spender address is whitelisted
-func (v *Validator) ValidateSpender(spender string) error {
+// ValidateSpender validates that the spender address is whitelisted on the chain
+func (v *Validator) ValidateSpender(chainID, spender string) error {
 	spender = strings.ToLower(spender)
-	if !v.whitelistedSpenders[spender] {
-		return fmt.Errorf("spender not whitelisted: %s", spender)
+	if v.whitelistedSpenders[spender] {
+		return nil
+	}
+	if rule, ok := v.chains[chainID]; ok && rule.spenders[spender] {
+		return nil
+	}
+	return fmt.Errorf("spender not whitelisted on chain %s: %s", chainID, spender)
+}
+
+// ValidatePrograms validates that every program invoked by a Solana tx is whitelisted on the chain
+func (v *Validator) ValidatePrograms(chainID string, programs []string) error {
+	rule := v.chains[chainID]
+	for _, program := range programs {
+		if v.whitelistedPrograms[program] || (rule != nil && rule.programs[program]) {
+			continue
+		}
+		return fmt.Errorf("program not whitelisted on chain %s: %s", chainID, program)
 	}
 	return nil
 }
 
-// ValidateValue validates that the transaction value is within limits
-func (v *Validator) ValidateValue(valueWei *big.Int) error {
-	if valueWei.Cmp(v.maxValueWei) > 0 {
-		return fmt.Errorf("value exceeds maximum: %s > %s", valueWei.String(), v.maxValueWei.String())
+// ValidateValue validates that the native transaction value is within the chain limit
+func (v *Validator) ValidateValue(chainID string, valueWei *big.Int) error {
+	if valueWei == nil || valueWei.Sign() < 0 {
+		return fmt.Errorf("invalid value")
+	}
+
+	max := v.maxValueWei
+	if rule, ok := v.chains[chainID]; ok && rule.maxValueWei != nil {
+		max = rule.maxValueWei
+	}
+	if valueWei.Cmp(max) > 0 {
+		return fmt.Errorf("value exceeds maximum: %s > %s", valueWei.String(), max.String())
 	}
 	return nil
 }
+
+// ValidateTokenAmount validates the sell amount against the per-token limit of the chain.
+// Tokens without a configured limit are not restricted.
+func (v *Validator) ValidateTokenAmount(chainID, token string, amount *big.Int) error {
+	if amount == nil || amount.Sign() < 0 {
+		return fmt.Errorf("invalid amount")
+	}
+
+	rule, ok := v.chains[chainID]
+	if !ok {
+		return nil
+	}
+	max, ok := rule.tokenMaxAmounts[strings.ToLower(token)]
+	if !ok {
+		return nil
+	}
+	if amount.Cmp(max) > 0 {
+		return fmt.Errorf("amount of token %s exceeds maximum: %s > %s", token, amount.String(), max.String())
+	}
+	return nil
+}
+
+func toAddressSet(addresses []string) map[string]bool {
+	set := make(map[string]bool, len(addresses))
+	for _, addr := range addresses {
+		set[strings.ToLower(addr)] = true
+	}
+	return set
+}
+
+// toProgramSet Solana 地址是区分大小写的 base58，不做归一化
+func toProgramSet(programs []string) map[string]bool {
+	set := make(map[string]bool, len(programs))
+	for _, program := range programs {
+		set[strings.TrimSpace(program)] = true
+	}
+	return set
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roothash-pay/wallet-services/config"
+)
+
+func TestValidator(t *testing.T) {
+	v, err := NewValidator(config.ValidatorConfig{
+		MaxValueWei: "1000",
+		Chains: map[string]config.ValidatorChainConfig{
+			"1": {
+				Routers:     []string{"0x00000000000000000000000000000000000000AA"},
+				MaxValueWei: "500",
+				TokenMaxAmounts: map[string]string{
+					"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48": "100",
+				},
+			},
+			"56":     {},
+			"97":     {Disabled: true},
+			"solana": {Programs: []string{"JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4"}},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	assert.NoError(t, v.ValidateChainID(ctx, "1"))
+	assert.NoError(t, v.ValidateChainID(ctx, "56"))
+	assert.Error(t, v.ValidateChainID(ctx, "97"))
+	assert.Error(t, v.ValidateChainID(ctx, "137"))
+
+	// default routers apply to every chain, configured ones only to their chain
+	assert.NoError(t, v.ValidateRouter("56", "0x1231deb6f5749ef6ce6943a275a1d3e7486f4eae"))
+	assert.NoError(t, v.ValidateRouter("1", "0x00000000000000000000000000000000000000aa"))
+	assert.Error(t, v.ValidateRouter("56", "0x00000000000000000000000000000000000000aa"))
+
+	// solana programs are case sensitive; the built-in list applies to every chain, configured ones only to their chain
+	assert.NoError(t, v.ValidatePrograms("solana", []string{"JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4"}))
+	assert.Error(t, v.ValidatePrograms("solana", []string{"jup6lkbzbjs1jkkwapdhny74zcz3tluzoi5qnyvtav4"}))
+	assert.NoError(t, v.ValidatePrograms("solana-devnet", []string{"ComputeBudget111111111111111111111111111111", "JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4"}))
+	assert.Error(t, v.ValidatePrograms("solana-devnet", []string{"AnyProgram1111111111111111111111111111111"}))
+	assert.NoError(t, v.ValidateSpender("1", "0x000000000022D473030F116dDEE9F6B43aC78BA3"))
+	assert.Error(t, v.ValidateSpender("1", "0x00000000000000000000000000000000000000aa"))
+
+	assert.NoError(t, v.ValidateValue("1", big.NewInt(500)))
+	assert.Error(t, v.ValidateValue("1", big.NewInt(501)))
+	assert.NoError(t, v.ValidateValue("56", big.NewInt(1000)))
+	assert.Error(t, v.ValidateValue("56", big.NewInt(1001)))
+
+	assert.NoError(t, v.ValidateTokenAmount("1", "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", big.NewInt(100)))
+	assert.Error(t, v.ValidateTokenAmount("1", "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", big.NewInt(101)))
+	assert.NoError(t, v.ValidateTokenAmount("56", "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", big.NewInt(101)))
+
+	_, err = NewValidator(config.ValidatorConfig{MaxValueWei: "abc"}, nil)
+	assert.Error(t, err)
+}
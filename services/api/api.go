@@ -11,6 +11,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	redis1 "github.com/redis/go-redis/v9"
 	httpSwagger "github.com/swaggo/http-swagger"
 
@@ -25,6 +26,7 @@ import (
 	"github.com/roothash-pay/wallet-services/common/httputil"
 	"github.com/roothash-pay/wallet-services/config"
 	"github.com/roothash-pay/wallet-services/database"
+	"github.com/roothash-pay/wallet-services/metrics"
 	"github.com/roothash-pay/wallet-services/services/api/routes"
 	"github.com/roothash-pay/wallet-services/services/api/service"
 	common2 "github.com/roothash-pay/wallet-services/services/common"
@@ -45,15 +47,19 @@ type APIConfig struct {
 }
 
 type API struct {
-	router      *chi.Mux
-	apiServer   *httputil.HTTPServer
-	db          *database.DB
-	marketCache cache.Cache
-	stopped     atomic.Bool
+	router          *chi.Mux
+	apiServer       *httputil.HTTPServer
+	metricsServer   *httputil.HTTPServer
+	metricsRegistry *prometheus.Registry
+	db              *database.DB
+	marketCache     cache.Cache
+	stopped         atomic.Bool
 }
 
 func NewApi(ctx context.Context, cfg *config.Config) (*API, error) {
-	out := &API{}
+	out := &API{
+		metricsRegistry: metrics.NewRegistry(),
+	}
 	if err := out.initFromConfig(ctx, cfg); err != nil {
 		return nil, errors.Join(err, out.Stop(ctx))
 	}
@@ -73,6 +79,9 @@ func (a *API) initFromConfig(ctx context.Context, cfg *config.Config) error {
 	if err := a.startServer(cfg.HttpServer); err != nil {
 		return fmt.Errorf("failed to start API server: %w", err)
 	}
+	if err := a.startMetricsServer(cfg.MetricsServer); err != nil {
+		return fmt.Errorf("failed to start metrics server: %w", err)
+	}
 	return nil
 }
 func (a *API) initDB(ctx context.Context, cfg *config.Config) error {
@@ -198,11 +207,14 @@ func (a *API) initRouter(ctx context.Context, cfg *config.Config) {
 	})
 
 	// Initialize Aggregator service and register routes
-	aggregatorService, err := service.InitAggregatorService(a.db, cfg)
+	// 指标只在 metrics_server 端口暴露，不挂在对外的 API 路由上
+	aggregatorMetrics := metrics.NewAggregatorMetrics(a.metricsRegistry, "aggregator")
+
+	aggregatorService, err := service.InitAggregatorService(a.db, cfg, aggregatorMetrics)
 	if err != nil {
 		log.Error("failed to initialize Aggregator service", "err", err)
 	} else if aggregatorService != nil {
-		aggregatorRoutes := routes.NewAggregatorRoutes(aggregatorService)
+		aggregatorRoutes := routes.NewAggregatorRoutes(aggregatorService, routes.AdminAuth(common2.NewSIWEVerifier(cfg.JWTSecret, cfg.Domain)))
 		aggregatorRoutes.RegisterRoutes(apiRouter)
 		log.Info("Aggregator routes registered successfully")
 	}
@@ -243,6 +255,21 @@ func (a *API) startServer(serverConfig config.ServerConfig) error {
 	a.apiServer = srv
 	return nil
 }
+
+func (a *API) startMetricsServer(cfg config.ServerConfig) error {
+	if cfg.Port == 0 {
+		log.Warn("metrics server not configured, aggregator metrics are not exposed")
+		return nil
+	}
+	srv, err := metrics.StartServer(a.metricsRegistry, cfg.Host, cfg.Port)
+	if err != nil {
+		return err
+	}
+	a.metricsServer = srv
+	log.Info("metrics server started", "port", cfg.Port, "addr", srv.Addr())
+	return nil
+}
+
 func (a *API) Start(ctx context.Context) error {
 	return nil
 }
@@ -254,6 +281,11 @@ func (a *API) Stop(ctx context.Context) error {
 			result = errors.Join(result, fmt.Errorf("failed to stop API server: %w", err))
 		}
 	}
+	if a.metricsServer != nil {
+		if err := a.metricsServer.Close(); err != nil {
+			result = errors.Join(result, fmt.Errorf("failed to close metrics server: %w", err))
+		}
+	}
 	if a.db != nil {
 		if err := a.db.Close(); err != nil {
 			result = errors.Join(result, fmt.Errorf("failed to close DB: %w", err))
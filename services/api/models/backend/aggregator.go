@@ -2,6 +2,8 @@ package backend
 
 import (
 	"time"
+
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 )
 
 // ChainType represents the blockchain type
@@ -21,8 +23,46 @@ const (
 	ActionTypeBridge  ActionType = "BRIDGE"  // 跨链桥接
 	ActionTypeWrap    ActionType = "WRAP"    // 包装原生代币
 	ActionTypeUnwrap  ActionType = "UNWRAP"  // 解包装代币
+
+	ActionTypeSignOrder     ActionType = "SIGN_ORDER"      // 签名链下订单（gasless/意图成交，如 1inch Fusion），不上链
+	ActionTypeSignTypedData ActionType = "SIGN_TYPED_DATA" // 签名 EIP-712 数据（Permit2 / EIP-2612 permit），代替链上 approve
+)
+
+// ProviderResultStatus describes how a provider took part in a quote request
+type ProviderResultStatus string
+
+const (
+	ProviderResultOK          ProviderResultStatus = "OK"           // 返回了报价
+	ProviderResultUnsupported ProviderResultStatus = "UNSUPPORTED"  // 不支持该链类型/链 ID，未请求
+	ProviderResultFailed      ProviderResultStatus = "FAILED"       // 请求失败
+	ProviderResultCircuitOpen ProviderResultStatus = "CIRCUIT_OPEN" // 熔断中，未请求
+)
+
+// CircuitState is the circuit breaker state of a provider
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "CLOSED"    // 正常
+	CircuitOpen     CircuitState = "OPEN"      // 熔断，拒绝请求
+	CircuitHalfOpen CircuitState = "HALF_OPEN" // 探测中，放行一个请求
 )
 
+// ProviderHealth is a snapshot of a provider's circuit breaker and health stats
+type ProviderHealth struct {
+	Provider            string       `json:"provider"`
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	Requests            int          `json:"requests"`       // 统计窗口内请求数
+	Failures            int          `json:"failures"`       // 统计窗口内失败数
+	ErrorRate           float64      `json:"error_rate"`     // 统计窗口内错误率
+	AvgLatencyMs        int64        `json:"avg_latency_ms"` // 统计窗口内平均耗时
+	P95LatencyMs        int64        `json:"p95_latency_ms"`
+	TimeoutMs           int64        `json:"timeout_ms"` // 单 provider 报价超时
+	LastError           string       `json:"last_error,omitempty"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	NextProbeAt         *time.Time   `json:"next_probe_at,omitempty"`
+}
+
 // TxStatus represents the status of a transaction (unified with database)
 // 使用与 database/backend/wallet_tx_record.go 相同的状态定义
 const (
@@ -30,14 +70,22 @@ const (
 	TxStatusPending = 1 // PENDING: 广播成功并拿到 txHash
 	TxStatusFailed  = 2 // FAILED: 广播失败或链上执行失败或超时
 	TxStatusSuccess = 3 // SUCCESS: 链上确认成功
+	// 跨链桥终态（源链成功后按目标链结果区分）
+	TxStatusPartial  = 4 // PARTIAL: 桥已完成，但目标链收到的不是目标代币（例如桥上的中间代币）
+	TxStatusRefunded = 5 // REFUNDED: 桥失败，资金已在源链退回
+	// 已上链但未达到链的确认数，期间被重组移除会退回 PENDING
+	TxStatusConfirming = 6 // CONFIRMING: 已打包，等待确认深度
 )
 
 // TxStatusNames provides human-readable names for status codes
 var TxStatusNames = map[int]string{
-	TxStatusCreated: "CREATED",
-	TxStatusPending: "PENDING",
-	TxStatusFailed:  "FAILED",
-	TxStatusSuccess: "SUCCESS",
+	TxStatusCreated:    "CREATED",
+	TxStatusPending:    "PENDING",
+	TxStatusFailed:     "FAILED",
+	TxStatusSuccess:    "SUCCESS",
+	TxStatusPartial:    "PARTIAL",
+	TxStatusRefunded:   "REFUNDED",
+	TxStatusConfirming: "CONFIRMING",
 }
 
 type RoutesRequest struct {
@@ -74,6 +122,10 @@ type QuoteRequest struct {
 	SlippageBps float64 `json:"slippage_bps" validate:"required,min=0,max=10000"`
 	UserAddress string  `json:"user_address,omitempty"`
 	WalletUUID  string  `json:"wallet_uuid,omitempty"` // Optional: wallet UUID for tracking
+
+	Gasless            bool            `json:"gasless,omitempty"`             // Optional: 优先使用 gasless 订单模式（1inch Fusion）
+	RankingStrategy    RankingStrategy `json:"ranking_strategy,omitempty"`    // Optional: 排序策略，默认取链配置
+	PreferredProviders []string        `json:"preferred_providers,omitempty"` // Optional: PREFERRED_PROVIDER 策略偏好的 provider
 }
 
 // Quote represents a swap quote from a provider
@@ -81,6 +133,7 @@ type Quote struct {
 	Provider    string    `json:"provider"`
 	ChainType   ChainType `json:"chain_type"`
 	ChainID     string    `json:"chain_id"`
+	ToChainID   string    `json:"to_chain_id,omitempty"` // 跨链时的目标链，同链为空
 	FromToken   string    `json:"from_token"`
 	ToToken     string    `json:"to_token"`
 	FromAmount  string    `json:"from_amount"`
@@ -90,6 +143,45 @@ type Quote struct {
 	Spender     string    `json:"spender,omitempty"` // EVM only: approval spender
 	Router      string    `json:"router,omitempty"`  // EVM only: swap router
 	Raw         string    `json:"raw,omitempty"`     // Raw provider response
+	Gasless     bool      `json:"gasless,omitempty"` // 链下订单报价，swap 由 resolver 成交，用户不付 gas
+
+	MinToAmount string       `json:"min_to_amount,omitempty"` // 扣除滑点后的最小输出
+	Issues      *QuoteIssues `json:"issues,omitempty"`        // provider 按用户地址检查到的问题（目前为 0x）
+
+	Score *QuoteScore `json:"score,omitempty"` // 排序分数明细
+}
+
+// QuoteIssues are problems the provider found with the taker's balance or allowance
+type QuoteIssues struct {
+	AllowanceActual      string   `json:"allowance_actual,omitempty"`  // 当前授权额度（不足时）
+	AllowanceSpender     string   `json:"allowance_spender,omitempty"` // 需要授权的 spender
+	BalanceToken         string   `json:"balance_token,omitempty"`
+	BalanceActual        string   `json:"balance_actual,omitempty"` // 当前余额（不足时）
+	BalanceExpected      string   `json:"balance_expected,omitempty"`
+	SimulationIncomplete bool     `json:"simulation_incomplete,omitempty"` // provider 未能完整模拟
+	InvalidSources       []string `json:"invalid_sources,omitempty"`       // 请求中无效的流动性来源
+}
+
+// RankingStrategy decides how quotes are ordered
+type RankingStrategy string
+
+const (
+	RankingBestNetOutput     RankingStrategy = "BEST_NET_OUTPUT"    // 扣除 gas 成本后的净输出最大
+	RankingLowestGas         RankingStrategy = "LOWEST_GAS"         // gas 成本最低
+	RankingPreferredProvider RankingStrategy = "PREFERRED_PROVIDER" // 偏好指定 provider
+	RankingMostReliable      RankingStrategy = "MOST_RELIABLE"      // 历史成功率最高
+)
+
+// QuoteScore is the score breakdown of a quote under the chosen strategy
+type QuoteScore struct {
+	Strategy     RankingStrategy `json:"strategy"`
+	Score        float64         `json:"score"`                    // 排序分数，越大越好
+	OutputUSD    float64         `json:"output_usd,omitempty"`     // 输出代币价值（USD）
+	GasCostUSD   float64         `json:"gas_cost_usd,omitempty"`   // gas 成本（USD）
+	NetOutputUSD float64         `json:"net_output_usd,omitempty"` // OutputUSD - GasCostUSD
+	ProviderBias float64         `json:"provider_bias,omitempty"`  // 偏好 provider 加成（比例）
+	Reliability  float64         `json:"reliability,omitempty"`    // provider 历史成功率 0~1
+	Note         string          `json:"note,omitempty"`           // 降级说明，例如价格缺失
 }
 
 type QuoteStore struct {
@@ -100,15 +192,44 @@ type QuoteStore struct {
 	BestQuotesIndex int       `json:"best_quotes_index"`
 	BestQuotes      []*Quote  `json:"best_quotes"`
 	Raws            []string  `json:"raws,omitempty"`
+
+	Request *QuoteRequest `json:"request,omitempty"` // 原始报价请求，refresh 时重新询价
+}
+
+// ProviderResult records the outcome of a single provider for a quote request
+type ProviderResult struct {
+	Provider string               `json:"provider"`
+	Status   ProviderResultStatus `json:"status"`
+	Reason   string               `json:"reason,omitempty"`
 }
 
 // QuoteResponse represents the response containing quotes
 type QuoteResponse struct {
-	QuoteID     string    `json:"quote_id"`
-	UserAddress string    `json:"user_address"`
-	WalletUUID  string    `json:"wallet_uuid"`
-	ExpiresAt   time.Time `json:"expires_at"`
-	BestQuotes  []*Quote  `json:"best_quotes"`
+	QuoteID     string            `json:"quote_id"`
+	UserAddress string            `json:"user_address"`
+	WalletUUID  string            `json:"wallet_uuid"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+	BestQuotes  []*Quote          `json:"best_quotes"`
+	Providers   []*ProviderResult `json:"providers,omitempty"` // 每个 provider 的参与情况（跳过/失败原因）
+	Ranking     RankingStrategy   `json:"ranking_strategy"`    // 本次使用的排序策略
+}
+
+// RefreshQuoteRequest represents a request to re-quote a stored quote
+type RefreshQuoteRequest struct {
+	AcceptPriceChange bool `json:"accept_price_change,omitempty"` // Optional: 接受超出容忍度的输出下降
+}
+
+// RefreshQuoteResponse represents the result of a quote refresh
+type RefreshQuoteResponse struct {
+	QuoteID          string    `json:"quote_id"`
+	BestQuotesIndex  int       `json:"best_quotes_index"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	Quote            *Quote    `json:"quote"`              // provider 最新报价
+	PreviousToAmount string    `json:"previous_to_amount"` // 刷新前的 ToAmount
+	DriftBps         int64     `json:"drift_bps"`          // 输出下降幅度（bps），负数表示输出增加
+	ToleranceBps     int       `json:"tolerance_bps"`
+	PriceDrifted     bool      `json:"price_drifted"` // 下降超出容忍度
+	Refreshed        bool      `json:"refreshed"`     // 新报价已生效且有效期已延长；false 时需带 accept_price_change 重试或重新报价
 }
 
 // PrepareSwapRequest represents a request to prepare a swap
@@ -125,17 +246,45 @@ type SigningPayload struct {
 	Value   string `json:"value,omitempty"`
 	Gas     string `json:"gas,omitempty"`
 	ChainID string `json:"chain_id,omitempty"`
+	// 替换交易必须沿用原 nonce 并给出加价后的费用；swap 计划中的费用为 normal 档建议值，钱包可按 Action.Fee 调整
+	Nonce                string `json:"nonce,omitempty"`
+	MaxFeePerGas         string `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas,omitempty"`
+	GasPrice             string `json:"gas_price,omitempty"` // 不支持 EIP-1559 的链
 
 	// Solana fields
-	SerializedTx string `json:"serialized_tx,omitempty"` // TODO: Solana transaction payload
+	SerializedTx         string `json:"serialized_tx,omitempty"`           // base64 编码的未签名交易（legacy 或 v0 versioned），钱包签名后原样提交
+	LastValidBlockHeight uint64 `json:"last_valid_block_height,omitempty"` // recent blockhash 的最后有效区块高度，超过后交易不会再上链
+
+	// Typed-data fields (SIGN_ORDER / SIGN_TYPED_DATA)
+	TypedData *apitypes.TypedData `json:"typed_data,omitempty"` // EIP-712 typed data，客户端用 eth_signTypedData_v4 签名
+	OrderData string              `json:"order_data,omitempty"` // provider 提交订单所需的附加数据（不需要签名）
 }
 
 // Action represents a single action in a transaction plan
 type Action struct {
 	ActionType     ActionType      `json:"action_type"`
 	ChainID        string          `json:"chain_id"`
-	SigningPayload *SigningPayload `json:"signing_payload"`
+	SigningPayload *SigningPayload `json:"signing_payload"` // 为空表示该交易依赖前面的签名步骤，提交签名后才生成
 	Description    string          `json:"description,omitempty"`
+	Fee            *FeeEstimate    `json:"fee,omitempty"` // 网络费用建议，链费用不可用时为空
+}
+
+// FeeTier is the network fee of an action at one speed
+type FeeTier struct {
+	MaxFeePerGas         string  `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas string  `json:"max_priority_fee_per_gas,omitempty"`
+	GasPrice             string  `json:"gas_price,omitempty"` // 不支持 EIP-1559 的链
+	Fee                  string  `json:"fee"`                 // 预计费用，原生币最小单位
+	FeeUSD               float64 `json:"fee_usd,omitempty"`
+}
+
+// FeeEstimate is the suggested network fee of an action in slow / normal / fast tiers
+type FeeEstimate struct {
+	GasLimit string   `json:"gas_limit,omitempty"` // EVM only
+	Slow     *FeeTier `json:"slow"`
+	Normal   *FeeTier `json:"normal"`
+	Fast     *FeeTier `json:"fast"`
 }
 
 // TxPlan represents a plan of actions to execute a swap
@@ -146,8 +295,28 @@ type TxPlan struct {
 
 // PrepareSwapResponse represents the response from prepare swap
 type PrepareSwapResponse struct {
-	SwapID  string    `json:"swap_id"`
-	Actions []*Action `json:"actions"`
+	SwapID     string              `json:"swap_id"`
+	Actions    []*Action           `json:"actions"`
+	Simulation []*SimulationResult `json:"simulation,omitempty"` // EVM 交易的 eth_call 模拟结果
+}
+
+// SimulationVerdict is the outcome of dry-running a prepared tx
+type SimulationVerdict string
+
+const (
+	SimulationSuccess  SimulationVerdict = "SUCCESS"  // eth_call 成功
+	SimulationReverted SimulationVerdict = "REVERTED" // 交易会失败，见 reason_code / revert_reason
+	SimulationSkipped  SimulationVerdict = "SKIPPED"  // 未模拟：依赖前置 approve/签名，或链未配置 rpc_url
+	SimulationError    SimulationVerdict = "ERROR"    // 模拟调用本身失败（节点错误等）
+)
+
+// SimulationResult is the simulation verdict of one action
+type SimulationResult struct {
+	StepIndex    int               `json:"step_index"`
+	Verdict      SimulationVerdict `json:"verdict"`
+	ReasonCode   string            `json:"reason_code,omitempty"`   // INSUFFICIENT_BALANCE / INSUFFICIENT_ALLOWANCE / EXPIRED / SLIPPAGE / PANIC / REVERTED
+	RevertReason string            `json:"revert_reason,omitempty"` // 解码后的 revert 原因
+	Message      string            `json:"message,omitempty"`       // SKIPPED / ERROR 的原因
 }
 
 // BuildSwapResponse represents the response from provider build swap
@@ -165,7 +334,47 @@ type SubmitSignedTxRequest struct {
 
 // SubmitSignedTxResponse represents the response from submitting a signed tx
 type SubmitSignedTxResponse struct {
-	TxHash string `json:"tx_hash"`
+	TxHash   string `json:"tx_hash"`
+	InFlight bool   `json:"in_flight,omitempty"` // 相同幂等键的请求正在广播，稍后用同一幂等键重试或查询 swap 状态
+}
+
+// SubmitSignatureRequest represents a request to submit an EIP-712 signature for a typed-data step
+type SubmitSignatureRequest struct {
+	SwapID         string `json:"swap_id" validate:"required"`
+	StepIndex      int    `json:"step_index" validate:"min=0"`
+	Signature      string `json:"signature" validate:"required"` // 0x 开头的 65 字节签名
+	IdempotencyKey string `json:"idempotency_key" validate:"required"`
+}
+
+// SubmitSignatureResponse represents the response from submitting a signature
+type SubmitSignatureResponse struct {
+	OrderHash string    `json:"order_hash,omitempty"` // SIGN_ORDER: provider 订单哈希
+	Actions   []*Action `json:"actions,omitempty"`    // SIGN_TYPED_DATA: 带上 permit 后生成的后续交易，对应 step_index+1 起的步骤
+	InFlight  bool      `json:"in_flight,omitempty"`  // SIGN_ORDER: 相同幂等键的订单正在提交
+}
+
+// PermitSignature is a user-signed permit passed back to the provider to build the swap tx
+type PermitSignature struct {
+	TypedData *apitypes.TypedData `json:"typed_data"`
+	Signature string              `json:"signature"`
+}
+
+// OrderStatus is the status of an off-chain order reported by its provider
+type OrderStatus struct {
+	Status       int      `json:"status"`                   // 0=CREATED, 1=PENDING, 2=FAILED, 3=SUCCESS
+	FillTxHashes []string `json:"fill_tx_hashes,omitempty"` // resolver 成交交易
+	Reason       string   `json:"reason,omitempty"`         // provider 原始状态
+}
+
+// BridgeStatus is the cross-chain status of a bridge tx reported by its provider
+type BridgeStatus struct {
+	Status         int    `json:"status"`                    // 1=PENDING, 2=FAILED, 3=SUCCESS, 4=PARTIAL, 5=REFUNDED
+	Substatus      string `json:"substatus,omitempty"`       // provider 原始子状态
+	Message        string `json:"message,omitempty"`         // provider 说明
+	DestChainID    string `json:"dest_chain_id,omitempty"`   // 目标链
+	DestTxHash     string `json:"dest_tx_hash,omitempty"`    // 目标链到账交易
+	ReceivedToken  string `json:"received_token,omitempty"`  // 实际到账代币
+	ReceivedAmount string `json:"received_amount,omitempty"` // 实际到账数量
 }
 
 type SubmitTxHashRequest struct {
@@ -179,12 +388,37 @@ type SubmitTxHashResponse struct {
 	TxHash string `json:"tx_hash"`
 }
 
+// ReplaceTxRequest 为卡住的 EVM 交易生成替换交易：speed_up 同 nonce 同调用提高费用，cancel 同 nonce 0 值转给自己
+type ReplaceTxRequest struct {
+	RecordGuid string `json:"record_guid" validate:"required"` // 待替换的 wallet_tx_record，须为 PENDING 且是最新一笔替换
+	Kind       string `json:"kind" validate:"required,oneof=speed_up cancel"`
+}
+
+type ReplaceTxResponse struct {
+	RecordGuid     string          `json:"record_guid"`
+	Kind           string          `json:"kind"`
+	OriginalTxHash string          `json:"original_tx_hash"`
+	SigningPayload *SigningPayload `json:"signing_payload"`
+}
+
+// SubmitReplacementRequest 提交已签名的替换交易
+type SubmitReplacementRequest struct {
+	RecordGuid string `json:"record_guid" validate:"required"`
+	Kind       string `json:"kind" validate:"required,oneof=speed_up cancel"`
+	SignedTx   string `json:"signed_tx" validate:"required"`
+}
+
+type SubmitReplacementResponse struct {
+	RecordGuid string `json:"record_guid"` // 替换交易的记录
+	TxHash     string `json:"tx_hash"`
+}
+
 // Step represents a single transaction step in a swap
 type Step struct {
 	StepIndex        int        `json:"step_index"`
 	ActionType       ActionType `json:"action_type"`
 	TxHash           string     `json:"tx_hash,omitempty"`
-	Status           int        `json:"status"` // 0=CREATED, 1=PENDING, 2=FAILED, 3=SUCCESS
+	Status           int        `json:"status"` // 0=CREATED, 1=PENDING, 2=FAILED, 3=SUCCESS, 4=PARTIAL, 5=REFUNDED
 	SubmittedAt      *time.Time `json:"submitted_at,omitempty"`
 	ConfirmedAt      *time.Time `json:"confirmed_at,omitempty"`
 	FailReasonCode   string     `json:"fail_reason_code,omitempty"`
@@ -194,6 +428,24 @@ type Step struct {
 	ExpectedTo       string     `json:"expected_to,omitempty"`
 	ExpectedValueWei string     `json:"expected_value,omitempty"`     // wei，十进制或 hex 统一一种
 	ExpectedDataHash string     `json:"expected_data_hash,omitempty"` // 0x...
+
+	// Solana steps: prepare 时解析下发交易得到，提交的签名交易必须一致（ExpectedDataHash 为 message 的 keccak256）
+	ExpectedFeePayer     string   `json:"expected_fee_payer,omitempty"`
+	ExpectedProgramIDs   []string `json:"expected_program_ids,omitempty"`
+	ExpectedBlockhash    string   `json:"expected_blockhash,omitempty"`
+	LastValidBlockHeight uint64   `json:"last_valid_block_height,omitempty"`
+
+	// Typed-data steps (SIGN_ORDER / SIGN_TYPED_DATA)
+	TypedData *apitypes.TypedData `json:"typed_data,omitempty"` // prepare 时下发的待签名数据，ExpectedDataHash 为其 EIP-712 digest
+	OrderData string              `json:"order_data,omitempty"`
+	OrderHash string              `json:"order_hash,omitempty"` // provider 返回的订单哈希
+
+	// Bridge steps: 源链交易成功后继续跟踪目标链
+	DestChainID    string `json:"dest_chain_id,omitempty"`
+	DestTxHash     string `json:"dest_tx_hash,omitempty"`    // 目标链到账交易
+	BridgeStatus   string `json:"bridge_status,omitempty"`   // provider 原始子状态，例如 WAIT_DESTINATION_TRANSACTION
+	ReceivedToken  string `json:"received_token,omitempty"`  // 实际到账代币（PARTIAL 时与目标代币不同）
+	ReceivedAmount string `json:"received_amount,omitempty"` // 实际到账数量
 }
 
 // Swap represents a complete swap operation
@@ -202,7 +454,7 @@ type Swap struct {
 	QuoteID        string    `json:"quote_id"` // 待修改
 	UserAddress    string    `json:"user_address"`
 	WalletUUID     string    `json:"wallet_uuid,omitempty"` // Wallet UUID for tracking
-	Status         int       `json:"status"`                // 整体状态（根据所有 steps 计算）: 0=CREATED, 1=PENDING, 2=FAILED, 3=SUCCESS
+	Status         int       `json:"status"`                // 整体状态（根据所有 steps 计算）: 0=CREATED, 1=PENDING, 2=FAILED, 3=SUCCESS, 4=PARTIAL, 5=REFUNDED
 	Steps          []*Step   `json:"steps"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
@@ -212,9 +464,16 @@ type Swap struct {
 
 // SwapStatusResponse represents the response for swap status query
 type SwapStatusResponse struct {
-	SwapID         string  `json:"swap_id"`
-	Status         int     `json:"status"` // 0=CREATED, 1=PENDING, 2=FAILED, 3=SUCCESS
-	Steps          []*Step `json:"steps"`
-	FailReasonCode string  `json:"fail_reason_code,omitempty"`
-	FailMessage    string  `json:"fail_message,omitempty"`
+	SwapID         string        `json:"swap_id"`
+	Status         int           `json:"status"` // 0=CREATED, 1=PENDING, 2=FAILED, 3=SUCCESS, 4=PARTIAL, 5=REFUNDED, 6=CONFIRMING
+	Steps          []*StepStatus `json:"steps"`
+	FailReasonCode string        `json:"fail_reason_code,omitempty"`
+	FailMessage    string        `json:"fail_message,omitempty"`
+	FailReason     *FailReason   `json:"fail_reason,omitempty"` // 失败原因的展示形式
+}
+
+// StepStatus is a swap step with its failure reason in user-facing form
+type StepStatus struct {
+	*Step
+	FailReason *FailReason `json:"fail_reason,omitempty"`
 }
@@ -0,0 +1,52 @@
+package backend
+
+import (
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+)
+
+// FailReason is a failure reason code in a form that can be shown to users
+type FailReason struct {
+	Code   string `json:"code"`
+	Title  string `json:"title"`            // 简短说明
+	Hint   string `json:"hint,omitempty"`   // 用户可以采取的操作
+	Detail string `json:"detail,omitempty"` // 原始信息，如 revert 字符串或自定义 error 签名
+}
+
+// failReasonTexts 原因码对应的展示文案
+var failReasonTexts = map[string]struct{ title, hint string }{
+	dbBackend.FailReasonBroadcastFailed:       {"The transaction could not be broadcast", "Check your balance and network fee, then try again."},
+	dbBackend.FailReasonChainFailed:           {"The transaction failed on chain", ""},
+	dbBackend.FailReasonNotFoundTimeout:       {"The transaction was not confirmed in time", "It may have been dropped by the network. Try again with a higher fee."},
+	dbBackend.FailReasonUnknown:               {"The transaction failed", ""},
+	dbBackend.FailReasonBridgeFailed:          {"The bridge transfer failed", "Contact support with the transaction hash."},
+	dbBackend.FailReasonBridgeRefunded:        {"The bridge transfer was refunded", "The funds were returned on the source chain."},
+	dbBackend.FailReasonBridgeTimeout:         {"The bridge transfer did not arrive in time", "Contact support with the transaction hash."},
+	dbBackend.FailReasonReplaced:              {"The transaction was replaced", "A speed-up transaction with the same nonce was confirmed instead."},
+	dbBackend.FailReasonCancelled:             {"The transaction was cancelled", ""},
+	dbBackend.FailReasonOrderFailed:           {"The order was not filled", "It expired or was cancelled. Get a new quote and try again."},
+	dbBackend.FailReasonBlockhashExpired:      {"The transaction expired before it was processed", "Get a new quote and sign it right away."},
+	dbBackend.FailReasonSlippage:              {"The price moved beyond your slippage tolerance", "Get a new quote or increase the slippage tolerance."},
+	dbBackend.FailReasonExpired:               {"The quote or signature expired", "Get a new quote and submit it right away."},
+	dbBackend.FailReasonInsufficientAllowance: {"The token approval is too low", "Approve the token for the full amount and try again."},
+	dbBackend.FailReasonInsufficientBalance:   {"Insufficient balance", "Top up the token, or the native coin used for gas."},
+	dbBackend.FailReasonOutOfGas:              {"The transaction ran out of gas", "Try again with a higher gas limit."},
+	dbBackend.FailReasonPanic:                 {"The contract hit an internal error", ""},
+	dbBackend.FailReasonReverted:              {"The transaction was rejected by the contract", ""},
+}
+
+// NewFailReason returns the user-facing form of a failure reason code, nil if there is no code
+func NewFailReason(code, detail string) *FailReason {
+	if code == "" {
+		return nil
+	}
+	text, ok := failReasonTexts[code]
+	if !ok {
+		text = failReasonTexts[dbBackend.FailReasonUnknown]
+	}
+	return &FailReason{
+		Code:   code,
+		Title:  text.title,
+		Hint:   text.hint,
+		Detail: detail,
+	}
+}
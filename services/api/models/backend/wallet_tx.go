@@ -1,5 +1,9 @@
 package backend
 
+import (
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+)
+
 // 创建交易
 type CreateWalletTxRequest struct {
 	OperationID string `json:"operation_id"`
@@ -31,3 +35,16 @@ type UpdateWalletTxStatusRequest struct {
 	FailReasonCode string `json:"fail_reason_code,omitempty"`
 	FailReasonMsg  string `json:"fail_reason_msg,omitempty"`
 }
+
+// 查询交易，附带失败原因的展示形式
+type WalletTxRecordResponse struct {
+	*dbBackend.WalletTxRecord
+	FailReason *FailReason `json:"fail_reason,omitempty"`
+}
+
+func NewWalletTxRecordResponse(r *dbBackend.WalletTxRecord) *WalletTxRecordResponse {
+	return &WalletTxRecordResponse{
+		WalletTxRecord: r,
+		FailReason:     NewFailReason(r.FailReasonCode, r.FailReasonMsg),
+	}
+}
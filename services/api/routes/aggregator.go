@@ -2,6 +2,8 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/ethereum/go-ethereum/log"
@@ -14,12 +16,14 @@ import (
 // AggregatorRoutes handles swap aggregator related routes
 type AggregatorRoutes struct {
 	aggregatorService *service.AggregatorService
+	adminAuth         func(http.Handler) http.Handler // 运维接口鉴权
 }
 
 // NewAggregatorRoutes creates a new aggregator routes handler
-func NewAggregatorRoutes(aggregatorService *service.AggregatorService) *AggregatorRoutes {
+func NewAggregatorRoutes(aggregatorService *service.AggregatorService, adminAuth func(http.Handler) http.Handler) *AggregatorRoutes {
 	return &AggregatorRoutes{
 		aggregatorService: aggregatorService,
+		adminAuth:         adminAuth,
 	}
 }
 
@@ -27,9 +31,14 @@ func NewAggregatorRoutes(aggregatorService *service.AggregatorService) *Aggregat
 func (h *AggregatorRoutes) RegisterRoutes(r chi.Router) {
 	r.Route("/api/v1/aggregator", func(r chi.Router) {
 		r.Post("/quotes", h.GetQuotesHandler)
+		r.Post("/quotes/{id}/refresh", h.RefreshQuoteHandler)
 		r.Post("/swap/prepare", h.PrepareSwapHandler)
 		r.Post("/tx/submitSigned", h.SubmitSignedTxHandler)
+		r.Post("/signature/submit", h.SubmitSignatureHandler)
+		r.Post("/tx/replace/prepare", h.PrepareReplacementHandler)
+		r.Post("/tx/replace/submit", h.SubmitReplacementHandler)
 		r.Get("/swap/status", h.GetSwapStatusHandler)
+		r.With(h.adminAuth).Get("/admin/providers", h.GetProviderHealthHandler)
 	})
 }
 
@@ -62,6 +71,43 @@ func (h *AggregatorRoutes) GetQuotesHandler(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode(resp)
 }
 
+// RefreshQuoteHandler godoc
+// @Summary      刷新报价
+// @Description  用原始请求向已选报价的 provider 重新询价。输出下降未超过容忍度（或 accept_price_change=true）时替换报价并延长有效期；超出时 refreshed=false、price_drifted=true，原报价不变
+// @Tags         Aggregator
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string true "Quote ID"
+// @Param        request  body      backend.RefreshQuoteRequest false "refresh 请求"
+// @Success      200      {object}  backend.RefreshQuoteResponse
+// @Failure      400      {string}  string "invalid request body"
+// @Failure      500      {string}  string "internal error"
+// @Router       /aggregator/quotes/{id}/refresh [post]
+func (h *AggregatorRoutes) RefreshQuoteHandler(w http.ResponseWriter, r *http.Request) {
+	quoteID := chi.URLParam(r, "id")
+	if quoteID == "" {
+		http.Error(w, "quote id is required", http.StatusBadRequest)
+		return
+	}
+
+	// body 可省略
+	var req backend.RefreshQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.aggregatorService.RefreshQuote(r.Context(), quoteID, &req)
+	if err != nil {
+		log.Error("RefreshQuote failed", "quoteID", quoteID, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
 // PrepareSwapHandler godoc
 // @Summary      生成 swap 执行计划
 // @Description  根据报价 ID 和用户地址生成签名动作链路
@@ -123,6 +169,93 @@ func (h *AggregatorRoutes) SubmitSignedTxHandler(w http.ResponseWriter, r *http.
 	json.NewEncoder(w).Encode(resp)
 }
 
+// SubmitSignatureHandler godoc
+// @Summary      提交 EIP-712 签名
+// @Description  校验签名者为 swap 用户。SIGN_ORDER：提交 gasless 订单（1inch Fusion），订单状态通过 swap/status 查询；SIGN_TYPED_DATA：用 permit 生成后续 swap 交易并返回
+// @Tags         Aggregator
+// @Accept       json
+// @Produce      json
+// @Param        request  body      backend.SubmitSignatureRequest true "签名请求"
+// @Success      200      {object}  backend.SubmitSignatureResponse
+// @Failure      400      {string}  string "invalid request body"
+// @Failure      500      {string}  string "internal error"
+// @Router       /aggregator/signature/submit [post]
+func (h *AggregatorRoutes) SubmitSignatureHandler(w http.ResponseWriter, r *http.Request) {
+	var req backend.SubmitSignatureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.aggregatorService.SubmitSignature(r.Context(), &req)
+	if err != nil {
+		log.Error("SubmitSignature failed", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// PrepareReplacementHandler godoc
+// @Summary      生成替换交易（加速 / 取消）
+// @Description  为仍在 mempool 中的 EVM 交易生成同 nonce 的替换交易：speed_up 保持原调用并提高费用，cancel 为 0 值转给自己。返回待签名数据
+// @Tags         Aggregator
+// @Accept       json
+// @Produce      json
+// @Param        request  body      backend.ReplaceTxRequest true "替换请求"
+// @Success      200      {object}  backend.ReplaceTxResponse
+// @Failure      400      {string}  string "invalid request body"
+// @Failure      500      {string}  string "internal error"
+// @Router       /aggregator/tx/replace/prepare [post]
+func (h *AggregatorRoutes) PrepareReplacementHandler(w http.ResponseWriter, r *http.Request) {
+	var req backend.ReplaceTxRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.aggregatorService.PrepareReplacement(r.Context(), &req)
+	if err != nil {
+		log.Error("PrepareReplacement failed", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// SubmitReplacementHandler godoc
+// @Summary      提交已签名的替换交易
+// @Description  校验 nonce、费用加价与调用内容后广播，新交易与原交易关联在同一 operation_id/step_index 下，任一笔上链即决定该步骤结果
+// @Tags         Aggregator
+// @Accept       json
+// @Produce      json
+// @Param        request  body      backend.SubmitReplacementRequest true "已签名替换交易"
+// @Success      200      {object}  backend.SubmitReplacementResponse
+// @Failure      400      {string}  string "invalid request body"
+// @Failure      500      {string}  string "internal error"
+// @Router       /aggregator/tx/replace/submit [post]
+func (h *AggregatorRoutes) SubmitReplacementHandler(w http.ResponseWriter, r *http.Request) {
+	var req backend.SubmitReplacementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.aggregatorService.SubmitReplacement(r.Context(), &req)
+	if err != nil {
+		log.Error("SubmitReplacement failed", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
 // SubmitTxHashHandler godoc
 // @Summary      提交交易哈希（前端钱包已广播）
 // @Description  用户使用前端钱包（如 MetaMask）自行广播交易后，将 txHash 回传给后端。后端记录 swap step 的 txHash 并将状态置为 PENDING，后续可通过 GetSwapStatus/worker 跟踪链上结果。
@@ -179,3 +312,19 @@ func (h *AggregatorRoutes) GetSwapStatusHandler(w http.ResponseWriter, r *http.R
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
+
+// GetProviderHealthHandler godoc
+// @Summary      查询 provider 健康状态
+// @Description  返回每个 provider 的熔断状态、错误率、耗时统计和报价超时配置
+// @Tags         Aggregator
+// @Produce      json
+// @Param        Authorization  header  string  true  "Bearer <admin token>"
+// @Success      200  {array}   backend.ProviderHealth
+// @Failure      401  {string}  string "invalid or expired token"
+// @Router       /aggregator/admin/providers [get]
+func (h *AggregatorRoutes) GetProviderHealthHandler(w http.ResponseWriter, r *http.Request) {
+	resp := h.aggregatorService.ProviderHealth()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/roothash-pay/wallet-services/services/api/service"
+	"github.com/roothash-pay/wallet-services/services/market/model"
 )
 
 func (rs *Routes) MarketPriceApi() {
@@ -27,28 +28,40 @@ func (rs *Routes) MarketPriceApi() {
 
 // getMarketQuote godoc
 // @Summary Get real-time market price
-// @Description Get latest market price from cache (info-level)
+// @Description Get latest market price from cache (info-level), by symbol, token id or chain id + contract address
 // @Tags Market
 // @Accept json
 // @Produce json
-// @Param symbol query string true "Asset symbol, e.g. BTC"
+// @Param symbol query string false "Asset symbol, e.g. BTC"
+// @Param token_id query string false "Token guid"
+// @Param chain_id query string false "Chain id, used with contract"
+// @Param contract query string false "Token contract address, used with chain_id"
 // @Success 200 {object} model.Quote
-// @Failure 400 {string} string "symbol required"
+// @Failure 400 {string} string "symbol, token_id or chain_id+contract required"
 // @Failure 404 {string} string "price not found"
 // @Router /api/v1/market-price/quote [get]
 func (rs *Routes) getMarketQuote(w http.ResponseWriter, r *http.Request) {
-	symbol := r.URL.Query().Get("symbol")
-	if symbol == "" {
-		http.Error(w, "symbol required", http.StatusBadRequest)
-		return
-	}
+	q := r.URL.Query()
+	symbol, tokenID := q.Get("symbol"), q.Get("token_id")
+	chainID, contract := q.Get("chain_id"), q.Get("contract")
 
-	quote, err := rs.svc.MarketPriceService.GetPrice(
-		r.Context(),
-		symbol,
+	var (
+		quote *model.Quote
+		err   error
 	)
-	if err != nil {
-		log.Warn("get market quote failed", "symbol", symbol, "err", err)
+	switch {
+	case tokenID != "":
+		quote, err = rs.svc.MarketPriceService.GetPriceByTokenID(r.Context(), tokenID)
+	case chainID != "" && contract != "":
+		quote, err = rs.svc.MarketPriceService.GetPriceByContract(r.Context(), chainID, contract)
+	case symbol != "":
+		quote, err = rs.svc.MarketPriceService.GetPrice(r.Context(), symbol)
+	default:
+		http.Error(w, "symbol, token_id or chain_id+contract required", http.StatusBadRequest)
+		return
+	}
+	if err != nil || quote == nil {
+		log.Warn("get market quote failed", "symbol", symbol, "token_id", tokenID, "chain_id", chainID, "contract", contract, "err", err)
 		http.Error(w, "price not found", http.StatusNotFound)
 		return
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 
 	"github.com/roothash-pay/wallet-services/services/api/service"
+	"github.com/roothash-pay/wallet-services/services/common"
 )
 
 var (
@@ -73,3 +74,22 @@ func (rs *Routes) JWTAuth(next http.Handler) http.Handler {
 		return
 	})
 }
+
+// AdminAuth only lets through requests carrying an admin token issued by /api/v1/admin/login;
+// other tokens signed with the same key (e.g. wallet tokens) lack the admin claim and are rejected
+func AdminAuth(verifier *common.SIWEVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+				http.Error(w, "missing or invalid Authorization header", http.StatusUnauthorized)
+				return
+			}
+			if _, err := verifier.VerifyAdminJWT(parts[1]); err != nil {
+				http.Error(w, "invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roothash-pay/wallet-services/services/common"
+)
+
+func TestAdminAuthRequiresAdminClaim(t *testing.T) {
+	verifier := common.NewSIWEVerifier("test-secret", "example.com")
+	handler := AdminAuth(verifier)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	call := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/aggregator/admin/providers", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	admin, err := verifier.GenerateAdminJWT("admin-guid", 1)
+	require.NoError(t, err)
+	user, err := verifier.GenerateJWT("0xuser", 1)
+	require.NoError(t, err)
+	wallet, err := verifier.GenerateWalletJWT("0xuser", "wallet-1", time.Hour)
+	require.NoError(t, err)
+	forged, err := common.NewSIWEVerifier("other-secret", "example.com").GenerateAdminJWT("admin-guid", 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, call(admin))
+	// 同一密钥签发但没有 admin 声明的令牌不放行
+	assert.Equal(t, http.StatusUnauthorized, call(user))
+	assert.Equal(t, http.StatusUnauthorized, call(wallet))
+	assert.Equal(t, http.StatusUnauthorized, call(forged))
+	assert.Equal(t, http.StatusUnauthorized, call(""))
+}
@@ -20,6 +20,7 @@ func (rs *Routes) WalletApi() {
 		r.Get("/info", rs.getWallet)
 		r.Get("/by-uuid", rs.getWalletByUUID)
 		r.Get("/list", rs.listWallets)
+		r.Post("/ws-token", rs.issueWalletToken)
 	})
 }
 
@@ -123,3 +124,21 @@ func (rs *Routes) listWallets(w http.ResponseWriter, r *http.Request) {
 		"page_size": pageSize,
 	})
 }
+
+// issueWalletToken 签发 websocket 订阅钱包 / swap 推送所需的钱包令牌
+func (rs *Routes) issueWalletToken(w http.ResponseWriter, r *http.Request) {
+	var req service.WalletTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := rs.svc.WalletService.IssueWalletToken(r.Context(), req)
+	if err != nil {
+		log.Warn("issue wallet token failed", "wallet_uuid", req.WalletUUID, "err", err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
@@ -85,7 +85,7 @@ func (s *adminService) AdminUserLogin(
 		}, nil
 	}
 
-	token, err := s.siweVerifier.GenerateJWT(user.Guid, 24)
+	token, err := s.siweVerifier.GenerateAdminJWT(user.Guid, 24)
 	if err != nil {
 		log.Error("generate jwt fail", "err", err)
 		return &model.AdminLoginResponse{
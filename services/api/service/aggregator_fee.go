@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// defaultApproveGas approve 交易未给出 gas 时的估算值
+const defaultApproveGas = 60000
+
+// attachFees attaches slow / normal / fast fee tiers to every tx action and, for EVM txs without
+// provider fees, pre-fills the signing payload with the normal tier
+func (s *AggregatorService) attachFees(ctx context.Context, quote *backend.Quote, actions []*backend.Action) {
+	if s.fees == nil || quote.Gasless {
+		return
+	}
+
+	for _, action := range actions {
+		sp := action.SigningPayload
+		if sp == nil || sp.TypedData != nil {
+			continue
+		}
+		chainID := action.ChainID
+		if chainID == "" {
+			chainID = quote.ChainID
+		}
+
+		var gasLimit uint64
+		if sp.SerializedTx == "" {
+			if gasLimit = actionGasLimit(quote, action); gasLimit == 0 {
+				continue
+			}
+		}
+		estimate, err := s.fees.Estimate(ctx, chainID, gasLimit)
+		if err != nil {
+			log.Warn("Fee estimate unavailable", "chainID", chainID, "actionType", action.ActionType, "err", err)
+			continue
+		}
+		action.Fee = estimate
+
+		if sp.To != "" && sp.MaxFeePerGas == "" && sp.GasPrice == "" {
+			sp.MaxFeePerGas = estimate.Normal.MaxFeePerGas
+			sp.MaxPriorityFeePerGas = estimate.Normal.MaxPriorityFeePerGas
+			sp.GasPrice = estimate.Normal.GasPrice
+		}
+	}
+}
+
+// actionGasLimit provider 给出的 gas 优先；approve 使用默认值，swap 使用报价的 gas 估算
+func actionGasLimit(quote *backend.Quote, action *backend.Action) uint64 {
+	if v, err := normalizeValue(action.SigningPayload.Gas); err == nil {
+		if gas, ok := new(big.Int).SetString(v, 10); ok && gas.Sign() > 0 && gas.IsUint64() {
+			return gas.Uint64()
+		}
+	}
+	if action.ActionType == backend.ActionTypeApprove {
+		return defaultApproveGas
+	}
+	if gas, ok := new(big.Int).SetString(quote.GasEstimate, 10); ok && gas.Sign() > 0 && gas.IsUint64() {
+		return gas.Uint64()
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/store"
+)
+
+const (
+	// idempotencyLease is how long a reservation blocks other callers; it must cover a broadcast,
+	// after that the reservation is treated as abandoned and the key can be reserved again
+	idempotencyLease = 2 * time.Minute
+	// idempotencyWait is how long a losing caller waits for the in-flight request to finish
+	idempotencyWait = 5 * time.Second
+	// idempotencyPoll is the interval of that wait
+	idempotencyPoll = 200 * time.Millisecond
+)
+
+// awaitIdempotency returns the result for a caller that lost the reservation.
+// A completed result is returned as is; an in-flight one is polled for up to idempotencyWait,
+// and reported as still in flight (empty hash) if it does not finish in time.
+func (s *AggregatorService) awaitIdempotency(ctx context.Context, swapID string, stepIndex int, idempotencyKey string, reservation *store.IdempotencyReservation) (string, bool) {
+	if !reservation.InFlight {
+		return reservation.TxHash, false
+	}
+
+	ticker := time.NewTicker(idempotencyPoll)
+	defer ticker.Stop()
+	timeout := time.NewTimer(idempotencyWait)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", true
+		case <-timeout.C:
+			return "", true
+		case <-ticker.C:
+			if txHash, exists := s.swapStore.CheckIdempotency(ctx, swapID, stepIndex, idempotencyKey); exists {
+				return txHash, false
+			}
+		}
+	}
+}
+
+// releaseIdempotency gives back a reservation whose request failed before reaching the chain,
+// detached from the request context so a cancelled request still releases it
+func (s *AggregatorService) releaseIdempotency(swapID string, stepIndex int, idempotencyKey string, owner string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.swapStore.ReleaseIdempotency(ctx, swapID, stepIndex, idempotencyKey, owner); err != nil {
+		log.Warn("Failed to release idempotency reservation, it expires with its lease", "swapID", swapID, "stepIndex", stepIndex, "err", err)
+	}
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/google/uuid"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/provider"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/status"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/utils"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// SubmitSignature accepts the user's EIP-712 signature for a typed-data step:
+//   - SIGN_ORDER: the order is submitted to its provider and tracked through GetSwapStatus
+//   - SIGN_TYPED_DATA: the permit is passed to the provider's BuildSwap and the resulting
+//     swap tx(s) fill the following steps and are returned for signing
+func (s *AggregatorService) SubmitSignature(ctx context.Context, req *backend.SubmitSignatureRequest) (*backend.SubmitSignatureResponse, error) {
+	// Check idempotency
+	if orderHash, exists := s.swapStore.CheckIdempotency(ctx, req.SwapID, req.StepIndex, req.IdempotencyKey); exists && orderHash != "" {
+		log.Info("Duplicate signature request detected", "swapID", req.SwapID, "stepIndex", req.StepIndex, "orderHash", orderHash)
+		return &backend.SubmitSignatureResponse{OrderHash: orderHash}, nil
+	}
+
+	swap, err := s.swapStore.GetSwap(ctx, req.SwapID)
+	if err != nil {
+		return nil, err
+	}
+	if req.StepIndex < 0 || req.StepIndex >= len(swap.Steps) {
+		return nil, fmt.Errorf("invalid step index: %d", req.StepIndex)
+	}
+
+	step := swap.Steps[req.StepIndex]
+	if step.TypedData == nil {
+		return nil, fmt.Errorf("step %d does not take a signature", req.StepIndex)
+	}
+
+	// 待签名数据必须与 prepare 下发的一致，且签名者为 swap 用户
+	digest, err := utils.TypedDataDigest(step.TypedData)
+	if err != nil {
+		return nil, err
+	}
+	if digest.Hex() != step.ExpectedDataHash {
+		return nil, fmt.Errorf("typed data mismatch: got %s want %s", digest.Hex(), step.ExpectedDataHash)
+	}
+	if err = utils.VerifyTypedDataSignature(step.TypedData, req.Signature, swap.UserAddress); err != nil {
+		return nil, fmt.Errorf("signature validation failed: %w", err)
+	}
+
+	quote, err := s.loadSelectedQuote(ctx, swap.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch step.ActionType {
+	case backend.ActionTypeSignOrder:
+		return s.submitOrder(ctx, swap, quote, step, req)
+	case backend.ActionTypeSignTypedData:
+		return s.submitPermit(ctx, swap, quote, step, req)
+	default:
+		return nil, fmt.Errorf("step %d does not take a signature", req.StepIndex)
+	}
+}
+
+// submitOrder submits a signed off-chain order to its provider
+func (s *AggregatorService) submitOrder(ctx context.Context, swap *backend.Swap, quote *backend.Quote, step *backend.Step, req *backend.SubmitSignatureRequest) (*backend.SubmitSignatureResponse, error) {
+	if step.Status != backend.TxStatusCreated {
+		return nil, fmt.Errorf("step %d already submitted", req.StepIndex)
+	}
+
+	orderProvider, err := s.orderProvider(quote.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	// 预占幂等键，避免多实例重复提交同一订单
+	owner := uuid.New().String()
+	reservation, err := s.swapStore.ReserveIdempotency(ctx, req.SwapID, req.StepIndex, req.IdempotencyKey, owner, idempotencyLease)
+	if err != nil {
+		return nil, err
+	}
+	if !reservation.Reserved {
+		orderHash, inFlight := s.awaitIdempotency(ctx, req.SwapID, req.StepIndex, req.IdempotencyKey, reservation)
+		log.Info("Duplicate signature request detected", "swapID", req.SwapID, "stepIndex", req.StepIndex, "orderHash", orderHash, "inFlight", inFlight)
+		return &backend.SubmitSignatureResponse{OrderHash: orderHash, InFlight: inFlight}, nil
+	}
+
+	orderHash, err := orderProvider.SubmitOrder(ctx, quote, step, req.Signature)
+	if err != nil {
+		s.releaseIdempotency(req.SwapID, req.StepIndex, req.IdempotencyKey, owner)
+		step.Status = backend.TxStatusFailed // 2 = FAILED
+		step.FailReasonCode = dbBackend.FailReasonBroadcastFailed
+		step.FailMessage = err.Error()
+		if syncErr := s.statusEngine.SyncSwap(ctx, swap, status.SourceAPI); syncErr != nil {
+			log.Error("Failed to update swap", "swapID", req.SwapID, "err", syncErr)
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	step.OrderHash = orderHash
+	step.Status = backend.TxStatusPending // 1 = PENDING
+	step.SubmittedAt = &now
+	step.IdempotencyKey = req.IdempotencyKey
+	if err := s.swapStore.UpdateStep(ctx, req.SwapID, req.StepIndex, step); err != nil {
+		log.Error("Failed to update step", "err", err)
+	}
+
+	if err := s.swapStore.RecordIdempotency(ctx, req.SwapID, req.StepIndex, req.IdempotencyKey, orderHash); err != nil {
+		log.Error("Failed to record idempotency", "swapID", req.SwapID, "stepIndex", req.StepIndex, "err", err)
+	}
+
+	if err := s.statusEngine.ApplySwap(ctx, swap, backend.TxStatusPending, status.SourceAPI); err != nil {
+		log.Error("Failed to update swap status", "swapID", req.SwapID, "err", err)
+	}
+
+	log.Info("Order submitted", "swapID", req.SwapID, "stepIndex", req.StepIndex, "provider", quote.Provider, "orderHash", orderHash)
+	return &backend.SubmitSignatureResponse{OrderHash: orderHash}, nil
+}
+
+// submitPermit builds the swap tx(s) with the signed permit and stores them as the steps after the permit step.
+// Re-submitting the same signature rebuilds the txs as long as none of them was broadcast yet.
+func (s *AggregatorService) submitPermit(ctx context.Context, swap *backend.Swap, quote *backend.Quote, step *backend.Step, req *backend.SubmitSignatureRequest) (*backend.SubmitSignatureResponse, error) {
+	if step.Status != backend.TxStatusCreated && step.IdempotencyKey != req.IdempotencyKey {
+		return nil, fmt.Errorf("step %d already submitted", req.StepIndex)
+	}
+	for _, next := range swap.Steps[req.StepIndex+1:] {
+		if next.Status != backend.TxStatusCreated {
+			return nil, fmt.Errorf("step %d already submitted", next.StepIndex)
+		}
+	}
+
+	permitProvider, err := s.permitProvider(quote.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	buildResp, err := permitProvider.BuildSwapWithPermit(ctx, quote, swap.UserAddress, &backend.PermitSignature{
+		TypedData: step.TypedData,
+		Signature: req.Signature,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build swap with permit: %w", err)
+	}
+	if err = s.validateActions(quote, swap.UserAddress, buildResp.Actions); err != nil {
+		log.Error("Provider returned tx rejected by validator", "provider", quote.Provider, "chainID", quote.ChainID, "err", err)
+		return nil, fmt.Errorf("swap plan rejected: %w", err)
+	}
+
+	// 签名步骤无需上链，收到签名即完成
+	now := time.Now()
+	step.Status = backend.TxStatusSuccess
+	step.SubmittedAt = &now
+	step.ConfirmedAt = &now
+	step.IdempotencyKey = req.IdempotencyKey
+
+	// 生成的交易依次填入签名步骤之后的步骤
+	steps := swap.Steps[:req.StepIndex+1]
+	for i, action := range buildResp.Actions {
+		next := &backend.Step{
+			StepIndex:  req.StepIndex + 1 + i,
+			ActionType: action.ActionType,
+			Status:     backend.TxStatusCreated,
+		}
+		if err = fillExpectedFromSigningPayload(next, action.SigningPayload); err != nil {
+			return nil, fmt.Errorf("failed to build expected tx snapshot (step %d): %w", next.StepIndex, err)
+		}
+		steps = append(steps, next)
+	}
+	swap.Steps = steps
+
+	if err = s.swapStore.UpdateSwap(ctx, swap); err != nil {
+		return nil, err
+	}
+
+	s.attachFees(ctx, quote, buildResp.Actions)
+
+	log.Info("Permit accepted", "swapID", req.SwapID, "stepIndex", req.StepIndex, "provider", quote.Provider, "actions", len(buildResp.Actions))
+	return &backend.SubmitSignatureResponse{Actions: buildResp.Actions}, nil
+}
+
+// refreshOrderStatus queries the provider for a pending order and updates the step
+func (s *AggregatorService) refreshOrderStatus(ctx context.Context, swapID string, quote *backend.Quote, step *backend.Step) {
+	orderProvider, err := s.orderProvider(quote.Provider)
+	if err != nil {
+		log.Warn("Skip order status refresh", "swapID", swapID, "err", err)
+		return
+	}
+
+	status, err := orderProvider.GetOrderStatus(ctx, quote.ChainID, step.OrderHash)
+	if err != nil {
+		log.Warn("Failed to get order status", "orderHash", step.OrderHash, "err", err)
+		return
+	}
+
+	if len(status.FillTxHashes) > 0 && step.TxHash == "" {
+		step.TxHash = status.FillTxHashes[0]
+	}
+
+	switch status.Status {
+	case backend.TxStatusSuccess:
+		now := time.Now()
+		step.Status = backend.TxStatusSuccess // 3 = SUCCESS
+		step.ConfirmedAt = &now
+	case backend.TxStatusFailed:
+		step.Status = backend.TxStatusFailed // 2 = FAILED
+		step.FailReasonCode = dbBackend.FailReasonOrderFailed
+		step.FailMessage = fmt.Sprintf("Order not filled: %s", status.Reason)
+	}
+	_ = s.swapStore.UpdateStep(ctx, swapID, step.StepIndex, step)
+}
+
+// orderProvider finds the named provider and checks that it supports off-chain orders
+func (s *AggregatorService) orderProvider(name string) (provider.OrderProvider, error) {
+	for _, p := range s.providers {
+		if p.Name() != name {
+			continue
+		}
+		if op, ok := provider.Unwrap(p).(provider.OrderProvider); ok {
+			return op, nil
+		}
+		return nil, fmt.Errorf("provider %s does not support orders", name)
+	}
+	return nil, fmt.Errorf("provider not found: %s", name)
+}
+
+// permitProvider finds the named provider and checks that it can build swaps from a permit
+func (s *AggregatorService) permitProvider(name string) (provider.PermitProvider, error) {
+	for _, p := range s.providers {
+		if p.Name() != name {
+			continue
+		}
+		if pp, ok := provider.Unwrap(p).(provider.PermitProvider); ok {
+			return pp, nil
+		}
+		return nil, fmt.Errorf("provider %s does not support permits", name)
+	}
+	return nil, fmt.Errorf("provider not found: %s", name)
+}
+
+// validateTypedData checks that a typed-data payload targets the quote's chain and a whitelisted contract:
+// orders must be verified by a whitelisted router, permits by Permit2 or the sold token (EIP-2612)
+// with a whitelisted spender
+func (s *AggregatorService) validateTypedData(quote *backend.Quote, action *backend.Action) error {
+	td := action.SigningPayload.TypedData
+	domain := td.Domain
+	if domain.ChainId == nil || (*big.Int)(domain.ChainId).String() != quote.ChainID {
+		return fmt.Errorf("typed data chain mismatch: want %s", quote.ChainID)
+	}
+	if domain.VerifyingContract == "" {
+		return fmt.Errorf("typed data missing verifying contract")
+	}
+
+	switch action.ActionType {
+	case backend.ActionTypeSignOrder:
+		return s.validator.ValidateRouter(quote.ChainID, domain.VerifyingContract)
+	case backend.ActionTypeSignTypedData:
+		if addrEq(domain.VerifyingContract, utils.Permit2Address) {
+			if err := s.validator.ValidateSpender(quote.ChainID, domain.VerifyingContract); err != nil {
+				return err
+			}
+		} else if !addrEq(domain.VerifyingContract, quote.FromToken) {
+			return fmt.Errorf("permit verifying contract %s is neither Permit2 nor the sold token", domain.VerifyingContract)
+		}
+		spender, _ := td.Message["spender"].(string)
+		if spender == "" {
+			return fmt.Errorf("permit missing spender")
+		}
+		return s.validator.ValidateSpender(quote.ChainID, spender)
+	default:
+		return fmt.Errorf("unexpected typed data for %s action", action.ActionType)
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/provider"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// RefreshQuote re-queries the provider of the selected quote with the original request.
+// If the new output dropped by more than the drift tolerance the refresh is only flagged
+// (the stored quote is left untouched) unless the user accepts the price change;
+// otherwise the stored quote is replaced and ExpiresAt extended.
+func (s *AggregatorService) RefreshQuote(ctx context.Context, quoteID string, req *backend.RefreshQuoteRequest) (*backend.RefreshQuoteResponse, error) {
+	cachedQuote, err := s.quoteStore.Get(ctx, quoteID)
+	if err != nil {
+		return nil, fmt.Errorf("quote not found or refresh window passed: %w", err)
+	}
+	if cachedQuote.Request == nil {
+		return nil, fmt.Errorf("quote %s cannot be refreshed: original request missing", quoteID)
+	}
+
+	index := cachedQuote.BestQuotesIndex
+	oldQuote, err := selectedQuote(cachedQuote, index)
+	if err != nil {
+		return nil, err
+	}
+
+	var selectedProvider provider.Provider
+	for _, p := range s.providers {
+		if p.Name() == oldQuote.Provider {
+			selectedProvider = p
+			break
+		}
+	}
+	if selectedProvider == nil {
+		return nil, fmt.Errorf("provider not found: %s", oldQuote.Provider)
+	}
+
+	newQuote, err := selectedProvider.GetQuote(ctx, cachedQuote.Request)
+	if s.providerStats != nil && !errors.Is(err, provider.ErrCircuitOpen) {
+		s.providerStats.Record(oldQuote.Provider, err == nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh quote from %s: %w", oldQuote.Provider, err)
+	}
+
+	driftBps, err := priceDriftBps(oldQuote.ToAmount, newQuote.ToAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &backend.RefreshQuoteResponse{
+		QuoteID:          quoteID,
+		BestQuotesIndex:  index,
+		ExpiresAt:        cachedQuote.ExpiresAt,
+		PreviousToAmount: oldQuote.ToAmount,
+		DriftBps:         driftBps,
+		ToleranceBps:     s.driftBps,
+		PriceDrifted:     driftBps > int64(s.driftBps),
+	}
+
+	if resp.PriceDrifted && (req == nil || !req.AcceptPriceChange) {
+		log.Warn("Quote refresh rejected by price drift", "quoteID", quoteID, "provider", oldQuote.Provider, "oldToAmount", oldQuote.ToAmount, "newToAmount", newQuote.ToAmount, "driftBps", driftBps, "toleranceBps", s.driftBps)
+	} else {
+		newQuote.Score = oldQuote.Score
+		cachedQuote.BestQuotes[index] = newQuote
+		cachedQuote.ExpiresAt = time.Now().Add(s.quoteTTL)
+		if err = s.quoteStore.Update(ctx, quoteID, cachedQuote, s.quoteRetention()); err != nil {
+			return nil, fmt.Errorf("failed to update quote: %w", err)
+		}
+		resp.ExpiresAt = cachedQuote.ExpiresAt
+		resp.Refreshed = true
+		log.Info("Quote refreshed", "quoteID", quoteID, "provider", oldQuote.Provider, "oldToAmount", oldQuote.ToAmount, "newToAmount", newQuote.ToAmount, "driftBps", driftBps)
+	}
+
+	respQuote := *newQuote
+	respQuote.Raw = ""
+	resp.Quote = &respQuote
+	return resp, nil
+}
+
+// quoteRetention is how long a quote is kept in the store: its TTL plus the refresh window
+func (s *AggregatorService) quoteRetention() time.Duration {
+	return s.quoteTTL + s.refreshWindow
+}
+
+// priceDriftBps returns how far newAmount fell below oldAmount in bps (negative if it rose)
+func priceDriftBps(oldAmount, newAmount string) (int64, error) {
+	oldAmt, ok := new(big.Int).SetString(oldAmount, 10)
+	if !ok || oldAmt.Sign() <= 0 {
+		return 0, fmt.Errorf("invalid previous amount: %s", oldAmount)
+	}
+	newAmt, ok := new(big.Int).SetString(newAmount, 10)
+	if !ok {
+		return 0, fmt.Errorf("invalid refreshed amount: %s", newAmount)
+	}
+
+	diff := new(big.Int).Sub(oldAmt, newAmt)
+	diff.Mul(diff, big.NewInt(10000))
+	return diff.Quo(diff, oldAmt).Int64(), nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roothash-pay/wallet-services/config"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/provider"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/store"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// staticProvider returns a fixed ToAmount
+type staticProvider struct {
+	toAmount string
+}
+
+func (p *staticProvider) GetQuote(_ context.Context, req *backend.QuoteRequest) (*backend.Quote, error) {
+	return &backend.Quote{
+		Provider:   p.Name(),
+		ChainType:  backend.ChainTypeEVM,
+		ChainID:    req.FromChainID,
+		FromToken:  req.FromToken,
+		ToToken:    req.ToToken,
+		FromAmount: req.Amount,
+		ToAmount:   p.toAmount,
+	}, nil
+}
+
+func (p *staticProvider) BuildSwap(context.Context, *backend.Quote, string) (*backend.BuildSwapResponse, error) {
+	return &backend.BuildSwapResponse{}, nil
+}
+
+func (p *staticProvider) Name() string { return "static" }
+
+func (p *staticProvider) SupportedChainTypes() []backend.ChainType {
+	return []backend.ChainType{backend.ChainTypeEVM}
+}
+
+func TestRefreshQuote(t *testing.T) {
+	ctx := context.Background()
+	prov := &staticProvider{}
+	quotes := store.NewInMemoryQuoteStore()
+	s := NewAggregatorService([]provider.Provider{prov}, quotes, store.NewInMemorySwapStore(), nil, nil, nil, nil, nil, nil, nil,
+		config.QuoteConfig{DriftToleranceBps: 100})
+
+	expired := time.Now().Add(-time.Second)
+	require.NoError(t, quotes.Save(ctx, "q1", &backend.QuoteStore{
+		QuoteID:    "q1",
+		ExpiresAt:  expired,
+		BestQuotes: []*backend.Quote{{Provider: "static", ToAmount: "1000000"}},
+		Request:    &backend.QuoteRequest{FromChainID: "1", FromToken: "0xa", ToToken: "0xb", Amount: "1"},
+	}, time.Minute))
+
+	// 下降 2% 超出 1% 容忍度：只标记，不替换
+	prov.toAmount = "980000"
+	resp, err := s.RefreshQuote(ctx, "q1", &backend.RefreshQuoteRequest{})
+	require.NoError(t, err)
+	assert.True(t, resp.PriceDrifted)
+	assert.False(t, resp.Refreshed)
+	assert.Equal(t, int64(200), resp.DriftBps)
+	stored, err := quotes.Get(ctx, "q1")
+	require.NoError(t, err)
+	assert.Equal(t, "1000000", stored.BestQuotes[0].ToAmount)
+	assert.Equal(t, expired, stored.ExpiresAt)
+
+	// 用户确认后接受
+	resp, err = s.RefreshQuote(ctx, "q1", &backend.RefreshQuoteRequest{AcceptPriceChange: true})
+	require.NoError(t, err)
+	assert.True(t, resp.Refreshed)
+	assert.True(t, resp.ExpiresAt.After(time.Now()))
+	stored, err = quotes.Get(ctx, "q1")
+	require.NoError(t, err)
+	assert.Equal(t, "980000", stored.BestQuotes[0].ToAmount)
+
+	// 价格上涨直接生效
+	prov.toAmount = "990000"
+	resp, err = s.RefreshQuote(ctx, "q1", nil)
+	require.NoError(t, err)
+	assert.False(t, resp.PriceDrifted)
+	assert.True(t, resp.Refreshed)
+	assert.Equal(t, int64(-102), resp.DriftBps)
+}
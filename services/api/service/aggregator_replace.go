@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/google/uuid"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/status"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/utils"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+	"github.com/roothash-pay/wallet-services/services/common/chaininfo"
+	"github.com/roothash-pay/wallet-services/services/grpc_client/account"
+)
+
+// cancelGasLimit 0 值转账的 gas
+const cancelGasLimit = 21000
+
+// replaceTarget is a pending record that can still be replaced, with the tx currently in the mempool
+type replaceTarget struct {
+	record   *dbBackend.WalletTxRecord
+	original *types.Transaction
+	info     *chaininfo.Info
+}
+
+// PrepareReplacement builds an unsigned tx that replaces a stuck EVM tx with the same nonce:
+// a speed-up repeats the call with bumped fees, a cancel is a zero-value transfer to the sender
+func (s *AggregatorService) PrepareReplacement(ctx context.Context, req *backend.ReplaceTxRequest) (*backend.ReplaceTxResponse, error) {
+	target, err := s.loadReplaceTarget(ctx, req.RecordGuid, req.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestedTip, baseFee, err := s.evmCaller.SuggestFees(ctx, target.record.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fees: %w", err)
+	}
+	tip, feeCap := utils.ReplacementFees(target.original, suggestedTip, baseFee)
+
+	original := target.original
+	payload := &backend.SigningPayload{
+		ChainID:              original.ChainId().String(),
+		Nonce:                strconv.FormatUint(original.Nonce(), 10),
+		MaxFeePerGas:         feeCap.String(),
+		MaxPriorityFeePerGas: tip.String(),
+	}
+	if req.Kind == dbBackend.ReplaceKindCancel {
+		payload.To = target.record.FromAddress
+		payload.Value = "0"
+		payload.Data = "0x"
+		payload.Gas = strconv.Itoa(cancelGasLimit)
+	} else {
+		payload.To = original.To().Hex()
+		payload.Value = original.Value().String()
+		payload.Data = hexutil.Encode(original.Data())
+		payload.Gas = strconv.FormatUint(original.Gas(), 10)
+	}
+
+	return &backend.ReplaceTxResponse{
+		RecordGuid:     target.record.Guid,
+		Kind:           req.Kind,
+		OriginalTxHash: target.record.TxID,
+		SigningPayload: payload,
+	}, nil
+}
+
+// SubmitReplacement validates and broadcasts a signed replacement, recording it as a new
+// wallet_tx_record on the same operation step; the tracker settles the step with whichever tx is mined
+func (s *AggregatorService) SubmitReplacement(ctx context.Context, req *backend.SubmitReplacementRequest) (*backend.SubmitReplacementResponse, error) {
+	rawBytes, err := hexutil.Decode(req.SignedTx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signedTx hex: %w", err)
+	}
+	var replacement types.Transaction
+	if err = replacement.UnmarshalBinary(rawBytes); err != nil {
+		return nil, fmt.Errorf("failed to decode signed tx: %w", err)
+	}
+	txHash := replacement.Hash().Hex()
+
+	// 幂等：同一笔替换交易重复提交直接返回
+	if existing, err := s.db.BackendWalletTxRecord.GetByTxID(txHash); err == nil && existing != nil {
+		return &backend.SubmitReplacementResponse{RecordGuid: existing.Guid, TxHash: existing.TxID}, nil
+	}
+
+	target, err := s.loadReplaceTarget(ctx, req.RecordGuid, req.Kind)
+	if err != nil {
+		return nil, err
+	}
+	cancel := req.Kind == dbBackend.ReplaceKindCancel
+	if err = utils.ValidateReplacement(target.original, &replacement, common.HexToAddress(target.record.FromAddress), cancel); err != nil {
+		return nil, fmt.Errorf("replacement validation failed: %w", err)
+	}
+
+	result, err := s.accountClient.SendTx(ctx, account.SendTxParams{
+		ConsumerToken: target.info.ConsumerToken,
+		Chain:         target.info.WalletChain,
+		Coin:          target.info.WalletCoin,
+		Network:       target.info.WalletNetwork,
+		RawTx:         req.SignedTx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.TxHash != "" {
+		txHash = result.TxHash
+	}
+
+	record, err := s.saveReplacementRecord(target.record, req.Kind)
+	if err != nil {
+		// 已广播，worker 仍会通过原记录看到 nonce 被占用，这里只记录错误
+		log.Error("Failed to save replacement record", "recordGuid", target.record.Guid, "txHash", txHash, "err", err)
+		return &backend.SubmitReplacementResponse{TxHash: txHash}, nil
+	}
+
+	// 取消交易本身不推进步骤；加速交易替换步骤上的 tx_hash
+	if _, err = s.statusEngine.ApplyTx(ctx, record, status.Transition{
+		To:       dbBackend.TxStatusPending,
+		Source:   status.SourceAPI,
+		Updates:  map[string]interface{}{"tx_id": txHash},
+		SkipStep: cancel,
+	}); err != nil {
+		log.Error("Failed to update replacement record to pending", "recordGuid", record.Guid, "txHash", txHash, "err", err)
+	}
+
+	log.Info("Replacement tx submitted", "kind", req.Kind, "original", target.record.TxID, "replacement", txHash, "operationID", record.OperationID, "stepIndex", record.StepIndex)
+	return &backend.SubmitReplacementResponse{RecordGuid: record.Guid, TxHash: txHash}, nil
+}
+
+// loadReplaceTarget returns the record to replace if it is pending, the latest tx of its
+// replace group, and still waiting in the mempool
+func (s *AggregatorService) loadReplaceTarget(ctx context.Context, recordGuid, kind string) (*replaceTarget, error) {
+	if kind != dbBackend.ReplaceKindSpeedUp && kind != dbBackend.ReplaceKindCancel {
+		return nil, fmt.Errorf("invalid replace kind: %s", kind)
+	}
+	if s.db == nil || s.evmCaller == nil {
+		return nil, fmt.Errorf("tx replacement not available")
+	}
+
+	record, err := s.db.BackendWalletTxRecord.GetByGuid(recordGuid)
+	if err != nil {
+		return nil, fmt.Errorf("tx record not found: %w", err)
+	}
+	if record.Status != dbBackend.TxStatusPending || record.TxID == "" {
+		return nil, fmt.Errorf("tx is not pending: %s", backend.TxStatusNames[record.Status])
+	}
+	if record.ReplaceKind == dbBackend.ReplaceKindCancel {
+		return nil, fmt.Errorf("tx is already a cancel")
+	}
+	if record.ReplaceGroup != "" {
+		group, err := s.db.BackendWalletTxRecord.GetByReplaceGroup(record.ReplaceGroup)
+		if err != nil {
+			return nil, err
+		}
+		// 只能替换最新的一笔，否则同组内费用无法保证递增
+		if latest := group[len(group)-1]; latest.Guid != record.Guid {
+			return nil, fmt.Errorf("tx already replaced by %s", latest.TxID)
+		}
+	}
+
+	info, err := s.getChainInfo(ctx, record.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	if info.ChainType != "" && !strings.EqualFold(info.ChainType, string(backend.ChainTypeEVM)) {
+		return nil, fmt.Errorf("tx replacement is only supported on EVM chains")
+	}
+
+	original, pending, err := s.evmCaller.GetTransaction(ctx, record.ChainID, record.TxID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tx %s: %w", record.TxID, err)
+	}
+	if !pending {
+		return nil, fmt.Errorf("tx %s is no longer pending", record.TxID)
+	}
+	// 合约创建交易没有 to，加速时无法复用调用目标，只能取消
+	if original.To() == nil && kind == dbBackend.ReplaceKindSpeedUp {
+		return nil, fmt.Errorf("tx %s creates a contract and cannot be sped up, cancel it instead", record.TxID)
+	}
+
+	return &replaceTarget{record: record, original: original, info: info}, nil
+}
+
+// saveReplacementRecord stores the CREATED record of a replacement tx, linking it and the
+// replaced record through replace_group (the guid of the first tx)
+func (s *AggregatorService) saveReplacementRecord(replaced *dbBackend.WalletTxRecord, kind string) (*dbBackend.WalletTxRecord, error) {
+	group := replaced.ReplaceGroup
+	if group == "" {
+		group = replaced.Guid
+		if err := s.db.BackendWalletTxRecord.UpdateWalletTxRecord(replaced.Guid, map[string]interface{}{"replace_group": group}); err != nil {
+			return nil, err
+		}
+	}
+
+	record := &dbBackend.WalletTxRecord{
+		Guid:         uuid.New().String(),
+		OperationID:  replaced.OperationID,
+		StepIndex:    replaced.StepIndex,
+		WalletUUID:   replaced.WalletUUID,
+		AddressUUID:  replaced.AddressUUID,
+		TxTime:       time.Now().Format(time.RFC3339),
+		ChainID:      replaced.ChainID,
+		TokenID:      replaced.TokenID,
+		FromAddress:  replaced.FromAddress,
+		ToAddress:    replaced.ToAddress,
+		Amount:       replaced.Amount,
+		Memo:         replaced.Memo,
+		TxType:       replaced.TxType,
+		DestChainID:  replaced.DestChainID,
+		Status:       dbBackend.TxStatusCreated,
+		ReplaceGroup: group,
+		ReplaceKind:  kind,
+	}
+	if kind == dbBackend.ReplaceKindCancel {
+		record.ToAddress = replaced.FromAddress
+		record.Amount = "0"
+		record.Memo = fmt.Sprintf("cancel %s", replaced.TxID)
+		record.TxType = dbBackend.ReplaceKindCancel
+		record.DestChainID = ""
+	}
+
+	if err := s.db.BackendWalletTxRecord.StoreWalletTxRecord(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
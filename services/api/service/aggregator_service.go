@@ -2,9 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
-	"sort"
 	"strings"
 	"time"
 
@@ -20,33 +20,49 @@ import (
 	"github.com/roothash-pay/wallet-services/config"
 	"github.com/roothash-pay/wallet-services/database"
 	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/metrics"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/fee"
 	"github.com/roothash-pay/wallet-services/services/api/aggregator/provider"
 	"github.com/roothash-pay/wallet-services/services/api/aggregator/provider/jupiter"
 	"github.com/roothash-pay/wallet-services/services/api/aggregator/provider/lifi"
 	"github.com/roothash-pay/wallet-services/services/api/aggregator/provider/oneinch"
 	"github.com/roothash-pay/wallet-services/services/api/aggregator/provider/zerox"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/ranking"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/status"
 	"github.com/roothash-pay/wallet-services/services/api/aggregator/store"
 	"github.com/roothash-pay/wallet-services/services/api/aggregator/utils"
 	"github.com/roothash-pay/wallet-services/services/api/models/backend"
 
 	"github.com/roothash-pay/wallet-services/services/common/chaininfo"
 	"github.com/roothash-pay/wallet-services/services/grpc_client/account"
+	"github.com/roothash-pay/wallet-services/services/market/cache"
+	marketService "github.com/roothash-pay/wallet-services/services/market/service"
 )
 
 // AggregatorService handles swap aggregation operations
 type AggregatorService struct {
 	providers     []provider.Provider
+	router        *provider.Router
+	allowance     *utils.AllowanceChecker
+	evmCaller     *utils.EVMCaller
+	solanaCaller  *utils.SolanaCaller
+	fees          *fee.Service // 可选，nil 时 swap 计划不带费用建议
+	ranker        *ranking.Engine
+	providerStats *ranking.ProviderStats
 	quoteStore    store.QuoteStore
 	swapStore     store.SwapStore
 	validator     *utils.Validator
 	accountClient *account.WalletAccountClient
 	db            *database.DB
 	quoteTTL      time.Duration
+	refreshWindow time.Duration // 报价过期后仍可 refresh 的时长
+	driftBps      int           // refresh 允许的最大输出下降（bps）
 	chainInfo     chaininfo.Provider
+	statusEngine  *status.Engine
 }
 
 // initAggregatorService initializes the aggregator service with all dependencies
-func InitAggregatorService(db *database.DB, cfg *config.Config) (*AggregatorService, error) {
+func InitAggregatorService(db *database.DB, cfg *config.Config, aggregatorMetrics metrics.AggregatorMetricer) (*AggregatorService, error) {
 	// Skip initialization if wallet account address is not configured
 	if cfg.AggregatorConfig.WalletAccountAddr == "" {
 		log.Warn("Aggregator service not initialized: wallet_account_addr not configured")
@@ -64,16 +80,21 @@ func InitAggregatorService(db *database.DB, cfg *config.Config) (*AggregatorServ
 
 	// Initialize 0x provider if enabled
 	if cfg.AggregatorConfig.EnableProviders["0x"] && cfg.AggregatorConfig.ZeroXAPIURL != "" {
-		zeroXProvider := zerox.NewProvider(cfg.AggregatorConfig.ZeroXAPIURL, cfg.AggregatorConfig.ZeroXAPIKey)
+		zeroXProvider := zerox.NewProvider(cfg.AggregatorConfig.ZeroXAPIURL, cfg.AggregatorConfig.ZeroXAPIKey, zerox.FeeConfig{
+			Recipient:        cfg.AggregatorConfig.ZeroXFeeRecipient,
+			Bps:              cfg.AggregatorConfig.ZeroXFeeBps,
+			Token:            cfg.AggregatorConfig.ZeroXFeeToken,
+			SurplusRecipient: cfg.AggregatorConfig.ZeroXSurplusRecipient,
+		}, cfg.AggregatorConfig.ZeroXUsePermit2)
 		providers = append(providers, zeroXProvider)
-		log.Info("0x provider initialized", "url", cfg.AggregatorConfig.ZeroXAPIURL)
+		log.Info("0x provider initialized", "url", cfg.AggregatorConfig.ZeroXAPIURL, "permit2", cfg.AggregatorConfig.ZeroXUsePermit2, "feeBps", cfg.AggregatorConfig.ZeroXFeeBps)
 	}
 
-	// Initialize 1inch provider if enabled
-	if cfg.AggregatorConfig.EnableProviders["1inch"] && cfg.AggregatorConfig.OneInchAPIURL != "" {
-		oneInchProvider := oneinch.NewProvider(cfg.AggregatorConfig.OneInchAPIURL, cfg.AggregatorConfig.OneInchAPIKey)
-		providers = append(providers, oneInchProvider)
-		log.Info("1inch provider initialized", "url", cfg.AggregatorConfig.OneInchAPIURL)
+	// Initialize 1inch Solana provider if enabled
+	if cfg.AggregatorConfig.EnableProviders["1inch-solana"] && cfg.AggregatorConfig.OneInchSolanaAPIURL != "" {
+		oneInchSolanaProvider := oneinch.NewSolanaProvider(cfg.AggregatorConfig.OneInchSolanaAPIURL, cfg.AggregatorConfig.OneInchAPIKey)
+		providers = append(providers, oneInchSolanaProvider)
+		log.Info("1inch Solana provider initialized", "url", cfg.AggregatorConfig.OneInchSolanaAPIURL)
 	}
 
 	// Initialize Jupiter provider if enabled
@@ -93,7 +114,7 @@ func InitAggregatorService(db *database.DB, cfg *config.Config) (*AggregatorServ
 		}
 		log.Info("Redis client initialized", "addr", cfg.RedisConfig.Addr)
 	} else {
-		log.Warn("Redis not configured, using in-memory storage (not recommended for production)")
+		log.Warn("Redis not configured, swap and quote reads go straight to the database")
 	}
 
 	// Initialize chain metadata cache
@@ -110,6 +131,13 @@ func InitAggregatorService(db *database.DB, cfg *config.Config) (*AggregatorServ
 	// Create EVM caller for contract interactions
 	evmCaller := utils.NewEVMCaller(accountClient, chainInfoManager)
 
+	// Initialize 1inch provider if enabled
+	if cfg.AggregatorConfig.EnableProviders["1inch"] && cfg.AggregatorConfig.OneInchAPIURL != "" {
+		oneInchProvider := oneinch.NewProvider(cfg.AggregatorConfig.OneInchAPIURL, cfg.AggregatorConfig.OneInchAPIKey, cfg.AggregatorConfig.OneInchFusionAPIURL, evmCaller, cfg.AggregatorConfig.OneInchUsePermit2)
+		providers = append(providers, oneInchProvider)
+		log.Info("1inch provider initialized", "url", cfg.AggregatorConfig.OneInchAPIURL, "fusion", cfg.AggregatorConfig.OneInchFusionAPIURL != "", "permit2", cfg.AggregatorConfig.OneInchUsePermit2)
+	}
+
 	// Initialize LiFi provider if enabled
 	if cfg.AggregatorConfig.EnableProviders["lifi"] && cfg.AggregatorConfig.LiFiAPIURL != "" {
 		lifiProvider := lifi.NewProvider(cfg.AggregatorConfig.LiFiAPIURL, cfg.AggregatorConfig.LiFiAPIKey, evmCaller)
@@ -122,21 +150,50 @@ func InitAggregatorService(db *database.DB, cfg *config.Config) (*AggregatorServ
 		return nil, nil
 	}
 
-	// Create cache stores
-	var quoteStore store.QuoteStore
-	var swapStore store.SwapStore
+	// Wrap providers with circuit breakers and per-provider quote deadlines
+	for i, p := range providers {
+		settings := provider.NewBreakerSettings(cfg.AggregatorConfig.Breaker, p.Name())
+		providers[i] = provider.NewBreakerProvider(p, settings, aggregatorMetrics)
+		log.Info("Provider circuit breaker enabled", "provider", p.Name(), "quoteTimeout", settings.QuoteTimeout)
+	}
+
+	// Create stores: PostgreSQL is the source of truth, Redis an optional write-through cache
+	var quoteStore store.QuoteStore = store.NewDBQuoteStore(db.BackendSwapQuote)
+	var swapStore store.SwapStore = store.NewDBSwapStore(db.BackendSwap)
 	if redisClient != nil {
-		quoteStore = store.NewRedisQuoteStore(redisClient.Client)
-		swapStore = store.NewRedisSwapStore(redisClient.Client)
-		log.Info("Using Redis-based storage")
+		quoteStore = store.NewCachedQuoteStore(quoteStore, store.NewRedisQuoteStore(redisClient.Client))
+		swapStore = store.NewCachedSwapStore(swapStore, store.NewRedisSwapStore(redisClient.Client))
+		log.Info("Using database storage with Redis cache")
 	} else {
-		quoteStore = store.NewInMemoryQuoteStore()
-		swapStore = store.NewInMemorySwapStore()
-		log.Warn("Cannot connect to Redis, using in-memory storage (data will be lost on restart)")
+		log.Info("Using database storage without cache")
 	}
 
 	// Create validator
-	validator := utils.NewValidator()
+	validator, err := utils.NewValidator(cfg.AggregatorConfig.Validator, chainInfoManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create validator: %w", err)
+	}
+
+	// Create quote ranking engine
+	var marketCache cache.Cache
+	if redisClient != nil {
+		marketCache = cache.NewRedisCache(redisClient.Client)
+	} else {
+		marketCache = cache.NewMemoryCache()
+	}
+	providerStats := ranking.NewProviderStats()
+	priceSource := ranking.NewMarketPriceSource(marketService.NewMarketService(marketCache), db.BackendToken, chainInfoManager)
+	feeService := fee.NewService(accountClient, chainInfoManager, priceSource, 0)
+	ranker, err := ranking.NewEngine(
+		cfg.AggregatorConfig.Ranking,
+		priceSource,
+		// 实时网络费用优先，链适配不可用时使用配置的 gas price
+		ranking.NewFallbackGasPriceSource(feeService, ranking.NewConfigGasPriceSource(cfg.AggregatorConfig.Ranking.Chains, chainInfoManager)),
+		providerStats,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ranking engine: %w", err)
+	}
 
 	// Create aggregator service
 	aggregatorService := NewAggregatorService(
@@ -146,7 +203,11 @@ func InitAggregatorService(db *database.DB, cfg *config.Config) (*AggregatorServ
 		validator,
 		accountClient,
 		chainInfoManager,
+		ranker,
+		providerStats,
+		feeService,
 		db,
+		cfg.AggregatorConfig.Quote,
 	)
 
 	log.Info("Aggregator service initialized successfully", "providers", len(providers))
@@ -161,17 +222,57 @@ func NewAggregatorService(
 	validator *utils.Validator,
 	accountClient *account.WalletAccountClient,
 	chainInfo chaininfo.Provider,
+	ranker *ranking.Engine,
+	providerStats *ranking.ProviderStats,
+	fees *fee.Service,
 	db *database.DB,
+	quoteCfg config.QuoteConfig,
 ) *AggregatorService {
+	if ranker == nil {
+		// 未配置时按输出数量排序
+		var reliability ranking.ReliabilitySource
+		if providerStats != nil {
+			reliability = providerStats
+		}
+		ranker, _ = ranking.NewEngine(config.RankingConfig{}, nil, nil, reliability)
+	}
+	if quoteCfg.TTLSeconds <= 0 {
+		quoteCfg.TTLSeconds = 60 // Default 1 minutes
+	}
+	if quoteCfg.RefreshWindowSeconds <= 0 {
+		quoteCfg.RefreshWindowSeconds = 600
+	}
+	if quoteCfg.DriftToleranceBps <= 0 {
+		quoteCfg.DriftToleranceBps = 50
+	}
+	var (
+		records dbBackend.WalletTxRecordDB
+		audit   dbBackend.TxStatusTransitionDB
+	)
+	if db != nil {
+		records = db.BackendWalletTxRecord
+		audit = db.BackendTxTransition
+	}
+	evmCaller := utils.NewEVMCaller(accountClient, chainInfo)
 	return &AggregatorService{
 		providers:     providers,
+		router:        provider.NewRouter(providers, chainInfo),
+		allowance:     utils.NewAllowanceChecker(evmCaller),
+		evmCaller:     evmCaller,
+		solanaCaller:  utils.NewSolanaCaller(chainInfo),
+		fees:          fees,
+		ranker:        ranker,
+		providerStats: providerStats,
 		quoteStore:    quoteStore,
 		swapStore:     swapStore,
 		validator:     validator,
 		accountClient: accountClient,
 		db:            db,
-		quoteTTL:      1 * time.Minute, // Default 1 minutes
+		quoteTTL:      time.Duration(quoteCfg.TTLSeconds) * time.Second,
+		refreshWindow: time.Duration(quoteCfg.RefreshWindowSeconds) * time.Second,
+		driftBps:      quoteCfg.DriftToleranceBps,
 		chainInfo:     chainInfo,
+		statusEngine:  status.NewEngine(records, audit, swapStore),
 	}
 }
 
@@ -185,31 +286,57 @@ func (s *AggregatorService) getChainInfo(ctx context.Context, chainID string) (*
 	return s.chainInfo.Get(ctx, chainID)
 }
 
+// selectedQuote 返回报价缓存中 index 处的报价；index 来自客户端或缓存，越界时返回错误而不是 panic
+func selectedQuote(cached *backend.QuoteStore, index int) (*backend.Quote, error) {
+	if index < 0 || index >= len(cached.BestQuotes) {
+		return nil, fmt.Errorf("invalid best quotes index: %d", index)
+	}
+	return cached.BestQuotes[index], nil
+}
+
+// loadSelectedQuote 读取报价缓存中用户选中的报价
+func (s *AggregatorService) loadSelectedQuote(ctx context.Context, quoteID string) (*backend.Quote, error) {
+	cached, err := s.quoteStore.Get(ctx, quoteID)
+	if err != nil {
+		return nil, fmt.Errorf("quote not found: %w", err)
+	}
+	return selectedQuote(cached, cached.BestQuotesIndex)
+}
+
 // GetQuotes aggregates quotes from multiple providers
 func (s *AggregatorService) GetQuotes(ctx context.Context, req *backend.QuoteRequest) (*backend.QuoteResponse, error) {
 	// TODO: 限流
+	// 排序策略在请求 provider 之前校验，非法值不触发外部调用
+	if _, err := s.ranker.Strategy(req); err != nil {
+		return nil, err
+	}
+
 	// Validate chain ID
-	if err := s.validator.ValidateChainID(req.FromChainID); err != nil {
+	if err := s.validator.ValidateChainID(ctx, req.FromChainID); err != nil {
 		return nil, err
 	}
+	if req.ToChainID != "" && req.ToChainID != req.FromChainID {
+		if err := s.validator.ValidateChainID(ctx, req.ToChainID); err != nil {
+			return nil, err
+		}
+	}
 
 	// Fetch quotes from all providers concurrently
-	quotes, err := s.aggregateQuotes(ctx, req)
+	quotes, providerResults, err := s.aggregateQuotes(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(quotes) == 0 {
-		return nil, fmt.Errorf("no quotes available")
+		return nil, fmt.Errorf("no quotes available: %s", formatProviderResults(providerResults))
 	}
 
-	// Sort quotes by toAmount (descending)
+	// Rank quotes with the selected strategy
 	// top quote is the best quote
-	sort.Slice(quotes, func(i, j int) bool {
-		amountI, _ := new(big.Int).SetString(quotes[i].ToAmount, 10)
-		amountJ, _ := new(big.Int).SetString(quotes[j].ToAmount, 10)
-		return amountI.Cmp(amountJ) > 0
-	})
+	strategy, err := s.ranker.Rank(ctx, req, quotes)
+	if err != nil {
+		return nil, err
+	}
 
 	// Prepare response
 	quoteID := uuid.New().String()
@@ -221,10 +348,11 @@ func (s *AggregatorService) GetQuotes(ctx context.Context, req *backend.QuoteReq
 		ExpiresAt:   expiresAt,
 		WalletUUID:  req.WalletUUID,
 		BestQuotes:  quotes,
+		Request:     req,
 	}
 
-	//  Cache store quote snapshot
-	if err = s.quoteStore.Save(ctx, quoteID, storeData, s.quoteTTL); err != nil {
+	//  Cache store quote snapshot（保留到 refresh 窗口结束，有效期以 ExpiresAt 为准）
+	if err = s.quoteStore.Save(ctx, quoteID, storeData, s.quoteRetention()); err != nil {
 		log.Error("Failed to cache quote", "err", err)
 	}
 
@@ -234,6 +362,8 @@ func (s *AggregatorService) GetQuotes(ctx context.Context, req *backend.QuoteReq
 		ExpiresAt:   expiresAt,
 		WalletUUID:  req.WalletUUID,
 		BestQuotes:  quotes,
+		Providers:   providerResults,
+		Ranking:     strategy,
 	}
 
 	for _, v := range response.BestQuotes {
@@ -243,21 +373,44 @@ func (s *AggregatorService) GetQuotes(ctx context.Context, req *backend.QuoteReq
 	return response, nil
 }
 
-// aggregateQuotes fetches quotes from all providers concurrently
-func (s *AggregatorService) aggregateQuotes(ctx context.Context, req *backend.QuoteRequest) ([]*backend.Quote, error) {
+// aggregateQuotes fetches quotes concurrently from the providers that support the request's chain
+// and returns the per-provider outcome (including skipped/unsupported ones) alongside the quotes
+func (s *AggregatorService) aggregateQuotes(ctx context.Context, req *backend.QuoteRequest) ([]*backend.Quote, []*backend.ProviderResult, error) {
+	providers, skipped := s.router.Route(ctx, req)
+	for _, r := range skipped {
+		log.Debug("Provider skipped", "provider", r.Provider, "status", r.Status, "reason", r.Reason)
+	}
+
 	g, ctx := errgroup.WithContext(ctx)
-	quoteChan := make(chan *backend.Quote, len(s.providers))
+	quoteChan := make(chan *backend.Quote, len(providers))
+	resultChan := make(chan *backend.ProviderResult, len(providers))
 
-	// TODO: Filter providers based on chain type，比如evm 排除 solana的provider
-	for _, provider := range s.providers {
-		p := provider // Capture loop variable
+	for _, prov := range providers {
+		p := prov // Capture loop variable
 		g.Go(func() error {
 			quote, err := p.GetQuote(ctx, req)
+			if errors.Is(err, provider.ErrCircuitOpen) {
+				resultChan <- &backend.ProviderResult{
+					Provider: p.Name(),
+					Status:   backend.ProviderResultCircuitOpen,
+					Reason:   err.Error(),
+				}
+				return nil
+			}
 			if err != nil {
 				log.Warn("Provider failed", "provider", p.Name(), "err", err)
+				resultChan <- &backend.ProviderResult{
+					Provider: p.Name(),
+					Status:   backend.ProviderResultFailed,
+					Reason:   err.Error(),
+				}
 				return nil // Don't fail entire aggregation
 			}
 			quoteChan <- quote
+			resultChan <- &backend.ProviderResult{
+				Provider: p.Name(),
+				Status:   backend.ProviderResultOK,
+			}
 			return nil
 		})
 	}
@@ -265,6 +418,7 @@ func (s *AggregatorService) aggregateQuotes(ctx context.Context, req *backend.Qu
 	// Wait for all providers
 	_ = g.Wait()
 	close(quoteChan)
+	close(resultChan)
 
 	// Collect successful quotes
 	var quotes []*backend.Quote
@@ -272,7 +426,48 @@ func (s *AggregatorService) aggregateQuotes(ctx context.Context, req *backend.Qu
 		quotes = append(quotes, quote)
 	}
 
-	return quotes, nil
+	results := make([]*backend.ProviderResult, 0, len(providers)+len(skipped))
+	for r := range resultChan {
+		if s.providerStats != nil && r.Status != backend.ProviderResultCircuitOpen {
+			s.providerStats.Record(r.Provider, r.Status == backend.ProviderResultOK)
+		}
+		results = append(results, r)
+	}
+	results = append(results, skipped...)
+
+	return quotes, results, nil
+}
+
+// ProviderHealth returns the circuit breaker state and health stats of every provider
+func (s *AggregatorService) ProviderHealth() []*backend.ProviderHealth {
+	health := make([]*backend.ProviderHealth, 0, len(s.providers))
+	for _, p := range s.providers {
+		if b, ok := p.(*provider.BreakerProvider); ok {
+			health = append(health, b.Health())
+			continue
+		}
+		health = append(health, &backend.ProviderHealth{
+			Provider: p.Name(),
+			State:    backend.CircuitClosed,
+		})
+	}
+	return health
+}
+
+// formatProviderResults renders provider outcomes for error messages
+func formatProviderResults(results []*backend.ProviderResult) string {
+	if len(results) == 0 {
+		return "no provider available"
+	}
+	parts := make([]string, 0, len(results))
+	for _, r := range results {
+		if r.Reason == "" {
+			parts = append(parts, fmt.Sprintf("%s: %s", r.Provider, r.Status))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s (%s)", r.Provider, r.Status, r.Reason))
+	}
+	return strings.Join(parts, "; ")
 }
 
 // 前端获取报价后，用户接受该报价点击 swap，执行该方法
@@ -288,15 +483,19 @@ func (s *AggregatorService) PrepareSwap(ctx context.Context, quoteID string, bes
 		return nil, fmt.Errorf("quote expired")
 	}
 
+	// 先校验索引再写缓存，越界的索引不会被持久化
+	quote, err := selectedQuote(cachedQuote, bestQuotesIndex)
+	if err != nil {
+		return nil, err
+	}
+
 	// 更新缓存
 	cachedQuote.BestQuotesIndex = bestQuotesIndex
-	err = s.quoteStore.Update(ctx, quoteID, cachedQuote, s.quoteTTL)
+	err = s.quoteStore.Update(ctx, quoteID, cachedQuote, s.quoteRetention())
 	if err != nil {
 		return nil, fmt.Errorf("fail to update cache")
 	}
 
-	quote := cachedQuote.BestQuotes[bestQuotesIndex]
-
 	// Generate swap ID
 	swapID := uuid.New().String()
 
@@ -318,7 +517,20 @@ func (s *AggregatorService) PrepareSwap(ctx context.Context, quoteID string, bes
 		return nil, fmt.Errorf("failed to build swap: %w", err)
 	}
 
-	actions := buildResp.Actions
+	// 校验 provider 返回的交易：router/spender 白名单、金额上限
+	if err = s.validateActions(quote, cachedQuote.UserAddress, buildResp.Actions); err != nil {
+		log.Error("Provider returned tx rejected by validator", "provider", quote.Provider, "chainID", quote.ChainID, "err", err)
+		return nil, fmt.Errorf("swap plan rejected: %w", err)
+	}
+
+	// 已有足够授权时去掉 approve 步骤，避免用户多发一笔交易
+	actions := s.dropSatisfiedApprovals(ctx, quote, cachedQuote.UserAddress, buildResp.Actions)
+
+	// eth_call 模拟，交易会失败时提前告知用户
+	simulation := s.simulateActions(ctx, selectedProvider, quote, cachedQuote.UserAddress, actions)
+
+	// 附上 slow / normal / fast 费用建议，钱包无需自行估算
+	s.attachFees(ctx, quote, actions)
 
 	// Create swap record
 	swap := &backend.Swap{
@@ -348,18 +560,181 @@ func (s *AggregatorService) PrepareSwap(ctx context.Context, quoteID string, bes
 	}
 
 	return &backend.PrepareSwapResponse{
-		SwapID:  swapID,
-		Actions: actions,
+		SwapID:     swapID,
+		Actions:    actions,
+		Simulation: simulation,
 	}, nil
 }
 
+// validateActions checks the provider-built actions against the validator:
+// sell amount limit, for EVM txs the router/spender whitelist and native value limit,
+// for Solana txs the fee payer and program whitelist
+func (s *AggregatorService) validateActions(quote *backend.Quote, userAddress string, actions []*backend.Action) error {
+	if s.validator == nil {
+		return fmt.Errorf("validator not configured")
+	}
+
+	amount, ok := new(big.Int).SetString(quote.FromAmount, 10)
+	if !ok {
+		return fmt.Errorf("invalid quote amount: %s", quote.FromAmount)
+	}
+	if err := s.validator.ValidateTokenAmount(quote.ChainID, quote.FromToken, amount); err != nil {
+		return err
+	}
+
+	if quote.ChainType == backend.ChainTypeSolana {
+		return s.validateSolanaActions(quote, userAddress, actions)
+	}
+	if quote.ChainType != backend.ChainTypeEVM {
+		return nil
+	}
+
+	for i, action := range actions {
+		sp := action.SigningPayload
+		if sp != nil && sp.TypedData != nil {
+			if err := s.validateTypedData(quote, action); err != nil {
+				return fmt.Errorf("step %d: %w", i, err)
+			}
+			continue
+		}
+		if sp == nil || sp.To == "" {
+			continue
+		}
+
+		chainID := sp.ChainID
+		if chainID == "" {
+			chainID = quote.ChainID
+		}
+		data, err := hexutil.Decode(sp.Data)
+		if err != nil {
+			return fmt.Errorf("invalid data (step %d): %w", i, err)
+		}
+		valueStr, err := normalizeValue(sp.Value)
+		if err != nil {
+			return fmt.Errorf("invalid value (step %d): %w", i, err)
+		}
+		value, _ := new(big.Int).SetString(valueStr, 10)
+
+		if err = s.validateEVMCall(chainID, action.ActionType, sp.To, data, value); err != nil {
+			return fmt.Errorf("step %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateSignedTxPolicy re-checks the signed tx against the validator before broadcast
+func (s *AggregatorService) validateSignedTxPolicy(step *backend.Step, signedTxHex string) error {
+	if s.validator == nil {
+		return fmt.Errorf("validator not configured")
+	}
+	if step.ExpectedFeePayer != "" {
+		tx, err := utils.DecodeSolanaTx(signedTxHex)
+		if err != nil {
+			return err
+		}
+		return s.validator.ValidatePrograms(step.ExpectedChainID, tx.ProgramIDs)
+	}
+
+	rawBytes, err := hexutil.Decode(signedTxHex)
+	if err != nil {
+		return fmt.Errorf("invalid signedTx hex: %w", err)
+	}
+	var tx types.Transaction
+	if err = tx.UnmarshalBinary(rawBytes); err != nil {
+		return fmt.Errorf("failed to decode signed tx: %w", err)
+	}
+	if tx.To() == nil {
+		return fmt.Errorf("contract creation not allowed")
+	}
+
+	return s.validateEVMCall(step.ExpectedChainID, step.ActionType, tx.To().Hex(), tx.Data(), tx.Value())
+}
+
+// validateEVMCall approve 校验 spender，其余交易校验 router；都校验原生币金额
+func (s *AggregatorService) validateEVMCall(chainID string, actionType backend.ActionType, to string, data []byte, value *big.Int) error {
+	if actionType == backend.ActionTypeApprove {
+		spender, _, err := utils.DecodeApproveData(hexutil.Encode(data))
+		if err != nil {
+			return err
+		}
+		if err = s.validator.ValidateSpender(chainID, spender); err != nil {
+			return err
+		}
+	} else if err := s.validator.ValidateRouter(chainID, to); err != nil {
+		return err
+	}
+
+	return s.validator.ValidateValue(chainID, value)
+}
+
+// dropSatisfiedApprovals removes EVM approve actions whose spender already has enough allowance
+// for the quoted amount. If the allowance can't be queried the approve step is kept.
+func (s *AggregatorService) dropSatisfiedApprovals(ctx context.Context, quote *backend.Quote, userAddress string, actions []*backend.Action) []*backend.Action {
+	if quote.ChainType != backend.ChainTypeEVM || s.allowance == nil {
+		return actions
+	}
+
+	required, ok := new(big.Int).SetString(quote.FromAmount, 10)
+	if !ok {
+		log.Warn("Invalid quote amount, keep approve steps", "fromAmount", quote.FromAmount)
+		return actions
+	}
+
+	filtered := make([]*backend.Action, 0, len(actions))
+	for _, action := range actions {
+		if action.ActionType != backend.ActionTypeApprove || action.SigningPayload == nil {
+			filtered = append(filtered, action)
+			continue
+		}
+
+		spender, _, err := utils.DecodeApproveData(action.SigningPayload.Data)
+		if err != nil {
+			if quote.Spender == "" {
+				log.Warn("Unable to resolve approve spender, keep approve step", "err", err)
+				filtered = append(filtered, action)
+				continue
+			}
+			spender = quote.Spender
+		}
+
+		token := action.SigningPayload.To
+		allowance, sufficient, err := s.allowance.CheckAllowance(ctx, quote.ChainID, token, userAddress, spender, required)
+		if err != nil {
+			log.Warn("Failed to check allowance, keep approve step", "token", token, "spender", spender, "err", err)
+			filtered = append(filtered, action)
+			continue
+		}
+		if !sufficient {
+			filtered = append(filtered, action)
+			continue
+		}
+
+		log.Info("Allowance sufficient, skip approve step", "token", token, "spender", spender, "allowance", allowance.String(), "required", required.String())
+	}
+
+	return filtered
+}
+
 // SubmitSignedTx broadcasts a signed transaction
 func (s *AggregatorService) SubmitSignedTx(ctx context.Context, req *backend.SubmitSignedTxRequest) (*backend.SubmitSignedTxResponse, error) {
-	// Check idempotency
-	if txHash, exists := s.swapStore.CheckIdempotency(ctx, req.SwapID, req.StepIndex, req.IdempotencyKey); exists {
-		log.Info("Duplicate request detected", "swapID", req.SwapID, "stepIndex", req.StepIndex, "txHash", txHash)
-		return &backend.SubmitSignedTxResponse{TxHash: txHash}, nil
+	// Reserve the idempotency key so concurrent replicas do not broadcast the same tx twice
+	owner := uuid.New().String()
+	reservation, err := s.swapStore.ReserveIdempotency(ctx, req.SwapID, req.StepIndex, req.IdempotencyKey, owner, idempotencyLease)
+	if err != nil {
+		return nil, err
+	}
+	if !reservation.Reserved {
+		txHash, inFlight := s.awaitIdempotency(ctx, req.SwapID, req.StepIndex, req.IdempotencyKey, reservation)
+		log.Info("Duplicate request detected", "swapID", req.SwapID, "stepIndex", req.StepIndex, "txHash", txHash, "inFlight", inFlight)
+		return &backend.SubmitSignedTxResponse{TxHash: txHash, InFlight: inFlight}, nil
 	}
+	// 广播前失败则释放预占，允许用同一幂等键重试；广播后即使记录结果失败也保留到租约过期
+	broadcasted := false
+	defer func() {
+		if !broadcasted {
+			s.releaseIdempotency(req.SwapID, req.StepIndex, req.IdempotencyKey, owner)
+		}
+	}()
 
 	// Get swap
 	swap, err := s.swapStore.GetSwap(ctx, req.SwapID)
@@ -379,7 +754,10 @@ func (s *AggregatorService) SubmitSignedTx(ctx context.Context, req *backend.Sub
 	if err != nil {
 		return nil, fmt.Errorf("quote not found: %w", err)
 	}
-	quote := quoteResp.BestQuotes[quoteResp.BestQuotesIndex]
+	quote, err := selectedQuote(quoteResp, quoteResp.BestQuotesIndex)
+	if err != nil {
+		return nil, err
+	}
 	chainInfo, err := s.getChainInfo(ctx, quote.ChainID)
 	if err != nil {
 		return nil, err
@@ -389,10 +767,13 @@ func (s *AggregatorService) SubmitSignedTx(ctx context.Context, req *backend.Sub
 	if err = validateSignedTxAgainstStepExpected(req.SignedTx, step); err != nil {
 		return nil, fmt.Errorf("signed tx validation failed: %w", err)
 	}
+	if err = s.validateSignedTxPolicy(step, req.SignedTx); err != nil {
+		return nil, fmt.Errorf("signed tx validation failed: %w", err)
+	}
 
 	// 1: Save to database with CREATED status (before broadcast)
 	// This ensures we have a record even if broadcast fails
-	recordGuid := s.saveStepTxStatusCreated(ctx, swap, quote, req.StepIndex)
+	record := s.saveStepTxStatusCreated(ctx, swap, quote, req.StepIndex)
 
 	// 2: Broadcast transaction using SendTx
 	result, err := s.accountClient.SendTx(ctx, account.SendTxParams{
@@ -407,17 +788,24 @@ func (s *AggregatorService) SubmitSignedTx(ctx context.Context, req *backend.Sub
 		step.Status = backend.TxStatusFailed // 2 = FAILED
 		step.FailReasonCode = dbBackend.FailReasonBroadcastFailed
 		step.FailMessage = err.Error()
-		_ = s.swapStore.UpdateStep(ctx, req.SwapID, req.StepIndex, step)
+		if syncErr := s.statusEngine.SyncSwap(ctx, swap, status.SourceAPI); syncErr != nil {
+			log.Error("Failed to update swap", "swapID", req.SwapID, "err", syncErr)
+		}
 
 		// Update database record to FAILED
-		if recordGuid != "" {
-			s.updateStepTxStatusFailed(ctx, recordGuid, dbBackend.FailReasonBroadcastFailed, err.Error())
-		}
+		s.updateStepTxStatusFailed(ctx, record, dbBackend.FailReasonBroadcastFailed, err.Error())
 
 		return nil, err
 	}
 
 	txHash := result.TxHash
+	if txHash == "" && step.ExpectedFeePayer != "" {
+		// Solana 交易 id 即 fee payer 签名，广播前已确定
+		if tx, decodeErr := utils.DecodeSolanaTx(req.SignedTx); decodeErr == nil {
+			txHash = tx.Signature()
+		}
+	}
+	broadcasted = true
 
 	// Update step
 	now := time.Now()
@@ -430,17 +818,18 @@ func (s *AggregatorService) SubmitSignedTx(ctx context.Context, req *backend.Sub
 		log.Error("Failed to update step", "err", err)
 	}
 
-	// Record idempotency
-	_ = s.swapStore.RecordIdempotency(ctx, req.SwapID, req.StepIndex, req.IdempotencyKey, txHash)
+	// Record idempotency (commits the reservation)
+	if err := s.swapStore.RecordIdempotency(ctx, req.SwapID, req.StepIndex, req.IdempotencyKey, txHash); err != nil {
+		log.Error("Failed to record idempotency", "swapID", req.SwapID, "stepIndex", req.StepIndex, "err", err)
+	}
 
 	// Update swap status
-	swap.Status = backend.TxStatusPending // 1 = PENDING
-	_ = s.swapStore.UpdateSwap(ctx, swap)
+	if err := s.statusEngine.ApplySwap(ctx, swap, backend.TxStatusPending, status.SourceAPI); err != nil {
+		log.Error("Failed to update swap status", "swapID", req.SwapID, "err", err)
+	}
 
 	// 3: Update database record to PENDING (after successful broadcast)
-	if recordGuid != "" {
-		s.updateStepTxStatusPending(ctx, recordGuid, quote.ChainID, txHash)
-	}
+	s.updateStepTxStatusPending(ctx, record, txHash)
 
 	return &backend.SubmitSignedTxResponse{TxHash: txHash}, nil
 }
@@ -468,7 +857,22 @@ func fillExpectedFromSigningPayload(step *backend.Step, sp *backend.SigningPaylo
 	if sp == nil {
 		return nil
 	}
-	// 只对 EVM tx 做 expected（Solana 走 SerializedTx 另外处理）
+	// 链下签名订单：保存待签名数据，expected 为 EIP-712 digest
+	if sp.TypedData != nil {
+		digest, err := utils.TypedDataDigest(sp.TypedData)
+		if err != nil {
+			return fmt.Errorf("invalid signing payload typed data: %w", err)
+		}
+		step.TypedData = sp.TypedData
+		step.OrderData = sp.OrderData
+		step.ExpectedChainID = sp.ChainID
+		step.ExpectedDataHash = digest.Hex()
+		return nil
+	}
+	if sp.SerializedTx != "" {
+		return fillSolanaExpected(step, sp)
+	}
+	// EVM tx
 	if sp.To == "" || sp.Data == "" || sp.ChainID == "" {
 		return nil
 	}
@@ -491,6 +895,10 @@ func fillExpectedFromSigningPayload(step *backend.Step, sp *backend.SigningPaylo
 }
 
 func validateSignedTxAgainstStepExpected(signedTxHex string, step *backend.Step) error {
+	if step.ExpectedFeePayer != "" {
+		_, err := validateSolanaTxAgainstStep(signedTxHex, step)
+		return err
+	}
 	if step.ExpectedTo == "" || step.ExpectedDataHash == "" || step.ExpectedChainID == "" {
 		return fmt.Errorf("missing expected tx snapshot in step")
 	}
@@ -572,15 +980,21 @@ func (s *AggregatorService) SubmitTxHash(ctx context.Context, req *backend.Submi
 	_ = s.swapStore.UpdateStep(ctx, req.SwapID, req.StepIndex, step)
 
 	// 5) 更新 swap
-	swap.Status = backend.TxStatusPending
-	_ = s.swapStore.UpdateSwap(ctx, swap)
+	if err = s.statusEngine.ApplySwap(ctx, swap, backend.TxStatusPending, status.SourceAPI); err != nil {
+		log.Error("Failed to update swap status", "swapID", req.SwapID, "err", err)
+	}
 
 	// 6) 记录幂等
 	_ = s.swapStore.RecordIdempotency(ctx, req.SwapID, req.StepIndex, req.IdempotencyKey, req.TxHash)
 
-	// 7) 可选：写 wallet_tx_record（CREATED/PENDING）让 worker 接管后续状态
-	// recordGuid := s.saveStepTxStatusCreated(...)
-	// s.updateStepTxStatusPending(..., req.TxHash)
+	// 7) 写 wallet_tx_record（CREATED -> PENDING），由 worker 接管后续状态
+	quote, err := s.loadSelectedQuote(ctx, swap.QuoteID)
+	if err == nil {
+		record := s.saveStepTxStatusCreated(ctx, swap, quote, req.StepIndex)
+		s.updateStepTxStatusPending(ctx, record, req.TxHash)
+	} else {
+		log.Warn("Quote not available, skip saving step history", "swapID", req.SwapID, "err", err)
+	}
 
 	return &backend.SubmitTxHashResponse{TxHash: req.TxHash}, nil
 }
@@ -592,11 +1006,10 @@ func (s *AggregatorService) validateTxHashAgainstStep(
 	step *backend.Step,
 	txHash string,
 ) error {
-	quoteResp, err := s.quoteStore.Get(ctx, swap.QuoteID)
+	quote, err := s.loadSelectedQuote(ctx, swap.QuoteID)
 	if err != nil {
-		return fmt.Errorf("quote not found: %w", err)
+		return err
 	}
-	quote := quoteResp.BestQuotes[quoteResp.BestQuotesIndex]
 
 	if swap == nil || step == nil || quote == nil {
 		return fmt.Errorf("nil swap/step/quote")
@@ -605,8 +1018,9 @@ func (s *AggregatorService) validateTxHashAgainstStep(
 		return fmt.Errorf("empty txHash")
 	}
 
-	// Step expected 必须存在（至少 to/value/chain）
-	if step.ExpectedTo == "" || step.ExpectedChainID == "" || step.ExpectedValueWei == "" {
+	// Step expected 必须存在（EVM 至少 to/value/chain，Solana 至少 fee payer/chain）
+	solana := step.ExpectedFeePayer != ""
+	if step.ExpectedChainID == "" || (!solana && (step.ExpectedTo == "" || step.ExpectedValueWei == "")) {
 		return fmt.Errorf("missing expected snapshot in step")
 	}
 
@@ -636,6 +1050,14 @@ func (s *AggregatorService) validateTxHashAgainstStep(
 		return fmt.Errorf("tx not found: %s", txHash)
 	}
 
+	// Solana: 签名即交易 id，只需确认由 prepare 下发交易的 fee payer 签出（地址区分大小写）
+	if solana {
+		if txInfo.From != step.ExpectedFeePayer {
+			return fmt.Errorf("fee payer mismatch: got %s want %s", txInfo.From, step.ExpectedFeePayer)
+		}
+		return nil
+	}
+
 	// 2) from 必须是 swap.UserAddress（关键：防别人塞入任意 txHash 污染你的 swap）
 	if swap.UserAddress != "" && !addrEq(txInfo.From, swap.UserAddress) {
 		return fmt.Errorf("from mismatch: got %s want %s", txInfo.From, swap.UserAddress)
@@ -697,103 +1119,57 @@ func (s *AggregatorService) GetSwapStatus(ctx context.Context, swapID string) (*
 		return nil, err
 	}
 
-	var statusChainInfo *chaininfo.Info
-	if quoteResp, err := s.quoteStore.Get(ctx, swap.QuoteID); err == nil && quoteResp != nil && quoteResp.BestQuotes[0] != nil {
-		if info, err := s.getChainInfo(ctx, quoteResp.BestQuotes[0].ChainID); err == nil {
-			statusChainInfo = info
-		} else {
-			log.Warn("Chain settings missing for swap, skip status refresh", "swapID", swapID, "chainID", quoteResp.BestQuotes[0].ChainID, "err", err)
-		}
-	} else if err != nil {
+	statusQuote, err := s.loadSelectedQuote(ctx, swap.QuoteID)
+	if err != nil {
 		log.Warn("Failed to load quote for swap status refresh", "swapID", swapID, "err", err)
 	}
 
-	// Query status for each submitted transaction
+	// 链上交易的结果由 worker 经状态引擎写回 swap；这里只需查询链下订单的成交状态
 	for _, step := range swap.Steps {
-		if step.TxHash != "" && step.Status == backend.TxStatusPending { // 1 = PENDING
-			if statusChainInfo == nil {
-				continue
-			}
-
-			txInfo, err := s.accountClient.GetTxByHash(
-				ctx,
-				statusChainInfo.ConsumerToken,
-				statusChainInfo.WalletChain,
-				statusChainInfo.WalletCoin,
-				statusChainInfo.WalletNetwork,
-				step.TxHash,
-			)
-			if err != nil {
-				log.Warn("Failed to get tx status", "txHash", step.TxHash, "err", err)
-				continue
-			}
-
-			// Update step based on status
-			if txInfo.Status == 3 { // TxStatus_Success
-				now := time.Now()
-				step.Status = backend.TxStatusSuccess // 3 = SUCCESS
-				step.ConfirmedAt = &now
-				_ = s.swapStore.UpdateStep(ctx, swapID, step.StepIndex, step)
-			} else if txInfo.Status == 2 { // TxStatus_Failed
-				step.Status = backend.TxStatusFailed // 2 = FAILED
-				step.FailReasonCode = "TX_FAILED"
-				step.FailMessage = "Transaction failed on chain"
-				_ = s.swapStore.UpdateStep(ctx, swapID, step.StepIndex, step)
-			}
+		if step.OrderHash != "" && step.Status == backend.TxStatusPending && statusQuote != nil {
+			s.refreshOrderStatus(ctx, swapID, statusQuote, step)
 		}
 	}
 
 	// Determine overall swap status
-	allSuccess := true
-	anyFailed := false
-	for _, step := range swap.Steps {
-		if step.Status != backend.TxStatusSuccess { // 3 = SUCCESS
-			allSuccess = false
-		}
-		if step.Status == backend.TxStatusFailed { // 2 = FAILED
-			anyFailed = true
-		}
-	}
-
 	previousStatus := swap.Status
-
-	if anyFailed {
-		swap.Status = backend.TxStatusFailed // 2 = FAILED
-	} else if allSuccess {
-		swap.Status = backend.TxStatusSuccess // 3 = SUCCESS
+	if err := s.statusEngine.SyncSwap(ctx, swap, status.SourceAPI); err != nil {
+		log.Warn("Failed to sync swap status", "swapID", swapID, "err", err)
 	}
 
-	_ = s.swapStore.UpdateSwap(ctx, swap)
-
 	// Update database status when swap status changes
-	if previousStatus != swap.Status && (swap.Status == backend.TxStatusSuccess || swap.Status == backend.TxStatusFailed) {
+	if previousStatus != swap.Status && status.IsFinal(swap.Status) {
 		s.updateSwapTxStatus(ctx, swap)
 	}
 
+	steps := make([]*backend.StepStatus, 0, len(swap.Steps))
+	for _, step := range swap.Steps {
+		steps = append(steps, &backend.StepStatus{
+			Step:       step,
+			FailReason: backend.NewFailReason(step.FailReasonCode, step.FailMessage),
+		})
+	}
+
 	return &backend.SwapStatusResponse{
 		SwapID:         swap.SwapID,
 		Status:         swap.Status,
-		Steps:          swap.Steps,
+		Steps:          steps,
 		FailReasonCode: swap.FailReasonCode,
 		FailMessage:    swap.FailMessage,
+		FailReason:     backend.NewFailReason(swap.FailReasonCode, swap.FailMessage),
 	}, nil
 }
 
 // saveStepTxStatusCreated saves a step to database with CREATED status (before broadcast)
-// Returns the record GUID for later updates
-func (s *AggregatorService) saveStepTxStatusCreated(ctx context.Context, swap *backend.Swap, quote *backend.Quote, stepIndex int) string {
+// Returns the record for later status transitions, nil if it was not saved
+func (s *AggregatorService) saveStepTxStatusCreated(ctx context.Context, swap *backend.Swap, quote *backend.Quote, stepIndex int) *dbBackend.WalletTxRecord {
 	// Skip if database is not available
 	if s.db == nil {
 		log.Warn("Database not available, skip saving step history", "swapID", swap.SwapID, "stepIndex", stepIndex)
-		return ""
-	}
-
-	// Validate wallet_uuid
-	if swap.WalletUUID == "" {
-		log.Warn("Wallet UUID not provided, skip saving step history", "swapID", swap.SwapID, "stepIndex", stepIndex)
-		return ""
+		return nil
 	}
 
+	// wallet_uuid 可为空：链上结果由 worker 根据该记录推进，没有记录的步骤无法确认
 	recordGuid := uuid.New().String()
 
 	// Use amount string directly (no conversion needed, supports uint256)
@@ -834,53 +1210,53 @@ func (s *AggregatorService) saveStepTxStatusCreated(ctx context.Context, swap *b
 		TxType:      txType,                    // Transaction type: approve, swap, bridge, wrap, unwrap
 		Status:      dbBackend.TxStatusCreated, // Status: CREATED (0)
 	}
+	if step.ActionType == backend.ActionTypeBridge {
+		record.DestChainID = quote.ToChainID // 供 worker 查询跨链状态
+	}
+	if step.ExpectedFeePayer != "" {
+		record.LastValidBlockHeight = s.solanaLastValidBlockHeight(ctx, quote.ChainID, step)
+	}
 
 	// Save to database
 	if err := s.db.BackendWalletTxRecord.StoreWalletTxRecord(record); err != nil {
 		log.Error("Failed to save created step history", "swapID", swap.SwapID, "stepIndex", stepIndex, "actionType", step.ActionType, "walletUUID", swap.WalletUUID, "err", err)
-		return ""
+		return nil
 	}
 
 	log.Info("Created step history saved", "swapID", swap.SwapID, "stepIndex", stepIndex, "actionType", step.ActionType, "recordGuid", recordGuid, "walletUUID", swap.WalletUUID, "status", "CREATED")
-	return recordGuid
+	return record
 }
 
-// updateStepTxStatusPending updates step history to PENDING status after successful broadcast
-func (s *AggregatorService) updateStepTxStatusPending(_ context.Context, recordGuid string, _ string, txHash string) {
-	if s.db == nil || recordGuid == "" {
+// updateStepTxStatusPending moves step history to PENDING after successful broadcast
+func (s *AggregatorService) updateStepTxStatusPending(ctx context.Context, record *dbBackend.WalletTxRecord, txHash string) {
+	if record == nil {
 		return
 	}
 
-	// Update record
-	updates := map[string]interface{}{
-		"hash":   txHash,
-		"status": dbBackend.TxStatusPending, // Status: PENDING (1)
-	}
-
-	if err := s.db.BackendWalletTxRecord.UpdateWalletTxRecord(recordGuid, updates); err != nil {
-		log.Error("Failed to update step history to pending", "recordGuid", recordGuid, "txHash", txHash, "err", err)
-	} else {
-		log.Info("Step history updated to pending", "recordGuid", recordGuid, "txHash", txHash, "status", "PENDING")
+	_, err := s.statusEngine.ApplyTx(ctx, record, status.Transition{
+		To:      dbBackend.TxStatusPending,
+		Source:  status.SourceAPI,
+		Updates: map[string]interface{}{"tx_id": txHash},
+	})
+	if err != nil {
+		log.Error("Failed to update step history to pending", "recordGuid", record.Guid, "txHash", txHash, "err", err)
 	}
 }
 
-// updateStepTxStatusFailed updates step history to FAILED status
-func (s *AggregatorService) updateStepTxStatusFailed(ctx context.Context, recordGuid string, failReasonCode string, failReasonMsg string) {
-	if s.db == nil || recordGuid == "" {
+// updateStepTxStatusFailed moves step history to FAILED
+func (s *AggregatorService) updateStepTxStatusFailed(ctx context.Context, record *dbBackend.WalletTxRecord, failReasonCode string, failReasonMsg string) {
+	if record == nil {
 		return
 	}
 
-	// Update record
-	updates := map[string]interface{}{
-		"status":           dbBackend.TxStatusFailed, // Status: FAILED (2)
-		"fail_reason_code": failReasonCode,
-		"fail_reason_msg":  failReasonMsg,
-	}
-
-	if err := s.db.BackendWalletTxRecord.UpdateWalletTxRecord(recordGuid, updates); err != nil {
-		log.Error("Failed to update step history to failed", "recordGuid", recordGuid, "err", err)
-	} else {
-		log.Info("Step history updated to failed", "recordGuid", recordGuid, "status", "FAILED", "reason", failReasonCode)
+	_, err := s.statusEngine.ApplyTx(ctx, record, status.Transition{
+		To:             dbBackend.TxStatusFailed,
+		Source:         status.SourceAPI,
+		FailReasonCode: failReasonCode,
+		FailReasonMsg:  failReasonMsg,
+	})
+	if err != nil {
+		log.Error("Failed to update step history to failed", "recordGuid", record.Guid, "err", err)
 	}
 }
 
@@ -892,13 +1268,11 @@ func (s *AggregatorService) updateSwapTxStatus(ctx context.Context, swap *backen
 	}
 
 	// Get quote information
-	quoteResp, err := s.quoteStore.Get(ctx, swap.QuoteID)
+	quote, err := s.loadSelectedQuote(ctx, swap.QuoteID)
 	if err != nil {
 		log.Warn("Failed to get quote for status update", "swapID", swap.SwapID, "err", err)
 		return
 	}
-
-	quote := quoteResp.BestQuotes[0]
 	var chainInfoForSwap *chaininfo.Info
 	if info, err := s.getChainInfo(ctx, quote.ChainID); err == nil {
 		chainInfoForSwap = info
@@ -909,12 +1283,16 @@ func (s *AggregatorService) updateSwapTxStatus(ctx context.Context, swap *backen
 	// Find the final swap transaction hash
 	var finalTxHash string
 	var finalBlockHeight string
+	var finalStep *backend.Step
+	finalStepIndex := -1
 	for i := len(swap.Steps) - 1; i >= 0; i-- {
-		if swap.Steps[i].ActionType == backend.ActionTypeSwap && swap.Steps[i].TxHash != "" {
+		if (swap.Steps[i].ActionType == backend.ActionTypeSwap || swap.Steps[i].ActionType == backend.ActionTypeBridge) && swap.Steps[i].TxHash != "" {
 			finalTxHash = swap.Steps[i].TxHash
+			finalStep = swap.Steps[i]
+			finalStepIndex = i
 
-			// Try to get block height from chain
-			if swap.Steps[i].Status == backend.TxStatusSuccess && chainInfoForSwap != nil { // 3 = SUCCESS
+			// Try to get block height from chain（PARTIAL / REFUNDED 的源链交易同样已上链）
+			if swap.Steps[i].Status != backend.TxStatusFailed && chainInfoForSwap != nil {
 				txInfo, err := s.accountClient.GetTxByHash(
 					ctx,
 					chainInfoForSwap.ConsumerToken,
@@ -938,7 +1316,7 @@ func (s *AggregatorService) updateSwapTxStatus(ctx context.Context, swap *backen
 
 	// Build updated memo and status based on final status
 	var memo string
-	var status int
+	var txStatus int
 	var failReasonCode string
 	var failReasonMsg string
 
@@ -950,7 +1328,7 @@ func (s *AggregatorService) updateSwapTxStatus(ctx context.Context, swap *backen
 			s.formatAmount(quote.ToAmount),
 			s.getTokenSymbol(quote.ToToken),
 		)
-		status = dbBackend.TxStatusSuccess // Status: SUCCESS (3)
+		txStatus = dbBackend.TxStatusSuccess // Status: SUCCESS (3)
 	} else if swap.Status == backend.TxStatusFailed { // 2 = FAILED
 		memo = fmt.Sprintf("Swap via %s: %s %s -> %s %s (Failed: %s)",
 			quote.Provider,
@@ -960,32 +1338,98 @@ func (s *AggregatorService) updateSwapTxStatus(ctx context.Context, swap *backen
 			s.getTokenSymbol(quote.ToToken),
 			swap.FailMessage,
 		)
-		status = dbBackend.TxStatusFailed // Status: FAILED (2)
+		txStatus = dbBackend.TxStatusFailed // Status: FAILED (2)
 		failReasonCode = swap.FailReasonCode
 		if failReasonCode == "" {
 			failReasonCode = dbBackend.FailReasonChainFailed
 		}
 		failReasonMsg = swap.FailMessage
+	} else if swap.Status == backend.TxStatusPartial { // 4 = PARTIAL
+		memo = fmt.Sprintf("Bridge via %s: %s %s -> %s %s (Partial: received %s %s)",
+			quote.Provider,
+			s.formatAmount(quote.FromAmount),
+			s.getTokenSymbol(quote.FromToken),
+			s.formatAmount(quote.ToAmount),
+			s.getTokenSymbol(quote.ToToken),
+			s.formatAmount(finalStep.ReceivedAmount),
+			s.getTokenSymbol(finalStep.ReceivedToken),
+		)
+		txStatus = dbBackend.TxStatusPartial // Status: PARTIAL (4)
+	} else if swap.Status == backend.TxStatusRefunded { // 5 = REFUNDED
+		memo = fmt.Sprintf("Bridge via %s: %s %s -> %s %s (Refunded)",
+			quote.Provider,
+			s.formatAmount(quote.FromAmount),
+			s.getTokenSymbol(quote.FromToken),
+			s.formatAmount(quote.ToAmount),
+			s.getTokenSymbol(quote.ToToken),
+		)
+		txStatus = dbBackend.TxStatusRefunded // Status: REFUNDED (5)
+		failReasonCode = dbBackend.FailReasonBridgeRefunded
+		failReasonMsg = finalStep.FailMessage
+	}
+
+	// 最终步骤的交易记录代表整个 swap
+	record := s.findStepTxRecord(swap.SwapID, finalStepIndex, finalTxHash)
+	if record == nil {
+		log.Warn("No tx record found for final swap step", "swapID", swap.SwapID, "stepIndex", finalStepIndex, "txHash", finalTxHash)
+		return
 	}
 
-	// Update the record using SwapID as the record GUID
 	updates := map[string]interface{}{
 		"memo":         memo,
 		"block_height": finalBlockHeight,
-		"status":       status,
 	}
 
-	// Add failure info if failed
-	if status == dbBackend.TxStatusFailed {
-		updates["fail_reason_code"] = failReasonCode
-		updates["fail_reason_msg"] = failReasonMsg
+	// 跨链步骤记录目标链结果
+	if finalStep.DestTxHash != "" {
+		updates["dest_chain_id"] = finalStep.DestChainID
+		updates["dest_tx_id"] = finalStep.DestTxHash
 	}
 
-	if err := s.db.BackendWalletTxRecord.UpdateWalletTxRecord(swap.SwapID, updates); err != nil {
-		log.Error("Failed to update swap history status", "swapID", swap.SwapID, "walletUUID", swap.WalletUUID, "err", err)
-	} else {
-		log.Info("Swap history status updated", "swapID", swap.SwapID, "walletUUID", swap.WalletUUID, "status", swap.Status, "statusName", dbBackend.TxStatusNames[status])
+	// 状态经状态引擎流转；swap 状态本身由步骤推导而来，不再回写步骤
+	changed, err := s.statusEngine.ApplyTx(ctx, record, status.Transition{
+		To:             txStatus,
+		Source:         status.SourceAPI,
+		FailReasonCode: failReasonCode,
+		FailReasonMsg:  failReasonMsg,
+		Updates:        updates,
+		SkipStep:       true,
+	})
+	if err != nil {
+		log.Error("Failed to update swap history status", "swapID", swap.SwapID, "walletUUID", swap.WalletUUID, "recordGuid", record.Guid, "err", err)
+		return
+	}
+	if !changed {
+		// 记录已由 worker 推进到该状态，只补写展示字段
+		if err := s.db.BackendWalletTxRecord.UpdateWalletTxRecord(record.Guid, updates); err != nil {
+			log.Error("Failed to update swap history memo", "swapID", swap.SwapID, "recordGuid", record.Guid, "err", err)
+			return
+		}
+	}
+	log.Info("Swap history status updated", "swapID", swap.SwapID, "walletUUID", swap.WalletUUID, "recordGuid", record.Guid, "status", swap.Status, "statusName", dbBackend.TxStatusNames[txStatus])
+}
+
+// findStepTxRecord returns the wallet_tx_record of a swap step, preferring the one carrying txHash
+func (s *AggregatorService) findStepTxRecord(swapID string, stepIndex int, txHash string) *dbBackend.WalletTxRecord {
+	records, err := s.db.BackendWalletTxRecord.GetByOperationID(swapID)
+	if err != nil {
+		log.Warn("Failed to load swap tx records", "swapID", swapID, "err", err)
+		return nil
+	}
+
+	var found *dbBackend.WalletTxRecord
+	for _, record := range records {
+		if record.StepIndex != stepIndex {
+			continue
+		}
+		if record.TxID == txHash {
+			return record
+		}
+		if found == nil {
+			found = record
+		}
 	}
+	return found
 }
 
 // parseAmount converts amount string to int64 (handles decimals by removing decimal point)
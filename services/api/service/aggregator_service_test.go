@@ -1,11 +1,21 @@
 package service
 
 import (
+	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/roothash-pay/wallet-services/config"
+	"github.com/roothash-pay/wallet-services/database"
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/ranking"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/status"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/store"
 	"github.com/roothash-pay/wallet-services/services/api/models/backend"
 )
 
@@ -149,3 +159,117 @@ func TestGetTokenSymbol(t *testing.T) {
 		})
 	}
 }
+
+// swapHistoryRecords keeps the tx records of one swap and the updates written to them
+type swapHistoryRecords struct {
+	dbBackend.WalletTxRecordDB
+	records     []*dbBackend.WalletTxRecord
+	transitions map[string]map[string]interface{}
+	updates     map[string]map[string]interface{}
+}
+
+func (m *swapHistoryRecords) GetByOperationID(operationID string) ([]*dbBackend.WalletTxRecord, error) {
+	return m.records, nil
+}
+
+func (m *swapHistoryRecords) TransitionWalletTxRecord(guid string, fromStatus int, updates map[string]interface{}) (bool, error) {
+	for _, r := range m.records {
+		if r.Guid == guid && r.Status == fromStatus {
+			m.transitions[guid] = updates
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *swapHistoryRecords) UpdateWalletTxRecord(guid string, updates map[string]interface{}) error {
+	m.updates[guid] = updates
+	return nil
+}
+
+func TestUpdateSwapTxStatusGoesThroughEngine(t *testing.T) {
+	ctx := context.Background()
+	records := &swapHistoryRecords{
+		records: []*dbBackend.WalletTxRecord{
+			{Guid: "approve", OperationID: "swap-1", StepIndex: 0, TxID: "0xapprove", Status: dbBackend.TxStatusSuccess},
+			{Guid: "swap-replaced", OperationID: "swap-1", StepIndex: 1, TxID: "0xold", Status: dbBackend.TxStatusFailed},
+			{Guid: "swap", OperationID: "swap-1", StepIndex: 1, TxID: "0xswap", Status: dbBackend.TxStatusPending},
+		},
+		transitions: map[string]map[string]interface{}{},
+		updates:     map[string]map[string]interface{}{},
+	}
+	quotes := store.NewInMemoryQuoteStore()
+	s := &AggregatorService{
+		db:           &database.DB{BackendWalletTxRecord: records},
+		quoteStore:   quotes,
+		statusEngine: status.NewEngine(records, nil, nil),
+	}
+	swap := &backend.Swap{
+		SwapID:  "swap-1",
+		QuoteID: "quote-1",
+		Status:  backend.TxStatusSuccess,
+		Steps: []*backend.Step{
+			{StepIndex: 0, ActionType: backend.ActionTypeApprove, TxHash: "0xapprove", Status: backend.TxStatusSuccess},
+			{StepIndex: 1, ActionType: backend.ActionTypeSwap, TxHash: "0xswap", Status: backend.TxStatusSuccess},
+		},
+	}
+
+	// 越界的 BestQuotesIndex 不写任何记录
+	require.NoError(t, quotes.Save(ctx, "quote-1", &backend.QuoteStore{
+		QuoteID:         "quote-1",
+		BestQuotesIndex: 2,
+		BestQuotes:      []*backend.Quote{{Provider: "0x"}, {Provider: "lifi"}},
+	}, time.Minute))
+	s.updateSwapTxStatus(ctx, swap)
+	assert.Empty(t, records.transitions)
+	assert.Empty(t, records.updates)
+
+	require.NoError(t, quotes.Update(ctx, "quote-1", &backend.QuoteStore{
+		QuoteID:         "quote-1",
+		BestQuotesIndex: 1,
+		BestQuotes:      []*backend.Quote{{Provider: "0x"}, {Provider: "lifi", FromAmount: "1", ToAmount: "2"}},
+	}, time.Minute))
+	s.updateSwapTxStatus(ctx, swap)
+
+	require.Contains(t, records.transitions, "swap")
+	assert.Equal(t, dbBackend.TxStatusSuccess, records.transitions["swap"]["status"])
+	assert.Contains(t, records.transitions["swap"]["memo"], "Swap via lifi")
+	assert.Empty(t, records.updates)
+}
+
+func TestGetQuotesRejectsUnknownStrategyBeforeFanOut(t *testing.T) {
+	ranker, err := ranking.NewEngine(config.RankingConfig{}, nil, nil, nil)
+	require.NoError(t, err)
+	// 未设置 validator 和 router：校验之后的任何调用都会 panic
+	s := &AggregatorService{ranker: ranker}
+
+	_, err = s.GetQuotes(context.Background(), &backend.QuoteRequest{
+		FromChainID:     "1",
+		RankingStrategy: "CHEAPEST",
+	})
+	assert.EqualError(t, err, "unsupported ranking strategy: CHEAPEST")
+}
+
+func TestPrepareSwapRejectsOutOfRangeIndex(t *testing.T) {
+	ctx := context.Background()
+	quotes := store.NewInMemoryQuoteStore()
+	s := &AggregatorService{quoteStore: quotes, quoteTTL: time.Minute}
+	require.NoError(t, quotes.Save(ctx, "quote-1", &backend.QuoteStore{
+		QuoteID:    "quote-1",
+		ExpiresAt:  time.Now().Add(time.Minute),
+		BestQuotes: []*backend.Quote{{Provider: "0x"}, {Provider: "lifi"}},
+	}, time.Minute))
+
+	for _, index := range []int{-1, 2} {
+		_, err := s.PrepareSwap(ctx, "quote-1", index)
+		assert.EqualError(t, err, fmt.Sprintf("invalid best quotes index: %d", index))
+	}
+
+	// 越界索引未写入缓存，其它读取方仍拿到原来选中的报价
+	cached, err := quotes.Get(ctx, "quote-1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, cached.BestQuotesIndex)
+	quote, err := s.loadSelectedQuote(ctx, "quote-1")
+	require.NoError(t, err)
+	assert.Equal(t, "0x", quote.Provider)
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/provider"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/utils"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// simulateActions dry-runs the EVM txs of a prepared plan from the user's address.
+// A tx that depends on an earlier approve or signature cannot be simulated against current
+// state and is skipped. Reverts count as provider failures in health scoring.
+func (s *AggregatorService) simulateActions(ctx context.Context, p provider.Provider, quote *backend.Quote, userAddress string, actions []*backend.Action) []*backend.SimulationResult {
+	if quote.ChainType != backend.ChainTypeEVM || s.evmCaller == nil {
+		return nil
+	}
+
+	var results []*backend.SimulationResult
+	blocked := ""
+	for i, action := range actions {
+		switch action.ActionType {
+		case backend.ActionTypeApprove:
+			blocked = "requires approval in an earlier step"
+			continue
+		case backend.ActionTypeSignOrder, backend.ActionTypeSignTypedData:
+			blocked = "requires signature in an earlier step"
+			continue
+		}
+
+		result := &backend.SimulationResult{StepIndex: i}
+		results = append(results, result)
+		sp := action.SigningPayload
+		switch {
+		case sp == nil || sp.To == "":
+			result.Verdict = backend.SimulationSkipped
+			result.Message = "tx is built after signature"
+			continue
+		case blocked != "":
+			result.Verdict = backend.SimulationSkipped
+			result.Message = blocked
+			continue
+		}
+
+		value := big.NewInt(0)
+		if sp.Value != "" {
+			v, err := normalizeWeiString(sp.Value)
+			if err != nil {
+				result.Verdict = backend.SimulationError
+				result.Message = err.Error()
+				continue
+			}
+			value = v
+		}
+
+		reason, err := s.evmCaller.SimulateCall(ctx, quote.ChainID, userAddress, sp.To, sp.Data, value)
+		switch {
+		case errors.Is(err, utils.ErrSimulationUnavailable):
+			result.Verdict = backend.SimulationSkipped
+			result.Message = err.Error()
+		case err != nil:
+			log.Warn("Swap simulation failed", "provider", quote.Provider, "chainID", quote.ChainID, "step", i, "err", err)
+			result.Verdict = backend.SimulationError
+			result.Message = err.Error()
+		case reason != nil:
+			result.Verdict = backend.SimulationReverted
+			result.ReasonCode = reason.Code
+			result.RevertReason = reason.Message
+			log.Warn("Swap simulation reverted", "provider", quote.Provider, "chainID", quote.ChainID, "step", i, "reasonCode", reason.Code, "reason", reason.Message)
+			s.reportSimulationFailure(p, reason)
+		default:
+			result.Verdict = backend.SimulationSuccess
+		}
+	}
+	return results
+}
+
+// reportSimulationFailure feeds a reverted simulation into the provider's breaker and reliability stats.
+// Reverts caused by the user's own balance or allowance are not the provider's fault and are ignored.
+func (s *AggregatorService) reportSimulationFailure(p provider.Provider, reason *utils.RevertReason) {
+	if reason.Code == utils.RevertInsufficientBalance || reason.Code == utils.RevertInsufficientAllowance {
+		return
+	}
+	if b, ok := p.(*provider.BreakerProvider); ok {
+		b.ReportFailure("simulate", fmt.Errorf("simulation reverted: %s", reason.Message))
+	}
+	if s.providerStats != nil {
+		s.providerStats.Record(p.Name(), false)
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/utils"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// validateSolanaActions checks provider-built Solana txs: the user pays the fee and signs,
+// and every invoked program is whitelisted
+func (s *AggregatorService) validateSolanaActions(quote *backend.Quote, userAddress string, actions []*backend.Action) error {
+	for i, action := range actions {
+		sp := action.SigningPayload
+		if sp == nil || sp.SerializedTx == "" {
+			continue
+		}
+		tx, err := utils.DecodeSolanaTx(sp.SerializedTx)
+		if err != nil {
+			return fmt.Errorf("step %d: %w", i, err)
+		}
+		if userAddress != "" && tx.FeePayer() != userAddress {
+			return fmt.Errorf("step %d: fee payer mismatch: got %s want %s", i, tx.FeePayer(), userAddress)
+		}
+		if err = s.validator.ValidatePrograms(quote.ChainID, tx.ProgramIDs); err != nil {
+			return fmt.Errorf("step %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// fillSolanaExpected 记录下发交易的 fee payer、program、blockhash 与 message 哈希，提交时逐项比对
+func fillSolanaExpected(step *backend.Step, sp *backend.SigningPayload) error {
+	tx, err := utils.DecodeSolanaTx(sp.SerializedTx)
+	if err != nil {
+		return fmt.Errorf("invalid signing payload serialized tx: %w", err)
+	}
+	step.ExpectedChainID = sp.ChainID
+	step.ExpectedFeePayer = tx.FeePayer()
+	step.ExpectedProgramIDs = tx.ProgramIDs
+	step.ExpectedBlockhash = tx.RecentBlockhash
+	step.ExpectedDataHash = crypto.Keccak256Hash(tx.Message).Hex()
+	step.LastValidBlockHeight = sp.LastValidBlockHeight
+	return nil
+}
+
+// validateSolanaTxAgainstStep checks that the signed tx is the one built at prepare: same fee payer,
+// programs, recent blockhash and message bytes (accounts, instruction data), fully signed. The wallet
+// may only add its signatures; a different blockhash would also move the expiry the tracker relies on.
+func validateSolanaTxAgainstStep(signedTx string, step *backend.Step) (*utils.SolanaTx, error) {
+	tx, err := utils.DecodeSolanaTx(signedTx)
+	if err != nil {
+		return nil, err
+	}
+	if tx.FeePayer() != step.ExpectedFeePayer {
+		return nil, fmt.Errorf("fee payer mismatch: got %s want %s", tx.FeePayer(), step.ExpectedFeePayer)
+	}
+	if tx.RecentBlockhash != step.ExpectedBlockhash {
+		return nil, fmt.Errorf("recent blockhash mismatch: got %s want %s", tx.RecentBlockhash, step.ExpectedBlockhash)
+	}
+	if len(tx.ProgramIDs) != len(step.ExpectedProgramIDs) {
+		return nil, fmt.Errorf("program ids mismatch: got %v want %v", tx.ProgramIDs, step.ExpectedProgramIDs)
+	}
+	for i, program := range tx.ProgramIDs {
+		if program != step.ExpectedProgramIDs[i] {
+			return nil, fmt.Errorf("program ids mismatch: got %v want %v", tx.ProgramIDs, step.ExpectedProgramIDs)
+		}
+	}
+	if step.ExpectedDataHash == "" {
+		return nil, fmt.Errorf("missing expected tx snapshot in step")
+	}
+	if got := crypto.Keccak256Hash(tx.Message).Hex(); got != step.ExpectedDataHash {
+		return nil, fmt.Errorf("message mismatch: got %s want %s", got, step.ExpectedDataHash)
+	}
+	if err = tx.VerifySignatures(); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// solanaLastValidBlockHeight provider 未给出最后有效高度时，以当前高度 + 150 作为上限：
+// blockhash 取自提交之前，真实的过期高度只会更早，worker 不会提前判失败
+func (s *AggregatorService) solanaLastValidBlockHeight(ctx context.Context, chainID string, step *backend.Step) int64 {
+	if step.LastValidBlockHeight > 0 {
+		return int64(step.LastValidBlockHeight)
+	}
+	if s.solanaCaller == nil {
+		return 0
+	}
+	height, err := s.solanaCaller.BlockHeight(ctx, chainID, utils.CommitmentConfirmed)
+	if err != nil {
+		log.Warn("Failed to get solana block height, blockhash expiry not tracked", "chainID", chainID, "err", err)
+		return 0
+	}
+	return int64(height) + utils.SolanaBlockhashValidity
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/utils"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
+)
+
+// solanaSwapTx serializes a v0 tx calling one program with one byte of instruction data; sign=false
+// leaves the fee payer signature empty as providers return it
+func solanaSwapTx(t *testing.T, key ed25519.PrivateKey, program string, blockhash byte, data byte, sign bool) string {
+	programKey, err := utils.DecodeBase58(program)
+	require.NoError(t, err)
+
+	var msg bytes.Buffer
+	msg.Write([]byte{0x80, 1, 0, 1, 2})
+	msg.Write(key.Public().(ed25519.PublicKey))
+	msg.Write(programKey)
+	msg.Write(bytes.Repeat([]byte{blockhash}, 32))
+	msg.Write([]byte{1, 1, 1, 0, 1, data}) // one instruction, payer account, 1 byte data
+	msg.WriteByte(0)                       // no lookup tables
+
+	sig := make([]byte, 64)
+	if sign {
+		sig = ed25519.Sign(key, msg.Bytes())
+	}
+	return base64.StdEncoding.EncodeToString(append(append([]byte{1}, sig...), msg.Bytes()...))
+}
+
+func TestSolanaSignedTxAgainstStep(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, 32))
+	const jupiter = "JUP6LkbZbjS1jKKwapdHNy74zcZ3tLUZoi5QNyVTaV4"
+
+	step := &backend.Step{StepIndex: 0, ActionType: backend.ActionTypeSwap}
+	require.NoError(t, fillExpectedFromSigningPayload(step, &backend.SigningPayload{
+		ChainID:              "solana",
+		SerializedTx:         solanaSwapTx(t, key, jupiter, 9, 0x2a, false),
+		LastValidBlockHeight: 280000150,
+	}))
+	assert.Equal(t, utils.EncodeBase58(key.Public().(ed25519.PublicKey)), step.ExpectedFeePayer)
+	assert.Equal(t, []string{jupiter}, step.ExpectedProgramIDs)
+	assert.Equal(t, utils.EncodeBase58(bytes.Repeat([]byte{9}, 32)), step.ExpectedBlockhash)
+	assert.Equal(t, uint64(280000150), step.LastValidBlockHeight)
+	assert.NotEmpty(t, step.ExpectedDataHash)
+	assert.Empty(t, step.ExpectedTo)
+
+	assert.NoError(t, validateSignedTxAgainstStepExpected(solanaSwapTx(t, key, jupiter, 9, 0x2a, true), step))
+
+	// unsigned, re-built with another blockhash, another program or another payer
+	assert.ErrorContains(t, validateSignedTxAgainstStepExpected(solanaSwapTx(t, key, jupiter, 9, 0x2a, false), step), "invalid signature")
+	assert.ErrorContains(t, validateSignedTxAgainstStepExpected(solanaSwapTx(t, key, jupiter, 8, 0x2a, true), step), "blockhash mismatch")
+	assert.ErrorContains(t, validateSignedTxAgainstStepExpected(solanaSwapTx(t, key, "11111111111111111111111111111111", 9, 0x2a, true), step), "program ids mismatch")
+	// same programs and blockhash but other instruction data (amounts, accounts)
+	assert.ErrorContains(t, validateSignedTxAgainstStepExpected(solanaSwapTx(t, key, jupiter, 9, 0x2b, true), step), "message mismatch")
+	other := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{2}, 32))
+	assert.ErrorContains(t, validateSignedTxAgainstStepExpected(solanaSwapTx(t, other, jupiter, 9, 0x2a, true), step), "fee payer mismatch")
+}
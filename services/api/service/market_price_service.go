@@ -2,15 +2,14 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/roothash-pay/wallet-services/database"
 	"github.com/roothash-pay/wallet-services/database/backend"
 	"github.com/roothash-pay/wallet-services/services/market/cache"
 	"github.com/roothash-pay/wallet-services/services/market/model"
+	marketService "github.com/roothash-pay/wallet-services/services/market/service"
 )
 
 type MarketPriceService interface {
@@ -19,6 +18,8 @@ type MarketPriceService interface {
 	GetByGuid(ctx context.Context, guid string) (*backend.MarketPrice, error)
 
 	GetPrice(ctx context.Context, symbol string) (*model.Quote, error)
+	GetPriceByTokenID(ctx context.Context, tokenID string) (*model.Quote, error)
+	GetPriceByContract(ctx context.Context, chainID, contract string) (*model.Quote, error)
 }
 
 type SetMarketPriceRequest struct {
@@ -34,12 +35,12 @@ type SetMarketPriceRequest struct {
 }
 
 type marketPriceService struct {
-	db    *database.DB
-	cache cache.Cache
+	db     *database.DB
+	market marketService.MarketService
 }
 
 func NewMarketPriceService(db *database.DB, cache cache.Cache) MarketPriceService {
-	return &marketPriceService{db: db, cache: cache}
+	return &marketPriceService{db: db, market: marketService.NewMarketService(cache)}
 }
 
 // 读缓存里的最新价格（只读，不裁决）
@@ -47,22 +48,23 @@ func (s *marketPriceService) GetPrice(
 	ctx context.Context,
 	symbol string,
 ) (*model.Quote, error) {
-	key := "price:" + strings.ToUpper(symbol)
+	return s.market.GetPrice(ctx, symbol)
+}
 
-	data, ok, err := s.cache.Get(ctx, key)
-	if err != nil {
-		return nil, err
-	}
-	if !ok {
-		return nil, nil // 或者自定义 ErrCacheMiss
+// 按 token guid 读缓存里的规范资产价格
+func (s *marketPriceService) GetPriceByTokenID(ctx context.Context, tokenID string) (*model.Quote, error) {
+	if tokenID == "" {
+		return nil, fmt.Errorf("token_id required")
 	}
+	return s.market.GetPriceByTokenID(ctx, tokenID)
+}
 
-	var quote model.Quote
-	if err := json.Unmarshal(data, &quote); err != nil {
-		return nil, err
+// 按链与合约地址读缓存里的价格
+func (s *marketPriceService) GetPriceByContract(ctx context.Context, chainID, contract string) (*model.Quote, error) {
+	if chainID == "" || contract == "" {
+		return nil, fmt.Errorf("chain_id and contract required")
 	}
-
-	return &quote, nil
+	return s.market.GetPriceByContract(ctx, chainID, contract)
 }
 
 func (s *marketPriceService) SetMarketPrice(
@@ -89,7 +91,7 @@ func (s *marketPriceService) SetMarketPrice(
 				"usd_price":    req.UsdPrice,
 				"market_cap":   req.MarketCap,
 				"liquidity":    req.Liquidity,
-				"24h_volume":   req.Volume24h,
+				"volume_24h":   req.Volume24h,
 				"price_change": req.PriceChange,
 				"ranking":      req.Ranking,
 				"updated_at":   time.Now(),
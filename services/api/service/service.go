@@ -120,7 +120,7 @@ func New(v *validator.Validator,
 		ChainService:             NewChainService(db),
 		TokenService:             NewTokenService(db),
 		ChainTokenService:        NewChainTokenService(db),
-		WalletService:            NewWalletService(db),
+		WalletService:            NewWalletService(db, common.NewSIWEVerifier(jwtSecret, domain)),
 		WalletAddressService:     NewWalletAddressService(db),
 		WalletAssetService:       NewWalletAssetService(db),
 		AssetAmountStatService:   NewAssetAmountStatService(db),
@@ -3,12 +3,17 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/roothash-pay/wallet-services/database"
 	"github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/common"
 )
 
+// walletTokenTTL 钱包令牌有效期，过期后客户端重新签名获取
+const walletTokenTTL = 24 * time.Hour
+
 type WalletService interface {
 	CreateWallet(ctx context.Context, req CreateWalletRequest) (*backend.Wallet, error)
 	UpdateWallet(ctx context.Context, req UpdateWalletRequest) error
@@ -22,6 +27,19 @@ type WalletService interface {
 	IsExistRawTx(rawTx string) bool
 	StoreRawTx(rawTx string, txHash string) error
 	QueryTxInfoByHash(txHash string) (*backend.QueueTx, error)
+	IssueWalletToken(ctx context.Context, req WalletTokenRequest) (*WalletTokenResponse, error)
+}
+
+// WalletTokenRequest 用钱包内任一地址的 SIWE 签名换取钱包令牌
+type WalletTokenRequest struct {
+	WalletUUID string `json:"wallet_uuid"`
+	Message    string `json:"message"`
+	Signature  string `json:"signature"`
+}
+
+type WalletTokenResponse struct {
+	Token     string `json:"token"`
+	ExpiresAt int64  `json:"expires_at"`
 }
 
 type CreateWalletRequest struct {
@@ -39,11 +57,12 @@ type UpdateWalletRequest struct {
 }
 
 type walletService struct {
-	db *database.DB
+	db           *database.DB
+	siweVerifier *common.SIWEVerifier
 }
 
-func NewWalletService(db *database.DB) WalletService {
-	return &walletService{db: db}
+func NewWalletService(db *database.DB, siweVerifier *common.SIWEVerifier) WalletService {
+	return &walletService{db: db, siweVerifier: siweVerifier}
 }
 
 func (s *walletService) CreateWallet(
@@ -129,3 +148,39 @@ func (s *walletService) StoreRawTx(rawTx string, txHash string) error {
 func (s *walletService) QueryTxInfoByHash(txHash string) (*backend.QueueTx, error) {
 	return s.db.QueneTxDB.QueryTxInfoByHash(txHash)
 }
+
+// IssueWalletToken 校验签名地址属于该钱包后签发绑定钱包的令牌，websocket 订阅钱包 / swap 推送时使用
+func (s *walletService) IssueWalletToken(
+	ctx context.Context,
+	req WalletTokenRequest,
+) (*WalletTokenResponse, error) {
+
+	if req.WalletUUID == "" || req.Message == "" || req.Signature == "" {
+		return nil, fmt.Errorf("wallet_uuid, message and signature required")
+	}
+	address, err := s.siweVerifier.VerifySignature(req.Message, req.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := s.db.BackendWalletAddress.GetByWalletUUID(req.WalletUUID)
+	if err != nil {
+		return nil, err
+	}
+	owned := false
+	for _, a := range addresses {
+		if strings.EqualFold(a.Address, address) {
+			owned = true
+			break
+		}
+	}
+	if !owned {
+		return nil, fmt.Errorf("address %s does not belong to wallet %s", address, req.WalletUUID)
+	}
+
+	token, err := s.siweVerifier.GenerateWalletJWT(address, req.WalletUUID, walletTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &WalletTokenResponse{Token: token, ExpiresAt: time.Now().Add(walletTokenTTL).Unix()}, nil
+}
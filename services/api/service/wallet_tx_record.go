@@ -8,13 +8,15 @@ import (
 
 	"github.com/roothash-pay/wallet-services/database"
 	"github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/status"
+	model "github.com/roothash-pay/wallet-services/services/api/models/backend"
 )
 
 type WalletTxRecordService interface {
 	CreateWalletTx(ctx context.Context, req CreateWalletTxRequest) (*backend.WalletTxRecord, error)
 	UpdateWalletTx(ctx context.Context, req UpdateWalletTxRequest) error
-	GetWalletTx(ctx context.Context, guid string) (*backend.WalletTxRecord, error)
-	GetByOperationID(ctx context.Context, operationID string) ([]*backend.WalletTxRecord, error)
+	GetWalletTx(ctx context.Context, guid string) (*model.WalletTxRecordResponse, error)
+	GetByOperationID(ctx context.Context, operationID string) ([]*model.WalletTxRecordResponse, error)
 }
 
 type CreateWalletTxRequest struct {
@@ -38,11 +40,15 @@ type UpdateWalletTxRequest struct {
 }
 
 type walletTxRecordService struct {
-	db *database.DB
+	db     *database.DB
+	engine *status.Engine
 }
 
 func NewWalletTxRecordService(db *database.DB) WalletTxRecordService {
-	return &walletTxRecordService{db: db}
+	return &walletTxRecordService{
+		db:     db,
+		engine: status.NewEngine(db.BackendWalletTxRecord, db.BackendTxTransition, nil),
+	}
 }
 
 func (s *walletTxRecordService) CreateWalletTx(
@@ -102,25 +108,29 @@ func (s *walletTxRecordService) UpdateWalletTx(
 	if err != nil {
 		return err
 	}
-	oldStatus := oldTx.Status
 
-	// 2. 更新交易本身
-	req.Updates["updated_at"] = time.Now()
-	if err := s.db.BackendWalletTxRecord.UpdateWalletTxRecord(
-		req.Guid,
-		req.Updates,
-	); err != nil {
-		return err
+	// 2. 本次更新没涉及 status，直接更新交易本身
+	newStatus, ok := statusValue(req.Updates["status"])
+	if !ok {
+		req.Updates["updated_at"] = time.Now()
+		return s.db.BackendWalletTxRecord.UpdateWalletTxRecord(
+			req.Guid,
+			req.Updates,
+		)
 	}
 
-	// 3. 判断：是否是「第一次成功」
-	newStatus, ok := req.Updates["status"].(int)
-	if !ok {
-		return nil // 本次更新没涉及 status，直接结束
+	// 3. 状态变化经由状态引擎校验并记录
+	delete(req.Updates, "status")
+	changed, err := s.engine.ApplyTx(ctx, oldTx, status.Transition{
+		To:      newStatus,
+		Source:  status.SourceAPI,
+		Updates: req.Updates,
+	})
+	if err != nil {
+		return err
 	}
 
-	if oldStatus == backend.TxStatusSuccess ||
-		newStatus != backend.TxStatusSuccess {
+	if !changed || newStatus != backend.TxStatusSuccess {
 		return nil // 不是第一次成功，不动余额
 	}
 
@@ -128,26 +138,49 @@ func (s *walletTxRecordService) UpdateWalletTx(
 	return s.applyTxToWalletAsset(ctx, oldTx)
 }
 
+// statusValue 读取 updates 中的 status，JSON 解码后为 float64
+func statusValue(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
 func (s *walletTxRecordService) GetWalletTx(
 	ctx context.Context,
 	guid string,
-) (*backend.WalletTxRecord, error) {
+) (*model.WalletTxRecordResponse, error) {
 
 	if guid == "" {
 		return nil, fmt.Errorf("guid required")
 	}
-	return s.db.BackendWalletTxRecord.GetByGuid(guid)
+	record, err := s.db.BackendWalletTxRecord.GetByGuid(guid)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewWalletTxRecordResponse(record), nil
 }
 
 func (s *walletTxRecordService) GetByOperationID(
 	ctx context.Context,
 	operationID string,
-) ([]*backend.WalletTxRecord, error) {
+) ([]*model.WalletTxRecordResponse, error) {
 
 	if operationID == "" {
 		return nil, fmt.Errorf("operation_id required")
 	}
-	return s.db.BackendWalletTxRecord.GetByOperationID(operationID)
+	records, err := s.db.BackendWalletTxRecord.GetByOperationID(operationID)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*model.WalletTxRecordResponse, 0, len(records))
+	for _, record := range records {
+		list = append(list, model.NewWalletTxRecordResponse(record))
+	}
+	return list, nil
 }
 
 func (s *walletTxRecordService) applyTxToWalletAsset(
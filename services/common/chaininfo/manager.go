@@ -33,6 +33,9 @@ type Info struct {
 	ConsumerToken string `json:"consumer_token"`
 	RPCURL        string `json:"rpc_url"`
 	IsEnabled     bool   `json:"is_enabled"`
+	Confirmations int    `json:"confirmations"` // 链配置的确认数，0 使用 worker 默认值
+	IsL2          bool   `json:"is_l2"`
+	BlockTimeMs   int    `json:"block_time_ms"` // 出块时间，0 未配置
 }
 
 // Provider exposes the functionality required by API/worker layers.
@@ -203,6 +206,9 @@ func (m *Manager) convertInfo(src *dbBackend.Chain) *Info {
 		WalletCoin:    src.WalletCoin,
 		RPCURL:        src.RpcURL,
 		IsEnabled:     src.IsEnabled,
+		Confirmations: src.RequiredConfs,
+		IsL2:          src.IsL2,
+		BlockTimeMs:   src.BlockTimeMs,
 	}
 	info.ConsumerToken = m.resolveConsumerToken(src.ChainID)
 	return info
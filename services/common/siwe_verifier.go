@@ -27,7 +27,9 @@ type SIWEMessage struct {
 }
 
 type JWTClaims struct {
-	Address string `json:"address"`
+	Address    string `json:"address"`
+	WalletUUID string `json:"wallet_uuid,omitempty"` // 钱包令牌绑定的钱包，websocket 订阅用
+	Admin      bool   `json:"admin,omitempty"`       // 仅管理员登录签发的令牌为 true
 	jwt.RegisteredClaims
 }
 
@@ -108,13 +110,25 @@ func (v *SIWEVerifier) VerifySignature(message, signature string) (string, error
 }
 
 func (v *SIWEVerifier) GenerateJWT(address string, expirationHours int) (string, error) {
-	claims := JWTClaims{
-		Address: strings.ToLower(address),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expirationHours) * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+	return v.signJWT(JWTClaims{Address: strings.ToLower(address)}, time.Duration(expirationHours)*time.Hour)
+}
+
+// GenerateAdminJWT 签发管理员令牌，AdminAuth 只放行带 admin 声明的令牌
+func (v *SIWEVerifier) GenerateAdminJWT(subject string, expirationHours int) (string, error) {
+	return v.signJWT(JWTClaims{Address: strings.ToLower(subject), Admin: true}, time.Duration(expirationHours)*time.Hour)
+}
+
+// GenerateWalletJWT 签发绑定 walletUUID 的钱包令牌
+func (v *SIWEVerifier) GenerateWalletJWT(address, walletUUID string, ttl time.Duration) (string, error) {
+	return v.signJWT(JWTClaims{Address: strings.ToLower(address), WalletUUID: walletUUID}, ttl)
+}
+
+func (v *SIWEVerifier) signJWT(claims JWTClaims, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -140,3 +154,27 @@ func (v *SIWEVerifier) VerifyJWT(tokenString string) (*JWTClaims, error) {
 	}
 	return nil, errors.New("invalid token")
 }
+
+// WalletUUID 校验钱包令牌并返回其绑定的钱包，未绑定钱包的令牌视为无效
+func (v *SIWEVerifier) WalletUUID(tokenString string) (string, error) {
+	claims, err := v.VerifyJWT(tokenString)
+	if err != nil {
+		return "", err
+	}
+	if claims.WalletUUID == "" {
+		return "", errors.New("token is not bound to a wallet")
+	}
+	return claims.WalletUUID, nil
+}
+
+// VerifyAdminJWT 校验令牌且要求带 admin 声明
+func (v *SIWEVerifier) VerifyAdminJWT(tokenString string) (*JWTClaims, error) {
+	claims, err := v.VerifyJWT(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if !claims.Admin {
+		return nil, errors.New("not an admin token")
+	}
+	return claims, nil
+}
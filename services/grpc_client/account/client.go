@@ -3,6 +3,8 @@ package account
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
@@ -169,6 +171,98 @@ func (c *WalletAccountClient) GetTxByHash(ctx context.Context, consumerToken, ch
 	}, nil
 }
 
+// BlockHeaderInfo is the part of a block header used to track confirmations
+type BlockHeaderInfo struct {
+	Hash   string
+	Number int64
+}
+
+// GetBlockHeaderByNumber queries a block header by height, 0 for the latest block
+func (c *WalletAccountClient) GetBlockHeaderByNumber(ctx context.Context, consumerToken, chain, network string, height int64) (*BlockHeaderInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req := &pb.BlockHeaderNumberRequest{
+		ConsumerToken: consumerToken,
+		Chain:         chain,
+		Network:       network,
+		Height:        height,
+	}
+
+	resp, err := c.client.GetBlockHeaderByNumber(ctx, req)
+	if err != nil {
+		log.Error("GetBlockHeaderByNumber RPC failed", "err", err)
+		return nil, fmt.Errorf("failed to get block header: %w", err)
+	}
+
+	if resp.Code != common.ReturnCode_SUCCESS {
+		return nil, fmt.Errorf("get block header failed: %s", resp.Msg)
+	}
+
+	if resp.BlockHeader == nil {
+		return nil, fmt.Errorf("block header not found")
+	}
+
+	number, err := parseBlockNumber(resp.BlockHeader.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlockHeaderInfo{
+		Hash:   resp.BlockHeader.Hash,
+		Number: number,
+	}, nil
+}
+
+// FeeInfo holds the slow / normal / fast fee levels as returned by the chain adapter.
+// EVM adapters report "gasPrice|gasTipCap" with an optional "|*N" multiplier, others a single amount.
+type FeeInfo struct {
+	Slow   string
+	Normal string
+	Fast   string
+}
+
+// GetFee queries the suggested fee levels of a chain
+func (c *WalletAccountClient) GetFee(ctx context.Context, consumerToken, chain, coin, network string) (*FeeInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req := &pb.FeeRequest{
+		ConsumerToken: consumerToken,
+		Chain:         chain,
+		Coin:          coin,
+		Network:       network,
+	}
+
+	resp, err := c.client.GetFee(ctx, req)
+	if err != nil {
+		log.Error("GetFee RPC failed", "err", err)
+		return nil, fmt.Errorf("failed to get fee: %w", err)
+	}
+
+	if resp.Code != common.ReturnCode_SUCCESS {
+		return nil, fmt.Errorf("get fee failed: %s", resp.Msg)
+	}
+
+	return &FeeInfo{
+		Slow:   resp.SlowFee,
+		Normal: resp.NormalFee,
+		Fast:   resp.FastFee,
+	}, nil
+}
+
+// parseBlockNumber accepts both decimal and 0x-prefixed hex block numbers
+func parseBlockNumber(s string) (int64, error) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return strconv.ParseInt(s[2:], 16, 64)
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid block number %q: %w", s, err)
+	}
+	return n, nil
+}
+
 func (c *WalletAccountClient) Close() error {
 	if c.conn != nil {
 		return c.conn.Close()
@@ -0,0 +1,199 @@
+package asset
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/market/model"
+)
+
+// defaultRefreshInterval token 表变更不频繁，定期重新加载即可
+const defaultRefreshInterval = 5 * time.Minute
+
+// Registry maps provider symbols and (chain_id, contract_address) pairs onto the canonical
+// assets of the token table, so that tokens sharing a symbol are priced separately
+type Registry struct {
+	tokens          dbBackend.TokenView
+	chainTokens     dbBackend.ChainTokenView
+	refreshInterval time.Duration
+
+	mu         sync.RWMutex
+	assets     map[string]*model.Asset   // token guid -> asset
+	byContract map[string]*model.Asset   // chain_id:contract -> asset
+	bySymbol   map[string][]*model.Asset // SYMBOL -> assets
+	loadedAt   time.Time
+}
+
+// NewRegistry creates a registry backed by the token and chain_token tables;
+// refreshInterval <= 0 uses the default
+func NewRegistry(tokens dbBackend.TokenView, chainTokens dbBackend.ChainTokenView, refreshInterval time.Duration) *Registry {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultRefreshInterval
+	}
+	return &Registry{
+		tokens:          tokens,
+		chainTokens:     chainTokens,
+		refreshInterval: refreshInterval,
+		assets:          make(map[string]*model.Asset),
+		byContract:      make(map[string]*model.Asset),
+		bySymbol:        make(map[string][]*model.Asset),
+	}
+}
+
+// Refresh reloads the tables when the loaded snapshot is older than the refresh interval.
+// On failure the previous snapshot keeps serving.
+func (r *Registry) Refresh(ctx context.Context) error {
+	r.mu.RLock()
+	fresh := !r.loadedAt.IsZero() && time.Since(r.loadedAt) < r.refreshInterval
+	r.mu.RUnlock()
+	if fresh || r.tokens == nil {
+		return nil
+	}
+
+	tokens, err := r.tokens.GetAllTokens()
+	if err != nil {
+		return fmt.Errorf("load tokens: %w", err)
+	}
+	var chainTokens []*dbBackend.ChainToken
+	if r.chainTokens != nil {
+		if chainTokens, err = r.chainTokens.GetAllChainTokens(); err != nil {
+			return fmt.Errorf("load chain tokens: %w", err)
+		}
+	}
+	r.Load(tokens, chainTokens)
+	log.Info("asset registry loaded", "assets", len(tokens), "chain_tokens", len(chainTokens))
+	return nil
+}
+
+// Load replaces the registry content. Every token row is an asset deployed at its contract on
+// token_chain_id; chain_token rows list further chains where the token lives at the same address
+// (native coin placeholders, CREATE2 deployments).
+func (r *Registry) Load(tokens []*dbBackend.Token, chainTokens []*dbBackend.ChainToken) {
+	assets := make(map[string]*model.Asset, len(tokens))
+	for _, t := range tokens {
+		if t == nil || t.Guid == "" {
+			continue
+		}
+		a := &model.Asset{ID: t.Guid, Symbol: strings.ToUpper(t.TokenSymbol)}
+		if t.ChainID != "" {
+			a.Contracts = append(a.Contracts, model.Contract{ChainID: t.ChainID, Address: model.NormalizeContract(t.TokenContractAddress)})
+		}
+		assets[t.Guid] = a
+	}
+	for _, ct := range chainTokens {
+		if ct == nil || ct.ChainID == "" {
+			continue
+		}
+		a, ok := assets[ct.TokenID]
+		if !ok || len(a.Contracts) == 0 || hasChain(a, ct.ChainID) {
+			continue
+		}
+		a.Contracts = append(a.Contracts, model.Contract{ChainID: ct.ChainID, Address: a.Contracts[0].Address})
+	}
+
+	byContract := make(map[string]*model.Asset)
+	bySymbol := make(map[string][]*model.Asset)
+	for _, a := range assets {
+		for _, c := range a.Contracts {
+			key := contractKey(c.ChainID, c.Address)
+			if existing, ok := byContract[key]; ok {
+				log.Warn("duplicate token contract, keeping first", "chain_id", c.ChainID, "contract", c.Address, "token_id", existing.ID, "ignored", a.ID)
+				continue
+			}
+			byContract[key] = a
+		}
+		if a.Symbol != "" {
+			bySymbol[a.Symbol] = append(bySymbol[a.Symbol], a)
+		}
+	}
+
+	r.mu.Lock()
+	r.assets = assets
+	r.byContract = byContract
+	r.bySymbol = bySymbol
+	r.loadedAt = time.Now()
+	r.mu.Unlock()
+}
+
+// Get returns the asset of a token guid
+func (r *Registry) Get(tokenID string) (*model.Asset, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.assets[tokenID]
+	return a, ok
+}
+
+// Lookup returns the asset deployed at contract on chainID
+func (r *Registry) Lookup(chainID, contract string) (*model.Asset, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.byContract[contractKey(chainID, model.NormalizeContract(contract))]
+	return a, ok
+}
+
+// Bind maps quotes onto canonical assets. It returns the quotes to resolve per symbol and per asset:
+//   - quotes with a contract (DEX pools) bind only to the asset registered at that contract, and take
+//     its symbol; unregistered contracts are dropped so a look-alike token cannot move the symbol price
+//   - symbol-only quotes (CEX, aggregators) bind only when the symbol maps to exactly one asset; when
+//     several assets share the symbol the quote cannot tell them apart and only feeds the symbol price
+func (r *Registry) Bind(quotes []model.Quote) (symbolQuotes, assetQuotes []model.Quote) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	symbolQuotes = make([]model.Quote, 0, len(quotes))
+	assetQuotes = make([]model.Quote, 0, len(quotes))
+	ambiguous := make(map[string]bool)
+	for _, q := range quotes {
+		if q.ContractAddress != "" {
+			a, ok := r.byContract[contractKey(q.ChainID, model.NormalizeContract(q.ContractAddress))]
+			if !ok {
+				continue
+			}
+			q.BaseAsset = a.Symbol
+			symbolQuotes = append(symbolQuotes, q)
+			q.AssetID = a.ID
+			assetQuotes = append(assetQuotes, q)
+			continue
+		}
+
+		symbolQuotes = append(symbolQuotes, q)
+		symbol := strings.ToUpper(q.BaseAsset)
+		switch assets := r.bySymbol[symbol]; len(assets) {
+		case 0:
+		case 1:
+			q.AssetID = assets[0].ID
+			assetQuotes = append(assetQuotes, q)
+		default:
+			ambiguous[symbol] = true
+		}
+	}
+	if len(ambiguous) > 0 {
+		symbols := make([]string, 0, len(ambiguous))
+		for symbol := range ambiguous {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+		log.Debug("symbol-only quotes not bound to assets, symbol shared by several tokens", "symbols", symbols)
+	}
+	return symbolQuotes, assetQuotes
+}
+
+func contractKey(chainID, contract string) string {
+	return chainID + ":" + contract
+}
+
+func hasChain(a *model.Asset, chainID string) bool {
+	for _, c := range a.Contracts {
+		if c.ChainID == chainID {
+			return true
+		}
+	}
+	return false
+}
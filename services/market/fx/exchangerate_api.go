@@ -0,0 +1,74 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/roothash-pay/wallet-services/services/market/model"
+)
+
+// ExchangeRateAPIProvider open.er-api.com 免费接口，无需 key，每日更新
+type ExchangeRateAPIProvider struct {
+	client  *http.Client
+	baseURL string
+}
+
+func NewExchangeRateAPIProvider() *ExchangeRateAPIProvider {
+	return &ExchangeRateAPIProvider{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		baseURL: "https://open.er-api.com/v6/latest/",
+	}
+}
+
+func (p *ExchangeRateAPIProvider) Name() string { return "exchangerate_api" }
+
+/*
+GET https://open.er-api.com/v6/latest/USD
+
+	{
+	  "result": "success",
+	  "base_code": "USD",
+	  "time_last_update_unix": 1700000000,
+	  "rates": {"CNY": 7.2, "EUR": 0.92}
+	}
+*/
+type erAPIResp struct {
+	Result             string             `json:"result"`
+	ErrorType          string             `json:"error-type"`
+	TimeLastUpdateUnix int64              `json:"time_last_update_unix"`
+	Rates              map[string]float64 `json:"rates"`
+}
+
+func (p *ExchangeRateAPIProvider) FetchRates(ctx context.Context, base string, currencies []string) ([]model.FXRate, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+strings.ToUpper(base), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchangerate api http status %d", resp.StatusCode)
+	}
+
+	var out erAPIResp
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if out.Result != "success" {
+		return nil, fmt.Errorf("exchangerate api error: %s", out.ErrorType)
+	}
+
+	ts := time.Now()
+	if out.TimeLastUpdateUnix > 0 {
+		ts = time.Unix(out.TimeLastUpdateUnix, 0)
+	}
+	return pick(p.Name(), base, out.Rates, currencies, ts), nil
+}
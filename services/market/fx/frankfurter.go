@@ -0,0 +1,71 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/roothash-pay/wallet-services/services/market/model"
+)
+
+// FrankfurterProvider frankfurter.app 免费接口，欧洲央行参考汇率，工作日更新
+type FrankfurterProvider struct {
+	client  *http.Client
+	baseURL string
+}
+
+func NewFrankfurterProvider() *FrankfurterProvider {
+	return &FrankfurterProvider{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		baseURL: "https://api.frankfurter.app/latest",
+	}
+}
+
+func (p *FrankfurterProvider) Name() string { return "frankfurter" }
+
+/*
+GET https://api.frankfurter.app/latest?from=USD&to=CNY,EUR
+
+	{"amount": 1.0, "base": "USD", "date": "2024-01-02", "rates": {"CNY": 7.1, "EUR": 0.91}}
+*/
+type frankfurterResp struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+func (p *FrankfurterProvider) FetchRates(ctx context.Context, base string, currencies []string) ([]model.FXRate, error) {
+	q := url.Values{}
+	q.Set("from", strings.ToUpper(base))
+	q.Set("to", strings.ToUpper(strings.Join(currencies, ",")))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// 不支持的币种会返回 404，整体视为失败交给下一个源
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("frankfurter http status %d", resp.StatusCode)
+	}
+
+	var out frankfurterResp
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+
+	ts := time.Now()
+	if d, err := time.Parse("2006-01-02", out.Date); err == nil {
+		ts = d
+	}
+	return pick(p.Name(), base, out.Rates, currencies, ts), nil
+}
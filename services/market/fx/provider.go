@@ -0,0 +1,36 @@
+package fx
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/roothash-pay/wallet-services/services/market/model"
+)
+
+// Provider 法币汇率源，与 market/provider.Provider 对应
+type Provider interface {
+	Name() string
+	// FetchRates 返回 1 base 兑换各 currency 的汇率，源不支持的币种直接省略
+	FetchRates(ctx context.Context, base string, currencies []string) ([]model.FXRate, error)
+}
+
+// pick 从源返回的全量汇率里挑出需要的币种
+func pick(source, base string, all map[string]float64, currencies []string, ts time.Time) []model.FXRate {
+	rates := make([]model.FXRate, 0, len(currencies))
+	for _, c := range currencies {
+		c = strings.ToUpper(c)
+		rate, ok := all[c]
+		if !ok || rate <= 0 {
+			continue
+		}
+		rates = append(rates, model.FXRate{
+			Base:      strings.ToUpper(base),
+			Currency:  c,
+			Rate:      rate,
+			Source:    source,
+			Timestamp: ts,
+		})
+	}
+	return rates
+}
@@ -0,0 +1,55 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/roothash-pay/wallet-services/services/market/model"
+)
+
+// StaticFileProvider 从本地 JSON 文件读取汇率，作为在线源都不可用时的兜底：
+//
+//	{"base": "USD", "updated_at": "2026-10-01T00:00:00Z", "rates": {"CNY": 7.1, "EUR": 0.92}}
+//
+// updated_at 为空时使用文件修改时间
+type StaticFileProvider struct {
+	path string
+}
+
+func NewStaticFileProvider(path string) *StaticFileProvider {
+	return &StaticFileProvider{path: path}
+}
+
+func (p *StaticFileProvider) Name() string { return "static_file" }
+
+type staticRates struct {
+	Base      string             `json:"base"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	Rates     map[string]float64 `json:"rates"`
+}
+
+func (p *StaticFileProvider) FetchRates(ctx context.Context, base string, currencies []string) ([]model.FXRate, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+	var out staticRates
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("invalid fx rate file %s: %w", p.path, err)
+	}
+	if !strings.EqualFold(out.Base, base) {
+		return nil, fmt.Errorf("fx rate file base %s, want %s", out.Base, base)
+	}
+
+	ts := out.UpdatedAt
+	if ts.IsZero() {
+		if info, err := os.Stat(p.path); err == nil {
+			ts = info.ModTime()
+		}
+	}
+	return pick(p.Name(), base, out.Rates, currencies, ts), nil
+}
@@ -0,0 +1,25 @@
+package model
+
+import "strings"
+
+// Contract is one deployment of an asset
+type Contract struct {
+	ChainID string
+	Address string
+}
+
+// Asset is a canonical asset, identified by its token table guid
+type Asset struct {
+	ID        string
+	Symbol    string
+	Contracts []Contract
+}
+
+// NormalizeContract lowercases EVM hex addresses; base58 addresses (Solana) are case-sensitive and kept as is
+func NormalizeContract(addr string) string {
+	addr = strings.TrimSpace(addr)
+	if strings.HasPrefix(addr, "0x") || strings.HasPrefix(addr, "0X") {
+		return strings.ToLower(addr)
+	}
+	return addr
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// FXRate 法币汇率：1 Base = Rate Currency
+type FXRate struct {
+	Base      string
+	Currency  string
+	Rate      float64
+	Source    string
+	Timestamp time.Time
+}
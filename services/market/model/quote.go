@@ -9,4 +9,21 @@ type Quote struct {
 	Volume24h  float64
 	Source     string
 	Timestamp  time.Time
+
+	// DEX 行情带上链与合约地址，按合约而不是符号识别资产
+	ChainID         string `json:",omitempty"`
+	ContractAddress string `json:",omitempty"`
+	// AssetID 绑定到的规范资产（token 表 guid），为空时只按符号计价
+	AssetID string `json:",omitempty"`
+
+	// Confidence 多源聚合的置信度 [0,1]：参与源的权重占比、源数量与价格离散度
+	Confidence float64 `json:",omitempty"`
+}
+
+// Key is the identity a quote is resolved under: the canonical asset when bound, else the symbol
+func (q Quote) Key() string {
+	if q.AssetID != "" {
+		return q.AssetID
+	}
+	return q.BaseAsset
 }
@@ -27,6 +27,9 @@ func NewPancakeSwapV2GraphProvider(limit int) *PancakeSwapV2GraphProvider {
 func (p *PancakeSwapV2GraphProvider) Name() string { return "pancakeswap_v2_graph" }
 
 // 注意：subgraph 可能会随时间变更（如果调用失败，再换最新 endpoint）
+// pancakeV2ChainID 该 subgraph 索引的是 BSC
+const pancakeV2ChainID = "56"
+
 const pancakeV2URL = "https://api.thegraph.com/subgraphs/name/pancakeswap/exchange-v2"
 
 func (p *PancakeSwapV2GraphProvider) FetchQuotes(ctx context.Context) ([]model.Quote, error) {
@@ -34,8 +37,8 @@ func (p *PancakeSwapV2GraphProvider) FetchQuotes(ctx context.Context) ([]model.Q
 query TopPairs($n: Int!) {
   pairs(first: $n, orderBy: volumeUSD, orderDirection: desc) {
     id
-    token0 { symbol }
-    token1 { symbol }
+    token0 { id symbol }
+    token1 { id symbol }
     token0Price
     token1Price
     volumeUSD
@@ -44,12 +47,12 @@ query TopPairs($n: Int!) {
 
 	var resp struct {
 		Pairs []struct {
-			ID          string                  `json:"id"`
-			Token0      struct{ Symbol string } `json:"token0"`
-			Token1      struct{ Symbol string } `json:"token1"`
-			Token0Price string                  `json:"token0Price"`
-			Token1Price string                  `json:"token1Price"`
-			VolumeUSD   string                  `json:"volumeUSD"`
+			ID          string           `json:"id"`
+			Token0      utils.GraphToken `json:"token0"`
+			Token1      utils.GraphToken `json:"token1"`
+			Token0Price string           `json:"token0Price"`
+			Token1Price string           `json:"token1Price"`
+			VolumeUSD   string           `json:"volumeUSD"`
 		} `json:"pairs"`
 	}
 
@@ -73,22 +76,26 @@ query TopPairs($n: Int!) {
 			price := utils.ParseFloat(pair.Token0Price)
 			if price > 0 {
 				quotes = append(quotes, model.Quote{
-					BaseAsset:  a,
-					QuoteAsset: "USDT",
-					Price:      price,
-					Source:     p.Name(),
-					Timestamp:  now,
+					BaseAsset:       a,
+					QuoteAsset:      "USDT",
+					Price:           price,
+					Source:          p.Name(),
+					Timestamp:       now,
+					ChainID:         pancakeV2ChainID,
+					ContractAddress: pair.Token0.ID,
 				})
 			}
 		} else if isStable(a) {
 			price := utils.ParseFloat(pair.Token1Price)
 			if price > 0 {
 				quotes = append(quotes, model.Quote{
-					BaseAsset:  b,
-					QuoteAsset: "USDT",
-					Price:      price,
-					Source:     p.Name(),
-					Timestamp:  now,
+					BaseAsset:       b,
+					QuoteAsset:      "USDT",
+					Price:           price,
+					Source:          p.Name(),
+					Timestamp:       now,
+					ChainID:         pancakeV2ChainID,
+					ContractAddress: pair.Token1.ID,
 				})
 			}
 		}
@@ -10,3 +10,6 @@ type Provider interface {
 	Name() string
 	FetchQuotes(ctx context.Context) ([]model.Quote, error)
 }
+
+// DEXSources 链上 DEX 行情源的 Name()，聚合时与 CEX 区别加权
+var DEXSources = []string{"uniswap_v3_graph", "pancakeswap_v2_graph"}
@@ -28,6 +28,9 @@ func NewUniswapV3GraphProvider(limit int) *UniswapV3GraphProvider {
 func (p *UniswapV3GraphProvider) Name() string { return "uniswap_v3_graph" }
 
 // const uniswapV3URL = "https://api.thegraph.com/subgraphs/name/uniswap/uniswap-v3"
+// uniswapV3ChainID 该 subgraph 索引的是以太坊主网
+const uniswapV3ChainID = "1"
+
 const uniswapV3URL = "https://gateway.thegraph.com/api/fc75acc62ddc7e0abbb1276c0dfdd386/subgraphs/id/5zvR82QoaXYFyDEKLZ9t6v9adgnptxYpKpSbxtgVENFV"
 
 func (p *UniswapV3GraphProvider) FetchQuotes(ctx context.Context) ([]model.Quote, error) {
@@ -52,10 +55,12 @@ query Pools($first: Int = 10) {
     id
     feeTier
     token0 {
+      id
       symbol
       decimals
     }
     token1 {
+      id
       symbol
       decimals
     }
@@ -65,13 +70,13 @@ query Pools($first: Int = 10) {
   `
 	var resp struct {
 		Pools []struct {
-			ID          string                  `json:"id"`
-			Token0      struct{ Symbol string } `json:"token0"`
-			Token1      struct{ Symbol string } `json:"token1"`
-			Token0Price string                  `json:"token0Price"` // token0 in token1
-			Token1Price string                  `json:"token1Price"` // token1 in token0
-			VolumeUSD   string                  `json:"volumeUSD"`
-			TVLUSD      string                  `json:"totalValueLockedUSD"`
+			ID          string           `json:"id"`
+			Token0      utils.GraphToken `json:"token0"`
+			Token1      utils.GraphToken `json:"token1"`
+			Token0Price string           `json:"token0Price"` // token0 in token1
+			Token1Price string           `json:"token1Price"` // token1 in token0
+			VolumeUSD   string           `json:"volumeUSD"`
+			TVLUSD      string           `json:"totalValueLockedUSD"`
 		} `json:"pools"`
 	}
 
@@ -103,12 +108,14 @@ query Pools($first: Int = 10) {
 			price := utils.ParseFloat(pool.Token0Price)
 			if price > 0 {
 				quotes = append(quotes, model.Quote{
-					BaseAsset:  a,
-					QuoteAsset: "USDT",
-					Price:      price,
-					Volume24h:  volumeUSD,
-					Source:     p.Name(),
-					Timestamp:  now,
+					BaseAsset:       a,
+					QuoteAsset:      "USDT",
+					Price:           price,
+					Volume24h:       volumeUSD,
+					Source:          p.Name(),
+					Timestamp:       now,
+					ChainID:         uniswapV3ChainID,
+					ContractAddress: pool.Token0.ID,
 				})
 			}
 		} else if isStable(a) {
@@ -116,12 +123,14 @@ query Pools($first: Int = 10) {
 			price := utils.ParseFloat(pool.Token1Price)
 			if price > 0 {
 				quotes = append(quotes, model.Quote{
-					BaseAsset:  b,
-					QuoteAsset: "USDT",
-					Price:      price,
-					Volume24h:  volumeUSD,
-					Source:     p.Name(),
-					Timestamp:  now,
+					BaseAsset:       b,
+					QuoteAsset:      "USDT",
+					Price:           price,
+					Volume24h:       volumeUSD,
+					Source:          p.Name(),
+					Timestamp:       now,
+					ChainID:         uniswapV3ChainID,
+					ContractAddress: pool.Token1.ID,
 				})
 			}
 		}
@@ -19,6 +19,12 @@ func NewGraphClient() *GraphClient {
 	}
 }
 
+// GraphToken 是 subgraph 里的 token，id 即合约地址
+type GraphToken struct {
+	ID     string `json:"id"`
+	Symbol string `json:"symbol"`
+}
+
 type graphReq struct {
 	Query     string                 `json:"query"`
 	Variables map[string]interface{} `json:"variables,omitempty"`
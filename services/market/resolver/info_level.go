@@ -42,17 +42,17 @@ func (r *InfoLevelResolver) Resolve(quotes []model.Quote) (map[string]model.Quot
 			continue
 		}
 
-		// 极简规则：同一 BaseAsset，选 volume 最大的
-		existing, ok := result[q.BaseAsset]
+		// 极简规则：同一资产（规范资产或 BaseAsset），选 volume 最大的
+		existing, ok := result[q.Key()]
 		if !ok || q.Volume24h > existing.Volume24h {
 			log.Debug(
 				"resolver select quote",
-				"asset", q.BaseAsset,
+				"asset", q.Key(),
 				"price", q.Price,
 				"volume24h", q.Volume24h,
 				"source", q.Source,
 			)
-			result[q.BaseAsset] = q
+			result[q.Key()] = q
 		}
 	}
 
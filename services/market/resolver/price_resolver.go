@@ -1,7 +1,35 @@
 package resolver
 
-import "github.com/roothash-pay/wallet-services/services/market/model"
+import (
+	"fmt"
+	"strings"
 
+	"github.com/roothash-pay/wallet-services/config"
+	"github.com/roothash-pay/wallet-services/services/market/model"
+)
+
+const (
+	TypeWeightedMedian = "weighted_median"
+	TypeInfoLevel      = "info_level"
+)
+
+// Resolver 从多源行情中为每个资产（Quote.Key()）裁决出一个价格
 type Resolver interface {
 	Resolve(quotes []model.Quote) (map[string]model.Quote, error)
 }
+
+// New creates the resolver selected by cfg.Type, the weighted median when empty
+func New(cfg config.MarketResolverConfig) (Resolver, error) {
+	switch strings.ToLower(cfg.Type) {
+	case "", TypeWeightedMedian:
+		return NewWeightedMedianResolver(cfg.MaxAge, cfg.MaxDeviation, cfg.MinSources, cfg.CEXWeight, cfg.DEXWeight, cfg.DEXSources), nil
+	case TypeInfoLevel:
+		r := NewInfoLevelResolver()
+		if cfg.MaxAge > 0 {
+			r.MaxAge = cfg.MaxAge
+		}
+		return r, nil
+	default:
+		return nil, fmt.Errorf("unsupported market resolver: %s", cfg.Type)
+	}
+}
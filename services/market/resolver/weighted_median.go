@@ -0,0 +1,223 @@
+package resolver
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/roothash-pay/wallet-services/services/market/model"
+	"github.com/roothash-pay/wallet-services/services/market/provider"
+)
+
+const (
+	defaultMaxDeviation = 0.05
+	defaultMinSources   = 1
+	defaultCEXWeight    = 1.0
+	defaultDEXWeight    = 0.5
+)
+
+// WeightedMedianResolver merges the quotes of several sources into a weighted median, each source
+// weighted by its kind (CEX / DEX) times the order of magnitude of its 24h volume (see weight).
+// Quotes further than MaxDeviation from the median are rejected as outliers, and a price is only
+// published when at least MinSources sources agree. The default MinSources of 1 keeps publishing
+// assets with a single source (most DEX long-tail tokens); fewer agreeing sources lower the confidence.
+type WeightedMedianResolver struct {
+	MaxAge       time.Duration
+	MaxDeviation float64
+	MinSources   int
+	CEXWeight    float64
+	DEXWeight    float64
+
+	dexSources map[string]bool
+}
+
+// NewWeightedMedianResolver creates the resolver; zero values use the defaults and empty
+// dexSources uses the built-in DEX providers
+func NewWeightedMedianResolver(maxAge time.Duration, maxDeviation float64, minSources int, cexWeight, dexWeight float64, dexSources []string) *WeightedMedianResolver {
+	if maxAge <= 0 {
+		maxAge = 2 * time.Minute
+	}
+	if maxDeviation <= 0 {
+		maxDeviation = defaultMaxDeviation
+	}
+	if minSources <= 0 {
+		minSources = defaultMinSources
+	}
+	if cexWeight <= 0 {
+		cexWeight = defaultCEXWeight
+	}
+	if dexWeight <= 0 {
+		dexWeight = defaultDEXWeight
+	}
+	if len(dexSources) == 0 {
+		dexSources = provider.DEXSources
+	}
+	dex := make(map[string]bool, len(dexSources))
+	for _, s := range dexSources {
+		dex[strings.ToLower(s)] = true
+	}
+	return &WeightedMedianResolver{
+		MaxAge:       maxAge,
+		MaxDeviation: maxDeviation,
+		MinSources:   minSources,
+		CEXWeight:    cexWeight,
+		DEXWeight:    dexWeight,
+		dexSources:   dex,
+	}
+}
+
+type sample struct {
+	quote  model.Quote
+	weight float64
+}
+
+func (r *WeightedMedianResolver) Resolve(quotes []model.Quote) (map[string]model.Quote, error) {
+	if len(quotes) == 0 {
+		return nil, errors.New("no quotes")
+	}
+	log.Info(
+		"resolver start",
+		"resolver", "weighted_median",
+		"input_quotes", len(quotes),
+	)
+
+	// 同一资产同一源只保留一票：成交量最大的那条（如同一 DEX 的多个池子）
+	now := time.Now()
+	groups := make(map[string]map[string]model.Quote)
+	for _, q := range quotes {
+		if now.Sub(q.Timestamp) > r.MaxAge || q.Price <= 0 || math.IsInf(q.Price, 0) || math.IsNaN(q.Price) {
+			continue
+		}
+		bySource, ok := groups[q.Key()]
+		if !ok {
+			bySource = make(map[string]model.Quote)
+			groups[q.Key()] = bySource
+		}
+		existing, ok := bySource[q.Source]
+		if !ok || q.Volume24h > existing.Volume24h || (q.Volume24h == existing.Volume24h && q.Timestamp.After(existing.Timestamp)) {
+			bySource[q.Source] = q
+		}
+	}
+
+	result := make(map[string]model.Quote, len(groups))
+	rejected := 0
+	for key, bySource := range groups {
+		q, ok := r.resolveAsset(bySource)
+		if !ok {
+			rejected++
+			log.Debug("resolver reject asset", "asset", key, "sources", len(bySource))
+			continue
+		}
+		result[key] = q
+	}
+
+	if len(result) == 0 {
+		return nil, errors.New("no valid quotes after resolve")
+	}
+	log.Info(
+		"resolver finished",
+		"resolver", "weighted_median",
+		"output_assets", len(result),
+		"rejected_assets", rejected,
+	)
+	return result, nil
+}
+
+func (r *WeightedMedianResolver) resolveAsset(bySource map[string]model.Quote) (model.Quote, bool) {
+	if len(bySource) < r.MinSources {
+		return model.Quote{}, false
+	}
+
+	samples := make([]sample, 0, len(bySource))
+	var totalWeight float64
+	for _, q := range bySource {
+		w := r.weight(q)
+		samples = append(samples, sample{quote: q, weight: w})
+		totalWeight += w
+	}
+	median := weightedMedian(samples)
+
+	agreeing := make([]sample, 0, len(samples))
+	for _, s := range samples {
+		if math.Abs(s.quote.Price-median)/median <= r.MaxDeviation {
+			agreeing = append(agreeing, s)
+		}
+	}
+	if len(agreeing) < r.MinSources {
+		return model.Quote{}, false
+	}
+	price := weightedMedian(agreeing)
+
+	var (
+		agreeWeight float64
+		deviation   float64
+		best        = agreeing[0]
+		latest      = agreeing[0].quote.Timestamp
+		sources     = make([]string, 0, len(agreeing))
+	)
+	for _, s := range agreeing {
+		agreeWeight += s.weight
+		deviation += s.weight * math.Abs(s.quote.Price-price) / price
+		if s.weight > best.weight {
+			best = s
+		}
+		if s.quote.Timestamp.After(latest) {
+			latest = s.quote.Timestamp
+		}
+		sources = append(sources, s.quote.Source)
+	}
+	sort.Strings(sources)
+
+	// 置信度 = 参与源的权重占比 × 离散度（平均偏离相对阈值）× 源数量
+	share := agreeWeight / totalWeight
+	tightness := 1 - (deviation/agreeWeight)/r.MaxDeviation
+	coverage := float64(len(agreeing)) / float64(len(agreeing)+1)
+
+	out := best.quote
+	out.Price = price
+	out.Source = strings.Join(sources, ",")
+	out.Timestamp = latest
+	out.Confidence = math.Max(0, math.Min(1, share*tightness*coverage))
+	return out, true
+}
+
+// weight = 源类型权重 × (1 + log10(1+成交量))。不直接用成交量：薄池子刷出的成交量可以比主流交易所大几个数量级，
+// 线性权重下它一家就能决定中位数（见测试中的 uniswap_v3_graph 3900）；各源成交量口径也不一致（计价币、统计窗口）。
+// 取对数后成交量每大一个数量级权重 +1，大源仍然优先，但单一源无法独占权重
+func (r *WeightedMedianResolver) weight(q model.Quote) float64 {
+	kind := r.CEXWeight
+	if r.dexSources[strings.ToLower(q.Source)] {
+		kind = r.DEXWeight
+	}
+	return kind * (1 + math.Log10(1+math.Max(0, q.Volume24h)))
+}
+
+// weightedMedian returns the price at which the cumulative weight reaches half of the total,
+// averaging the two middle prices when it lands exactly on the half
+func weightedMedian(samples []sample) float64 {
+	sorted := make([]sample, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].quote.Price < sorted[j].quote.Price })
+
+	var total float64
+	for _, s := range sorted {
+		total += s.weight
+	}
+	half := total / 2
+
+	var cum float64
+	for i, s := range sorted {
+		cum += s.weight
+		if cum > half || i == len(sorted)-1 {
+			return s.quote.Price
+		}
+		if math.Abs(cum-half) < 1e-12 {
+			return (s.quote.Price + sorted[i+1].quote.Price) / 2
+		}
+	}
+	return 0
+}
@@ -0,0 +1,120 @@
+package resolver
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/roothash-pay/wallet-services/config"
+	"github.com/roothash-pay/wallet-services/services/market/model"
+)
+
+func TestWeightedMedianResolver(t *testing.T) {
+	now := time.Now()
+	quote := func(asset, source string, price, volume float64) model.Quote {
+		return model.Quote{BaseAsset: asset, QuoteAsset: "USDT", Price: price, Volume24h: volume, Source: source, Timestamp: now}
+	}
+	r := NewWeightedMedianResolver(0, 0.05, 2, 0, 0, nil)
+
+	out, err := r.Resolve([]model.Quote{
+		quote("ETH", "binance", 3000, 1e6),
+		quote("ETH", "okx", 3002, 5e5),
+		quote("ETH", "coingecko", 2999, 1e7),
+		// 薄池子放大成交量报出离谱价格：被当作异常值剔除
+		quote("ETH", "uniswap_v3_graph", 3900, 1e12),
+		quote("ETH", "uniswap_v3_graph", 3001, 1e3),
+		// 只有一个源：不发布
+		quote("PEPE", "binance", 0.00001, 1e9),
+		// 过期
+		{BaseAsset: "SOL", Price: 150, Source: "binance", Timestamp: now.Add(-time.Hour)},
+		{BaseAsset: "SOL", Price: 150, Source: "okx", Timestamp: now.Add(-time.Hour)},
+	})
+	require.NoError(t, err)
+	require.Contains(t, out, "ETH")
+	assert.NotContains(t, out, "PEPE")
+	assert.NotContains(t, out, "SOL")
+
+	eth := out["ETH"]
+	// 只有一个源的资产使用默认 MinSources 时仍发布，置信度较低
+	single, err := NewWeightedMedianResolver(0, 0, 0, 0, 0, nil).Resolve([]model.Quote{
+		quote("PEPE", "uniswap_v3_graph", 0.00001, 1e5),
+		quote("ETH", "binance", 3000, 1e6),
+		quote("ETH", "okx", 3001, 1e6),
+	})
+	require.NoError(t, err)
+	require.Contains(t, single, "PEPE")
+	assert.Equal(t, 0.00001, single["PEPE"].Price)
+	assert.Less(t, single["PEPE"].Confidence, single["ETH"].Confidence)
+
+	assert.InDelta(t, 3000, eth.Price, 2)
+	assert.Equal(t, "binance,coingecko,okx", eth.Source)
+	assert.Greater(t, eth.Confidence, 0.3)
+	assert.Less(t, eth.Confidence, 1.0)
+
+	// 分歧太大时没有足够多的源同意
+	_, err = r.Resolve([]model.Quote{
+		quote("ARB", "binance", 1.0, 1e6),
+		quote("ARB", "okx", 1.5, 1e6),
+	})
+	assert.Error(t, err)
+
+	// 三个一致的源比两个一致、一个异常的置信度高
+	agreed, err := r.Resolve([]model.Quote{
+		quote("OP", "binance", 2.0, 1e6),
+		quote("OP", "okx", 2.0, 1e6),
+		quote("OP", "coingecko", 2.0, 1e6),
+	})
+	require.NoError(t, err)
+	split, err := r.Resolve([]model.Quote{
+		quote("OP", "binance", 2.0, 1e6),
+		quote("OP", "okx", 2.0, 1e6),
+		quote("OP", "coingecko", 3.0, 1e6),
+	})
+	require.NoError(t, err)
+	assert.Greater(t, agreed["OP"].Confidence, split["OP"].Confidence)
+}
+
+// 权重按成交量的数量级：成交量大的源决定中位数，但刷大几个数量级的成交量也无法压过多数源
+func TestWeightedMedianVolumeWeight(t *testing.T) {
+	now := time.Now()
+	quote := func(source string, price, volume float64) model.Quote {
+		return model.Quote{BaseAsset: "ARB", QuoteAsset: "USDT", Price: price, Volume24h: volume, Source: source, Timestamp: now}
+	}
+	r := NewWeightedMedianResolver(0, 0.05, 1, 0, 0, nil)
+
+	out, err := r.Resolve([]model.Quote{
+		quote("binance", 1.02, 1e8),
+		quote("okx", 1.00, 1e2),
+		quote("bybit", 1.01, 1e2),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1.02, out["ARB"].Price, "the high-volume source wins the median")
+
+	out, err = r.Resolve([]model.Quote{
+		quote("binance", 1.00, 1e6),
+		quote("okx", 1.00, 1e6),
+		quote("bybit", 1.00, 1e6),
+		quote("gate", 1.04, 1e15),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1.00, out["ARB"].Price, "an inflated volume cannot take over the median")
+
+	assert.Equal(t, 1.0, r.weight(quote("okx", 1, 0)))
+	assert.Equal(t, 7.0, math.Round(r.weight(quote("okx", 1, 1e6))))
+}
+
+func TestNewResolver(t *testing.T) {
+	r, err := New(config.MarketResolverConfig{})
+	require.NoError(t, err)
+	assert.IsType(t, &WeightedMedianResolver{}, r)
+
+	r, err = New(config.MarketResolverConfig{Type: "info_level", MaxAge: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, r.(*InfoLevelResolver).MaxAge)
+
+	_, err = New(config.MarketResolverConfig{Type: "mean"})
+	assert.Error(t, err)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/roothash-pay/wallet-services/services/market/asset"
 	"github.com/roothash-pay/wallet-services/services/market/cache"
 	"github.com/roothash-pay/wallet-services/services/market/model"
 	"github.com/roothash-pay/wallet-services/services/market/provider"
@@ -15,20 +16,25 @@ type MarketCollector struct {
 	providers []provider.Provider
 	resolver  resolver.Resolver
 	cache     cache.Cache
+	assets    *asset.Registry // 可选，nil 时只按符号计价
 }
 
 func NewMarketCollector(
 	providers []provider.Provider,
 	resolver resolver.Resolver,
 	cache cache.Cache,
+	assets *asset.Registry,
 ) *MarketCollector {
 	return &MarketCollector{
 		providers: providers,
 		resolver:  resolver,
 		cache:     cache,
+		assets:    assets,
 	}
 }
 
+// Collect fetches all providers, resolves one price per symbol and per canonical asset and caches them.
+// The result is keyed by Quote.Key(): the symbol, or the token guid for asset prices.
 func (mc *MarketCollector) Collect(ctx context.Context) (map[string]model.Quote, error) {
 	allQuotes := make([]model.Quote, 0, 256)
 
@@ -46,39 +52,53 @@ func (mc *MarketCollector) Collect(ctx context.Context) (map[string]model.Quote,
 		return nil, nil
 	}
 
-	finalQuotes, err := mc.resolver.Resolve(allQuotes)
+	symbolQuotes, assetQuotes := allQuotes, []model.Quote(nil)
+	if mc.assets != nil {
+		if err := mc.assets.Refresh(ctx); err != nil {
+			log.Warn("asset registry refresh failed", "err", err)
+		}
+		symbolQuotes, assetQuotes = mc.assets.Bind(allQuotes)
+	}
+
+	finalQuotes, err := mc.resolver.Resolve(symbolQuotes)
 	if err != nil {
 		return nil, err
 	}
-
-	// 比如 resolver 返回 map[string]Quote
 	for symbol, q := range finalQuotes {
-		key := "price:" + symbol
+		mc.store(ctx, PriceKey(symbol), q)
+	}
 
-		buf, err := json.Marshal(q)
+	assetCount := 0
+	if len(assetQuotes) > 0 {
+		assetFinal, err := mc.resolver.Resolve(assetQuotes)
 		if err != nil {
-			log.Error("marshal quote failed", "key", key, "err", err)
-			continue
+			log.Warn("resolve asset quotes failed", "err", err)
 		}
-
-		// log.Info("cache set try", "key", key, "price", q.Price)
-
-		if err := mc.cache.Set(ctx, key, buf); err != nil {
-			log.Error("cache set failed", "key", key, "err", err)
-		} else {
-			log.Info("cache set success", "key", key)
+		for tokenID, q := range assetFinal {
+			mc.store(ctx, TokenPriceKey(tokenID), q)
+			if a, ok := mc.assets.Get(tokenID); ok {
+				for _, c := range a.Contracts {
+					mc.store(ctx, ContractPriceKey(c.ChainID, c.Address), q)
+				}
+			}
+			finalQuotes[tokenID] = q
+			assetCount++
 		}
-
-		// // debug 读取确认
-		// val, ok, err := mc.cache.Get(ctx, key)
-		// if err != nil {
-		// 	log.Error("cache get after set failed", "key", key, "err", err)
-		// } else {
-		// 	log.Info("cache get after set ok", "key", key, "type", fmt.Sprintf("%T", val), "ok", ok)
-		// }
-
 	}
 
-	log.Info("market quotes collected", "symbols", len(finalQuotes))
+	log.Info("market quotes collected", "symbols", len(finalQuotes)-assetCount, "assets", assetCount)
 	return finalQuotes, nil
 }
+
+func (mc *MarketCollector) store(ctx context.Context, key string, q model.Quote) {
+	buf, err := json.Marshal(q)
+	if err != nil {
+		log.Error("marshal quote failed", "key", key, "err", err)
+		return
+	}
+	if err := mc.cache.Set(ctx, key, buf); err != nil {
+		log.Error("cache set failed", "key", key, "err", err)
+	} else {
+		log.Debug("cache set success", "key", key)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/market/asset"
+	"github.com/roothash-pay/wallet-services/services/market/cache"
+	"github.com/roothash-pay/wallet-services/services/market/model"
+	"github.com/roothash-pay/wallet-services/services/market/provider"
+	"github.com/roothash-pay/wallet-services/services/market/resolver"
+)
+
+type staticProvider struct {
+	name   string
+	quotes []model.Quote
+}
+
+func (p staticProvider) Name() string { return p.name }
+func (p staticProvider) FetchQuotes(ctx context.Context) ([]model.Quote, error) {
+	return p.quotes, nil
+}
+
+func TestCollectByAsset(t *testing.T) {
+	const (
+		usdcEth  = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+		usdcBsc  = "0x8AC76a51cc950d9822D68b83fE1Ad97B32Cd580d"
+		fakeUSDC = "0x00000000000000000000000000000000000dead1"
+	)
+	registry := asset.NewRegistry(nil, nil, 0)
+	registry.Load([]*dbBackend.Token{
+		{Guid: "usdc-eth", TokenSymbol: "usdc", TokenContractAddress: usdcEth, ChainID: "1"},
+		{Guid: "usdc-bsc", TokenSymbol: "USDC", TokenContractAddress: usdcBsc, ChainID: "56"},
+		{Guid: "eth", TokenSymbol: "ETH", TokenContractAddress: "0x0000000000000000000000000000000000000000", ChainID: "1"},
+	}, []*dbBackend.ChainToken{
+		{ChainID: "42161", TokenID: "eth"},
+	})
+
+	now := time.Now()
+	providers := []provider.Provider{
+		staticProvider{name: "cex", quotes: []model.Quote{
+			{BaseAsset: "ETH", QuoteAsset: "USDT", Price: 3000, Volume24h: 1e6, Source: "cex", Timestamp: now},
+			{BaseAsset: "USDC", QuoteAsset: "USDT", Price: 1, Volume24h: 1e3, Source: "cex", Timestamp: now},
+		}},
+		staticProvider{name: "dex", quotes: []model.Quote{
+			// 未登记的同名代币：成交量再大也不能影响 USDC
+			{BaseAsset: "USDC", QuoteAsset: "USDT", Price: 0.01, Volume24h: 1e9, Source: "dex", Timestamp: now, ChainID: "1", ContractAddress: fakeUSDC},
+			// 登记过的 BSC USDC，按合约只影响 usdc-bsc
+			{BaseAsset: "USDC", QuoteAsset: "USDT", Price: 0.998, Volume24h: 1e4, Source: "dex", Timestamp: now, ChainID: "56", ContractAddress: usdcBsc},
+		}},
+	}
+
+	c := cache.NewMemoryCache()
+	collector := NewMarketCollector(providers, resolver.NewInfoLevelResolver(), c, registry)
+	_, err := collector.Collect(context.Background())
+	require.NoError(t, err)
+
+	market := NewMarketService(c)
+	ctx := context.Background()
+
+	// 两个 token 同为 USDC：只有符号的 CEX 报价分不清是哪一个，不绑定到任何 token
+	q, err := market.GetPriceByContract(ctx, "1", usdcEth)
+	require.NoError(t, err)
+	assert.Nil(t, q)
+
+	q, err = market.GetPriceByTokenID(ctx, "usdc-bsc")
+	require.NoError(t, err)
+	require.NotNil(t, q)
+	assert.Equal(t, 0.998, q.Price)
+	assert.Equal(t, "usdc-bsc", q.AssetID)
+
+	q, err = market.GetPriceByContract(ctx, "1", fakeUSDC)
+	require.NoError(t, err)
+	assert.Nil(t, q)
+
+	// chain_token 把 ETH 扩展到 Arbitrum 的同一占位地址
+	q, err = market.GetPriceByContract(ctx, "42161", "0x0000000000000000000000000000000000000000")
+	require.NoError(t, err)
+	require.NotNil(t, q)
+	assert.Equal(t, 3000.0, q.Price)
+	assert.Equal(t, "eth", q.AssetID, "a symbol owned by one token binds to it")
+
+	q, err = market.GetPrice(ctx, "usdc")
+	require.NoError(t, err)
+	require.NotNil(t, q)
+	assert.Equal(t, 0.998, q.Price, "only registered contracts feed the symbol price")
+}
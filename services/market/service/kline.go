@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/google/uuid"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/market/model"
+)
+
+// maxKlineBackfill 停机后每个周期最多补齐的 K 线根数（1m 约一天）
+const maxKlineBackfill = 1440
+
+// KlineInterval is one candle period written by the kline builder
+type KlineInterval struct {
+	Name     string
+	Duration time.Duration
+}
+
+// KlineIntervals are the periods built from price ticks
+var KlineIntervals = []KlineInterval{
+	{Name: "1m", Duration: time.Minute},
+	{Name: "5m", Duration: 5 * time.Minute},
+	{Name: "1h", Duration: time.Hour},
+	{Name: "1d", Duration: 24 * time.Hour},
+}
+
+// KlineStore is the part of the kline table the builder needs
+type KlineStore interface {
+	GetLatestKline(tokenID, interval string, before time.Time) (*dbBackend.Kline, error)
+	UpsertKlines(list []*dbBackend.Kline) error
+}
+
+// lastCandle 最近写入的一根，同一根内的 tick 在内存里合并 high / low，供推送使用
+type lastCandle struct {
+	openTime time.Time
+	open     string
+	high     float64
+	low      float64
+	close    string
+}
+
+// KlineBuilder aggregates resolved price ticks into candles of every KlineInterval.
+// Providers only report rolling 24h volume, so candle volumes stay 0.
+type KlineBuilder struct {
+	store KlineStore
+
+	mu   sync.Mutex
+	last map[string]lastCandle // token_id|interval -> 最近写入的一根
+}
+
+// NewKlineBuilder creates a kline builder writing to store
+func NewKlineBuilder(store KlineStore) *KlineBuilder {
+	return &KlineBuilder{
+		store: store,
+		last:  make(map[string]lastCandle),
+	}
+}
+
+// Apply writes the asset-bound quotes as ticks and returns the current candle of every asset and
+// period. When the previous candle of a period is more than one period old (worker downtime, asset
+// without quotes for a while) the gap is filled with flat candles at the last close, so charts have no holes.
+func (b *KlineBuilder) Apply(ctx context.Context, quotes map[string]model.Quote) ([]*dbBackend.Kline, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	list := make([]*dbBackend.Kline, 0, len(quotes)*len(KlineIntervals))
+	current := make([]*dbBackend.Kline, 0, len(quotes)*len(KlineIntervals))
+	now := time.Now()
+	for _, q := range quotes {
+		if q.AssetID == "" || q.Price <= 0 {
+			continue
+		}
+		ts := q.Timestamp.UTC()
+		if ts.IsZero() || ts.After(now) {
+			ts = now.UTC()
+		}
+		price := formatPrice(q.Price)
+
+		for _, interval := range KlineIntervals {
+			openTime := ts.Truncate(interval.Duration)
+			key := q.AssetID + "|" + interval.Name
+
+			last, ok := b.last[key]
+			if !ok {
+				// 包含当前这一根：重启后继续合并其 high / low
+				latest, err := b.store.GetLatestKline(q.AssetID, interval.Name, openTime.Add(interval.Duration))
+				if err != nil {
+					b.last = make(map[string]lastCandle)
+					return nil, err
+				}
+				if latest != nil {
+					last = candleOf(latest)
+					ok = true
+				}
+			}
+			if ok && openTime.Before(last.openTime) {
+				// 源的时间戳落后于已写入的 K 线，忽略
+				continue
+			}
+			if ok && last.openTime.Before(openTime) {
+				list = append(list, backfill(q.AssetID, interval, last, openTime)...)
+			}
+
+			list = append(list, newKline(q.AssetID, interval.Name, openTime, price))
+			if !ok || !last.openTime.Equal(openTime) {
+				last = lastCandle{openTime: openTime, open: price, high: q.Price, low: q.Price}
+			}
+			last.high = math.Max(last.high, q.Price)
+			last.low = math.Min(last.low, q.Price)
+			last.close = price
+			b.last[key] = last
+
+			k := newKline(q.AssetID, interval.Name, openTime, price)
+			k.OpenPrice, k.HighPrice, k.LowPrice = last.open, formatPrice(last.high), formatPrice(last.low)
+			current = append(current, k)
+		}
+	}
+
+	if err := b.store.UpsertKlines(list); err != nil {
+		// 写入失败时下次重新从库里读取最近一根
+		b.last = make(map[string]lastCandle)
+		return nil, err
+	}
+	log.Debug("klines upserted", "count", len(list))
+	return current, nil
+}
+
+func candleOf(k *dbBackend.Kline) lastCandle {
+	high, _ := strconv.ParseFloat(k.HighPrice, 64)
+	low, _ := strconv.ParseFloat(k.LowPrice, 64)
+	return lastCandle{openTime: k.OpenTime.UTC(), open: k.OpenPrice, high: high, low: low, close: k.ClosePrice}
+}
+
+// backfill returns the flat candles between the last candle and openTime, at most maxKlineBackfill
+func backfill(tokenID string, interval KlineInterval, last lastCandle, openTime time.Time) []*dbBackend.Kline {
+	start := last.openTime.Add(interval.Duration)
+	if earliest := openTime.Add(-time.Duration(maxKlineBackfill) * interval.Duration); start.Before(earliest) {
+		start = earliest
+	}
+	var list []*dbBackend.Kline
+	for t := start; t.Before(openTime); t = t.Add(interval.Duration) {
+		list = append(list, newKline(tokenID, interval.Name, t, last.close))
+	}
+	return list
+}
+
+func newKline(tokenID, interval string, openTime time.Time, price string) *dbBackend.Kline {
+	now := time.Now()
+	return &dbBackend.Kline{
+		Guid:         uuid.New().String(),
+		TokenID:      tokenID,
+		TimeInterval: interval,
+		OpenTime:     openTime,
+		OpenPrice:    price,
+		HighPrice:    price,
+		LowPrice:     price,
+		ClosePrice:   price,
+		Volume:       "0",
+		QuoteVolume:  "0",
+		TradeCount:   "0",
+		CreateTime:   now,
+		UpdateTime:   now,
+	}
+}
+
+// formatPrice 与 numeric(20,8) 列精度一致
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', 8, 64)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/market/model"
+)
+
+// fakeKlineStore merges upserts like the ON CONFLICT clause of the kline table
+type fakeKlineStore struct {
+	candles map[string]*dbBackend.Kline
+}
+
+func (s *fakeKlineStore) key(tokenID, interval string, openTime time.Time) string {
+	return tokenID + "|" + interval + "|" + openTime.UTC().Format(time.RFC3339)
+}
+
+func (s *fakeKlineStore) GetLatestKline(tokenID, interval string, before time.Time) (*dbBackend.Kline, error) {
+	var latest *dbBackend.Kline
+	for _, k := range s.candles {
+		if k.TokenID == tokenID && k.TimeInterval == interval && k.OpenTime.Before(before) && (latest == nil || k.OpenTime.After(latest.OpenTime)) {
+			latest = k
+		}
+	}
+	return latest, nil
+}
+
+func (s *fakeKlineStore) UpsertKlines(list []*dbBackend.Kline) error {
+	for _, k := range list {
+		key := s.key(k.TokenID, k.TimeInterval, k.OpenTime)
+		existing, ok := s.candles[key]
+		if !ok {
+			s.candles[key] = k
+			continue
+		}
+		if k.HighPrice > existing.HighPrice {
+			existing.HighPrice = k.HighPrice
+		}
+		if k.LowPrice < existing.LowPrice {
+			existing.LowPrice = k.LowPrice
+		}
+		existing.ClosePrice = k.ClosePrice
+	}
+	return nil
+}
+
+func (s *fakeKlineStore) GetKlines(tokenID, interval string, start, end time.Time, limit int) ([]*dbBackend.Kline, error) {
+	var first *dbBackend.Kline
+	for _, k := range s.candles {
+		if k.TokenID == tokenID && k.TimeInterval == interval && !k.OpenTime.Before(start) && (first == nil || k.OpenTime.Before(first.OpenTime)) {
+			first = k
+		}
+	}
+	if first == nil {
+		return nil, nil
+	}
+	return []*dbBackend.Kline{first}, nil
+}
+
+type fakeMarketPrices map[string]*dbBackend.MarketPrice
+
+func (f fakeMarketPrices) UpsertByTokenID(m *dbBackend.MarketPrice) error {
+	f[m.TokenID] = m
+	return nil
+}
+
+func TestKlineBuilder(t *testing.T) {
+	minute := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+	store := &fakeKlineStore{candles: make(map[string]*dbBackend.Kline)}
+	// 停机前最后一根 1m K 线
+	require.NoError(t, store.UpsertKlines([]*dbBackend.Kline{newKline("eth", "1m", minute.Add(-3*time.Minute), formatPrice(2900))}))
+
+	b := NewKlineBuilder(store)
+	ctx := context.Background()
+	tick := func(price float64, at time.Time) []*dbBackend.Kline {
+		current, err := b.Apply(ctx, map[string]model.Quote{
+			"eth": {BaseAsset: "ETH", AssetID: "eth", Price: price, Timestamp: at},
+			"BTC": {BaseAsset: "BTC", Price: 60000, Timestamp: at}, // 未绑定资产，不写 K 线
+		})
+		require.NoError(t, err)
+		return current
+	}
+	tick(3000, minute.Add(5*time.Second))
+	tick(3050, minute.Add(20*time.Second))
+	current := tick(2980, minute.Add(40*time.Second))
+
+	// 返回的当前 K 线与库里合并后的一致
+	require.Len(t, current, len(KlineIntervals))
+	assert.Equal(t, "1m", current[0].TimeInterval)
+	assert.Equal(t, formatPrice(3000), current[0].OpenPrice)
+	assert.Equal(t, formatPrice(3050), current[0].HighPrice)
+	assert.Equal(t, formatPrice(2980), current[0].LowPrice)
+
+	k := store.candles[store.key("eth", "1m", minute)]
+	require.NotNil(t, k)
+	assert.Equal(t, formatPrice(3000), k.OpenPrice)
+	assert.Equal(t, formatPrice(3050), k.HighPrice)
+	assert.Equal(t, formatPrice(2980), k.LowPrice)
+	assert.Equal(t, formatPrice(2980), k.ClosePrice)
+
+	// 停机期间缺失的两根用上一根收盘价补齐
+	for _, gap := range []time.Duration{-2 * time.Minute, -time.Minute} {
+		k := store.candles[store.key("eth", "1m", minute.Add(gap))]
+		require.NotNil(t, k, "gap %s", gap)
+		assert.Equal(t, formatPrice(2900), k.OpenPrice)
+		assert.Equal(t, formatPrice(2900), k.ClosePrice)
+	}
+	assert.NotNil(t, store.candles[store.key("eth", "1h", minute)])
+	assert.NotNil(t, store.candles[store.key("eth", "1d", minute.Truncate(24*time.Hour))])
+	for _, k := range store.candles {
+		assert.Equal(t, "eth", k.TokenID)
+	}
+
+	prices := fakeMarketPrices{}
+	p := NewPricePersister(prices, store, nil)
+	require.NoError(t, p.Persist(ctx, map[string]model.Quote{
+		"eth":  {BaseAsset: "ETH", QuoteAsset: "USDT", AssetID: "eth", Price: 3300, Volume24h: 1234.567, Confidence: 0.8, Source: "binance,okx"},
+		"USDT": {BaseAsset: "USDT", QuoteAsset: "USD", Price: 0.999},
+	}))
+	m := prices["eth"]
+	require.NotNil(t, m)
+	assert.Equal(t, formatPrice(3300), m.UsdtPrice)
+	assert.Equal(t, formatPrice(3300*0.999), m.UsdPrice)
+	assert.Equal(t, "1234.57", m.Volume24h)
+	assert.Equal(t, "10.00", m.PriceChange) // 相对 24 小时内第一根 1h K 线开盘价 3000
+	assert.Equal(t, "0.8000", m.Confidence)
+	assert.Equal(t, "binance,okx", m.Source)
+	assert.Len(t, prices, 1)
+}
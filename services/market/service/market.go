@@ -9,9 +9,28 @@ import (
 	"github.com/roothash-pay/wallet-services/services/market/model"
 )
 
+// PriceKey 按符号的价格缓存 key
+func PriceKey(symbol string) string {
+	return "price:" + strings.ToUpper(symbol)
+}
+
+// TokenPriceKey 按规范资产（token guid）的价格缓存 key
+func TokenPriceKey(tokenID string) string {
+	return "price:token:" + tokenID
+}
+
+// ContractPriceKey 按链与合约地址的价格缓存 key
+func ContractPriceKey(chainID, contract string) string {
+	return "price:contract:" + chainID + ":" + model.NormalizeContract(contract)
+}
+
 type MarketService interface {
 	// 读缓存里的最新价格（只读，不裁决）
 	GetPrice(ctx context.Context, symbol string) (*model.Quote, error)
+	// 按 token 表 guid 读取规范资产的价格
+	GetPriceByTokenID(ctx context.Context, tokenID string) (*model.Quote, error)
+	// 按链与合约地址读取价格，同名代币互不影响
+	GetPriceByContract(ctx context.Context, chainID, contract string) (*model.Quote, error)
 }
 
 type marketService struct {
@@ -29,9 +48,18 @@ func (s *marketService) GetPrice(
 	ctx context.Context,
 	symbol string,
 ) (*model.Quote, error) {
+	return s.get(ctx, PriceKey(symbol))
+}
 
-	key := "price:" + strings.ToUpper(symbol)
+func (s *marketService) GetPriceByTokenID(ctx context.Context, tokenID string) (*model.Quote, error) {
+	return s.get(ctx, TokenPriceKey(tokenID))
+}
+
+func (s *marketService) GetPriceByContract(ctx context.Context, chainID, contract string) (*model.Quote, error) {
+	return s.get(ctx, ContractPriceKey(chainID, contract))
+}
 
+func (s *marketService) get(ctx context.Context, key string) (*model.Quote, error) {
 	data, ok, err := s.cache.Get(ctx, key)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/market/asset"
+	"github.com/roothash-pay/wallet-services/services/market/model"
+)
+
+// MarketPriceStore is the part of the market_price table the persister needs
+type MarketPriceStore interface {
+	UpsertByTokenID(m *dbBackend.MarketPrice) error
+}
+
+// KlineReader reads candles back to compute the 24h change
+type KlineReader interface {
+	GetKlines(tokenID, interval string, start, end time.Time, limit int) ([]*dbBackend.Kline, error)
+}
+
+// PricePersister upserts the resolved asset prices into market_price
+type PricePersister struct {
+	prices MarketPriceStore
+	klines KlineReader
+	assets *asset.Registry
+}
+
+// NewPricePersister creates a persister; klines may be nil, the 24h change is then 0
+func NewPricePersister(prices MarketPriceStore, klines KlineReader, assets *asset.Registry) *PricePersister {
+	return &PricePersister{
+		prices: prices,
+		klines: klines,
+		assets: assets,
+	}
+}
+
+// Persist writes one market_price row per asset-bound quote. Quotes are priced in USDT; the USD price
+// uses the resolved USDT price when there is one.
+func (p *PricePersister) Persist(ctx context.Context, quotes map[string]model.Quote) error {
+	usdPerUSDT := 1.0
+	if q, ok := quotes["USDT"]; ok && q.AssetID == "" && q.Price > 0 {
+		usdPerUSDT = q.Price
+	}
+
+	var firstErr error
+	written := 0
+	for _, q := range quotes {
+		if q.AssetID == "" || q.Price <= 0 {
+			continue
+		}
+		usdt, usd := q.Price, q.Price*usdPerUSDT
+		if strings.EqualFold(q.QuoteAsset, "USD") {
+			usdt, usd = q.Price/usdPerUSDT, q.Price
+		}
+
+		m := &dbBackend.MarketPrice{
+			TokenID:     q.AssetID,
+			UsdtPrice:   formatPrice(usdt),
+			UsdPrice:    formatPrice(usd),
+			MarketCap:   "0",
+			Liquidity:   "0",
+			Volume24h:   strconv.FormatFloat(q.Volume24h, 'f', 2, 64),
+			PriceChange: p.change24h(q.AssetID, usdt),
+			Ranking:     "",
+			Confidence:  strconv.FormatFloat(q.Confidence, 'f', 4, 64),
+			Source:      q.Source,
+		}
+		if p.assets != nil {
+			if a, ok := p.assets.Get(q.AssetID); ok && len(a.Contracts) > 0 {
+				m.ChainID = a.Contracts[0].ChainID
+			}
+		}
+		if err := p.prices.UpsertByTokenID(m); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		written++
+	}
+
+	log.Info("market prices persisted", "count", written)
+	return firstErr
+}
+
+// change24h 以 24 小时前那根 1h K 线的开盘价为基准，返回涨跌百分比
+func (p *PricePersister) change24h(tokenID string, price float64) string {
+	if p.klines == nil {
+		return "0"
+	}
+	list, err := p.klines.GetKlines(tokenID, "1h", time.Now().UTC().Add(-24*time.Hour), time.Time{}, 1)
+	if err != nil || len(list) == 0 {
+		return "0"
+	}
+	open, err := strconv.ParseFloat(list[0].OpenPrice, 64)
+	if err != nil || open <= 0 {
+		return "0"
+	}
+	return strconv.FormatFloat((price-open)/open*100, 'f', 2, 64)
+}
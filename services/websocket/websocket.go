@@ -3,6 +3,7 @@ package websocket
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,45 +16,156 @@ const (
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
 	maxMessageSize = 512
+
+	// maxChannels 每个连接最多订阅的行情频道数
+	maxChannels = 200
+	// maxSwaps 每个连接最多订阅的 swap 数
+	maxSwaps = 50
 )
 
+// WalletAuthenticator 校验客户端的钱包令牌，返回令牌绑定的钱包
+type WalletAuthenticator interface {
+	WalletUUID(token string) (string, error)
+}
+
+// PriceChannel 按符号的价格频道
+func PriceChannel(symbol string) string {
+	return "price:" + strings.ToUpper(symbol)
+}
+
+// TokenPriceChannel 按 token guid 的价格频道
+func TokenPriceChannel(tokenID string) string {
+	return "price:token:" + tokenID
+}
+
+// KlineChannel 某个 token 某个周期的 K 线频道
+func KlineChannel(tokenID, interval string) string {
+	return "kline:" + tokenID + ":" + interval
+}
+
 type Client struct {
 	hub    *Hub
 	conn   *websocket.Conn
 	send   chan []byte
 	mu     sync.Mutex
 	closed bool
+	// 令牌绑定的钱包，未认证时为空，只能订阅行情
+	walletUUID string
+	// 订阅的钱包与 swap，状态推送只发给订阅方
+	walletUUIDs map[string]bool
+	swapIDs     map[string]bool
+	// 订阅的行情 / K 线频道
+	channels map[string]bool
 }
 
 type Message struct {
-	Type string      `json:"type"`
-	Data interface{} `json:"data"`
-	Time int64       `json:"time"`
+	Type    string      `json:"type"`
+	Channel string      `json:"channel,omitempty"`
+	Data    interface{} `json:"data"`
+	Time    int64       `json:"time"`
 }
 
+// BridgeFinalizedMessage 跨链最终结果，作为 Message.Data 只推送给订阅了对应 wallet_uuid 或 swap_id 的连接
 type BridgeFinalizedMessage struct {
-	Type        string `json:"type"`
+	WalletUUID  string `json:"wallet_uuid"`
+	OperationID string `json:"operation_id"` // swap_id
+	StepIndex   int    `json:"step_index"`
 	FromAddress string `json:"from_address"`
 	ToAddress   string `json:"to_address"`
+	ChainID     string `json:"chain_id"`
 	TxHash      string `json:"tx_hash"`
+	DestChainID string `json:"dest_chain_id"`
+	DestTxHash  string `json:"dest_tx_hash"`
 	Status      int64  `json:"status"`
-	Time        int64  `json:"time"`
+}
+
+// TxStatusMessage 交易或 swap 状态流转，只推送给订阅了对应 wallet_uuid 或 swap_id 的连接
+type TxStatusMessage struct {
+	Type           string `json:"type"` // tx_status / swap_status
+	WalletUUID     string `json:"wallet_uuid"`
+	SwapID         string `json:"swap_id,omitempty"`
+	StepIndex      int    `json:"step_index"`
+	RecordGuid     string `json:"record_guid,omitempty"`
+	ChainID        string `json:"chain_id,omitempty"`
+	TxHash         string `json:"tx_hash,omitempty"`
+	FromStatus     int64  `json:"from_status"`
+	Status         int64  `json:"status"`
+	FailReasonCode string `json:"fail_reason_code,omitempty"`
+	FailReasonMsg  string `json:"fail_reason_msg,omitempty"`
+	Time           int64  `json:"time"`
+}
+
+// SubscribeRequest 客户端发送的订阅消息。订阅钱包 / swap 之前先用钱包令牌认证：{"action":"auth","token":"..."}，
+// 之后 {"action":"subscribe","wallet_uuid":"..."} / {"action":"subscribe","swap_id":"..."}，
+// 行情：{"action":"subscribe","channel":"price","symbol":"ETH"} / {"action":"subscribe","channel":"price","token_id":"..."}，
+// K 线：{"action":"subscribe","channel":"kline","token_id":"...","interval":"1m"}
+type SubscribeRequest struct {
+	Action     string `json:"action"` // auth / subscribe / unsubscribe
+	Token      string `json:"token,omitempty"`
+	WalletUUID string `json:"wallet_uuid,omitempty"`
+	SwapID     string `json:"swap_id,omitempty"`
+	Channel    string `json:"channel,omitempty"` // price / kline
+	Symbol     string `json:"symbol,omitempty"`
+	TokenID    string `json:"token_id,omitempty"`
+	Interval   string `json:"interval,omitempty"`
+}
+
+// channelKey 订阅的行情频道，非行情订阅返回空
+func (r *SubscribeRequest) channelKey() string {
+	switch r.Channel {
+	case "price":
+		if r.TokenID != "" {
+			return TokenPriceChannel(r.TokenID)
+		}
+		if r.Symbol != "" {
+			return PriceChannel(r.Symbol)
+		}
+	case "kline":
+		if r.TokenID != "" && r.Interval != "" {
+			return KlineChannel(r.TokenID, r.Interval)
+		}
+	}
+	return ""
+}
+
+// keyedMessage 只发给订阅了 walletUUID、swapID 或 channel 的连接
+type keyedMessage struct {
+	walletUUID string
+	swapID     string
+	channel    string
+	data       []byte
+}
+
+// snapshotRequest 新订阅的连接请求频道最近一条消息
+type snapshotRequest struct {
+	client  *Client
+	channel string
 }
 
 type Hub struct {
+	auth       WalletAuthenticator // 为 nil 时拒绝所有钱包 / swap 订阅
 	clients    map[*Client]bool
 	broadcast  chan []byte
+	publish    chan *keyedMessage
 	register   chan *Client
 	unregister chan *Client
+	snapshot   chan *snapshotRequest
 	mu         sync.RWMutex
+
+	// 每个频道最近一条消息，订阅时立即下发
+	lastByChannel map[string][]byte
 }
 
-func NewHub() *Hub {
+func NewHub(auth WalletAuthenticator) *Hub {
 	return &Hub{
-		clients:    make(map[*Client]bool),
-		broadcast:  make(chan []byte),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
+		auth:          auth,
+		clients:       make(map[*Client]bool),
+		broadcast:     make(chan []byte),
+		publish:       make(chan *keyedMessage),
+		register:      make(chan *Client),
+		unregister:    make(chan *Client),
+		snapshot:      make(chan *snapshotRequest),
+		lastByChannel: make(map[string][]byte),
 	}
 }
 
@@ -86,6 +198,27 @@ func (h *Hub) Run() {
 				}
 			}
 			h.mu.RUnlock()
+
+		case req := <-h.snapshot:
+			h.sendLast(req)
+
+		case message := <-h.publish:
+			h.mu.Lock()
+			if message.channel != "" {
+				h.lastByChannel[message.channel] = message.data
+			}
+			for client := range h.clients {
+				if !client.subscribed(message) {
+					continue
+				}
+				select {
+				case client.send <- message.data:
+				default:
+					close(client.send)
+					delete(h.clients, client)
+				}
+			}
+			h.mu.Unlock()
 		}
 	}
 }
@@ -106,20 +239,79 @@ func (h *Hub) Broadcast(event string, data any) {
 	h.broadcast <- b
 }
 
-func (h *Hub) BroadcastBridgeFinalized(msg *BridgeFinalizedMessage) {
-	// msg.Type = "bridge_finalized"
-	// msg.Time = time.Now().Unix()
+// PublishBridgeFinalized 推送跨链最终结果给订阅了该钱包或 swap 的连接
+func (h *Hub) PublishBridgeFinalized(msg *BridgeFinalizedMessage) {
+	data, err := json.Marshal(Message{
+		Type: "bridge_finalized",
+		Data: msg,
+		Time: time.Now().Unix(),
+	})
+	if err != nil {
+		log.Error("Failed to marshal bridge finalized message", "err", err)
+		return
+	}
+
+	h.publish <- &keyedMessage{
+		walletUUID: msg.WalletUUID,
+		swapID:     msg.OperationID,
+		data:       data,
+	}
+	log.Info("Published bridge finalized event", "tx_hash", msg.TxHash, "dest_tx_hash", msg.DestTxHash, "status", msg.Status)
+}
 
-	// data, err := json.Marshal(msg)
-	// if err != nil {
-	// 	log.Error("Failed to marshal bridge finalized message", "err", err)
-	// 	return
-	// }
+// PublishTxStatus 推送状态流转给订阅了该钱包或 swap 的连接
+func (h *Hub) PublishTxStatus(msg *TxStatusMessage) {
+	if msg.Time == 0 {
+		msg.Time = time.Now().Unix()
+	}
 
-	// h.broadcast <- data
-	// log.Info("Broadcasted bridge finalized event", "tx_hash", msg.TxHash)
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Error("Failed to marshal tx status message", "err", err)
+		return
+	}
 
-	h.Broadcast("bridge_finalized", msg)
+	h.publish <- &keyedMessage{
+		walletUUID: msg.WalletUUID,
+		swapID:     msg.SwapID,
+		data:       data,
+	}
+}
+
+// PublishChannel 推送行情 / K 线消息给订阅了该频道的连接
+func (h *Hub) PublishChannel(channel string, event string, data any) {
+	msg := Message{
+		Type:    event,
+		Channel: channel,
+		Data:    data,
+		Time:    time.Now().Unix(),
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		log.Error("failed to marshal ws message", "event", event, "channel", channel, "err", err)
+		return
+	}
+
+	h.publish <- &keyedMessage{
+		channel: channel,
+		data:    b,
+	}
+}
+
+// sendLast 把频道最近一条消息发给刚订阅的连接；连接已被移除（send 已关闭）时跳过
+func (h *Hub) sendLast(req *snapshotRequest) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	last, ok := h.lastByChannel[req.channel]
+	if !ok || !h.clients[req.client] {
+		return
+	}
+	select {
+	case req.client.send <- last:
+	default:
+	}
 }
 
 func (h *Hub) GetClientCount() int {
@@ -170,7 +362,102 @@ func (c *Client) readPump() {
 		}
 
 		log.Debug("Received WebSocket message", "message", string(message))
+		c.handleMessage(message)
+	}
+}
+
+// handleMessage 处理订阅 / 取消订阅，其余消息忽略
+func (c *Client) handleMessage(message []byte) {
+	var req SubscribeRequest
+	if err := json.Unmarshal(message, &req); err != nil {
+		return
+	}
+
+	if req.Action == "auth" {
+		c.authenticate(req.Token)
+		return
+	}
+
+	channel := req.channelKey()
+
+	c.mu.Lock()
+	switch req.Action {
+	case "subscribe":
+		if c.walletUUIDs == nil {
+			c.walletUUIDs = make(map[string]bool)
+			c.swapIDs = make(map[string]bool)
+			c.channels = make(map[string]bool)
+		}
+		// 只能订阅令牌绑定的钱包；swap 推送在下发时再核对所属钱包
+		if req.WalletUUID != "" && req.WalletUUID == c.walletUUID {
+			c.walletUUIDs[req.WalletUUID] = true
+		}
+		if req.SwapID != "" && c.walletUUID != "" && (c.swapIDs[req.SwapID] || len(c.swapIDs) < maxSwaps) {
+			c.swapIDs[req.SwapID] = true
+		}
+		if channel != "" && (c.channels[channel] || len(c.channels) < maxChannels) {
+			c.channels[channel] = true
+		} else {
+			channel = ""
+		}
+	case "unsubscribe":
+		delete(c.walletUUIDs, req.WalletUUID)
+		delete(c.swapIDs, req.SwapID)
+		delete(c.channels, channel)
+		channel = ""
+	default:
+		channel = ""
+	}
+	c.mu.Unlock()
+
+	// 订阅行情后立即下发该频道最近一条，不用等下一次变化
+	// 经由 Run 循环处理，保证在注册之后、且不会写入已关闭的 send
+	if channel != "" && c.hub != nil {
+		c.hub.snapshot <- &snapshotRequest{client: c, channel: channel}
+	}
+}
+
+// authenticate 校验钱包令牌并绑定钱包，换绑钱包时清空之前的钱包 / swap 订阅
+func (c *Client) authenticate(token string) {
+	var walletUUID string
+	if c.hub != nil && c.hub.auth != nil {
+		var err error
+		if walletUUID, err = c.hub.auth.WalletUUID(token); err != nil {
+			log.Debug("WebSocket auth failed", "err", err)
+		}
+	}
+
+	c.mu.Lock()
+	if walletUUID != c.walletUUID {
+		c.walletUUID = walletUUID
+		c.walletUUIDs = make(map[string]bool)
+		c.swapIDs = make(map[string]bool)
+	}
+	c.mu.Unlock()
+
+	c.reply("auth", map[string]interface{}{"success": walletUUID != "", "wallet_uuid": walletUUID})
+}
+
+// reply 回复当前连接，发送缓冲已满时丢弃
+func (c *Client) reply(msgType string, data interface{}) {
+	b, err := json.Marshal(Message{Type: msgType, Data: data, Time: time.Now().Unix()})
+	if err != nil {
+		return
 	}
+	select {
+	case c.send <- b:
+	default:
+	}
+}
+
+// subscribed 是否订阅了该钱包、swap 或频道；钱包 / swap 推送只发给绑定了消息所属钱包的连接
+func (c *Client) subscribed(message *keyedMessage) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	owned := c.walletUUID != "" && message.walletUUID == c.walletUUID
+	return (owned && (c.walletUUIDs[message.walletUUID] || (message.swapID != "" && c.swapIDs[message.swapID]))) ||
+		(message.channel != "" && c.channels[message.channel])
 }
 
 func (c *Client) writePump() {
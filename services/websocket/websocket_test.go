@@ -0,0 +1,206 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenAuth 令牌即 "token-" + wallet_uuid
+type tokenAuth struct{}
+
+func (tokenAuth) WalletUUID(token string) (string, error) {
+	if len(token) > 6 && token[:6] == "token-" {
+		return token[6:], nil
+	}
+	return "", errors.New("invalid token")
+}
+
+func newTestClient(hub *Hub) *Client {
+	client := &Client{
+		hub:  hub,
+		send: make(chan []byte, 4),
+	}
+	hub.register <- client
+	return client
+}
+
+func receive(t *testing.T, client *Client) *TxStatusMessage {
+	select {
+	case data := <-client.send:
+		var msg TxStatusMessage
+		require.NoError(t, json.Unmarshal(data, &msg))
+		return &msg
+	case <-time.After(100 * time.Millisecond):
+		return nil
+	}
+}
+
+func TestPublishTxStatusToSubscribers(t *testing.T) {
+	hub := NewHub(tokenAuth{})
+	go hub.Run()
+
+	byWallet := newTestClient(hub)
+	bySwap := newTestClient(hub)
+	other := newTestClient(hub)
+
+	authenticate(t, byWallet, "wallet-1")
+	authenticate(t, bySwap, "wallet-1")
+	authenticate(t, other, "wallet-2")
+	byWallet.handleMessage([]byte(`{"action":"subscribe","wallet_uuid":"wallet-1"}`))
+	bySwap.handleMessage([]byte(`{"action":"subscribe","swap_id":"swap-1"}`))
+	other.handleMessage([]byte(`{"action":"subscribe","wallet_uuid":"wallet-2"}`))
+	// 其它钱包的连接订阅同一 swap 也收不到
+	other.handleMessage([]byte(`{"action":"subscribe","swap_id":"swap-1"}`))
+
+	hub.PublishTxStatus(&TxStatusMessage{Type: "swap_status", WalletUUID: "wallet-1", SwapID: "swap-1", Status: 3})
+
+	for _, client := range []*Client{byWallet, bySwap} {
+		msg := receive(t, client)
+		require.NotNil(t, msg)
+		assert.Equal(t, "swap_status", msg.Type)
+		assert.Equal(t, "swap-1", msg.SwapID)
+		assert.EqualValues(t, 3, msg.Status)
+		assert.NotZero(t, msg.Time)
+	}
+	assert.Nil(t, receive(t, other))
+}
+
+func TestUnsubscribe(t *testing.T) {
+	hub := NewHub(tokenAuth{})
+	go hub.Run()
+
+	client := newTestClient(hub)
+	authenticate(t, client, "wallet-1")
+	client.handleMessage([]byte(`{"action":"subscribe","wallet_uuid":"wallet-1"}`))
+	client.handleMessage([]byte(`{"action":"unsubscribe","wallet_uuid":"wallet-1"}`))
+	client.handleMessage([]byte(`not json`))
+
+	hub.PublishTxStatus(&TxStatusMessage{Type: "tx_status", WalletUUID: "wallet-1"})
+	assert.Nil(t, receive(t, client))
+}
+
+// authenticate 用钱包令牌认证并确认回执
+func authenticate(t *testing.T, client *Client, walletUUID string) {
+	client.handleMessage([]byte(`{"action":"auth","token":"token-` + walletUUID + `"}`))
+	msg := receiveMessage(t, client)
+	require.NotNil(t, msg)
+	assert.Equal(t, "auth", msg.Type)
+	assert.Equal(t, map[string]interface{}{"success": true, "wallet_uuid": walletUUID}, msg.Data)
+}
+
+func receiveMessage(t *testing.T, client *Client) *Message {
+	select {
+	case data := <-client.send:
+		var msg Message
+		require.NoError(t, json.Unmarshal(data, &msg))
+		return &msg
+	case <-time.After(100 * time.Millisecond):
+		return nil
+	}
+}
+
+func TestPublishChannel(t *testing.T) {
+	hub := NewHub(tokenAuth{})
+	go hub.Run()
+
+	bySymbol := newTestClient(hub)
+	byToken := newTestClient(hub)
+	byKline := newTestClient(hub)
+
+	bySymbol.handleMessage([]byte(`{"action":"subscribe","channel":"price","symbol":"eth"}`))
+	byToken.handleMessage([]byte(`{"action":"subscribe","channel":"price","token_id":"token-1"}`))
+	byKline.handleMessage([]byte(`{"action":"subscribe","channel":"kline","token_id":"token-1","interval":"1m"}`))
+	// 缺少 interval 的 K 线订阅无效
+	bySymbol.handleMessage([]byte(`{"action":"subscribe","channel":"kline","token_id":"token-1"}`))
+
+	hub.PublishChannel(PriceChannel("ETH"), "market:price", map[string]float64{"price": 3000})
+	hub.PublishChannel(TokenPriceChannel("token-1"), "market:price", map[string]float64{"price": 1})
+	hub.PublishChannel(KlineChannel("token-1", "1m"), "market:kline", map[string]string{"close": "1"})
+
+	for client, channel := range map[*Client]string{
+		bySymbol: "price:ETH",
+		byToken:  "price:token:token-1",
+		byKline:  "kline:token-1:1m",
+	} {
+		msg := receiveMessage(t, client)
+		require.NotNil(t, msg, channel)
+		assert.Equal(t, channel, msg.Channel)
+		assert.Nil(t, receiveMessage(t, client), channel)
+	}
+
+	// 新订阅立即收到最近一条
+	late := newTestClient(hub)
+	late.handleMessage([]byte(`{"action":"subscribe","channel":"price","symbol":"ETH"}`))
+	msg := receiveMessage(t, late)
+	require.NotNil(t, msg)
+	assert.Equal(t, "market:price", msg.Type)
+
+	late.handleMessage([]byte(`{"action":"unsubscribe","channel":"price","symbol":"ETH"}`))
+	hub.PublishChannel(PriceChannel("ETH"), "market:price", map[string]float64{"price": 3001})
+	assert.Nil(t, receiveMessage(t, late))
+	assert.NotNil(t, receiveMessage(t, bySymbol))
+}
+
+func TestPublishBridgeFinalized(t *testing.T) {
+	hub := NewHub(tokenAuth{})
+	go hub.Run()
+
+	bySwap := newTestClient(hub)
+	other := newTestClient(hub)
+	authenticate(t, bySwap, "wallet-1")
+	authenticate(t, other, "wallet-2")
+	bySwap.handleMessage([]byte(`{"action":"subscribe","swap_id":"swap-1"}`))
+	other.handleMessage([]byte(`{"action":"subscribe","wallet_uuid":"wallet-2"}`))
+
+	hub.PublishBridgeFinalized(&BridgeFinalizedMessage{WalletUUID: "wallet-1", OperationID: "swap-1", TxHash: "0xsrc", DestTxHash: "0xdst", Status: 3})
+
+	msg := receiveMessage(t, bySwap)
+	require.NotNil(t, msg)
+	assert.Equal(t, "bridge_finalized", msg.Type)
+	assert.NotZero(t, msg.Time)
+	data, ok := msg.Data.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "0xdst", data["dest_tx_hash"])
+	// 未订阅该钱包 / swap 的连接收不到
+	assert.Nil(t, receiveMessage(t, other))
+}
+
+func TestKeyedSubscribeRequiresWalletToken(t *testing.T) {
+	hub := NewHub(tokenAuth{})
+	go hub.Run()
+
+	anonymous := newTestClient(hub)
+	anonymous.handleMessage([]byte(`{"action":"subscribe","wallet_uuid":"wallet-1"}`))
+	anonymous.handleMessage([]byte(`{"action":"subscribe","swap_id":"swap-1"}`))
+	anonymous.handleMessage([]byte(`{"action":"auth","token":"forged"}`))
+	msg := receiveMessage(t, anonymous)
+	require.NotNil(t, msg)
+	assert.Equal(t, map[string]interface{}{"success": false, "wallet_uuid": ""}, msg.Data)
+
+	// 认证后也只能订阅令牌绑定的钱包
+	other := newTestClient(hub)
+	authenticate(t, other, "wallet-2")
+	other.handleMessage([]byte(`{"action":"subscribe","wallet_uuid":"wallet-1"}`))
+
+	hub.PublishTxStatus(&TxStatusMessage{Type: "swap_status", WalletUUID: "wallet-1", SwapID: "swap-1"})
+	assert.Nil(t, receive(t, anonymous))
+	assert.Nil(t, receive(t, other))
+
+	// 每个连接订阅的 swap 数有上限
+	client := newTestClient(hub)
+	authenticate(t, client, "wallet-1")
+	for i := 0; i < maxSwaps+10; i++ {
+		client.handleMessage([]byte(`{"action":"subscribe","swap_id":"swap-` + strconv.Itoa(i) + `"}`))
+	}
+	assert.Len(t, client.swapIDs, maxSwaps)
+
+	// 换绑钱包时清空之前的订阅
+	authenticate(t, client, "wallet-2")
+	assert.Empty(t, client.swapIDs)
+}
@@ -21,6 +21,11 @@ import (
 	"github.com/roothash-pay/wallet-services/config"
 	"github.com/roothash-pay/wallet-services/database"
 	"github.com/roothash-pay/wallet-services/metrics"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/provider"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/provider/lifi"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/status"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/store"
+	"github.com/roothash-pay/wallet-services/services/common"
 	"github.com/roothash-pay/wallet-services/services/common/chaininfo"
 	"github.com/roothash-pay/wallet-services/services/grpc_client/account"
 	"github.com/roothash-pay/wallet-services/services/market/cache"
@@ -37,6 +42,7 @@ type WalletServices struct {
 	marketPriceWorker  *market_task.MarketPriceWorker
 	fiatCurrencyWorker *market_task.FiatCurrencyWorker
 	txRecordWorker     *aggregator_task.WalletTxRecordWorker
+	txStatusPushWorker *aggregator_task.TxStatusPushWorker
 	wsHub              *websocket.Hub
 	wsServer           *httputil.HTTPServer
 	shutdown           context.CancelCauseFunc
@@ -88,6 +94,8 @@ func (as *WalletServices) Start(ctx context.Context) error {
 		return errFcwWorker
 	}
 
+	as.txStatusPushWorker.Start()
+
 	// Start tx record worker if initialized
 	if as.txRecordWorker != nil {
 		as.txRecordWorker.Start()
@@ -106,6 +114,10 @@ func (as *WalletServices) Stop(ctx context.Context) error {
 		as.txRecordWorker.Stop()
 	}
 
+	if as.txStatusPushWorker != nil {
+		as.txStatusPushWorker.Stop()
+	}
+
 	if as.DB != nil {
 		if err := as.DB.Close(); err != nil {
 			result = errors.Join(result, fmt.Errorf("failed to close DB: %w", err))
@@ -144,7 +156,8 @@ func (as *WalletServices) initFromConfig(ctx context.Context, cfg *config.Config
 		return fmt.Errorf("failed to init DB: %w", err)
 	}
 
-	as.wsHub = websocket.NewHub()
+	// 钱包 / swap 订阅需要 API 签发的钱包令牌
+	as.wsHub = websocket.NewHub(common.NewSIWEVerifier(cfg.JWTSecret, cfg.Domain))
 	go as.wsHub.Run()
 
 	if err := as.startWebSocketServer(cfg.WebsocketServer); err != nil {
@@ -202,16 +215,20 @@ func (as *WalletServices) initWorker(cfg *config.Config) error {
 	}
 	as.marketPriceWorker = marketPriceWorker
 
-	fiatCurrencyWorkerConfig := &market_task.FiatCurrencyWorkerConfig{
-		LoopInterval: time.Second * 5,
-	}
-	fiatCurrencyWorker, err := market_task.NewFiatCurrencyWorker(as.DB, fiatCurrencyWorkerConfig, as.wsHub, as.shutdown)
+	fiatCurrencyWorker, err := market_task.NewFiatCurrencyWorker(as.DB, &cfg.FiatCurrencyWorkerConfig, as.wsHub, metrics.NewFiatRateMetrics(as.metricsRegistry, "fiat"), as.shutdown)
 	if err != nil {
 		log.Error("new fiat currency worker fail", "err", err)
 		return err
 	}
 	as.fiatCurrencyWorker = fiatCurrencyWorker
 
+	// 状态流转（含 API 进程产生的）由审计表推送给 websocket 订阅方
+	as.txStatusPushWorker = aggregator_task.NewTxStatusPushWorker(
+		as.DB.BackendTxTransition,
+		as.wsHub,
+		aggregator_task.TxStatusPushWorkerConfig{},
+	)
+
 	// Initialize wallet tx record worker if aggregator is enabled
 	if cfg.AggregatorConfig.WalletAccountAddr != "" {
 		accountClient, err := account.NewWalletAccountClient(cfg.AggregatorConfig.WalletAccountAddr)
@@ -235,24 +252,49 @@ func (as *WalletServices) initWorker(cfg *config.Config) error {
 				log.Warn("failed to warm up chain info cache for worker", "err", warmErr)
 			}
 
+			// LiFi 跨链交易需跟踪到目标链到账
+			var bridgeStatus provider.BridgeStatusProvider
+			if cfg.AggregatorConfig.EnableProviders["lifi"] && cfg.AggregatorConfig.LiFiAPIURL != "" {
+				bridgeStatus = lifi.NewProvider(cfg.AggregatorConfig.LiFiAPIURL, cfg.AggregatorConfig.LiFiAPIKey, nil)
+			}
+
+			// swap 步骤的链上结果同步回 swap，Redis 可用时与 API 共用缓存
+			var swapStore store.SwapStore = store.NewDBSwapStore(as.DB.BackendSwap)
+			if chainInfoRedis != nil {
+				swapStore = store.NewCachedSwapStore(swapStore, store.NewRedisSwapStore(chainInfoRedis.Client))
+			}
+			statusEngine := status.NewEngine(as.DB.BackendWalletTxRecord, as.DB.BackendTxTransition, swapStore)
+
 			txWorkerConfig := aggregator_task.WalletTxRecordWorkerConfig{
-				ScanInterval:         10,   // 10 seconds
-				LastCheckedThreshold: 5,    // 5 seconds
-				BatchSize:            100,  // 100 records per batch
-				Concurrency:          10,   // 10 concurrent workers
-				TimeoutThreshold:     3600, // 1 hour timeout
+				ScanInterval:           2,     // claim due txs every 2 seconds
+				BatchSize:              100,   // 100 records per chain per claim
+				Concurrency:            10,    // 10 concurrent workers
+				LeaseDuration:          60,    // claimed rows come back after 60 seconds if unfinished
+				DefaultBlockTimeMs:     12000, // chain.block_time_ms overrides
+				TimeoutBlocks:          300,   // ~1 hour on ethereum
+				MinTimeout:             120,   // 2 minutes
+				MaxCheckInterval:       60,    // backoff cap
+				StaleAge:               900,   // pending for 15 minutes is stale
+				StaleCheckInterval:     300,   // stale txs are probed every 5 minutes
+				BridgeTimeoutThreshold: 86400, // 24 hours bridge timeout
+				Confirmations:          12,    // L1 default, chain.required_confirmations overrides
+				L2Confirmations:        1,     // L2 default (sequencer inclusion)
+				SolanaCommitment:       "finalized",
 			}
 			txRecordWorker := aggregator_task.NewWalletTxRecordWorker(
 				as.DB.BackendWalletTxRecord,
 				accountClient,
 				chainInfoManager,
+				bridgeStatus,
+				statusEngine,
+				as.wsHub,
 				txWorkerConfig,
 			)
 			as.txRecordWorker = txRecordWorker
 			log.Info("Wallet tx record worker initialized",
 				"scanInterval", txWorkerConfig.ScanInterval,
 				"concurrency", txWorkerConfig.Concurrency,
-				"timeout", txWorkerConfig.TimeoutThreshold)
+				"timeoutBlocks", txWorkerConfig.TimeoutBlocks)
 		}
 	} else {
 		log.Info("Wallet tx record worker not initialized: wallet_account_addr not configured")
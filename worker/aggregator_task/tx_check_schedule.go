@@ -0,0 +1,80 @@
+// tx_check_schedule.go
+package aggregator_task
+
+import (
+	"context"
+	"time"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+)
+
+// minCheckInterval 出块很快的链也不低于此间隔轮询
+const minCheckInterval = time.Second
+
+// maxBackoffShift 退避指数上限，避免移位溢出
+const maxBackoffShift = 16
+
+// chainSchedule 单链的轮询参数，由出块时间推导
+type chainSchedule struct {
+	blockTime time.Duration
+	timeout   time.Duration // 超过此时间仍未上链判超时
+}
+
+// scheduleFor 返回链的轮询参数，链未配置出块时间时使用默认值
+func (w *WalletTxRecordWorker) scheduleFor(ctx context.Context, chainID string) chainSchedule {
+	blockTime := time.Duration(w.config.DefaultBlockTimeMs) * time.Millisecond
+	if info, err := w.getChainInfo(ctx, chainID); err == nil && info.BlockTimeMs > 0 {
+		blockTime = time.Duration(info.BlockTimeMs) * time.Millisecond
+	}
+
+	timeout := blockTime * time.Duration(w.config.TimeoutBlocks)
+	if minTimeout := time.Duration(w.config.MinTimeout) * time.Second; timeout < minTimeout {
+		timeout = minTimeout
+	}
+	return chainSchedule{blockTime: blockTime, timeout: timeout}
+}
+
+// checkInterval 距下次检查的间隔：
+// 等待确认深度的交易每个区块检查一次；跨链交易等待目标链按退避上限轮询；
+// 新提交的交易从 1 个区块开始按 2^n 退避；陈旧交易低频探测
+func (w *WalletTxRecordWorker) checkInterval(record *dbBackend.WalletTxRecord, sched chainSchedule, now time.Time) time.Duration {
+	maxInterval := time.Duration(w.config.MaxCheckInterval) * time.Second
+
+	var interval time.Duration
+	switch {
+	case record.Status == dbBackend.TxStatusConfirming:
+		interval = sched.blockTime
+	case w.isBridgeInFlight(record):
+		return maxInterval
+	case now.Sub(record.CreateTime) > time.Duration(w.config.StaleAge)*time.Second:
+		return time.Duration(w.config.StaleCheckInterval) * time.Second
+	default:
+		shift := record.CheckCount - 1
+		if shift < 0 {
+			shift = 0
+		}
+		if shift > maxBackoffShift {
+			shift = maxBackoffShift
+		}
+		interval = sched.blockTime << shift
+	}
+
+	if interval < minCheckInterval {
+		interval = minCheckInterval
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+	return interval
+}
+
+// nextCheckAt 下次检查时间，未上链的交易不晚于其超时时刻
+func (w *WalletTxRecordWorker) nextCheckAt(record *dbBackend.WalletTxRecord, sched chainSchedule, now time.Time) time.Time {
+	next := now.Add(w.checkInterval(record, sched, now))
+	if record.Status == dbBackend.TxStatusPending && !w.isBridgeInFlight(record) {
+		if deadline := timeoutBase(record).Add(sched.timeout); deadline.After(now) && next.After(deadline) {
+			next = deadline
+		}
+	}
+	return next
+}
@@ -0,0 +1,66 @@
+package aggregator_task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+)
+
+func TestCheckInterval(t *testing.T) {
+	w := NewWalletTxRecordWorker(nil, nil, nil, nil, nil, nil, WalletTxRecordWorkerConfig{})
+	now := time.Now()
+	eth := chainSchedule{blockTime: 12 * time.Second, timeout: time.Hour}
+	sol := chainSchedule{blockTime: 400 * time.Millisecond, timeout: 2 * time.Minute}
+
+	fresh := func(checks int) *dbBackend.WalletTxRecord {
+		return &dbBackend.WalletTxRecord{Status: dbBackend.TxStatusPending, CheckCount: checks, CreateTime: now.Add(-30 * time.Second)}
+	}
+
+	// fresh txs back off from one block, capped
+	assert.Equal(t, 12*time.Second, w.checkInterval(fresh(1), eth, now))
+	assert.Equal(t, 24*time.Second, w.checkInterval(fresh(2), eth, now))
+	assert.Equal(t, 60*time.Second, w.checkInterval(fresh(5), eth, now))
+	assert.Equal(t, 60*time.Second, w.checkInterval(fresh(100), eth, now))
+
+	// fast chains are not polled more than once a second
+	assert.Equal(t, time.Second, w.checkInterval(fresh(1), sol, now))
+	assert.Equal(t, 1600*time.Millisecond, w.checkInterval(fresh(3), sol, now))
+
+	// confirming txs follow the block time
+	confirming := fresh(10)
+	confirming.Status = dbBackend.TxStatusConfirming
+	assert.Equal(t, 12*time.Second, w.checkInterval(confirming, eth, now))
+
+	// stale txs are probed rarely
+	stale := fresh(20)
+	stale.CreateTime = now.Add(-20 * time.Minute)
+	assert.Equal(t, 5*time.Minute, w.checkInterval(stale, eth, now))
+}
+
+func TestNextCheckAtNotAfterTimeout(t *testing.T) {
+	w := NewWalletTxRecordWorker(nil, nil, nil, nil, nil, nil, WalletTxRecordWorkerConfig{})
+	now := time.Now()
+	sched := chainSchedule{blockTime: 12 * time.Second, timeout: time.Hour}
+
+	record := &dbBackend.WalletTxRecord{Status: dbBackend.TxStatusPending, CheckCount: 30, CreateTime: now.Add(-time.Hour + 10*time.Second)}
+	assert.Equal(t, record.CreateTime.Add(time.Hour), w.nextCheckAt(record, sched, now))
+}
+
+func TestTimeoutFromLastSeen(t *testing.T) {
+	w := NewWalletTxRecordWorker(nil, nil, nil, nil, nil, nil, WalletTxRecordWorkerConfig{})
+	now := time.Now()
+	sched := chainSchedule{blockTime: 12 * time.Second, timeout: time.Hour}
+
+	record := &dbBackend.WalletTxRecord{Status: dbBackend.TxStatusPending, CheckCount: 30, CreateTime: now.Add(-2 * time.Hour)}
+	assert.True(t, w.isTimeout(record, sched, now))
+
+	// 重组退回 PENDING 或刚在 mempool 中查到：超时从最后一次查到算起
+	lastSeen := now.Add(-58 * time.Minute)
+	record.LastSeenAt = &lastSeen
+	assert.False(t, w.isTimeout(record, sched, now))
+	assert.Equal(t, lastSeen.Add(time.Hour), w.nextCheckAt(record, sched, now))
+	assert.True(t, w.isTimeout(record, sched, lastSeen.Add(time.Hour+time.Second)))
+}
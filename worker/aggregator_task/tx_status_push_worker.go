@@ -0,0 +1,151 @@
+// tx_status_push_worker.go
+package aggregator_task
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/websocket"
+)
+
+// TxStatusPushWorkerConfig 配置
+type TxStatusPushWorkerConfig struct {
+	// 轮询间隔（毫秒）
+	PollInterval int
+	// 每次读取的最大记录数
+	BatchSize int
+	// 回看窗口（毫秒）：created_at 早于已读位置但晚提交的流转在窗口内仍会被读到
+	Overlap int
+}
+
+// txStatusPublisher 推送状态消息，由 websocket.Hub 实现
+type txStatusPublisher interface {
+	PublishTxStatus(msg *websocket.TxStatusMessage)
+}
+
+// TxStatusPushWorker 读取 tx_status_transition 的新记录并推送给订阅方。
+// 状态流转可能发生在 API 进程，审计表即事件源，推送统一由持有 websocket.Hub 的进程完成。
+// 自增 id 按分配顺序而不是提交顺序可见，因此按 created_at 轮询并回看 Overlap，已推送的 id 去重。
+type TxStatusPushWorker struct {
+	db        dbBackend.TxStatusTransitionDB
+	publisher txStatusPublisher
+	config    TxStatusPushWorkerConfig
+	cursor    time.Time           // 已读到的最大 created_at
+	pushed    map[int64]time.Time // 回看窗口内已推送的 id -> created_at
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewTxStatusPushWorker 创建 worker
+func NewTxStatusPushWorker(db dbBackend.TxStatusTransitionDB, wsHub *websocket.Hub, config TxStatusPushWorkerConfig) *TxStatusPushWorker {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 1000 // 默认 1 秒
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 200 // 默认 200 条
+	}
+	if config.Overlap <= 0 {
+		config.Overlap = 30000 // 默认 30 秒
+	}
+
+	return &TxStatusPushWorker{
+		db:        db,
+		publisher: wsHub,
+		config:    config,
+		pushed:    make(map[int64]time.Time),
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start 启动 worker，从启动时刻往前回看一个窗口开始推送
+func (w *TxStatusPushWorker) Start() {
+	w.cursor = time.Now()
+
+	w.wg.Add(1)
+	go w.run()
+	log.Info("TxStatusPushWorker started", "pollInterval", w.config.PollInterval, "from", w.cursor)
+}
+
+// Stop 停止 worker
+func (w *TxStatusPushWorker) Stop() {
+	close(w.stopCh)
+	w.wg.Wait()
+	log.Info("TxStatusPushWorker stopped")
+}
+
+// run 主循环
+func (w *TxStatusPushWorker) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(w.config.PollInterval) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.push()
+		}
+	}
+}
+
+// push 推送回看窗口起点之后尚未推送的流转，一批读满时继续读下一批
+func (w *TxStatusPushWorker) push() {
+	overlap := time.Duration(w.config.Overlap) * time.Millisecond
+	since, afterID := w.cursor.Add(-overlap), int64(0)
+	for {
+		transitions, err := w.db.GetTransitionsSince(since, afterID, w.config.BatchSize)
+		if err != nil {
+			log.Error("Failed to get status transitions", "since", since, "err", err)
+			return
+		}
+
+		for _, t := range transitions {
+			since, afterID = t.CreateTime, t.ID
+			if t.CreateTime.After(w.cursor) {
+				w.cursor = t.CreateTime
+			}
+			if _, ok := w.pushed[t.ID]; ok {
+				continue
+			}
+			w.publisher.PublishTxStatus(toTxStatusMessage(t))
+			w.pushed[t.ID] = t.CreateTime
+		}
+
+		if len(transitions) < w.config.BatchSize {
+			break
+		}
+	}
+
+	// 窗口之外的 id 不会再被读到
+	for id, createdAt := range w.pushed {
+		if createdAt.Before(w.cursor.Add(-overlap)) {
+			delete(w.pushed, id)
+		}
+	}
+}
+
+func toTxStatusMessage(t *dbBackend.TxStatusTransition) *websocket.TxStatusMessage {
+	msgType := "tx_status"
+	if t.Kind == dbBackend.TransitionKindSwap {
+		msgType = "swap_status"
+	}
+	return &websocket.TxStatusMessage{
+		Type:           msgType,
+		WalletUUID:     t.WalletUUID,
+		SwapID:         t.OperationID,
+		StepIndex:      t.StepIndex,
+		RecordGuid:     t.RecordGuid,
+		ChainID:        t.ChainID,
+		TxHash:         t.TxID,
+		FromStatus:     int64(t.FromStatus),
+		Status:         int64(t.ToStatus),
+		FailReasonCode: t.FailReasonCode,
+		FailReasonMsg:  t.FailReasonMsg,
+		Time:           t.CreateTime.Unix(),
+	}
+}
@@ -0,0 +1,79 @@
+package aggregator_task
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/websocket"
+)
+
+// memTransitions keeps the committed transitions in memory
+type memTransitions struct {
+	dbBackend.TxStatusTransitionDB
+	rows []*dbBackend.TxStatusTransition
+}
+
+func (m *memTransitions) GetTransitionsSince(since time.Time, afterID int64, limit int) ([]*dbBackend.TxStatusTransition, error) {
+	sort.Slice(m.rows, func(i, j int) bool {
+		if !m.rows[i].CreateTime.Equal(m.rows[j].CreateTime) {
+			return m.rows[i].CreateTime.Before(m.rows[j].CreateTime)
+		}
+		return m.rows[i].ID < m.rows[j].ID
+	})
+	var list []*dbBackend.TxStatusTransition
+	for _, r := range m.rows {
+		if r.CreateTime.After(since) || (r.CreateTime.Equal(since) && r.ID > afterID) {
+			list = append(list, r)
+		}
+		if len(list) == limit {
+			break
+		}
+	}
+	return list, nil
+}
+
+type recordingPublisher []int64
+
+func (p *recordingPublisher) PublishTxStatus(msg *websocket.TxStatusMessage) {
+	*p = append(*p, int64(msg.StepIndex))
+}
+
+func TestTxStatusPushWorkerLateCommit(t *testing.T) {
+	start := time.Now()
+	row := func(id int64, at time.Duration) *dbBackend.TxStatusTransition {
+		// StepIndex 带上 id，便于断言推送顺序
+		return &dbBackend.TxStatusTransition{ID: id, StepIndex: int(id), CreateTime: start.Add(at)}
+	}
+	db := &memTransitions{rows: []*dbBackend.TxStatusTransition{row(1, -time.Hour), row(3, time.Second)}}
+	pub := &recordingPublisher{}
+	w := &TxStatusPushWorker{
+		db:        db,
+		publisher: pub,
+		config:    TxStatusPushWorkerConfig{BatchSize: 1, Overlap: 30000},
+		cursor:    start,
+		pushed:    map[int64]time.Time{},
+	}
+
+	// 启动前窗口之外的历史不推送
+	w.push()
+	assert.Equal(t, []int64{3}, []int64(*pub))
+
+	// id 2 先分配、后提交：created_at 早于已读位置，仍在回看窗口内
+	db.rows = append(db.rows, row(2, 500*time.Millisecond), row(4, 2*time.Second))
+	w.push()
+	assert.Equal(t, []int64{3, 2, 4}, []int64(*pub))
+
+	// 重复轮询不重复推送
+	w.push()
+	assert.Equal(t, []int64{3, 2, 4}, []int64(*pub))
+
+	// 窗口之外的 id 被清理
+	db.rows = append(db.rows, row(5, time.Minute))
+	w.push()
+	assert.Equal(t, []int64{3, 2, 4, 5}, []int64(*pub))
+	assert.Len(t, w.pushed, 1)
+}
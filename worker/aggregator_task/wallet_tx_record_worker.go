@@ -4,28 +4,51 @@ package aggregator_task
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 
 	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/provider"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/status"
+	"github.com/roothash-pay/wallet-services/services/api/aggregator/utils"
+	"github.com/roothash-pay/wallet-services/services/api/models/backend"
 	"github.com/roothash-pay/wallet-services/services/common/chaininfo"
 	"github.com/roothash-pay/wallet-services/services/grpc_client/account"
+	"github.com/roothash-pay/wallet-services/services/websocket"
 )
 
 // WalletTxRecordWorkerConfig 配置
 type WalletTxRecordWorkerConfig struct {
-	// 扫描间隔（秒）
+	// 领取到期交易的间隔（秒），各链实际的轮询间隔由出块时间推导
 	ScanInterval int
-	// 上次检查时间阈值（秒）- 只扫描上次检查时间早于此阈值的记录
-	LastCheckedThreshold int
-	// 每次扫描的最大记录数
+	// 每条链每次领取的最大记录数
 	BatchSize int
 	// 并发度（worker pool 大小）
 	Concurrency int
-	// 超时阈值（秒）- 超过此时间未确认的交易标记为失败
-	TimeoutThreshold int
+	// 领取租约（秒）- 实例在此时间内未完成检查，其他实例可重新领取
+	LeaseDuration int
+	// 链未配置 block_time_ms 时的默认出块时间（毫秒）
+	DefaultBlockTimeMs int
+	// 超时区块数 - 超过此数量区块的时间仍未上链的交易标记为失败
+	TimeoutBlocks int
+	// 最短超时（秒）- 避免出块快的链过早判超时
+	MinTimeout int
+	// 退避上限（秒）
+	MaxCheckInterval int
+	// 陈旧阈值（秒）- 提交超过此时长仍未上链的交易按 StaleCheckInterval 低频探测
+	StaleAge           int
+	StaleCheckInterval int
+	// 跨链超时阈值（秒）- 源链成功后超过此时间目标链仍未到账的标记为失败
+	BridgeTimeoutThreshold int
+	// 默认确认数（链表未配置 required_confirmations 时使用），L1 与 L2 分别配置
+	Confirmations   int
+	L2Confirmations int
+	// Solana 链未配置 required_confirmations 时要求的 commitment：confirmed / finalized
+	SolanaCommitment string
 }
 
 // WalletTxRecordWorker 定时扫描 pending 交易并更新状态
@@ -33,6 +56,11 @@ type WalletTxRecordWorker struct {
 	db            dbBackend.WalletTxRecordDB
 	accountClient *account.WalletAccountClient
 	chainInfo     chaininfo.Provider
+	bridgeStatus  provider.BridgeStatusProvider // 可选，nil 时 bridge 交易源链确认即成功
+	engine        *status.Engine                // 状态流转统一经由状态引擎
+	evmCaller     *utils.EVMCaller              // 解析 EVM 交易失败原因
+	solanaCaller  *utils.SolanaCaller           // 查询 Solana 区块高度，判断 blockhash 过期
+	wsHub         *websocket.Hub                // 可选，推送跨链最终结果
 	config        WalletTxRecordWorkerConfig
 	stopCh        chan struct{}
 	wg            sync.WaitGroup
@@ -43,14 +71,14 @@ func NewWalletTxRecordWorker(
 	db dbBackend.WalletTxRecordDB,
 	accountClient *account.WalletAccountClient,
 	chainInfo chaininfo.Provider,
+	bridgeStatus provider.BridgeStatusProvider,
+	engine *status.Engine,
+	wsHub *websocket.Hub,
 	config WalletTxRecordWorkerConfig,
 ) *WalletTxRecordWorker {
 	// 设置默认值
 	if config.ScanInterval <= 0 {
-		config.ScanInterval = 10 // 默认 10 秒
-	}
-	if config.LastCheckedThreshold <= 0 {
-		config.LastCheckedThreshold = 5 // 默认 5 秒
+		config.ScanInterval = 2 // 默认 2 秒
 	}
 	if config.BatchSize <= 0 {
 		config.BatchSize = 100 // 默认 100 条
@@ -58,14 +86,49 @@ func NewWalletTxRecordWorker(
 	if config.Concurrency <= 0 {
 		config.Concurrency = 10 // 默认 10 个并发
 	}
-	if config.TimeoutThreshold <= 0 {
-		config.TimeoutThreshold = 3600 // 默认 1 小时
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = 60 // 默认 60 秒
+	}
+	if config.DefaultBlockTimeMs <= 0 {
+		config.DefaultBlockTimeMs = 12000 // 默认 12 秒（以太坊）
+	}
+	if config.TimeoutBlocks <= 0 {
+		config.TimeoutBlocks = 300 // 默认 300 个区块，以太坊约 1 小时
+	}
+	if config.MinTimeout <= 0 {
+		config.MinTimeout = 120 // 默认 2 分钟
+	}
+	if config.MaxCheckInterval <= 0 {
+		config.MaxCheckInterval = 60 // 默认 60 秒
+	}
+	if config.StaleAge <= 0 {
+		config.StaleAge = 900 // 默认 15 分钟
+	}
+	if config.StaleCheckInterval <= 0 {
+		config.StaleCheckInterval = 300 // 默认 5 分钟
+	}
+	if config.BridgeTimeoutThreshold <= 0 {
+		config.BridgeTimeoutThreshold = 86400 // 默认 24 小时
+	}
+	if config.Confirmations <= 0 {
+		config.Confirmations = 12 // 默认 12 个区块
+	}
+	if config.L2Confirmations <= 0 {
+		config.L2Confirmations = 1 // 默认打包即确认（sequencer 确认）
+	}
+	if config.SolanaCommitment == "" {
+		config.SolanaCommitment = utils.CommitmentFinalized
 	}
 
 	return &WalletTxRecordWorker{
 		db:            db,
 		accountClient: accountClient,
 		chainInfo:     chainInfo,
+		bridgeStatus:  bridgeStatus,
+		engine:        engine,
+		evmCaller:     utils.NewEVMCaller(accountClient, chainInfo),
+		solanaCaller:  utils.NewSolanaCaller(chainInfo),
+		wsHub:         wsHub,
 		config:        config,
 		stopCh:        make(chan struct{}),
 	}
@@ -105,20 +168,29 @@ func (w *WalletTxRecordWorker) run() {
 	}
 }
 
-// scanAndUpdate 扫描并更新 pending 交易
+// scanAndUpdate 按链领取到期的 pending 交易并更新。
+// 领取带租约（FOR UPDATE SKIP LOCKED），多个实例同时运行时各自拿到不同的记录
 func (w *WalletTxRecordWorker) scanAndUpdate() {
 	ctx := context.Background()
 
-	// 计算上次检查时间阈值
-	lastCheckedBefore := time.Now().Add(-time.Duration(w.config.LastCheckedThreshold) * time.Second)
-
-	// 查询需要检查的 pending 交易
-	records, err := w.db.GetPendingTxsForCheck(lastCheckedBefore, w.config.BatchSize)
+	chainIDs, err := w.db.GetPendingChainIDs()
 	if err != nil {
-		log.Error("Failed to get pending txs for check", "err", err)
+		log.Error("Failed to get chains with pending txs", "err", err)
 		return
 	}
 
+	now := time.Now()
+	leaseUntil := now.Add(time.Duration(w.config.LeaseDuration) * time.Second)
+	var records []*dbBackend.WalletTxRecord
+	for _, chainID := range chainIDs {
+		claimed, err := w.db.ClaimPendingTxsForCheck(chainID, now, leaseUntil, w.config.BatchSize)
+		if err != nil {
+			log.Error("Failed to claim pending txs for check", "chainID", chainID, "err", err)
+			continue
+		}
+		records = append(records, claimed...)
+	}
+
 	if len(records) == 0 {
 		return
 	}
@@ -161,18 +233,24 @@ func (w *WalletTxRecordWorker) worker(ctx context.Context, jobs <-chan *dbBacken
 
 // checkAndUpdateTx 检查并更新单个交易状态
 func (w *WalletTxRecordWorker) checkAndUpdateTx(ctx context.Context, record *dbBackend.WalletTxRecord) {
-	// 更新 last_checked_at
-	now := time.Now()
+	// 检查完成后按链的调度写入下次检查时间，释放租约
+	sched := w.scheduleFor(ctx, record.ChainID)
+	seen := false
 	defer func() {
-		updates := map[string]interface{}{
-			"last_checked_at": now,
+		now := time.Now()
+		updates := map[string]interface{}{}
+		if seen {
+			// 链上能查到交易，超时从此刻重新计算
+			record.LastSeenAt = &now
+			updates["last_seen_at"] = now
 		}
+		updates["next_check_at"] = w.nextCheckAt(record, sched, now)
 		_ = w.db.UpdateWalletTxRecord(record.Guid, updates)
 	}()
 
-	// 检查是否超时
-	if w.isTimeout(record) {
-		w.markAsFailed(record, dbBackend.FailReasonNotFoundTimeout, "Transaction not found and timeout")
+	// 源链已确认的跨链交易，只需查询目标链结果
+	if w.isBridgeInFlight(record) {
+		w.checkBridge(ctx, record)
 		return
 	}
 
@@ -191,75 +269,444 @@ func (w *WalletTxRecordWorker) checkAndUpdateTx(ctx context.Context, record *dbB
 		record.TxID,
 	)
 	if err != nil {
+		// 查询失败不代表交易不存在，不按超时处理
 		log.Warn("Failed to get tx by hash", "guid", record.Guid, "hash", record.TxID, "err", err)
+		w.failIfBlockhashExpired(ctx, record, info)
+		return
+	}
+
+	// TxStatus: 0=NotFound, 1=Pending, 2=Failed, 3=Success, 4=ContractExecuteFailed
+	seen = txInfo != nil && txInfo.Status != 0
+	mined := seen && (txInfo.Status == 2 || txInfo.Status == 3 || txInfo.Status == 4)
+	if !mined {
+		if record.Status == dbBackend.TxStatusConfirming {
+			// 所在区块被重组移除，交易回到 mempool；上次检查时仍在块内，超时从现在算起
+			seen = true
+			w.demoteToPending(ctx, record, "tx no longer in a block")
+			return
+		}
+		if !seen {
+			log.Warn("Tx not found", "guid", record.Guid, "hash", record.TxID)
+			w.failNotFound(ctx, record, info, sched)
+		}
 		return
 	}
 
-	if txInfo == nil {
-		log.Warn("Tx not found", "guid", record.Guid, "hash", record.TxID)
+	if txInfo.Status == 3 && w.isBridge(record) { // pb.TxStatus_Success
+		// 源链成功，记录区块高度后继续等待目标链（目标链结果由桥 provider 在源链最终确认后给出）
+		w.settleReplaceGroup(ctx, record, false)
+		w.markSourceConfirmed(record, txInfo.Height, txInfo.Datetime)
+		return
+	}
+
+	w.confirm(ctx, record, info, txInfo)
+}
+
+// confirm 等待已上链交易达到链的确认数。CONFIRMING 期间每次检查记录的区块仍在主链上，
+// 区块被重组替换或交易被打包进其他区块时退回 PENDING，重新计算确认数
+func (w *WalletTxRecordWorker) confirm(ctx context.Context, record *dbBackend.WalletTxRecord, info *chaininfo.Info, txInfo *account.TxInfo) {
+	required := w.requiredConfirmations(info)
+	if required <= 1 {
+		w.finalize(ctx, record, info, txInfo)
+		return
+	}
+
+	height, err := strconv.ParseInt(txInfo.Height, 10, 64)
+	if err != nil {
+		log.Warn("Invalid tx block height", "guid", record.Guid, "hash", record.TxID, "height", txInfo.Height)
+		return
+	}
+	header, err := w.accountClient.GetBlockHeaderByNumber(ctx, info.ConsumerToken, info.WalletChain, info.WalletNetwork, height)
+	if err != nil {
+		log.Warn("Failed to get tx block header", "guid", record.Guid, "hash", record.TxID, "height", height, "err", err)
+		return
+	}
+
+	if record.Status == dbBackend.TxStatusConfirming {
+		if record.BlockHeight != txInfo.Height || !strings.EqualFold(record.BlockHash, header.Hash) {
+			log.Warn("Tx block reorged", "guid", record.Guid, "hash", record.TxID, "height", record.BlockHeight, "blockHash", record.BlockHash, "newHeight", txInfo.Height, "canonicalHash", header.Hash)
+			w.demoteToPending(ctx, record, "block reorged")
+			return
+		}
+	} else {
+		_, err = w.engine.ApplyTx(ctx, record, status.Transition{
+			To:     dbBackend.TxStatusConfirming,
+			Source: status.SourceWorker,
+			Updates: map[string]interface{}{
+				"block_height": txInfo.Height,
+				"block_hash":   header.Hash,
+				"tx_time":      txInfo.Datetime,
+			},
+		})
+		if err != nil {
+			log.Error("Failed to mark tx as confirming", "guid", record.Guid, "hash", record.TxID, "err", err)
+			return
+		}
+		record.BlockHeight = txInfo.Height
+		record.BlockHash = header.Hash
+	}
+
+	latest, err := w.accountClient.GetBlockHeaderByNumber(ctx, info.ConsumerToken, info.WalletChain, info.WalletNetwork, 0)
+	if err != nil {
+		log.Warn("Failed to get latest block header", "chainID", record.ChainID, "err", err)
+		return
+	}
+	if confirmations := latest.Number - height + 1; confirmations < int64(required) {
+		log.Debug("Waiting for confirmations", "guid", record.Guid, "hash", record.TxID, "confirmations", confirmations, "required", required)
+		return
+	}
+	w.finalize(ctx, record, info, txInfo)
+}
+
+// finalize 交易达到确认数后写入链上结果；同 nonce 的交易只有一笔能上链，其余替换交易作废
+func (w *WalletTxRecordWorker) finalize(ctx context.Context, record *dbBackend.WalletTxRecord, info *chaininfo.Info, txInfo *account.TxInfo) {
+	cancelled := record.ReplaceKind == dbBackend.ReplaceKindCancel
+	w.settleReplaceGroup(ctx, record, cancelled)
+	if cancelled {
+		w.markCancelMined(ctx, record, txInfo.Status == 3, txInfo.Height, txInfo.Datetime)
 		return
 	}
 
-	// 根据链上状态更新数据库
-	// TxStatus: 0=NotFound, 1=Pending, 2=Failed, 3=Success, 4=ContractExecuteFailed
 	if txInfo.Status == 3 { // pb.TxStatus_Success
-		// 链上确认成功
-		w.markAsSuccess(record, txInfo.Height, txInfo.Datetime)
-	} else if txInfo.Status == 2 || txInfo.Status == 4 { // pb.TxStatus_Failed or ContractExecuteFailed
-		// 链上执行失败
-		w.markAsFailed(record, dbBackend.FailReasonChainFailed, "Transaction failed on chain")
+		w.markAsSuccess(ctx, record, txInfo.Height, txInfo.Datetime)
+	} else { // pb.TxStatus_Failed or ContractExecuteFailed
+		code, msg := w.failureReason(ctx, record, info)
+		w.markAsFailed(ctx, record, code, msg)
 	}
 }
 
-// isTimeout 检查交易是否超时
-func (w *WalletTxRecordWorker) isTimeout(record *dbBackend.WalletTxRecord) bool {
-	// 从 created_at 开始计算
-	timeout := time.Duration(w.config.TimeoutThreshold) * time.Second
-	return time.Since(record.CreateTime) > timeout
+// failureReason 通过回执与重放解析链上执行失败的原因（滑点、过期、授权不足等），
+// 非 EVM 链或无法解析时返回通用的 CHAIN_FAILED
+func (w *WalletTxRecordWorker) failureReason(ctx context.Context, record *dbBackend.WalletTxRecord, info *chaininfo.Info) (string, string) {
+	code, msg := dbBackend.FailReasonChainFailed, "Transaction failed on chain"
+	if !strings.EqualFold(info.ChainType, string(backend.ChainTypeEVM)) {
+		return code, msg
+	}
+
+	reason, err := w.evmCaller.ExplainFailedTx(ctx, record.ChainID, record.TxID)
+	if err != nil {
+		log.Warn("Failed to explain failed tx", "guid", record.Guid, "hash", record.TxID, "err", err)
+		return code, msg
+	}
+	if reason == nil {
+		return code, msg
+	}
+	log.Info("Failed tx explained", "guid", record.Guid, "hash", record.TxID, "code", reason.Code, "reason", reason.Message)
+	return reason.Code, reason.Message
+}
+
+// demoteToPending 交易被重组移出区块，退回 PENDING 重新等待上链
+func (w *WalletTxRecordWorker) demoteToPending(ctx context.Context, record *dbBackend.WalletTxRecord, reason string) {
+	_, err := w.engine.ApplyTx(ctx, record, status.Transition{
+		To:     dbBackend.TxStatusPending,
+		Source: status.SourceWorker,
+		Updates: map[string]interface{}{
+			"block_height": "",
+			"block_hash":   "",
+		},
+	})
+	if err != nil {
+		log.Error("Failed to demote tx to pending", "guid", record.Guid, "hash", record.TxID, "reason", reason, "err", err)
+		return
+	}
+	record.BlockHeight = ""
+	record.BlockHash = ""
+	log.Warn("Tx demoted to pending", "guid", record.Guid, "hash", record.TxID, "reason", reason)
+}
+
+// requiredConfirmations 链表配置优先，否则 Solana 按 commitment、EVM 按 L1 / L2 使用 worker 默认值。
+// Solana 的高度为 slot：account 服务返回的交易已是 confirmed，finalized 需再等 32 个 slot
+func (w *WalletTxRecordWorker) requiredConfirmations(info *chaininfo.Info) int {
+	if info.Confirmations > 0 {
+		return info.Confirmations
+	}
+	if isSolana(info) {
+		if w.config.SolanaCommitment == utils.CommitmentFinalized {
+			return utils.SolanaFinalizedDepth
+		}
+		return 1
+	}
+	if info.IsL2 {
+		return w.config.L2Confirmations
+	}
+	return w.config.Confirmations
+}
+
+// failIfBlockhashExpired 未上链的 Solana 交易在 recent blockhash 过期后不会再被处理，直接判失败，
+// 不必等到通用超时。链未配置 rpc_url 时无法取得区块高度，仍按超时处理
+func (w *WalletTxRecordWorker) failIfBlockhashExpired(ctx context.Context, record *dbBackend.WalletTxRecord, info *chaininfo.Info) {
+	if record.Status != dbBackend.TxStatusPending || record.LastValidBlockHeight <= 0 || !isSolana(info) {
+		return
+	}
+	height, err := w.solanaCaller.BlockHeight(ctx, record.ChainID, utils.CommitmentConfirmed)
+	if err != nil {
+		log.Warn("Failed to get solana block height", "guid", record.Guid, "chainID", record.ChainID, "err", err)
+		return
+	}
+	if int64(height) <= record.LastValidBlockHeight {
+		return
+	}
+	msg := fmt.Sprintf("Blockhash expired at block height %d (current %d)", record.LastValidBlockHeight, height)
+	w.markAsFailed(ctx, record, dbBackend.FailReasonBlockhashExpired, msg)
+}
+
+// isSolana 链类型为 SOLANA
+func isSolana(info *chaininfo.Info) bool {
+	return strings.EqualFold(info.ChainType, string(backend.ChainTypeSolana))
+}
+
+// failNotFound 链上查不到的 PENDING 交易：Solana blockhash 过期即失败，否则超时后失败。
+// 已被替换的交易以最新一笔替换交易的超时为准，在此之前仍可能上链
+func (w *WalletTxRecordWorker) failNotFound(ctx context.Context, record *dbBackend.WalletTxRecord, info *chaininfo.Info, sched chainSchedule) {
+	w.failIfBlockhashExpired(ctx, record, info)
+	if record.Status != dbBackend.TxStatusPending || !w.isTimeout(record, sched, time.Now()) || w.awaitingReplaceGroup(record) {
+		return
+	}
+	if w.markAsFailed(ctx, record, dbBackend.FailReasonNotFoundTimeout, "Transaction not found and timeout") {
+		w.settleReplaceGroup(ctx, record, false)
+	}
+}
+
+// isTimeout 检查交易是否超时，超时时长按链的出块时间计算
+func (w *WalletTxRecordWorker) isTimeout(record *dbBackend.WalletTxRecord, sched chainSchedule, now time.Time) bool {
+	return now.Sub(timeoutBase(record)) > sched.timeout
+}
+
+// timeoutBase 超时起点：提交（记录创建）时间，之后在链上查到过则取最后一次查到的时间
+func timeoutBase(record *dbBackend.WalletTxRecord) time.Time {
+	if record.LastSeenAt != nil && record.LastSeenAt.After(record.CreateTime) {
+		return *record.LastSeenAt
+	}
+	return record.CreateTime
 }
 
 // markAsSuccess 标记交易为成功
-func (w *WalletTxRecordWorker) markAsSuccess(record *dbBackend.WalletTxRecord, blockHeight string, txTime string) {
+func (w *WalletTxRecordWorker) markAsSuccess(ctx context.Context, record *dbBackend.WalletTxRecord, blockHeight string, txTime string) {
+	_, err := w.engine.ApplyTx(ctx, record, status.Transition{
+		To:     dbBackend.TxStatusSuccess,
+		Source: status.SourceWorker,
+		Updates: map[string]interface{}{
+			"block_height": blockHeight,
+			"tx_time":      txTime,
+			"memo":         w.updateMemoStatus(record.Memo, "Success"),
+		},
+	})
+	if err != nil {
+		log.Error("Failed to mark tx as success", "guid", record.Guid, "hash", record.TxID, "err", err)
+	}
+}
+
+// markAsFailed 标记交易为失败，返回是否由本次调用完成流转
+func (w *WalletTxRecordWorker) markAsFailed(ctx context.Context, record *dbBackend.WalletTxRecord, failReasonCode string, failReasonMsg string) bool {
+	changed, err := w.engine.ApplyTx(ctx, record, status.Transition{
+		To:             dbBackend.TxStatusFailed,
+		Source:         status.SourceWorker,
+		FailReasonCode: failReasonCode,
+		FailReasonMsg:  failReasonMsg,
+		Updates: map[string]interface{}{
+			"memo": w.updateMemoStatus(record.Memo, "Failed: "+failReasonMsg),
+		},
+	})
+	if err != nil {
+		log.Error("Failed to mark tx as failed", "guid", record.Guid, "hash", record.TxID, "err", err)
+	}
+	return changed
+}
+
+// awaitingReplaceGroup 同组内已有更新的替换交易（加速 / 取消），或有交易已上链等待确认，
+// 此时本交易的超时不作数，由同组其他交易决定结果
+func (w *WalletTxRecordWorker) awaitingReplaceGroup(record *dbBackend.WalletTxRecord) bool {
+	if record.ReplaceGroup == "" {
+		return false
+	}
+	group, err := w.db.GetByReplaceGroup(record.ReplaceGroup)
+	if err != nil || len(group) == 0 {
+		return false
+	}
+	for _, sibling := range group {
+		if sibling.Status == dbBackend.TxStatusConfirming {
+			return true
+		}
+	}
+	return group[len(group)-1].Guid != record.Guid
+}
+
+// settleReplaceGroup 同组内 record 已上链（或最终超时），将其余未完成的交易置为失败。
+// 取消交易上链时，被取消的交易以 CANCELLED 决定步骤结果；否则其余交易为 REPLACED，不影响步骤。
+func (w *WalletTxRecordWorker) settleReplaceGroup(ctx context.Context, record *dbBackend.WalletTxRecord, cancelled bool) {
+	if record.ReplaceGroup == "" {
+		return
+	}
+	group, err := w.db.GetByReplaceGroup(record.ReplaceGroup)
+	if err != nil {
+		log.Error("Failed to get replace group", "group", record.ReplaceGroup, "err", err)
+		return
+	}
+
+	for _, sibling := range group {
+		if sibling.Guid == record.Guid || status.IsFinal(sibling.Status) {
+			continue
+		}
+		t := status.Transition{
+			To:             dbBackend.TxStatusFailed,
+			Source:         status.SourceWorker,
+			FailReasonCode: dbBackend.FailReasonReplaced,
+			FailReasonMsg:  fmt.Sprintf("Replaced by %s", record.TxID),
+			SkipStep:       true,
+		}
+		if cancelled {
+			t.FailReasonCode = dbBackend.FailReasonCancelled
+			t.FailReasonMsg = fmt.Sprintf("Cancelled by %s", record.TxID)
+			t.SkipStep = false
+		}
+		t.Updates = map[string]interface{}{
+			"memo": w.updateMemoStatus(sibling.Memo, "Failed: "+t.FailReasonMsg),
+		}
+		if _, err := w.engine.ApplyTx(ctx, sibling, t); err != nil {
+			log.Error("Failed to settle replaced tx", "guid", sibling.Guid, "hash", sibling.TxID, "by", record.TxID, "err", err)
+		}
+	}
+}
+
+// markCancelMined 取消交易上链只消耗 nonce，本身不推进步骤
+func (w *WalletTxRecordWorker) markCancelMined(ctx context.Context, record *dbBackend.WalletTxRecord, success bool, blockHeight string, txTime string) {
+	t := status.Transition{
+		To:     dbBackend.TxStatusSuccess,
+		Source: status.SourceWorker,
+		Updates: map[string]interface{}{
+			"block_height": blockHeight,
+			"tx_time":      txTime,
+		},
+		SkipStep: true,
+	}
+	if !success {
+		t.To = dbBackend.TxStatusFailed
+		t.FailReasonCode = dbBackend.FailReasonChainFailed
+		t.FailReasonMsg = "Transaction failed on chain"
+	}
+	if _, err := w.engine.ApplyTx(ctx, record, t); err != nil {
+		log.Error("Failed to mark cancel tx mined", "guid", record.Guid, "hash", record.TxID, "err", err)
+	}
+}
+
+// isBridge 是否为需要跟踪目标链的跨链交易
+func (w *WalletTxRecordWorker) isBridge(record *dbBackend.WalletTxRecord) bool {
+	return w.bridgeStatus != nil && record.TxType == "bridge" && record.DestChainID != ""
+}
+
+// isBridgeInFlight 跨链交易源链已确认（已写入 block_height）但目标链尚未完成
+func (w *WalletTxRecordWorker) isBridgeInFlight(record *dbBackend.WalletTxRecord) bool {
+	return w.isBridge(record) && record.BlockHeight != ""
+}
+
+// markSourceConfirmed 记录源链确认信息，状态保持 PENDING
+func (w *WalletTxRecordWorker) markSourceConfirmed(record *dbBackend.WalletTxRecord, blockHeight string, txTime string) {
 	updates := map[string]interface{}{
-		"status":       dbBackend.TxStatusSuccess,
 		"block_height": blockHeight,
 		"tx_time":      txTime,
-		"memo":         w.updateMemoStatus(record.Memo, "Success"),
 	}
 
 	if err := w.db.UpdateWalletTxRecord(record.Guid, updates); err != nil {
-		log.Error("Failed to mark tx as success", "guid", record.Guid, "hash", record.TxID, "err", err)
+		log.Error("Failed to mark bridge source tx confirmed", "guid", record.Guid, "hash", record.TxID, "err", err)
 	} else {
-		log.Info("Tx marked as success", "guid", record.Guid, "hash", record.TxID, "blockHeight", blockHeight)
+		log.Info("Bridge source tx confirmed, waiting for destination", "guid", record.Guid, "hash", record.TxID, "destChainID", record.DestChainID)
 	}
 }
 
-// markAsFailed 标记交易为失败
-func (w *WalletTxRecordWorker) markAsFailed(record *dbBackend.WalletTxRecord, failReasonCode string, failReasonMsg string) {
-	updates := map[string]interface{}{
-		"status":           dbBackend.TxStatusFailed,
-		"fail_reason_code": failReasonCode,
-		"fail_reason_msg":  failReasonMsg,
-		"memo":             w.updateMemoStatus(record.Memo, "Failed: "+failReasonMsg),
+// checkBridge 查询跨链状态，到达终态后更新记录并推送
+func (w *WalletTxRecordWorker) checkBridge(ctx context.Context, record *dbBackend.WalletTxRecord) {
+	bridge, err := w.bridgeStatus.GetBridgeStatus(ctx, record.ChainID, record.DestChainID, record.TxID)
+	if err != nil {
+		log.Warn("Failed to get bridge status", "guid", record.Guid, "hash", record.TxID, "err", err)
+		return
 	}
 
-	if err := w.db.UpdateWalletTxRecord(record.Guid, updates); err != nil {
-		log.Error("Failed to mark tx as failed", "guid", record.Guid, "hash", record.TxID, "err", err)
-	} else {
-		log.Info("Tx marked as failed", "guid", record.Guid, "hash", record.TxID, "reason", failReasonCode)
+	updates := map[string]interface{}{}
+	if bridge.DestChainID != "" {
+		updates["dest_chain_id"] = bridge.DestChainID
 	}
+	if bridge.DestTxHash != "" {
+		updates["dest_tx_id"] = bridge.DestTxHash
+	}
+
+	transition := status.Transition{
+		To:      bridge.Status,
+		Source:  status.SourceWorker,
+		Updates: updates,
+		Bridge:  bridge,
+	}
+	switch bridge.Status {
+	case dbBackend.TxStatusSuccess:
+		updates["memo"] = w.updateMemoStatus(record.Memo, "Success")
+	case dbBackend.TxStatusPartial:
+		updates["memo"] = w.updateMemoStatus(record.Memo, "Partial")
+	case dbBackend.TxStatusRefunded:
+		updates["memo"] = w.updateMemoStatus(record.Memo, "Refunded")
+		transition.FailReasonCode = dbBackend.FailReasonBridgeRefunded
+		transition.FailReasonMsg = bridge.Message
+	case dbBackend.TxStatusFailed:
+		updates["memo"] = w.updateMemoStatus(record.Memo, "Failed: "+bridge.Message)
+		transition.FailReasonCode = dbBackend.FailReasonBridgeFailed
+		transition.FailReasonMsg = bridge.Message
+	default:
+		// 仍在跨链中
+		if w.isBridgeTimeout(record) {
+			if w.markAsFailed(ctx, record, dbBackend.FailReasonBridgeTimeout, "Bridge not completed on destination chain and timeout") {
+				w.broadcastBridgeFinalized(record, bridge.DestChainID, bridge.DestTxHash, dbBackend.TxStatusFailed)
+			}
+		} else if len(updates) > 0 {
+			_ = w.db.UpdateWalletTxRecord(record.Guid, updates)
+		}
+		return
+	}
+
+	changed, err := w.engine.ApplyTx(ctx, record, transition)
+	if err != nil {
+		log.Error("Failed to update bridge tx", "guid", record.Guid, "hash", record.TxID, "err", err)
+		return
+	}
+	if !changed {
+		return
+	}
+	log.Info("Bridge tx finalized", "guid", record.Guid, "hash", record.TxID, "destTxHash", bridge.DestTxHash, "status", dbBackend.TxStatusNames[bridge.Status])
+	w.broadcastBridgeFinalized(record, bridge.DestChainID, bridge.DestTxHash, bridge.Status)
+}
+
+// isBridgeTimeout 跨链交易是否超时
+func (w *WalletTxRecordWorker) isBridgeTimeout(record *dbBackend.WalletTxRecord) bool {
+	timeout := time.Duration(w.config.BridgeTimeoutThreshold) * time.Second
+	return time.Since(record.CreateTime) > timeout
+}
+
+// broadcastBridgeFinalized 推送跨链最终结果给订阅了该钱包或 swap 的连接
+func (w *WalletTxRecordWorker) broadcastBridgeFinalized(record *dbBackend.WalletTxRecord, destChainID string, destTxHash string, status int) {
+	if w.wsHub == nil {
+		return
+	}
+	if destChainID == "" {
+		destChainID = record.DestChainID
+	}
+	w.wsHub.PublishBridgeFinalized(&websocket.BridgeFinalizedMessage{
+		WalletUUID:  record.WalletUUID,
+		OperationID: record.OperationID,
+		StepIndex:   record.StepIndex,
+		FromAddress: record.FromAddress,
+		ToAddress:   record.ToAddress,
+		ChainID:     record.ChainID,
+		TxHash:      record.TxID,
+		DestChainID: destChainID,
+		DestTxHash:  destTxHash,
+		Status:      int64(status),
+	})
 }
 
 // updateMemoStatus 更新 memo 中的状态
 func (w *WalletTxRecordWorker) updateMemoStatus(memo string, newStatus string) string {
-	// 简单替换：将 (Pending) 或 (Created) 替换为新状态
-	// TODO: 更精确的替换逻辑
+	// 将 (Pending) 或 (Created) 替换为新状态；没有状态后缀的备注（如取消交易的 "cancel <hash>"）直接追加
 	if len(memo) > 0 {
 		// 移除旧状态
-		memo = memo[:len(memo)-len(" (Pending)")]
-		if len(memo) > len(" (Created)") && memo[len(memo)-len(" (Created)"):] == " (Created)" {
-			memo = memo[:len(memo)-len(" (Created)")]
-		}
+		memo = strings.TrimSuffix(memo, " (Pending)")
+		memo = strings.TrimSuffix(memo, " (Created)")
 		// 添加新状态
 		return memo + " (" + newStatus + ")"
 	}
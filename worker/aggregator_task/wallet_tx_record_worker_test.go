@@ -0,0 +1,17 @@
+package aggregator_task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateMemoStatus(t *testing.T) {
+	w := NewWalletTxRecordWorker(nil, nil, nil, nil, nil, nil, WalletTxRecordWorkerConfig{})
+
+	assert.Equal(t, "Swap via lifi (Success)", w.updateMemoStatus("Swap via lifi (Pending)", "Success"))
+	assert.Equal(t, "Swap via lifi (Failed)", w.updateMemoStatus("Swap via lifi (Created)", "Failed"))
+	// 取消交易的备注没有状态后缀，不能截掉交易哈希
+	assert.Equal(t, "cancel 0xabcdef0123456789 (Success)", w.updateMemoStatus("cancel 0xabcdef0123456789", "Success"))
+	assert.Equal(t, "", w.updateMemoStatus("", "Success"))
+}
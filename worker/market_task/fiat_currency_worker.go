@@ -9,29 +9,64 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/roothash-pay/wallet-services/common/tasks"
+	"github.com/roothash-pay/wallet-services/config"
 	"github.com/roothash-pay/wallet-services/database"
+	"github.com/roothash-pay/wallet-services/metrics"
+	"github.com/roothash-pay/wallet-services/services/market/fx"
 	"github.com/roothash-pay/wallet-services/services/websocket"
 )
 
-type FiatCurrencyWorkerConfig struct {
-	LoopInterval time.Duration
-}
+var defaultFiatCurrencies = []string{"CNY", "EUR", "JPY", "KRW", "HKD", "GBP", "SGD"}
 
 type FiatCurrencyWorker struct {
 	db             *database.DB
-	wConf          *FiatCurrencyWorkerConfig
+	wConf          *config.FiatCurrencyWorkerConfig
 	wsHub          *websocket.Hub
+	syncer         *fiatRateSyncer
 	resourceCtx    context.Context
 	resourceCancel context.CancelFunc
 	tasks          tasks.Group
 }
 
-func NewFiatCurrencyWorker(db *database.DB, wConf *FiatCurrencyWorkerConfig, wsHub *websocket.Hub, shutdown context.CancelCauseFunc) (*FiatCurrencyWorker, error) {
+func NewFiatCurrencyWorker(db *database.DB, wConf *config.FiatCurrencyWorkerConfig, wsHub *websocket.Hub, m metrics.FiatRateMetricer, shutdown context.CancelCauseFunc) (*FiatCurrencyWorker, error) {
+	if wConf.LoopInterval <= 0 {
+		wConf.LoopInterval = 10 * time.Minute
+	}
+	if wConf.Base == "" {
+		wConf.Base = "USD"
+	}
+	if len(wConf.Currencies) == 0 {
+		wConf.Currencies = defaultFiatCurrencies
+	}
+	if wConf.StaleAfter <= 0 {
+		wConf.StaleAfter = 72 * time.Hour
+	}
+
+	// 按顺序尝试，前一个源缺失的币种交给下一个
+	providers := []fx.Provider{
+		fx.NewExchangeRateAPIProvider(),
+		fx.NewFrankfurterProvider(),
+	}
+	var fallback fx.Provider
+	if wConf.StaticFile != "" {
+		fallback = fx.NewStaticFileProvider(wConf.StaticFile)
+	}
+
 	resCtx, resCancel := context.WithCancel(context.Background())
 	return &FiatCurrencyWorker{
-		db:             db,
-		wConf:          wConf,
-		wsHub:          wsHub,
+		db:    db,
+		wConf: wConf,
+		wsHub: wsHub,
+		syncer: newFiatRateSyncer(
+			providers,
+			fallback,
+			db.BackendFiatCurrencyRate,
+			wConf.Base,
+			wConf.Currencies,
+			wConf.StaleAfter,
+			m,
+			time.Now(),
+		),
 		resourceCtx:    resCtx,
 		resourceCancel: resCancel,
 		tasks: tasks.Group{
@@ -49,18 +84,27 @@ func (mpw *FiatCurrencyWorker) Close() error {
 
 func (mpw *FiatCurrencyWorker) Start() error {
 	workerTicker := time.NewTicker(mpw.wConf.LoopInterval)
-	mpw.tasks.Go(func() error {
-		for range workerTicker.C {
-			log.Info("==== star ======")
-		}
-		return nil
-	})
 
 	mpw.tasks.Go(func() error {
-		for range workerTicker.C {
-			log.Info("==== star ======")
+		defer workerTicker.Stop()
+		// 启动时先更新一次，不等第一个周期
+		mpw.sync()
+		for {
+			select {
+			case <-mpw.resourceCtx.Done():
+				return nil
+			case <-workerTicker.C:
+				mpw.sync()
+			}
 		}
-		return nil
 	})
 	return nil
 }
+
+func (mpw *FiatCurrencyWorker) sync() {
+	ctx, cancel := context.WithTimeout(mpw.resourceCtx, 30*time.Second)
+	defer cancel()
+	if _, err := mpw.syncer.sync(ctx, time.Now()); err != nil {
+		log.Warn("fiat currency rate sync failed", "err", err)
+	}
+}
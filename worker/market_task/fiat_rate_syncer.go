@@ -0,0 +1,191 @@
+package market_task
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/google/uuid"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/metrics"
+	"github.com/roothash-pay/wallet-services/services/market/fx"
+	"github.com/roothash-pay/wallet-services/services/market/model"
+)
+
+// fiatRateStore is the part of the fiat_currency_rate table the syncer needs
+type fiatRateStore interface {
+	UpsertRate(r *dbBackend.FiatCurrencyRate) error
+}
+
+// fiatRateSyncer fetches the configured currencies from the live providers in order, falls back to
+// the static provider for the ones still missing, and raises an alarm for currencies whose latest
+// live rate, by the provider's rate time, is older than staleAfter
+type fiatRateSyncer struct {
+	providers  []fx.Provider
+	fallback   fx.Provider // 可为 nil
+	store      fiatRateStore
+	base       string
+	currencies []string
+	staleAfter time.Duration
+	metrics    metrics.FiatRateMetricer // 可为 nil
+
+	lastLive map[string]time.Time // currency -> 最近写入的在线汇率的 RateTime
+	stale    map[string]bool
+}
+
+func newFiatRateSyncer(providers []fx.Provider, fallback fx.Provider, store fiatRateStore, base string, currencies []string, staleAfter time.Duration, m metrics.FiatRateMetricer, now time.Time) *fiatRateSyncer {
+	s := &fiatRateSyncer{
+		providers:  providers,
+		fallback:   fallback,
+		store:      store,
+		base:       strings.ToUpper(base),
+		staleAfter: staleAfter,
+		metrics:    m,
+		lastLive:   make(map[string]time.Time, len(currencies)),
+		stale:      make(map[string]bool, len(currencies)),
+	}
+	for _, c := range currencies {
+		c = strings.ToUpper(c)
+		s.currencies = append(s.currencies, c)
+		// 启动时视为刚更新过，避免一启动就告警
+		s.lastLive[c] = now
+	}
+	return s
+}
+
+// sync runs one update round and returns the number of rates written
+func (s *fiatRateSyncer) sync(ctx context.Context, now time.Time) (int, error) {
+	missing := s.currencies
+	written := 0
+	var firstErr error
+
+	for _, p := range s.providers {
+		if len(missing) == 0 {
+			break
+		}
+		rateTimes, rest, err := s.fetch(ctx, p, missing)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		for c, rateTime := range rateTimes {
+			s.lastLive[c] = rateTime
+		}
+		written += len(rateTimes)
+		missing = rest
+	}
+
+	if len(missing) > 0 && s.fallback != nil {
+		rateTimes, rest, err := s.fetch(ctx, s.fallback, missing)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		written += len(rateTimes)
+		missing = rest
+	}
+	if len(missing) > 0 {
+		log.Warn("fiat currency rates missing", "base", s.base, "currencies", strings.Join(missing, ","))
+	}
+
+	s.checkStale(now)
+	if written == 0 && firstErr == nil {
+		firstErr = errors.New("no fiat currency rate fetched")
+	}
+	if written > 0 {
+		// 部分源失败但有汇率写入时只记日志
+		if firstErr != nil {
+			log.Warn("fiat currency rate provider failed", "err", firstErr)
+		}
+		return written, nil
+	}
+	return 0, firstErr
+}
+
+// fetch writes the rates p returns for currencies; it returns the rate time of each written currency
+// and the currencies it did not cover
+func (s *fiatRateSyncer) fetch(ctx context.Context, p fx.Provider, currencies []string) (map[string]time.Time, []string, error) {
+	rates, err := p.FetchRates(ctx, s.base, currencies)
+	if err != nil {
+		log.Warn("fetch fiat currency rates failed", "provider", p.Name(), "err", err)
+		return nil, currencies, err
+	}
+
+	done := make(map[string]time.Time, len(rates))
+	var firstErr error
+	for _, r := range rates {
+		if _, ok := done[r.Currency]; ok || !contains(currencies, r.Currency) {
+			continue
+		}
+		row := rateRow(r)
+		if err := s.store.UpsertRate(row); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		done[r.Currency] = row.RateTime
+	}
+
+	rest := make([]string, 0, len(currencies)-len(done))
+	for _, c := range currencies {
+		if _, ok := done[c]; !ok {
+			rest = append(rest, c)
+		}
+	}
+	log.Info("fiat currency rates updated", "provider", p.Name(), "count", len(done))
+	return done, rest, firstErr
+}
+
+// checkStale 币种最新在线汇率的 RateTime 超过 staleAfter 时告警，恢复后记录一次；汇率年龄与告警状态同时上报指标
+func (s *fiatRateSyncer) checkStale(now time.Time) {
+	for _, c := range s.currencies {
+		age := now.Sub(s.lastLive[c])
+		if s.metrics != nil {
+			s.metrics.RecordFiatRateAge(s.base, c, age)
+		}
+		if s.staleAfter <= 0 {
+			continue
+		}
+		switch {
+		case age > s.staleAfter && !s.stale[c]:
+			s.stale[c] = true
+			log.Error("fiat currency rate stale", "base", s.base, "currency", c, "last_update", s.lastLive[c], "age", age)
+		case age <= s.staleAfter && s.stale[c]:
+			s.stale[c] = false
+			log.Info("fiat currency rate recovered", "base", s.base, "currency", c)
+		}
+		if s.metrics != nil {
+			s.metrics.RecordFiatRateStale(s.base, c, s.stale[c])
+		}
+	}
+}
+
+// rateRow key_name 沿用 USD_CNY 形式
+func rateRow(r model.FXRate) *dbBackend.FiatCurrencyRate {
+	now := time.Now()
+	rateTime := r.Timestamp
+	if rateTime.IsZero() {
+		rateTime = now
+	}
+	return &dbBackend.FiatCurrencyRate{
+		Guid:       uuid.New().String(),
+		KeyName:    r.Base + "_" + r.Currency,
+		ValueData:  strconv.FormatFloat(r.Rate, 'f', -1, 64),
+		CreateTime: now,
+		UpdateTime: now,
+		Source:     r.Source,
+		RateTime:   rateTime,
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package market_task
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/market/fx"
+	"github.com/roothash-pay/wallet-services/services/market/model"
+)
+
+type fakeFXProvider struct {
+	name     string
+	rates    map[string]float64
+	rateTime time.Time
+	err      error
+}
+
+func (p *fakeFXProvider) Name() string { return p.name }
+
+func (p *fakeFXProvider) FetchRates(ctx context.Context, base string, currencies []string) ([]model.FXRate, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	var out []model.FXRate
+	for _, c := range currencies {
+		if r, ok := p.rates[c]; ok {
+			out = append(out, model.FXRate{Base: base, Currency: c, Rate: r, Source: p.name, Timestamp: p.rateTime})
+		}
+	}
+	return out, nil
+}
+
+type fakeFiatRateStore map[string]*dbBackend.FiatCurrencyRate
+
+func (s fakeFiatRateStore) UpsertRate(r *dbBackend.FiatCurrencyRate) error {
+	s[r.KeyName] = r
+	return nil
+}
+
+type fakeFiatRateMetrics struct {
+	age   map[string]time.Duration
+	stale map[string]bool
+}
+
+func (m *fakeFiatRateMetrics) RecordFiatRateAge(base, currency string, age time.Duration) {
+	m.age[base+"_"+currency] = age
+}
+
+func (m *fakeFiatRateMetrics) RecordFiatRateStale(base, currency string, stale bool) {
+	m.stale[base+"_"+currency] = stale
+}
+
+func TestFiatRateSyncer(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "fx-rates.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"base":"USD","updated_at":"2026-10-01T00:00:00Z","rates":{"KRW":1350.5,"JPY":1}}`), 0o644))
+
+	start := time.Now()
+	primary := &fakeFXProvider{name: "primary", rates: map[string]float64{"CNY": 7.25}, rateTime: start}
+	secondary := &fakeFXProvider{name: "secondary", rates: map[string]float64{"CNY": 7.3, "EUR": 0.92, "JPY": 150}, rateTime: start}
+	store := fakeFiatRateStore{}
+	m := &fakeFiatRateMetrics{age: map[string]time.Duration{}, stale: map[string]bool{}}
+	s := newFiatRateSyncer([]fx.Provider{primary, secondary}, fx.NewStaticFileProvider(file), store, "usd", []string{"cny", "eur", "jpy", "krw"}, time.Hour, m, start)
+
+	n, err := s.sync(context.Background(), start)
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	// 按源的顺序取，前一个源已有的币种不再被覆盖
+	assert.Equal(t, "7.25", store["USD_CNY"].ValueData)
+	assert.Equal(t, "primary", store["USD_CNY"].Source)
+	assert.Equal(t, "0.92", store["USD_EUR"].ValueData)
+	assert.Equal(t, "150", store["USD_JPY"].ValueData)
+	assert.Equal(t, "secondary", store["USD_JPY"].Source)
+	// 在线源都没有的币种用静态文件兜底，保留文件里的汇率时间
+	assert.Equal(t, "1350.5", store["USD_KRW"].ValueData)
+	assert.Equal(t, "static_file", store["USD_KRW"].Source)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), store["USD_KRW"].RateTime.UTC())
+
+	// 在线源全部失败：静态文件兜底的不算在线更新，超过阈值后告警
+	primary.err = errors.New("down")
+	secondary.err = errors.New("down")
+	later := start.Add(2 * time.Hour)
+	n, err = s.sync(context.Background(), later)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	for _, c := range []string{"CNY", "EUR", "JPY"} {
+		assert.True(t, s.stale[c], c)
+	}
+	assert.True(t, s.stale["KRW"])
+	assert.True(t, m.stale["USD_EUR"])
+	assert.Equal(t, 2*time.Hour, m.age["USD_EUR"])
+
+	// 在线源恢复，但按源的 RateTime 判断：旧汇率写入后仍然告警
+	primary.err = nil
+	_, err = s.sync(context.Background(), later.Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, s.stale["CNY"])
+	assert.Equal(t, 2*time.Hour+time.Minute, m.age["USD_CNY"])
+
+	// 源发布新汇率后解除告警
+	primary.rateTime = later
+	_, err = s.sync(context.Background(), later.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.False(t, s.stale["CNY"])
+	assert.False(t, m.stale["USD_CNY"])
+	assert.Equal(t, 2*time.Minute, m.age["USD_CNY"])
+	assert.True(t, s.stale["EUR"])
+
+	// 没有任何汇率写入时返回错误
+	s = newFiatRateSyncer([]fx.Provider{secondary}, nil, fakeFiatRateStore{}, "USD", []string{"CNY"}, time.Hour, nil, start)
+	_, err = s.sync(context.Background(), start)
+	assert.Error(t, err)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/roothash-pay/wallet-services/common/tasks"
 	"github.com/roothash-pay/wallet-services/config"
 	"github.com/roothash-pay/wallet-services/database"
+	"github.com/roothash-pay/wallet-services/services/market/asset"
 	"github.com/roothash-pay/wallet-services/services/market/cache"
 	"github.com/roothash-pay/wallet-services/services/market/provider"
 	"github.com/roothash-pay/wallet-services/services/market/resolver"
@@ -22,7 +23,10 @@ type MarketPriceWorker struct {
 	db              *database.DB
 	wConf           *config.MarketPriceWorkerConfig
 	wsHub           *websocket.Hub
+	publisher       *marketPublisher
 	marketCollector *service.MarketCollector
+	klineBuilder    *service.KlineBuilder
+	pricePersister  *service.PricePersister
 	resourceCtx     context.Context
 	resourceCancel  context.CancelFunc
 	tasks           tasks.Group
@@ -51,21 +55,32 @@ func NewMarketPriceWorker(db *database.DB, marketCache cache.Cache, wConf *confi
 	}
 
 	// 2. resolver
-	resolver := resolver.NewInfoLevelResolver()
+	resolver, err := resolver.New(wConf.Resolver)
+	if err != nil {
+		resCancel()
+		return nil, err
+	}
 
 	// 3. cache (from parameter)
 
-	// 4. Market Collector
+	// 4. asset registry：按 token / chain_token 表把行情绑定到规范资产
+	assets := asset.NewRegistry(db.BackendToken, db.BackendChainToken, 0)
+
+	// 5. Market Collector
 	collector := service.NewMarketCollector(
 		providers,
 		resolver,
 		marketCache,
+		assets,
 	)
 	return &MarketPriceWorker{
 		db:              db,
 		wConf:           wConf,
 		wsHub:           wsHub,
+		publisher:       newMarketPublisher(wsHub, wConf.PushInterval),
 		marketCollector: collector,
+		klineBuilder:    service.NewKlineBuilder(db.BackendKline),
+		pricePersister:  service.NewPricePersister(db.BackendMarketPrice, db.BackendKline, assets),
 		resourceCtx:     resCtx,
 		resourceCancel:  resCancel,
 		tasks: tasks.Group{
@@ -102,7 +117,20 @@ func (mpw *MarketPriceWorker) Start() error {
 				}
 
 				if len(finalQuotes) > 0 {
-					mpw.wsHub.Broadcast("market:price", finalQuotes)
+					// 先写 K 线，涨跌幅依赖 24 小时前的 K 线
+					klines, err := mpw.klineBuilder.Apply(ctx, finalQuotes)
+					if err != nil {
+						log.Warn("market kline build failed", "err", err)
+					}
+					if err := mpw.pricePersister.Persist(ctx, finalQuotes); err != nil {
+						log.Warn("market price persist failed", "err", err)
+					}
+
+					// 只推送变化了的价格与 K 线给订阅方
+					now := time.Now()
+					prices := mpw.publisher.publishPrices(finalQuotes, now)
+					candles := mpw.publisher.publishKlines(klines, now)
+					log.Debug("market updates pushed", "prices", prices, "klines", candles)
 				}
 
 				cancel()
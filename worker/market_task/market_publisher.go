@@ -0,0 +1,86 @@
+package market_task
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/market/model"
+	"github.com/roothash-pay/wallet-services/services/websocket"
+)
+
+// defaultPushInterval 同一频道两次推送的最小间隔
+const defaultPushInterval = time.Second
+
+// channelPublisher 即 websocket.Hub 的频道推送
+type channelPublisher interface {
+	PublishChannel(channel string, event string, data any)
+}
+
+type pushed struct {
+	value string
+	at    time.Time
+}
+
+// marketPublisher 只推送变化了的价格 / K 线，且每个频道按 interval 限流；
+// 被限流的变化不会丢，下一轮与上次推送的值比较时仍会发出
+type marketPublisher struct {
+	hub      channelPublisher
+	interval time.Duration
+
+	mu   sync.Mutex
+	last map[string]pushed // channel -> 上次推送
+}
+
+func newMarketPublisher(hub channelPublisher, interval time.Duration) *marketPublisher {
+	if interval <= 0 {
+		interval = defaultPushInterval
+	}
+	return &marketPublisher{
+		hub:      hub,
+		interval: interval,
+		last:     make(map[string]pushed),
+	}
+}
+
+// publishPrices 价格按规范资产推送到 token 频道，只有符号的推送到符号频道
+func (p *marketPublisher) publishPrices(quotes map[string]model.Quote, now time.Time) int {
+	count := 0
+	for _, q := range quotes {
+		channel := websocket.PriceChannel(q.BaseAsset)
+		if q.AssetID != "" {
+			channel = websocket.TokenPriceChannel(q.AssetID)
+		}
+		if p.publish(channel, "market:price", strconv.FormatFloat(q.Price, 'f', 8, 64), q, now) {
+			count++
+		}
+	}
+	return count
+}
+
+// publishKlines 推送每个 token 每个周期的当前 K 线
+func (p *marketPublisher) publishKlines(klines []*dbBackend.Kline, now time.Time) int {
+	count := 0
+	for _, k := range klines {
+		value := k.OpenTime.String() + "|" + k.OpenPrice + "|" + k.HighPrice + "|" + k.LowPrice + "|" + k.ClosePrice
+		if p.publish(websocket.KlineChannel(k.TokenID, k.TimeInterval), "market:kline", value, k, now) {
+			count++
+		}
+	}
+	return count
+}
+
+func (p *marketPublisher) publish(channel, event, value string, data any, now time.Time) bool {
+	p.mu.Lock()
+	last, ok := p.last[channel]
+	if ok && (last.value == value || now.Sub(last.at) < p.interval) {
+		p.mu.Unlock()
+		return false
+	}
+	p.last[channel] = pushed{value: value, at: now}
+	p.mu.Unlock()
+
+	p.hub.PublishChannel(channel, event, data)
+	return true
+}
@@ -0,0 +1,46 @@
+package market_task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	dbBackend "github.com/roothash-pay/wallet-services/database/backend"
+	"github.com/roothash-pay/wallet-services/services/market/model"
+)
+
+type recordingHub struct {
+	channels []string
+}
+
+func (h *recordingHub) PublishChannel(channel string, event string, data any) {
+	h.channels = append(h.channels, channel)
+}
+
+func TestMarketPublisher(t *testing.T) {
+	hub := &recordingHub{}
+	p := newMarketPublisher(hub, time.Second)
+	now := time.Now()
+
+	quotes := map[string]model.Quote{
+		"ETH":     {BaseAsset: "ETH", Price: 3000},
+		"token-1": {BaseAsset: "USDC", AssetID: "token-1", Price: 1},
+	}
+	assert.Equal(t, 2, p.publishPrices(quotes, now))
+	assert.ElementsMatch(t, []string{"price:ETH", "price:token:token-1"}, hub.channels)
+
+	// 变化但在限流间隔内：不推送；间隔过后推送最新值
+	quotes["ETH"] = model.Quote{BaseAsset: "ETH", Price: 3001}
+	assert.Equal(t, 0, p.publishPrices(quotes, now.Add(500*time.Millisecond)))
+	assert.Equal(t, 1, p.publishPrices(quotes, now.Add(2*time.Second)))
+
+	// 价格未变化：不推送
+	assert.Equal(t, 0, p.publishPrices(quotes, now.Add(time.Minute)))
+
+	openTime := now.Truncate(time.Minute)
+	k := &dbBackend.Kline{TokenID: "token-1", TimeInterval: "1m", OpenTime: openTime, OpenPrice: "1", HighPrice: "1", LowPrice: "1", ClosePrice: "1"}
+	assert.Equal(t, 1, p.publishKlines([]*dbBackend.Kline{k}, now))
+	assert.Equal(t, "kline:token-1:1m", hub.channels[len(hub.channels)-1])
+	assert.Equal(t, 0, p.publishKlines([]*dbBackend.Kline{k}, now.Add(time.Minute)))
+}